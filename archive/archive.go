@@ -0,0 +1,653 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// specialBits are the setuid, setgid and sticky bits of a tar header's mode.
+const specialBits = 04000 | 02000 | 01000
+
+// xattrRecordPrefix prefixes the PAX records holding extended attributes,
+// as GNU tar and bsdtar write them.
+const xattrRecordPrefix = "SCHILY.xattr."
+
+const copyBufferSize = 32 * 1024
+
+var copyBuffers = sync.Pool{
+	New: func() interface{} {
+		return make([]byte, copyBufferSize)
+	},
+}
+
+type SymlinkPolicy int
+
+const (
+	// PreserveSymlinks archives symlinks as links.
+	PreserveSymlinks SymlinkPolicy = iota
+
+	// FollowSymlinks archives the contents of the file a symlink points to.
+	// Symlinks to directories are always preserved as links.
+	FollowSymlinks
+)
+
+// SpecialFilePolicy says what to do with FIFOs, sockets and devices.
+type SpecialFilePolicy int
+
+const (
+	// SkipSpecialFiles leaves special files out of the archive.
+	SkipSpecialFiles SpecialFilePolicy = iota
+
+	// PreserveSpecialFiles archives FIFOs and devices as such. Sockets can't
+	// be archived, so they're always left out.
+	PreserveSpecialFiles
+
+	// FailOnSpecialFiles refuses to archive a directory containing special
+	// files.
+	FailOnSpecialFiles
+)
+
+type Options struct {
+	// Files limits the archive to the given paths, relative to the source
+	// directory. If empty, the whole directory is archived.
+	Files []string
+
+	// Exclude skips any path whose slash-separated relative path or base
+	// name matches one of these patterns. Excluded directories are skipped
+	// entirely.
+	Exclude []string
+
+	// ExcludePaths skips these slash-separated relative paths, and anything
+	// under them.
+	ExcludePaths []string
+
+	Symlinks SymlinkPolicy
+
+	// CompressionLevel is the gzip level to compress with. Zero means
+	// gzip.DefaultCompression.
+	CompressionLevel int
+
+	// Parallelism is the number of directories read concurrently while
+	// walking src. Zero means the number of CPUs.
+	Parallelism int
+
+	// Tee, if set, is written a copy of the compressed archive as it is
+	// uploaded.
+	Tee io.Writer
+
+	// Xattrs archives the extended attributes of files and directories.
+	// They're only read on Linux.
+	Xattrs bool
+
+	// SubSecondMtimes keeps modification times to the nanosecond rather
+	// than truncating them to the second.
+	SubSecondMtimes bool
+
+	SpecialFiles SpecialFilePolicy
+
+	// SkipUnreadable leaves out files and directories that can't be read
+	// for lack of permission, rather than failing.
+	SkipUnreadable bool
+
+	// StripSpecialBits drops the setuid, setgid and sticky bits.
+	StripSpecialBits bool
+
+	// Skipped, if set, is called with everything left out of the archive by
+	// SpecialFiles or SkipUnreadable, and why, in the order they are walked.
+	Skipped func(relPath string, reason error)
+
+	// Progress, if set, is called with how many bytes of the files' contents
+	// have been archived so far, out of the total found by walking src: once
+	// with none before anything's archived, and then as they're written.
+	Progress func(archived int64, total int64)
+}
+
+func (opts Options) skip(relPath string, reason error) {
+	if opts.Skipped != nil {
+		opts.Skipped(relPath, reason)
+	}
+}
+
+// Compress writes a gzipped tarball of the src directory to dst.
+//
+// The tarball is in PAX format, so paths and link targets of any length are
+// kept intact.
+//
+// Unless a compression level is given, if most of the bytes to archive are
+// in files that are already compressed (images, video, archives, ...), the
+// stream is only Huffman-encoded, as deflating them again costs a lot of CPU
+// for next to no gain.
+func Compress(dst io.Writer, src string, opts Options) error {
+	level := opts.CompressionLevel
+
+	// picking the level, or reporting progress out of a total, takes
+	// knowing everything to archive up front; otherwise it's archived as
+	// it's found
+	if level != 0 && opts.Progress == nil {
+		tarball, err := newTarball(dst, level, opts)
+		if err != nil {
+			return err
+		}
+
+		err = walk(src, opts, tarball.write)
+		if err != nil {
+			return err
+		}
+
+		return tarball.Close()
+	}
+
+	var entries []entry
+	err := walk(src, opts, func(e entry) error {
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if level == 0 {
+		if mostlyCompressed(entries) {
+			level = gzip.HuffmanOnly
+		} else {
+			level = gzip.DefaultCompression
+		}
+	}
+
+	tarball, err := newTarball(dst, level, opts)
+	if err != nil {
+		return err
+	}
+
+	if opts.Progress != nil {
+		progress := &progressWriter{
+			dst:    tarball.tarWriter,
+			total:  contentSize(entries),
+			report: opts.Progress,
+		}
+
+		progress.report(0, progress.total)
+		tarball.contents = progress
+	}
+
+	for _, entry := range entries {
+		err := tarball.write(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tarball.Close()
+}
+
+// tarball writes entries to a gzipped tarball.
+type tarball struct {
+	gzWriter  *gzip.Writer
+	tarWriter *tar.Writer
+	contents  io.Writer
+	opts      Options
+
+	// files hard linked to one another are archived once, with the rest as
+	// links to the first; entries are walked in order, so that one is
+	// always extracted before its links
+	links map[fileKey]string
+}
+
+func newTarball(dst io.Writer, level int, opts Options) (*tarball, error) {
+	gzWriter, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return nil, err
+	}
+
+	tarWriter := tar.NewWriter(gzWriter)
+
+	return &tarball{
+		gzWriter:  gzWriter,
+		tarWriter: tarWriter,
+		contents:  tarWriter,
+		opts:      opts,
+		links:     map[fileKey]string{},
+	}, nil
+}
+
+func (tarball *tarball) write(e entry) error {
+	return writeEntry(tarball.tarWriter, tarball.contents, e.path, e.relPath, e.info, tarball.opts, tarball.links)
+}
+
+func (tarball *tarball) Close() error {
+	err := tarball.tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return tarball.gzWriter.Close()
+}
+
+// Extract unpacks a gzipped tarball read from src into the dst directory,
+// creating it if needed. Entries that would land outside of dst, or links
+// pointing outside of it, are rejected.
+func Extract(src io.Reader, dst string) error {
+	gzReader, err := gzip.NewReader(src)
+	if err != nil {
+		return err
+	}
+
+	defer gzReader.Close()
+
+	return ExtractTar(gzReader, dst)
+}
+
+// ExtractTar is like Extract, but reads an uncompressed tar stream.
+func ExtractTar(src io.Reader, dst string) error {
+	err := os.MkdirAll(dst, 0755)
+	if err != nil {
+		return err
+	}
+
+	tarReader := tar.NewReader(src)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = extractEntry(tarReader, header, dst)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (opts Options) excludes(relPath string) bool {
+	for _, excluded := range opts.ExcludePaths {
+		if relPath == excluded || strings.HasPrefix(relPath, excluded+"/") {
+			return true
+		}
+	}
+
+	for _, pattern := range opts.Exclude {
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return true
+		}
+
+		if matched, _ := path.Match(pattern, path.Base(relPath)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fileKey identifies a file on disk regardless of the paths linked to it.
+type fileKey struct {
+	dev uint64
+	ino uint64
+}
+
+// writeEntry writes an entry's header to tarWriter and a file's contents to
+// contents, which writes them through to tarWriter.
+func writeEntry(tarWriter *tar.Writer, contents io.Writer, filePath string, relPath string, info os.FileInfo, opts Options, links map[fileKey]string) error {
+	var linkTarget string
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Stat(filePath)
+		if opts.Symlinks == FollowSymlinks && err == nil && !target.IsDir() {
+			info = target
+		} else {
+			linkTarget, err = os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+
+			// links are extracted on Linux, so must point there with slashes
+			linkTarget = filepath.ToSlash(linkTarget)
+		}
+	}
+
+	header, err := tar.FileInfoHeader(info, linkTarget)
+	if err != nil {
+		return err
+	}
+
+	header.Name = relPath
+	if info.IsDir() {
+		header.Name += "/"
+	}
+
+	header.Format = tar.FormatPAX
+
+	// access and change times only make archives of the same files differ
+	header.AccessTime = time.Time{}
+	header.ChangeTime = time.Time{}
+
+	if !opts.SubSecondMtimes {
+		header.ModTime = header.ModTime.Truncate(time.Second)
+	}
+
+	if opts.Xattrs && (info.IsDir() || info.Mode().IsRegular()) {
+		xattrs, err := readXattrs(filePath)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range xattrs {
+			if header.PAXRecords == nil {
+				header.PAXRecords = map[string]string{}
+			}
+
+			header.PAXRecords[xattrRecordPrefix+name] = value
+		}
+	}
+
+	if opts.StripSpecialBits {
+		header.Mode &^= specialBits
+	}
+
+	if !info.Mode().IsRegular() {
+		return tarWriter.WriteHeader(header)
+	}
+
+	key, linked := hardlinkKey(info)
+	if linked {
+		if first, found := links[key]; found {
+			header.Typeflag = tar.TypeLink
+			header.Linkname = first
+			header.Size = 0
+
+			return tarWriter.WriteHeader(header)
+		}
+	}
+
+	// the file's opened before its header's written, so that it can still
+	// be left out if it can't be read
+	file, err := os.Open(filePath)
+	if err != nil {
+		if opts.SkipUnreadable && os.IsPermission(err) {
+			opts.skip(relPath, errUnreadable)
+			return nil
+		}
+
+		return err
+	}
+
+	defer file.Close()
+
+	if linked {
+		links[key] = relPath
+	}
+
+	err = tarWriter.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+
+	buffer := copyBuffers.Get().([]byte)
+	defer copyBuffers.Put(buffer)
+
+	_, err = io.CopyBuffer(contents, file, buffer)
+	return err
+}
+
+// contentSize is the size of the regular files among entries. Hard links are
+// counted every time, so it may be more than is archived.
+func contentSize(entries []entry) int64 {
+	var total int64
+	for _, entry := range entries {
+		if entry.info.Mode().IsRegular() {
+			total += entry.info.Size()
+		}
+	}
+
+	return total
+}
+
+type progressWriter struct {
+	dst      io.Writer
+	archived int64
+	total    int64
+	report   func(archived int64, total int64)
+}
+
+func (writer *progressWriter) Write(p []byte) (int, error) {
+	n, err := writer.dst.Write(p)
+	writer.archived += int64(n)
+	writer.report(writer.archived, writer.total)
+	return n, err
+}
+
+func extractEntry(tarReader *tar.Reader, header *tar.Header, dst string) error {
+	filePath, err := securePath(dst, header.Name)
+	if err != nil {
+		return err
+	}
+
+	mode := header.FileInfo().Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		err := secureDir(dst, filePath, header.Name)
+		if err != nil {
+			return err
+		}
+
+		err = os.MkdirAll(filePath, mode|0700)
+		if err != nil {
+			return err
+		}
+
+		err = restoreSpecialBits(filePath, mode|0700)
+		if err != nil {
+			return err
+		}
+
+		return writeXattrs(filePath, headerXattrs(header))
+
+	case tar.TypeReg, tar.TypeRegA:
+		err := makeParent(dst, filePath, header.Name)
+		if err != nil {
+			return err
+		}
+
+		// whatever was there, e.g. a symlink extracted earlier, is replaced
+		// rather than written through
+		err = removeExisting(filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(file, tarReader)
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		err = file.Close()
+		if err != nil {
+			return err
+		}
+
+		err = restoreSpecialBits(filePath, mode)
+		if err != nil {
+			return err
+		}
+
+		err = writeXattrs(filePath, headerXattrs(header))
+		if err != nil {
+			return err
+		}
+
+		if header.ModTime.IsZero() {
+			return nil
+		}
+
+		return os.Chtimes(filePath, header.ModTime, header.ModTime)
+
+	case tar.TypeSymlink:
+		target := filepath.FromSlash(header.Linkname)
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(filePath), target)
+		}
+
+		if !within(dst, target) {
+			return fmt.Errorf("illegal link in archive: %s -> %s", header.Name, header.Linkname)
+		}
+
+		err := makeParent(dst, filePath, header.Name)
+		if err != nil {
+			return err
+		}
+
+		err = removeExisting(filePath)
+		if err != nil {
+			return err
+		}
+
+		return os.Symlink(header.Linkname, filePath)
+
+	case tar.TypeLink:
+		linkPath, err := securePath(dst, header.Linkname)
+		if err != nil {
+			return err
+		}
+
+		err = secureDir(dst, filepath.Dir(linkPath), header.Linkname)
+		if err != nil {
+			return err
+		}
+
+		err = makeParent(dst, filePath, header.Name)
+		if err != nil {
+			return err
+		}
+
+		err = removeExisting(filePath)
+		if err != nil {
+			return err
+		}
+
+		return os.Link(linkPath, filePath)
+	}
+
+	return nil
+}
+
+// makeParent creates the directory an entry is extracted to, once sure it
+// is within dst.
+func makeParent(dst string, filePath string, name string) error {
+	dir := filepath.Dir(filePath)
+
+	err := secureDir(dst, dir, name)
+	if err != nil {
+		return err
+	}
+
+	return os.MkdirAll(dir, 0755)
+}
+
+// secureDir refuses to extract to dir if it, or as much of it as exists,
+// resolves outside of dst, e.g. through a symlink to the user's home
+// directory extracted before a file under it.
+func secureDir(dst string, dir string, name string) error {
+	root, err := filepath.EvalSymlinks(dst)
+	if err != nil {
+		return err
+	}
+
+	for existing := dir; ; existing = filepath.Dir(existing) {
+		resolved, err := filepath.EvalSymlinks(existing)
+		if err == nil {
+			if !within(root, resolved) {
+				return fmt.Errorf("illegal path in archive: %s", name)
+			}
+
+			return nil
+		}
+
+		if !os.IsNotExist(err) {
+			return err
+		}
+
+		if filepath.Dir(existing) == existing {
+			return nil
+		}
+	}
+}
+
+func removeExisting(filePath string) error {
+	_, err := os.Lstat(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return os.Remove(filePath)
+}
+
+// restoreSpecialBits sets the setuid, setgid and sticky bits if mode has
+// any, as creating the file with them isn't guaranteed to keep them.
+func restoreSpecialBits(filePath string, mode os.FileMode) error {
+	if mode&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) == 0 {
+		return nil
+	}
+
+	return os.Chmod(filePath, mode)
+}
+
+func headerXattrs(header *tar.Header) map[string]string {
+	var xattrs map[string]string
+	for key, value := range header.PAXRecords {
+		if !strings.HasPrefix(key, xattrRecordPrefix) {
+			continue
+		}
+
+		if xattrs == nil {
+			xattrs = map[string]string{}
+		}
+
+		xattrs[strings.TrimPrefix(key, xattrRecordPrefix)] = value
+	}
+
+	return xattrs
+}
+
+func securePath(dst string, name string) (string, error) {
+	filePath := filepath.Join(dst, filepath.FromSlash(name))
+
+	if !within(dst, filePath) {
+		return "", fmt.Errorf("illegal path in archive: %s", name)
+	}
+
+	return filePath, nil
+}
+
+// within says whether filePath is dst or under it, by its path alone.
+func within(dst string, filePath string) bool {
+	cleanDst, err := filepath.Abs(dst)
+	if err != nil {
+		return false
+	}
+
+	filePath, err = filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+
+	return filePath == cleanDst || strings.HasPrefix(filePath, strings.TrimSuffix(cleanDst, string(filepath.Separator))+string(filepath.Separator))
+}
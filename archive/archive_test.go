@@ -0,0 +1,567 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archive", func() {
+	var (
+		srcDir string
+		dstDir string
+
+		opts   archive.Options
+		buffer *bytes.Buffer
+	)
+
+	writeFile := func(path string, contents string, mode os.FileMode) {
+		fullPath := filepath.Join(srcDir, path)
+
+		err := os.MkdirAll(filepath.Dir(fullPath), 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(fullPath, []byte(contents), mode)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+
+		srcDir, err = ioutil.TempDir("", "archive-src")
+		Expect(err).NotTo(HaveOccurred())
+
+		dstDir, err = ioutil.TempDir("", "archive-dst")
+		Expect(err).NotTo(HaveOccurred())
+
+		opts = archive.Options{}
+		buffer = new(bytes.Buffer)
+
+		writeFile("some-file", "some-contents", 0644)
+		writeFile("some-dir/some-script", "#!/bin/sh", 0755)
+		writeFile("some-dir/some.log", "noise", 0644)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir)
+		os.RemoveAll(dstDir)
+	})
+
+	JustBeforeEach(func() {
+		err := archive.Compress(buffer, srcDir, opts)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("round-trips the directory", func() {
+		err := archive.Extract(buffer, dstDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		contents, err := ioutil.ReadFile(filepath.Join(dstDir, "some-file"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("some-contents"))
+
+		contents, err = ioutil.ReadFile(filepath.Join(dstDir, "some-dir", "some-script"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal("#!/bin/sh"))
+	})
+
+	It("preserves file modes", func() {
+		if runtime.GOOS == "windows" {
+			Skip("file modes are not meaningful on Windows")
+		}
+
+		err := archive.Extract(buffer, dstDir)
+		Expect(err).NotTo(HaveOccurred())
+
+		info, err := os.Stat(filepath.Join(dstDir, "some-dir", "some-script"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+	})
+
+	Context("with exclude patterns", func() {
+		BeforeEach(func() {
+			opts.Exclude = []string{"*.log"}
+		})
+
+		It("skips matching paths", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-dir", "some-script")).To(BeAnExistingFile())
+			Expect(filepath.Join(dstDir, "some-dir", "some.log")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("with excluded paths", func() {
+		BeforeEach(func() {
+			opts.ExcludePaths = []string{"some-dir"}
+		})
+
+		It("skips them and everything under them", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-file")).To(BeAnExistingFile())
+			Expect(filepath.Join(dstDir, "some-dir")).NotTo(BeADirectory())
+		})
+	})
+
+	Context("with an explicit file list", func() {
+		BeforeEach(func() {
+			opts.Files = []string{"some-dir/some-script"}
+		})
+
+		It("only archives the given files", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-dir", "some-script")).To(BeAnExistingFile())
+			Expect(filepath.Join(dstDir, "some-file")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("with a progress callback", func() {
+		var reports [][2]int64
+
+		BeforeEach(func() {
+			reports = nil
+			opts.Exclude = []string{"*.log"}
+			opts.Progress = func(archived int64, total int64) {
+				reports = append(reports, [2]int64{archived, total})
+			}
+		})
+
+		It("reports the contents archived out of the size of the files to archive", func() {
+			Expect(reports).NotTo(BeEmpty())
+			Expect(reports[0]).To(Equal([2]int64{0, 22}))
+			Expect(reports[len(reports)-1]).To(Equal([2]int64{22, 22}))
+		})
+	})
+
+	Context("with symlinks", func() {
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("symlinks require special privileges on Windows")
+			}
+
+			err := os.Symlink("some-file", filepath.Join(srcDir, "some-link"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("preserves them as links by default", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			target, err := os.Readlink(filepath.Join(dstDir, "some-link"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target).To(Equal("some-file"))
+		})
+
+		Context("when following symlinks", func() {
+			BeforeEach(func() {
+				opts.Symlinks = archive.FollowSymlinks
+			})
+
+			It("archives the contents of the target", func() {
+				err := archive.Extract(buffer, dstDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				info, err := os.Lstat(filepath.Join(dstDir, "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().IsRegular()).To(BeTrue())
+
+				contents, err := ioutil.ReadFile(filepath.Join(dstDir, "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("some-contents"))
+			})
+		})
+	})
+
+	Context("with hard links", func() {
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("hard links are archived as copies on Windows")
+			}
+
+			writeFile("store/big-file", strings.Repeat("x", 1024*1024), 0644)
+
+			err := os.Link(filepath.Join(srcDir, "store", "big-file"), filepath.Join(srcDir, "some-dir", "linked-file"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("archives the contents once, with the other paths as links", func() {
+			gzReader, err := gzip.NewReader(bytes.NewReader(buffer.Bytes()))
+			Expect(err).NotTo(HaveOccurred())
+
+			headers := map[string]*tar.Header{}
+
+			tarReader := tar.NewReader(gzReader)
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+
+				headers[header.Name] = header
+			}
+
+			Expect(headers["some-dir/linked-file"].Typeflag).To(Equal(byte(tar.TypeReg)))
+			Expect(headers["store/big-file"].Typeflag).To(Equal(byte(tar.TypeLink)))
+			Expect(headers["store/big-file"].Linkname).To(Equal("some-dir/linked-file"))
+		})
+
+		It("extracts them as hard links", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(dstDir, "store", "big-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(contents).To(HaveLen(1024 * 1024))
+
+			original, err := os.Stat(filepath.Join(dstDir, "some-dir", "linked-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			linked, err := os.Stat(filepath.Join(dstDir, "store", "big-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(os.SameFile(original, linked)).To(BeTrue())
+		})
+	})
+
+	Context("with a wide and deep tree", func() {
+		BeforeEach(func() {
+			for i := 0; i < 20; i++ {
+				writeFile(filepath.Join("wide", string('a'+rune(i)), "deep", "deeper", "some-file"), "contents", 0644)
+			}
+		})
+
+		It("archives identically regardless of parallelism", func() {
+			serial := new(bytes.Buffer)
+			err := archive.Compress(serial, srcDir, archive.Options{Parallelism: 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			parallel := new(bytes.Buffer)
+			err = archive.Compress(parallel, srcDir, archive.Options{Parallelism: 8})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(parallel.Bytes()).To(Equal(serial.Bytes()))
+		})
+
+		It("archives identically regardless of parallelism when archiving as it walks", func() {
+			serial := new(bytes.Buffer)
+			err := archive.Compress(serial, srcDir, archive.Options{Parallelism: 1, CompressionLevel: gzip.BestSpeed})
+			Expect(err).NotTo(HaveOccurred())
+
+			parallel := new(bytes.Buffer)
+			err = archive.Compress(parallel, srcDir, archive.Options{Parallelism: 8, CompressionLevel: gzip.BestSpeed})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(parallel.Bytes()).To(Equal(serial.Bytes()))
+		})
+
+		It("archives every directory before its contents", func() {
+			gzReader, err := gzip.NewReader(buffer)
+			Expect(err).NotTo(HaveOccurred())
+
+			seen := map[string]bool{}
+
+			tarReader := tar.NewReader(gzReader)
+			for {
+				header, err := tarReader.Next()
+				if err != nil {
+					break
+				}
+
+				parent := filepath.Dir(filepath.Clean(header.Name))
+				if parent != "." {
+					Expect(seen).To(HaveKey(parent + "/"))
+				}
+
+				seen[header.Name] = true
+			}
+
+			Expect(seen).To(HaveKey("wide/t/deep/deeper/some-file"))
+		})
+	})
+
+	Context("with paths too long for the USTAR format", func() {
+		var longPath string
+
+		BeforeEach(func() {
+			longPath = strings.Repeat("node_modules/some-package/", 12) + "index.js"
+			writeFile(longPath, "module.exports = {}", 0644)
+		})
+
+		It("keeps them intact", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(dstDir, filepath.FromSlash(longPath)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("module.exports = {}"))
+		})
+	})
+
+	Context("with an empty directory", func() {
+		BeforeEach(func() {
+			err := os.MkdirAll(filepath.Join(srcDir, "some-empty-dir"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps it", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-empty-dir")).To(BeADirectory())
+		})
+	})
+
+	Context("with unicode file names", func() {
+		BeforeEach(func() {
+			writeFile("résumé/日本語.txt", "konnichiwa", 0644)
+		})
+
+		It("keeps them byte for byte", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(dstDir, "résumé", "日本語.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("konnichiwa"))
+		})
+	})
+
+	Context("with setuid and sticky bits", func() {
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("windows has no setuid or sticky bits")
+			}
+
+			err := os.Chmod(filepath.Join(srcDir, "some-dir", "some-script"), 0755|os.ModeSetuid)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = os.Mkdir(filepath.Join(srcDir, "some-sticky-dir"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = os.Chmod(filepath.Join(srcDir, "some-sticky-dir"), 0777|os.ModeSticky)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		modeOf := func(path string) os.FileMode {
+			info, err := os.Stat(filepath.Join(dstDir, path))
+			Expect(err).NotTo(HaveOccurred())
+
+			return info.Mode()
+		}
+
+		It("keeps them by default", func() {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(modeOf("some-dir/some-script") & os.ModeSetuid).NotTo(BeZero())
+			Expect(modeOf("some-sticky-dir") & os.ModeSticky).NotTo(BeZero())
+		})
+
+		Context("when stripping them", func() {
+			BeforeEach(func() {
+				opts.StripSpecialBits = true
+			})
+
+			It("leaves them out", func() {
+				err := archive.Extract(buffer, dstDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(modeOf("some-dir/some-script") & os.ModeSetuid).To(BeZero())
+				Expect(modeOf("some-sticky-dir") & os.ModeSticky).To(BeZero())
+			})
+		})
+	})
+
+	Describe("modification times", func() {
+		mtime := time.Unix(1500000000, 123456789)
+
+		BeforeEach(func() {
+			err := os.Chtimes(filepath.Join(srcDir, "some-file"), mtime, mtime)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		extractedMtime := func() time.Time {
+			err := archive.Extract(buffer, dstDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			info, err := os.Stat(filepath.Join(dstDir, "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+
+			return info.ModTime()
+		}
+
+		It("keeps them to the second by default", func() {
+			Expect(extractedMtime().UnixNano()).To(Equal(mtime.Truncate(time.Second).UnixNano()))
+		})
+
+		Context("when keeping sub-second mtimes", func() {
+			BeforeEach(func() {
+				opts.SubSecondMtimes = true
+			})
+
+			It("keeps them to the nanosecond", func() {
+				Expect(extractedMtime().UnixNano()).To(Equal(mtime.UnixNano()))
+			})
+		})
+	})
+
+	Describe("already-compressed payloads", func() {
+		// a megabyte of zeroes deflates to almost nothing, but only
+		// Huffman-encoding it still takes a bit per byte
+		zeroes := string(make([]byte, 1024*1024))
+
+		Context("when most of the payload is in compressed formats", func() {
+			BeforeEach(func() {
+				writeFile("some-archive.zip", zeroes, 0644)
+			})
+
+			It("does not spend time deflating it", func() {
+				Expect(buffer.Len()).To(BeNumerically(">", 100*1024))
+			})
+
+			It("still round-trips", func() {
+				err := archive.Extract(buffer, dstDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(dstDir, "some-archive.zip"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal(zeroes))
+			})
+
+			Context("when a compression level is given", func() {
+				BeforeEach(func() {
+					opts.CompressionLevel = gzip.BestCompression
+				})
+
+				It("uses it", func() {
+					Expect(buffer.Len()).To(BeNumerically("<", 10*1024))
+				})
+			})
+		})
+
+		Context("when most of the payload is compressible", func() {
+			BeforeEach(func() {
+				writeFile("some-file.txt", zeroes, 0644)
+			})
+
+			It("deflates it", func() {
+				Expect(buffer.Len()).To(BeNumerically("<", 10*1024))
+			})
+		})
+	})
+
+	Describe("extracting a malicious archive", func() {
+		It("refuses entries outside of the destination", func() {
+			evil := new(bytes.Buffer)
+			gzWriter := gzip.NewWriter(evil)
+			tarWriter := tar.NewWriter(gzWriter)
+
+			err := tarWriter.WriteHeader(&tar.Header{
+				Name:     "../escaped",
+				Mode:     0644,
+				Typeflag: tar.TypeReg,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tarWriter.Close()).To(Succeed())
+			Expect(gzWriter.Close()).To(Succeed())
+
+			err = archive.Extract(evil, dstDir)
+			Expect(err).To(MatchError("illegal path in archive: ../escaped"))
+		})
+
+		Context("with links", func() {
+			var outsideDir string
+
+			BeforeEach(func() {
+				if runtime.GOOS == "windows" {
+					Skip("symlinks require special privileges on Windows")
+				}
+
+				var err error
+				outsideDir, err = ioutil.TempDir("", "outside")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(outsideDir)
+			})
+
+			evilArchive := func(headers ...*tar.Header) *bytes.Buffer {
+				evil := new(bytes.Buffer)
+				gzWriter := gzip.NewWriter(evil)
+				tarWriter := tar.NewWriter(gzWriter)
+
+				for _, header := range headers {
+					err := tarWriter.WriteHeader(header)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = tarWriter.Write(make([]byte, header.Size))
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				Expect(tarWriter.Close()).To(Succeed())
+				Expect(gzWriter.Close()).To(Succeed())
+
+				return evil
+			}
+
+			It("refuses symlinks pointing outside of the destination", func() {
+				err := archive.Extract(evilArchive(
+					&tar.Header{Name: "home", Linkname: outsideDir, Typeflag: tar.TypeSymlink},
+					&tar.Header{Name: "home/.bashrc", Mode: 0644, Size: 3, Typeflag: tar.TypeReg},
+				), dstDir)
+				Expect(err).To(MatchError("illegal link in archive: home -> " + outsideDir))
+
+				Expect(filepath.Join(outsideDir, ".bashrc")).NotTo(BeAnExistingFile())
+			})
+
+			It("refuses entries under a symlink already pointing outside of the destination", func() {
+				err := os.Symlink(outsideDir, filepath.Join(dstDir, "home"))
+				Expect(err).NotTo(HaveOccurred())
+
+				err = archive.Extract(evilArchive(
+					&tar.Header{Name: "home/.bashrc", Mode: 0644, Size: 3, Typeflag: tar.TypeReg},
+				), dstDir)
+				Expect(err).To(MatchError("illegal path in archive: home/.bashrc"))
+
+				Expect(filepath.Join(outsideDir, ".bashrc")).NotTo(BeAnExistingFile())
+			})
+
+			It("replaces a symlink with a file of the same name rather than writing through it", func() {
+				err := archive.Extract(evilArchive(
+					&tar.Header{Name: "some-file", Mode: 0644, Size: 3, Typeflag: tar.TypeReg},
+					&tar.Header{Name: "some-link", Linkname: "some-file", Typeflag: tar.TypeSymlink},
+					&tar.Header{Name: "some-link", Mode: 0644, Size: 5, Typeflag: tar.TypeReg},
+				), dstDir)
+				Expect(err).NotTo(HaveOccurred())
+
+				info, err := os.Lstat(filepath.Join(dstDir, "some-link"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().IsRegular()).To(BeTrue())
+
+				info, err = os.Stat(filepath.Join(dstDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Size()).To(Equal(int64(3)))
+			})
+		})
+	})
+})
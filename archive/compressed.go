@@ -0,0 +1,55 @@
+package archive
+
+import (
+	"path"
+	"strings"
+)
+
+var compressedExtensions = map[string]bool{
+	".7z":   true,
+	".avi":  true,
+	".br":   true,
+	".bz2":  true,
+	".gif":  true,
+	".gz":   true,
+	".jar":  true,
+	".jpeg": true,
+	".jpg":  true,
+	".lz4":  true,
+	".mkv":  true,
+	".mov":  true,
+	".mp3":  true,
+	".mp4":  true,
+	".png":  true,
+	".rar":  true,
+	".tgz":  true,
+	".war":  true,
+	".webm": true,
+	".webp": true,
+	".whl":  true,
+	".xz":   true,
+	".zip":  true,
+	".zst":  true,
+}
+
+func isCompressed(relPath string) bool {
+	return compressedExtensions[strings.ToLower(path.Ext(relPath))]
+}
+
+func mostlyCompressed(entries []entry) bool {
+	var total, compressed int64
+
+	for _, entry := range entries {
+		if !entry.info.Mode().IsRegular() {
+			continue
+		}
+
+		total += entry.info.Size()
+
+		if isCompressed(entry.relPath) {
+			compressed += entry.info.Size()
+		}
+	}
+
+	return total > 0 && compressed*2 > total
+}
@@ -0,0 +1,67 @@
+package archive
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+)
+
+//go:generate counterfeiter . Downloader
+
+type Downloader interface {
+	// Stream returns the archive at url as an uncompressed tar stream, so
+	// that it can be piped elsewhere without touching the disk. The caller
+	// must close it.
+	Stream(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+type httpDownloader struct {
+	client *http.Client
+}
+
+func NewDownloader(client *http.Client) Downloader {
+	return httpDownloader{client: client}
+}
+
+func (downloader httpDownloader) Stream(ctx context.Context, url string) (io.ReadCloser, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := downloader.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+
+		return nil, pipeBroken("downloading bits", response)
+	}
+
+	gzReader, err := gzip.NewReader(response.Body)
+	if err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	return tarStream{
+		Reader: gzReader,
+		gzip:   gzReader,
+		body:   response.Body,
+	}, nil
+}
+
+type tarStream struct {
+	io.Reader
+
+	gzip *gzip.Reader
+	body io.Closer
+}
+
+func (stream tarStream) Close() error {
+	stream.gzip.Close()
+	return stream.body.Close()
+}
@@ -0,0 +1,93 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Downloader", func() {
+	var (
+		server *ghttp.Server
+
+		downloader archive.Downloader
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		downloader = archive.NewDownloader(http.DefaultClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the pipe has an archive", func() {
+		BeforeEach(func() {
+			srcDir, err := ioutil.TempDir("", "downloader-src")
+			Expect(err).NotTo(HaveOccurred())
+
+			defer os.RemoveAll(srcDir)
+
+			err = ioutil.WriteFile(filepath.Join(srcDir, "some-file"), []byte("some-contents"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			compressed := new(bytes.Buffer)
+			err = archive.Compress(compressed, srcDir, archive.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/pipes/some-pipe"),
+					ghttp.RespondWith(http.StatusOK, compressed.Bytes()),
+				),
+			)
+		})
+
+		It("streams it as an uncompressed tar", func() {
+			stream, err := downloader.Stream(context.Background(), server.URL()+"/pipes/some-pipe")
+			Expect(err).NotTo(HaveOccurred())
+
+			defer stream.Close()
+
+			tarReader := tar.NewReader(stream)
+
+			header, err := tarReader.Next()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(header.Name).To(Equal("some-file"))
+
+			contents, err := ioutil.ReadAll(tarReader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+	})
+
+	Context("when the pipe is gone", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/pipes/some-pipe"),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				),
+			)
+		})
+
+		It("returns ErrPipeBroken", func() {
+			_, err := downloader.Stream(context.Background(), server.URL()+"/pipes/some-pipe")
+			Expect(err).To(Equal(archive.ErrPipeBroken{
+				Doing:  "downloading bits",
+				Status: "404 Not Found",
+			}))
+		})
+	})
+})
@@ -0,0 +1,19 @@
+// +build !windows
+
+package archive
+
+import (
+	"os"
+	"syscall"
+)
+
+// hardlinkKey identifies the file behind info if it has other hard links,
+// so that they can be archived as links to it rather than as copies.
+func hardlinkKey(info os.FileInfo) (fileKey, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink < 2 {
+		return fileKey{}, false
+	}
+
+	return fileKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}, true
+}
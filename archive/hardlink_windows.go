@@ -0,0 +1,11 @@
+// +build windows
+
+package archive
+
+import "os"
+
+// hardlinkKey always reports no links on Windows, where files are archived
+// as copies.
+func hardlinkKey(info os.FileInfo) (fileKey, bool) {
+	return fileKey{}, false
+}
@@ -0,0 +1,94 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"path"
+	"time"
+)
+
+// ImageMetadata is the metadata.json of an image artifact, describing how
+// processes are run in it.
+type ImageMetadata struct {
+	Env  []string `json:"env"`
+	User string   `json:"user"`
+}
+
+// CompressImage writes a gzipped tarball of an image artifact, in the form a
+// task can use as its image, to dst: the root filesystem read as a tarball
+// from rootfs, placed under rootfs/, alongside the image's metadata.json.
+func CompressImage(dst io.Writer, rootfs io.Reader, metadata ImageMetadata, level int) error {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	gzWriter, err := gzip.NewWriterLevel(dst, level)
+	if err != nil {
+		return err
+	}
+
+	tarWriter := tar.NewWriter(gzWriter)
+
+	payload, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.WriteHeader(&tar.Header{
+		Name:     "metadata.json",
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(payload)),
+		ModTime:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(payload)
+	if err != nil {
+		return err
+	}
+
+	buf := copyBuffers.Get().([]byte)
+	defer copyBuffers.Put(buf)
+
+	tarReader := tar.NewReader(rootfs)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		header.Name = path.Join("rootfs", header.Name)
+
+		// hard links are relative to the root of the archive, unlike
+		// symlinks which are resolved within the image
+		if header.Typeflag == tar.TypeLink {
+			header.Linkname = path.Join("rootfs", header.Linkname)
+		}
+
+		err = tarWriter.WriteHeader(header)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.CopyBuffer(tarWriter, tarReader, buf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzWriter.Close()
+}
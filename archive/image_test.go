@@ -0,0 +1,91 @@
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CompressImage", func() {
+	var (
+		rootfs  *bytes.Buffer
+		entries map[string]*tar.Header
+		files   map[string]string
+	)
+
+	BeforeEach(func() {
+		rootfs = new(bytes.Buffer)
+
+		tarWriter := tar.NewWriter(rootfs)
+
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "bin/sh", Typeflag: tar.TypeReg, Mode: 0755, Size: 5})).To(Succeed())
+		_, err := tarWriter.Write([]byte("shell"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "bin/bash", Typeflag: tar.TypeLink, Linkname: "bin/sh"})).To(Succeed())
+		Expect(tarWriter.WriteHeader(&tar.Header{Name: "bin/ash", Typeflag: tar.TypeSymlink, Linkname: "/bin/sh"})).To(Succeed())
+
+		Expect(tarWriter.Close()).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		compressed := new(bytes.Buffer)
+
+		err := archive.CompressImage(compressed, rootfs, archive.ImageMetadata{
+			Env:  []string{"PATH=/bin"},
+			User: "nobody",
+		}, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		gzReader, err := gzip.NewReader(compressed)
+		Expect(err).NotTo(HaveOccurred())
+
+		entries = map[string]*tar.Header{}
+		files = map[string]string{}
+
+		tarReader := tar.NewReader(gzReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			Expect(err).NotTo(HaveOccurred())
+
+			contents, err := ioutil.ReadAll(tarReader)
+			Expect(err).NotTo(HaveOccurred())
+
+			entries[header.Name] = header
+			files[header.Name] = string(contents)
+		}
+	})
+
+	It("places the root filesystem under rootfs/", func() {
+		Expect(entries).To(HaveKey("rootfs/bin"))
+		Expect(files["rootfs/bin/sh"]).To(Equal("shell"))
+		Expect(entries["rootfs/bin/sh"].Mode).To(Equal(int64(0755)))
+	})
+
+	It("rewrites hard links to point within rootfs/, leaving symlinks alone", func() {
+		Expect(entries["rootfs/bin/bash"].Linkname).To(Equal("rootfs/bin/sh"))
+		Expect(entries["rootfs/bin/ash"].Linkname).To(Equal("/bin/sh"))
+	})
+
+	It("includes the image's metadata", func() {
+		var metadata archive.ImageMetadata
+		Expect(json.Unmarshal([]byte(files["metadata.json"]), &metadata)).To(Succeed())
+		Expect(metadata).To(Equal(archive.ImageMetadata{
+			Env:  []string{"PATH=/bin"},
+			User: "nobody",
+		}))
+	})
+})
@@ -0,0 +1,153 @@
+// +build !windows
+
+package archive_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Special and unreadable entries", func() {
+	var (
+		srcDir string
+		opts   archive.Options
+
+		skipped []string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		srcDir, err = ioutil.TempDir("", "archive-src")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(srcDir, "some-file"), []byte("some-contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		skipped = nil
+		opts = archive.Options{
+			Skipped: func(relPath string, reason error) {
+				skipped = append(skipped, relPath+": "+reason.Error())
+			},
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir)
+	})
+
+	archivedNames := func() []string {
+		buffer := new(bytes.Buffer)
+
+		err := archive.Compress(buffer, srcDir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		return namesIn(buffer)
+	}
+
+	Context("with FIFOs", func() {
+		BeforeEach(func() {
+			err := syscall.Mkfifo(filepath.Join(srcDir, "some-fifo"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = syscall.Mkfifo(filepath.Join(srcDir, "another-fifo"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("skips them by default, reporting each in order", func() {
+			Expect(archivedNames()).To(Equal([]string{"some-file"}))
+			Expect(skipped).To(Equal([]string{
+				"another-fifo: special file",
+				"some-fifo: special file",
+			}))
+		})
+
+		Context("when preserving special files", func() {
+			BeforeEach(func() {
+				opts.SpecialFiles = archive.PreserveSpecialFiles
+			})
+
+			It("archives them as FIFOs", func() {
+				Expect(archivedNames()).To(Equal([]string{"another-fifo", "some-fifo", "some-file"}))
+				Expect(skipped).To(BeEmpty())
+			})
+		})
+
+		Context("when failing on special files", func() {
+			BeforeEach(func() {
+				opts.SpecialFiles = archive.FailOnSpecialFiles
+			})
+
+			It("returns an error", func() {
+				err := archive.Compress(new(bytes.Buffer), srcDir, opts)
+				Expect(err).To(MatchError(ContainSubstring("is a special file")))
+			})
+		})
+	})
+
+	Context("with unreadable files and directories", func() {
+		BeforeEach(func() {
+			if os.Geteuid() == 0 {
+				Skip("root can read anything")
+			}
+
+			err := ioutil.WriteFile(filepath.Join(srcDir, "some-secret"), []byte("shh"), 0000)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = os.Mkdir(filepath.Join(srcDir, "some-locked-dir"), 0000)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.Chmod(filepath.Join(srcDir, "some-locked-dir"), 0755)
+		})
+
+		It("fails by default", func() {
+			err := archive.Compress(new(bytes.Buffer), srcDir, opts)
+			Expect(os.IsPermission(err)).To(BeTrue())
+		})
+
+		Context("when skipping unreadable entries", func() {
+			BeforeEach(func() {
+				opts.SkipUnreadable = true
+			})
+
+			It("leaves them out, reporting each", func() {
+				Expect(archivedNames()).To(Equal([]string{"some-file", "some-locked-dir/"}))
+				Expect(skipped).To(Equal([]string{
+					"some-locked-dir/: permission denied",
+					"some-secret: permission denied",
+				}))
+			})
+		})
+	})
+})
+
+func namesIn(buffer *bytes.Buffer) []string {
+	gzReader, err := gzip.NewReader(buffer)
+	Expect(err).NotTo(HaveOccurred())
+
+	var names []string
+
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+
+		names = append(names, header.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,101 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Throttle limits the combined rate of every reader it wraps.
+type Throttle struct {
+	lock sync.Mutex
+
+	bytesPerSecond float64
+	burst          int
+
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle returns a Throttle allowing bytesPerSecond bytes per second on
+// average, shared between all of its readers.
+func NewThrottle(bytesPerSecond int64) *Throttle {
+	// allow roughly a tenth of a second's worth of data through at a time so
+	// the transfer is smooth rather than bursty, but no less than one buffer
+	burst := int(bytesPerSecond / 10)
+	if burst < 32*1024 {
+		burst = 32 * 1024
+	}
+
+	return &Throttle{
+		bytesPerSecond: float64(bytesPerSecond),
+		burst:          burst,
+		tokens:         float64(burst),
+		last:           time.Now(),
+	}
+}
+
+// Reader wraps r so that reads from it are held to the throttle's rate. A
+// read blocked on the throttle returns early with ctx.Err() once ctx is done.
+func (throttle *Throttle) Reader(ctx context.Context, r io.Reader) io.Reader {
+	return &throttledReader{ctx: ctx, throttle: throttle, reader: r}
+}
+
+func (throttle *Throttle) wait(ctx context.Context, n int) error {
+	throttle.lock.Lock()
+
+	now := time.Now()
+	throttle.tokens += now.Sub(throttle.last).Seconds() * throttle.bytesPerSecond
+	if throttle.tokens > float64(throttle.burst) {
+		throttle.tokens = float64(throttle.burst)
+	}
+	throttle.last = now
+
+	// spend the tokens up front, even if they're not there yet, so that
+	// concurrent readers queue up behind each other
+	throttle.tokens -= float64(n)
+
+	var wait time.Duration
+	if throttle.tokens < 0 {
+		wait = time.Duration(-throttle.tokens / throttle.bytesPerSecond * float64(time.Second))
+	}
+
+	throttle.lock.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type throttledReader struct {
+	ctx      context.Context
+	throttle *Throttle
+	reader   io.Reader
+}
+
+func (reader *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > reader.throttle.burst {
+		p = p[:reader.throttle.burst]
+	}
+
+	n, err := reader.reader.Read(p)
+	if n > 0 {
+		waitErr := reader.throttle.wait(reader.ctx, n)
+		if waitErr != nil {
+			return n, waitErr
+		}
+	}
+
+	return n, err
+}
@@ -0,0 +1,43 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"time"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Throttle", func() {
+	It("holds reads to the given rate", func() {
+		throttle := archive.NewThrottle(128 * 1024)
+
+		data := bytes.Repeat([]byte("x"), 96*1024)
+
+		start := time.Now()
+
+		read, err := ioutil.ReadAll(throttle.Reader(context.Background(), bytes.NewReader(data)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(read).To(Equal(data))
+
+		// the first 32KB is allowed through immediately; the remaining 64KB
+		// takes half a second at 128KB/s
+		Expect(time.Since(start)).To(BeNumerically(">=", 450*time.Millisecond))
+	})
+
+	It("gives up waiting once the context is done", func() {
+		throttle := archive.NewThrottle(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		reader := throttle.Reader(ctx, bytes.NewReader(bytes.Repeat([]byte("x"), 64*1024)))
+
+		_, err := ioutil.ReadAll(reader)
+		Expect(err).To(Equal(context.Canceled))
+	})
+})
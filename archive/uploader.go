@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DigestHeader carries the digest of an upload, as "sha-256=" and the
+// base64 of its SHA-256. fly sends it as a trailer, once the body is sent;
+// an ATC that answers with it too has its digest checked against fly's.
+// Current ATCs don't answer with it, so against them the check is a no-op.
+const DigestHeader = "Digest"
+
+const maxPipeBrokenBodyLength = 512
+
+// ErrPipeBroken is returned when the other end of a pipe rejects the
+// transfer, e.g. because the build consuming it has gone away. Body is the
+// start of the response, which is often from a proxy rather than the ATC.
+type ErrPipeBroken struct {
+	Doing  string
+	Status string
+	Body   string
+}
+
+func (err ErrPipeBroken) Error() string {
+	if err.Body == "" {
+		return fmt.Sprintf("bad response %s (%s)", err.Doing, err.Status)
+	}
+
+	return fmt.Sprintf("bad response %s (%s): %s", err.Doing, err.Status, err.Body)
+}
+
+func pipeBroken(doing string, response *http.Response) ErrPipeBroken {
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxPipeBrokenBodyLength+1))
+
+	excerpt := strings.TrimSpace(string(body))
+	if len(excerpt) > maxPipeBrokenBodyLength {
+		excerpt = excerpt[:maxPipeBrokenBodyLength] + "..."
+	}
+
+	return ErrPipeBroken{
+		Doing:  doing,
+		Status: response.Status,
+		Body:   excerpt,
+	}
+}
+
+// ErrUploadCorrupted is returned when the ATC received something other than
+// what was sent, e.g. because a proxy cut the upload short.
+type ErrUploadCorrupted struct {
+	Sent     string
+	Received string
+}
+
+func (err ErrUploadCorrupted) Error() string {
+	return fmt.Sprintf("the upload was corrupted on the way to the ATC: fly sent %s, but it received %s; is a proxy cutting uploads short?", err.Sent, err.Received)
+}
+
+//go:generate counterfeiter . Uploader
+
+type Uploader interface {
+	Upload(ctx context.Context, url string, src string, opts Options) error
+	UploadImage(ctx context.Context, url string, rootfs io.Reader, metadata ImageMetadata, opts Options) error
+	UploadArchive(ctx context.Context, url string, archive io.Reader) error
+}
+
+type httpUploader struct {
+	client   *http.Client
+	throttle *Throttle
+}
+
+// NewUploader returns an Uploader that streams the archive of a directory
+// as the body of a PUT request, e.g. to the write end of an ATC pipe.
+func NewUploader(client *http.Client) Uploader {
+	return httpUploader{client: client}
+}
+
+// NewThrottledUploader is like NewUploader, but holds the combined rate of
+// every upload made through it to bytesPerSecond.
+func NewThrottledUploader(client *http.Client, bytesPerSecond int64) Uploader {
+	return httpUploader{
+		client:   client,
+		throttle: NewThrottle(bytesPerSecond),
+	}
+}
+
+func (uploader httpUploader) Upload(ctx context.Context, url string, src string, opts Options) error {
+	return uploader.put(ctx, url, func(w io.Writer) error {
+		if opts.Tee != nil {
+			w = io.MultiWriter(w, opts.Tee)
+		}
+
+		return Compress(w, src, opts)
+	})
+}
+
+// UploadImage streams an image artifact built from a root filesystem
+// tarball, as written by CompressImage.
+func (uploader httpUploader) UploadImage(ctx context.Context, url string, rootfs io.Reader, metadata ImageMetadata, opts Options) error {
+	return uploader.put(ctx, url, func(w io.Writer) error {
+		return CompressImage(w, rootfs, metadata, opts.CompressionLevel)
+	})
+}
+
+// UploadArchive streams an already compressed archive, e.g. one recorded
+// with Options.Tee.
+func (uploader httpUploader) UploadArchive(ctx context.Context, url string, archive io.Reader) error {
+	return uploader.put(ctx, url, func(w io.Writer) error {
+		_, err := io.Copy(w, archive)
+		return err
+	})
+}
+
+func (uploader httpUploader) put(ctx context.Context, url string, compress func(io.Writer) error) error {
+	// the archive is compressed into the request body as it is sent, so only
+	// a few buffers' worth of it is ever held in memory
+	archiveStream, archiveWriter := io.Pipe()
+
+	// if the request fails before the body is fully read, unblock the
+	// compressor so it doesn't hang around holding files open
+	defer archiveStream.Close()
+
+	go func() {
+		archiveWriter.CloseWithError(compress(archiveWriter))
+	}()
+
+	var body io.Reader = archiveStream
+	if uploader.throttle != nil {
+		body = uploader.throttle.Reader(ctx, archiveStream)
+	}
+
+	digest := &digestReader{src: body, hash: sha256.New()}
+
+	request, err := http.NewRequest("PUT", url, digest)
+	if err != nil {
+		return err
+	}
+
+	request.ContentLength = -1
+
+	// the digest is only known once the body is sent, so it's sent after
+	// it; trailers are only sent with a chunked body
+	request.Trailer = http.Header{DigestHeader: nil}
+	digest.trailer = request.Trailer
+
+	response, err := uploader.client.Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return pipeBroken("uploading bits", response)
+	}
+
+	// only checked if the ATC reports what it received, which current ones
+	// don't
+	if received := response.Header.Get(DigestHeader); received != "" {
+		sent := digest.Digest()
+		if sent == "" {
+			sent = "an incomplete upload"
+		}
+
+		if received != sent {
+			return ErrUploadCorrupted{Sent: sent, Received: received}
+		}
+	}
+
+	return nil
+}
+
+// digestReader hashes what's read through it, and sets the digest in the
+// trailer once it's all been read.
+type digestReader struct {
+	src     io.Reader
+	hash    hash.Hash
+	trailer http.Header
+
+	digest string
+}
+
+func (reader *digestReader) Read(p []byte) (int, error) {
+	n, err := reader.src.Read(p)
+	reader.hash.Write(p[:n])
+
+	if err == io.EOF && reader.digest == "" {
+		reader.digest = "sha-256=" + base64.StdEncoding.EncodeToString(reader.hash.Sum(nil))
+		reader.trailer.Set(DigestHeader, reader.digest)
+	}
+
+	return n, err
+}
+
+// Digest returns the digest of everything read, or "" if it hasn't all been
+// read yet.
+func (reader *digestReader) Digest() string {
+	return reader.digest
+}
@@ -0,0 +1,210 @@
+package archive_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Uploader", func() {
+	var (
+		server *ghttp.Server
+		srcDir string
+
+		uploader archive.Uploader
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		var err error
+		srcDir, err = ioutil.TempDir("", "uploader-src")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(srcDir, "some-file"), []byte("some-contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		uploader = archive.NewUploader(http.DefaultClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(srcDir)
+	})
+
+	Context("when the pipe accepts the upload", func() {
+		var dstDir string
+
+		BeforeEach(func() {
+			var err error
+			dstDir, err = ioutil.TempDir("", "uploader-dst")
+			Expect(err).NotTo(HaveOccurred())
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/pipes/some-pipe"),
+					func(w http.ResponseWriter, r *http.Request) {
+						err := archive.Extract(r.Body, dstDir)
+						Expect(err).NotTo(HaveOccurred())
+					},
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dstDir)
+		})
+
+		It("streams the archive as the request body", func() {
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-file")).To(BeAnExistingFile())
+		})
+
+		It("sends the body chunked rather than computing its length up front", func() {
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			request := server.ReceivedRequests()[0]
+			Expect(request.ContentLength).To(Equal(int64(-1)))
+			Expect(request.TransferEncoding).To(Equal([]string{"chunked"}))
+		})
+
+		It("writes a copy of the archive to the tee", func() {
+			tee := new(bytes.Buffer)
+
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{
+				Tee: tee,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			copyDir, err := ioutil.TempDir("", "uploader-copy")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(copyDir)
+
+			err = archive.Extract(tee, copyDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(ioutil.ReadFile(filepath.Join(copyDir, "some-file"))).To(Equal([]byte("some-contents")))
+		})
+
+		It("can upload an archive as-is", func() {
+			compressed := new(bytes.Buffer)
+			err := archive.Compress(compressed, srcDir, archive.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = uploader.UploadArchive(context.Background(), server.URL()+"/pipes/some-pipe", compressed)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(filepath.Join(dstDir, "some-file")).To(BeAnExistingFile())
+		})
+	})
+
+	Context("when the ATC reports the digest of what it received", func() {
+		var (
+			received []byte
+			trailer  string
+			reported func(body []byte) string
+		)
+
+		digestOf := func(body []byte) string {
+			sum := sha256.Sum256(body)
+			return "sha-256=" + base64.StdEncoding.EncodeToString(sum[:])
+		}
+
+		BeforeEach(func() {
+			reported = digestOf
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/pipes/some-pipe"),
+					func(w http.ResponseWriter, r *http.Request) {
+						var err error
+						received, err = ioutil.ReadAll(r.Body)
+						Expect(err).NotTo(HaveOccurred())
+
+						trailer = r.Trailer.Get(archive.DigestHeader)
+
+						w.Header().Set(archive.DigestHeader, reported(received))
+					},
+				),
+			)
+		})
+
+		It("sends the digest of the archive as a trailer", func() {
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(trailer).To(Equal(digestOf(received)))
+		})
+
+		Context("when it doesn't match what was sent", func() {
+			BeforeEach(func() {
+				reported = func(body []byte) string {
+					return digestOf(body[:len(body)/2])
+				}
+			})
+
+			It("returns ErrUploadCorrupted", func() {
+				err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+				Expect(err).To(Equal(archive.ErrUploadCorrupted{
+					Sent:     digestOf(received),
+					Received: digestOf(received[:len(received)/2]),
+				}))
+			})
+		})
+	})
+
+	Context("when the pipe rejects the upload", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/pipes/some-pipe"),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				),
+			)
+		})
+
+		It("returns ErrPipeBroken", func() {
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+			Expect(err).To(Equal(archive.ErrPipeBroken{
+				Doing:  "uploading bits",
+				Status: "404 Not Found",
+			}))
+		})
+	})
+
+	Context("when something in the way rejects the upload", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/pipes/some-pipe"),
+					ghttp.RespondWith(http.StatusRequestEntityTooLarge, "<html>413 Request Entity Too Large</html>\n"+strings.Repeat("x", 1024)),
+				),
+			)
+		})
+
+		It("returns ErrPipeBroken with the start of the response", func() {
+			err := uploader.Upload(context.Background(), server.URL()+"/pipes/some-pipe", srcDir, archive.Options{})
+			Expect(err).To(Equal(archive.ErrPipeBroken{
+				Doing:  "uploading bits",
+				Status: "413 Request Entity Too Large",
+				Body:   ("<html>413 Request Entity Too Large</html>\n" + strings.Repeat("x", 1024))[:512] + "...",
+			}))
+			Expect(err.Error()).To(HavePrefix("bad response uploading bits (413 Request Entity Too Large): <html>413 Request Entity Too Large</html>"))
+		})
+	})
+})
@@ -0,0 +1,289 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+type entry struct {
+	path    string
+	relPath string
+	info    os.FileInfo
+}
+
+var (
+	errUnreadable  = errors.New("permission denied")
+	errSpecialFile = errors.New("special file")
+	errSocket      = errors.New("sockets can't be archived")
+)
+
+const specialModes = os.ModeNamedPipe | os.ModeSocket | os.ModeDevice | os.ModeCharDevice
+
+type byName []os.FileInfo
+
+func (e byName) Len() int           { return len(e) }
+func (e byName) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byName) Less(i, j int) bool { return e[i].Name() < e[j].Name() }
+
+type walker struct {
+	src   string
+	opts  Options
+	visit func(entry) error
+
+	// reads queues directories to be read ahead by a fixed pool of readers
+	reads chan *listing
+
+	// seen is only kept when opts.Files is given, as the paths may overlap
+	seen map[string]bool
+}
+
+// listing is a directory's entries, sorted by name, possibly read ahead of
+// being walked.
+type listing struct {
+	dir    string
+	queued bool
+	done   chan struct{}
+
+	children []os.FileInfo
+	err      error
+}
+
+// walk calls visit with everything to be archived from src, depth first
+// with each directory's entries sorted by name, so that archives are
+// reproducible and every directory precedes its contents. Directories are
+// read ahead by a fixed pool of readers, since on large trees the walk is
+// dominated by waiting on the filesystem. What's left out by the special
+// file and unreadable policies is passed to opts.Skipped as it's found.
+func walk(src string, opts Options, visit func(entry) error) error {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+
+	w := &walker{
+		src:   src,
+		opts:  opts,
+		visit: visit,
+		reads: make(chan *listing, parallelism),
+	}
+
+	for i := 0; i < parallelism; i++ {
+		go func() {
+			for l := range w.reads {
+				l.read()
+			}
+		}()
+	}
+
+	defer close(w.reads)
+
+	files := opts.Files
+	if len(files) == 0 {
+		files = []string{"."}
+	} else {
+		files = append([]string{}, files...)
+		sort.Strings(files)
+
+		w.seen = map[string]bool{}
+	}
+
+	for _, file := range files {
+		rootPath := filepath.Join(src, file)
+
+		if filepath.Clean(file) == "." {
+			// follow the source directory itself if it's a symlink
+			_, err := os.Stat(rootPath)
+			if err != nil {
+				return err
+			}
+
+			err = w.walkDir(rootPath, ".", w.list(rootPath))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		info, err := os.Lstat(rootPath)
+		if err != nil {
+			return err
+		}
+
+		e, ok, err := w.include(rootPath, info)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		var l *listing
+		if info.IsDir() {
+			l = w.list(rootPath)
+		}
+
+		err = w.emit(e, l)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// list returns the listing of dir, queued to be read ahead if a reader will
+// be free for it soon, or else to be read once it's walked.
+func (w *walker) list(dir string) *listing {
+	l := &listing{dir: dir, done: make(chan struct{})}
+
+	select {
+	case w.reads <- l:
+		l.queued = true
+	default:
+	}
+
+	return l
+}
+
+func (l *listing) read() {
+	l.children, l.err = readDir(l.dir)
+	sort.Sort(byName(l.children))
+	close(l.done)
+}
+
+func (l *listing) wait() ([]os.FileInfo, error) {
+	if !l.queued {
+		l.read()
+	}
+
+	<-l.done
+
+	return l.children, l.err
+}
+
+// walkDir visits the entries of a directory in order. Those to be archived
+// are picked first, so that their own directories can be read ahead while
+// the ones before them are walked.
+func (w *walker) walkDir(dirPath string, relPath string, l *listing) error {
+	children, err := l.wait()
+	if err != nil {
+		if w.opts.SkipUnreadable && os.IsPermission(err) && relPath != "." {
+			// the directory itself is still archived, empty
+			w.opts.skip(relPath+"/", errUnreadable)
+			return nil
+		}
+
+		return err
+	}
+
+	var entries []entry
+	var listings []*listing
+	for _, child := range children {
+		e, ok, err := w.include(filepath.Join(dirPath, child.Name()), child)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		var sub *listing
+		if child.IsDir() {
+			sub = w.list(e.path)
+		}
+
+		entries = append(entries, e)
+		listings = append(listings, sub)
+	}
+
+	for i, e := range entries {
+		err := w.emit(e, listings[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// emit visits an entry, and then the directory's contents if it is one.
+func (w *walker) emit(e entry, l *listing) error {
+	if w.seen != nil {
+		if w.seen[e.relPath] {
+			return nil
+		}
+
+		w.seen[e.relPath] = true
+	}
+
+	err := w.visit(e)
+	if err != nil {
+		return err
+	}
+
+	if l == nil {
+		return nil
+	}
+
+	return w.walkDir(e.path, e.relPath, l)
+}
+
+// include says whether a file is to be archived, and as what entry.
+func (w *walker) include(filePath string, info os.FileInfo) (entry, bool, error) {
+	relPath, err := filepath.Rel(w.src, filePath)
+	if err != nil {
+		return entry{}, false, err
+	}
+
+	relPath = filepath.ToSlash(relPath)
+
+	if w.opts.excludes(relPath) {
+		return entry{}, false, nil
+	}
+
+	if info.Mode()&specialModes != 0 {
+		ok, err := w.special(relPath, info)
+		if !ok || err != nil {
+			return entry{}, false, err
+		}
+	}
+
+	return entry{path: filePath, relPath: relPath, info: info}, true, nil
+}
+
+// special says whether a FIFO, socket or device is to be archived.
+func (w *walker) special(relPath string, info os.FileInfo) (bool, error) {
+	if info.Mode()&os.ModeSocket != 0 {
+		w.opts.skip(relPath, errSocket)
+		return false, nil
+	}
+
+	switch w.opts.SpecialFiles {
+	case PreserveSpecialFiles:
+		return true, nil
+
+	case FailOnSpecialFiles:
+		return false, fmt.Errorf("%s is a special file (%s)", relPath, info.Mode())
+	}
+
+	w.opts.skip(relPath, errSpecialFile)
+	return false, nil
+}
+
+func readDir(dir string) ([]os.FileInfo, error) {
+	file, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	return file.Readdir(-1)
+}
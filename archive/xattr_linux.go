@@ -0,0 +1,65 @@
+// +build linux
+
+package archive
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// readXattrs returns the extended attributes of the file at path.
+func readXattrs(path string) (map[string]string, error) {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size == 0 {
+		return nil, ignoreUnsupported(err)
+	}
+
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil, ignoreUnsupported(err)
+	}
+
+	xattrs := map[string]string{}
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) == 0 {
+			continue
+		}
+
+		valueSize, err := syscall.Getxattr(path, string(name), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		value := make([]byte, valueSize)
+		valueSize, err = syscall.Getxattr(path, string(name), value)
+		if err != nil {
+			return nil, err
+		}
+
+		xattrs[string(name)] = string(value[:valueSize])
+	}
+
+	return xattrs, nil
+}
+
+// writeXattrs sets the given extended attributes on the file at path,
+// skipping any the filesystem doesn't support.
+func writeXattrs(path string, xattrs map[string]string) error {
+	for name, value := range xattrs {
+		err := syscall.Setxattr(path, name, []byte(value), 0)
+		if ignoreUnsupported(err) != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func ignoreUnsupported(err error) error {
+	if err == syscall.ENOTSUP || err == syscall.EPERM {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,80 @@
+// +build linux
+
+package archive_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/concourse/fly/archive"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Extended attributes", func() {
+	var (
+		srcDir string
+		dstDir string
+	)
+
+	BeforeEach(func() {
+		var err error
+
+		srcDir, err = ioutil.TempDir("", "archive-src")
+		Expect(err).NotTo(HaveOccurred())
+
+		dstDir, err = ioutil.TempDir("", "archive-dst")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(srcDir, "some-file"), []byte("some-contents"), 0644)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = syscall.Setxattr(filepath.Join(srcDir, "some-file"), "user.some-attr", []byte("some-value"), 0)
+		if err == syscall.ENOTSUP {
+			Skip("the temp dir does not support extended attributes")
+		}
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(srcDir)
+		os.RemoveAll(dstDir)
+	})
+
+	roundTrip := func(opts archive.Options) {
+		buffer := new(bytes.Buffer)
+
+		err := archive.Compress(buffer, srcDir, opts)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archive.Extract(buffer, dstDir)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	getxattr := func() (string, error) {
+		value := make([]byte, 64)
+		size, err := syscall.Getxattr(filepath.Join(dstDir, "some-file"), "user.some-attr", value)
+		if err != nil {
+			return "", err
+		}
+
+		return string(value[:size]), nil
+	}
+
+	It("leaves them out by default", func() {
+		roundTrip(archive.Options{})
+
+		_, err := getxattr()
+		Expect(err).To(Equal(syscall.ENODATA))
+	})
+
+	It("archives and restores them when asked to", func() {
+		roundTrip(archive.Options{Xattrs: true})
+
+		Expect(getxattr()).To(Equal("some-value"))
+	})
+})
@@ -0,0 +1,13 @@
+// +build !linux
+
+package archive
+
+// readXattrs returns no extended attributes, as they're only archived on
+// Linux.
+func readXattrs(path string) (map[string]string, error) {
+	return nil, nil
+}
+
+func writeXattrs(path string, xattrs map[string]string) error {
+	return nil
+}
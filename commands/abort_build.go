@@ -11,7 +11,7 @@ import (
 
 type AbortBuildCommand struct {
 	Job   flaghelpers.JobFlag `short:"j" long:"job" value-name:"PIPELINE/JOB"   description:"Name of a job to cancel"`
-	Build string              `short:"b" long:"build" required:"true" description:"If job is specified: build number to cancel. If job not specified: build id"`
+	Build string              `short:"b" long:"build" required:"true" description:"If job is specified: build number to cancel. If job not specified: build id, or PIPELINE/JOB#BUILD (the latest build if #BUILD is left out)"`
 }
 
 func (command *AbortBuildCommand) Execute([]string) error {
@@ -25,12 +25,20 @@ func (command *AbortBuildCommand) Execute([]string) error {
 		return err
 	}
 
+	job, buildName, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
 	var build atc.Build
 	var exists bool
-	if command.Job.PipelineName == "" && command.Job.JobName == "" {
-		build, exists, err = target.Client().Build(command.Build)
+	if job.PipelineName == "" && job.JobName == "" {
+		build, exists, err = target.Client().Build(buildName)
+	} else if buildName == "" {
+		build, err = GetBuild(target.Client(), target.Team(), job.JobName, "", job.PipelineName)
+		exists = err == nil
 	} else {
-		build, exists, err = target.Team().JobBuild(command.Job.PipelineName, command.Job.JobName, command.Build)
+		build, exists, err = target.Team().JobBuild(job.PipelineName, job.JobName, buildName)
 	}
 	if err != nil {
 		return err
@@ -40,6 +48,11 @@ func (command *AbortBuildCommand) Execute([]string) error {
 		return fmt.Errorf("build does not exist")
 	}
 
+	switch atc.BuildStatus(build.Status) {
+	case atc.StatusSucceeded, atc.StatusFailed, atc.StatusErrored, atc.StatusAborted:
+		return fmt.Errorf("build %d has already finished: %s", build.ID, build.Status)
+	}
+
 	if err := target.Client().AbortBuild(strconv.Itoa(build.ID)); err != nil {
 		return err
 	}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/concourse/fly/rc"
+)
+
+func init() {
+	Fly.APIRetries = setAPIRetries
+	Fly.APIRetryInterval = setAPIRetryInterval
+}
+
+func setAPIRetries(value string) error {
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		return fmt.Errorf("invalid --api-retries '%s': must be a number of retries, 0 or more", value)
+	}
+
+	rc.APIRetries = retries
+
+	return nil
+}
+
+func setAPIRetryInterval(value string) error {
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		return fmt.Errorf("invalid --api-retry-interval '%s': must be a duration, e.g. 500ms", value)
+	}
+
+	rc.APIRetryInterval = interval
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/audit"
+	"github.com/concourse/fly/ui"
+)
+
+// AuditLogEnv names the environment variable that enables the audit log.
+// It may be set to "syslog" or to the path of a file to append to.
+const AuditLogEnv = "FLY_AUDIT_LOG"
+
+// EnableAudit records each fly invocation to the audit log configured by
+// AuditLogEnv, if any, once fly exits through Exit. activeCommand must
+// report the name of the command that ran.
+func EnableAudit(activeCommand func() string) error {
+	destination := os.Getenv(AuditLogEnv)
+	if destination == "" {
+		return nil
+	}
+
+	log, err := audit.Open(destination)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %s", err)
+	}
+
+	started := time.Now()
+
+	atexit.Register(func(code int) {
+		defer log.Close()
+
+		entry := audit.Entry{
+			Time:     started.UTC(),
+			Duration: time.Since(started),
+			Target:   string(Fly.Target),
+			Command:  activeCommand(),
+			ExitCode: code,
+		}
+
+		if current, err := user.Current(); err == nil {
+			entry.User = current.Username
+		}
+
+		if host, err := os.Hostname(); err == nil {
+			entry.Host = host
+		}
+
+		err := audit.Record(log, entry)
+		if err != nil {
+			fmt.Fprintln(ui.Stderr, "failed to write audit log:", err)
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+type BuildContainersCommand struct {
+	Job   flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB" description:"Name of the job the build belongs to"`
+	Build string              `short:"b" long:"build" required:"true"           description:"If job is specified: build number. If job not specified: build id, or PIPELINE/JOB#BUILD (the latest build if #BUILD is left out)"`
+}
+
+func (command *BuildContainersCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	job, buildName, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
+	var team concourse.Team
+	if job.JobName != "" {
+		team = target.Team()
+	}
+
+	build, err := GetBuild(target.Client(), team, job.JobName, buildName, job.PipelineName)
+	if err != nil {
+		return err
+	}
+
+	containers, err := target.Client().ListContainers(map[string]string{
+		"build_id": strconv.Itoa(build.ID),
+	})
+	if err != nil {
+		return err
+	}
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "step", Color: color.New(color.Bold)},
+			{Contents: "type", Color: color.New(color.Bold)},
+			{Contents: "attempt", Color: color.New(color.Bold)},
+			{Contents: "worker", Color: color.New(color.Bold)},
+			{Contents: "handle", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, c := range containers {
+		table.Data = append(table.Data, ui.TableRow{
+			stringOrDefault(c.StepName + c.ResourceName),
+			{Contents: c.Type},
+			stringOrDefault(c.Attempt, "n/a"),
+			{Contents: c.WorkerName},
+			{Contents: c.ID},
+		})
+	}
+
+	return table.Render(os.Stdout, Fly.PrintTableHeaders)
+}
+
+// stepContainers looks up the containers of a build's steps, matching them
+// up by the names the build's plan gives its steps. The plan is only fetched
+// once a container is first looked up.
+func stepContainers(client concourse.Client, buildID int) eventstream.ContainerLookup {
+	var steps map[string]eventstream.PlanStep
+
+	return func(planID string) (atc.Container, bool) {
+		if steps == nil {
+			steps = map[string]eventstream.PlanStep{}
+
+			buildPlan, found, err := client.BuildPlan(buildID)
+			if err != nil || !found || buildPlan.Plan == nil {
+				return atc.Container{}, false
+			}
+
+			var plan atc.Plan
+			err = json.Unmarshal(*buildPlan.Plan, &plan)
+			if err != nil {
+				return atc.Container{}, false
+			}
+
+			for _, step := range eventstream.PlanSteps(plan) {
+				steps[step.ID] = step
+			}
+		}
+
+		step, found := steps[planID]
+		if !found {
+			return atc.Container{}, false
+		}
+
+		containers, err := client.ListContainers(map[string]string{
+			"build_id": strconv.Itoa(buildID),
+		})
+		if err != nil {
+			return atc.Container{}, false
+		}
+
+		for _, container := range containers {
+			if container.Type == step.Type && container.StepName == step.Name {
+				return container, true
+			}
+		}
+
+		return atc.Container{}, false
+	}
+}
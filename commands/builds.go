@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
@@ -18,8 +20,21 @@ import (
 const timeDateLayout = "2006-01-02@15:04:05-0700"
 
 type BuildsCommand struct {
-	Count int                 `short:"c" long:"count" default:"50" description:"number of builds you want to limit the return to"`
-	Job   flaghelpers.JobFlag `short:"j" long:"job" value-name:"PIPELINE/JOB" description:"Name of a job to get builds for"`
+	Count    int                 `short:"c" long:"count" default:"50" description:"number of builds you want to limit the return to"`
+	Job      flaghelpers.JobFlag `short:"j" long:"job" value-name:"PIPELINE/JOB" description:"Name of a job to get builds for, by default the one in .fly.yml or set with set-defaults"`
+	Watch    bool                `short:"w" long:"watch" description:"Keep refreshing the builds until interrupted"`
+	Interval time.Duration       `long:"interval" default:"5s" value-name:"DURATION" description:"How often to refresh the builds with --watch"`
+	Format   string              `long:"format" value-name:"TEMPLATE" description:"Print each build with a Go template, e.g. '{{.Status}} {{.Duration}}'"`
+	JSONPath string              `long:"jsonpath" value-name:"PATH" description:"Print the value at a JSONPath of each build, e.g. '{.status}'"`
+	JSON     bool                `long:"json" description:"Print the builds as json, with their durations"`
+}
+
+// buildView is a build as given to --format, --jsonpath and --json, with its
+// duration as shown in the table.
+type buildView struct {
+	atc.Build
+
+	Duration string `json:"duration"`
 }
 
 func (command *BuildsCommand) Execute([]string) error {
@@ -33,11 +48,85 @@ func (command *BuildsCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
+	command.Job, _, err = defaultJob(command.Job)
+	if err != nil {
+		return err
+	}
+
+	if !command.Watch {
+		builds, err := command.builds(target)
+		if err != nil {
+			return err
+		}
+
+		return command.render(builds, formatter)
+	}
+
+	if command.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s", command.Interval)
+	}
+
+	_, isTTY := ui.ForTTY(os.Stdout)
+
+	for {
+		builds, err := command.builds(target)
+		if err != nil {
+			return err
+		}
+
+		// redraw in place on a terminal; otherwise each refresh is appended
+		if isTTY {
+			fmt.Print("\x1b[H\x1b[2J")
+		}
+
+		fmt.Printf("every %s: fly builds (%s)\n\n", command.Interval, time.Now().Format(timeDateLayout))
+
+		err = command.render(builds, formatter)
+		if err != nil {
+			return err
+		}
+
+		time.Sleep(command.Interval)
+	}
+}
+
+func (command *BuildsCommand) render(builds []atc.Build, formatter *formathelpers.Formatter) error {
+	views := []buildView{}
+	for _, b := range builds {
+		_, _, durationCell := populateTimeCells(time.Unix(b.StartTime, 0), time.Unix(b.EndTime, 0))
+		views = append(views, buildView{Build: b, Duration: durationCell.Contents})
+	}
+
+	if command.JSON {
+		payload, err := json.Marshal(views)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Printf("%s\n", payload)
+		return err
+	}
+
+	if formatter != nil {
+		return formatter.Print(os.Stdout, views)
+	}
+
+	return command.buildsTable(builds).Render(os.Stdout, Fly.PrintTableHeaders)
+}
+
+func (command *BuildsCommand) builds(target rc.Target) ([]atc.Build, error) {
 	page := concourse.Page{Limit: command.Count}
 
 	team := target.Team()
 	client := target.Client()
 
+	var err error
+
 	var builds []atc.Build
 	if command.Job.PipelineName != "" && command.Job.JobName != "" {
 		var found bool
@@ -47,7 +136,7 @@ func (command *BuildsCommand) Execute([]string) error {
 			page,
 		)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		if !found {
@@ -56,10 +145,18 @@ func (command *BuildsCommand) Execute([]string) error {
 	} else {
 		builds, _, err = client.Builds(page)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	if command.Count < len(builds) {
+		builds = builds[:command.Count]
+	}
+
+	return builds, nil
+}
+
+func (command *BuildsCommand) buildsTable(builds []atc.Build) ui.Table {
 	table := ui.Table{
 		Headers: ui.TableRow{
 			{Contents: "id", Color: color.New(color.Bold)},
@@ -72,14 +169,7 @@ func (command *BuildsCommand) Execute([]string) error {
 		},
 	}
 
-	var rangeUntil int
-	if command.Count < len(builds) {
-		rangeUntil = command.Count
-	} else {
-		rangeUntil = len(builds)
-	}
-
-	for _, b := range builds[:rangeUntil] {
+	for _, b := range builds {
 		startTimeCell, endTimeCell, durationCell := populateTimeCells(time.Unix(b.StartTime, 0), time.Unix(b.EndTime, 0))
 
 		var pipelineJobCell, buildCell ui.TableCell
@@ -91,25 +181,7 @@ func (command *BuildsCommand) Execute([]string) error {
 			buildCell.Contents = b.Name
 		}
 
-		var statusCell ui.TableCell
-		statusCell.Contents = b.Status
-
-		switch b.Status {
-		case "pending":
-			statusCell.Color = ui.PendingColor
-		case "started":
-			statusCell.Color = ui.StartedColor
-		case "succeeded":
-			statusCell.Color = ui.SucceededColor
-		case "failed":
-			statusCell.Color = ui.FailedColor
-		case "errored":
-			statusCell.Color = ui.ErroredColor
-		case "aborted":
-			statusCell.Color = ui.AbortedColor
-		case "paused":
-			statusCell.Color = ui.PausedColor
-		}
+		statusCell := buildStatusCell(b.Status)
 
 		table.Data = append(table.Data, []ui.TableCell{
 			{Contents: strconv.Itoa(b.ID)},
@@ -122,7 +194,7 @@ func (command *BuildsCommand) Execute([]string) error {
 		})
 	}
 
-	return table.Render(os.Stdout, Fly.PrintTableHeaders)
+	return table
 }
 
 func populateTimeCells(startTime time.Time, endTime time.Time) (ui.TableCell, ui.TableCell, ui.TableCell) {
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+type CausalityCommand struct {
+	Resource flaghelpers.ResourceFlag `short:"r" long:"resource" required:"true" value-name:"PIPELINE/RESOURCE" description:"Name of the resource"`
+	Version  atc.Version              `short:"v" long:"version"  required:"true" value-name:"VERSION"           description:"Version to trace, e.g. ref:abcd (can be specified multiple times for versions with several fields)"`
+}
+
+// Execute lists the builds that produced the version and those that used
+// it as an input, followed by the jobs that used it, so that a bad version
+// can be traced through the pipeline.
+func (command *CausalityCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	versioned, found, err := findResourceVersion(target, command.Resource, command.Version)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("resource '%s' has no version %s", command.Resource.ResourceName, versionString(command.Version))
+	}
+
+	outputOf, err := command.versionBuilds(target, versioned.ID, "output_of")
+	if err != nil {
+		return err
+	}
+
+	inputTo, err := command.versionBuilds(target, versioned.ID, "input_to")
+	if err != nil {
+		return err
+	}
+
+	bold := color.New(color.Bold)
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "id", Color: bold},
+			{Contents: "job", Color: bold},
+			{Contents: "build", Color: bold},
+			{Contents: "status", Color: bold},
+			{Contents: "version was", Color: bold},
+		},
+	}
+
+	for _, relation := range []struct {
+		builds []atc.Build
+		was    string
+	}{
+		{outputOf, "output"},
+		{inputTo, "input"},
+	} {
+		for _, build := range relation.builds {
+			table.Data = append(table.Data, ui.TableRow{
+				{Contents: strconv.Itoa(build.ID)},
+				{Contents: build.JobName},
+				{Contents: build.Name},
+				buildStatusCell(build.Status),
+				{Contents: relation.was},
+			})
+		}
+	}
+
+	fmt.Printf("builds of version %s of '%s'\n\n", versionString(versioned.Version), command.Resource.ResourceName)
+
+	err = table.Render(os.Stdout, Fly.PrintTableHeaders)
+	if err != nil {
+		return err
+	}
+
+	jobs := map[string]bool{}
+	for _, build := range inputTo {
+		jobs[build.JobName] = true
+	}
+
+	jobNames := []string{}
+	for name := range jobs {
+		jobNames = append(jobNames, name)
+	}
+
+	sort.Strings(jobNames)
+
+	fmt.Println()
+
+	if len(jobNames) == 0 {
+		fmt.Println("no jobs have used this version")
+		return nil
+	}
+
+	fmt.Println("jobs that used this version:")
+	for _, name := range jobNames {
+		fmt.Printf("  %s/%s\n", command.Resource.PipelineName, name)
+	}
+
+	return nil
+}
+
+// versionBuilds lists the builds the version was the input to or the output
+// of, as given by relation.
+func (command *CausalityCommand) versionBuilds(target rc.Target, versionID int, relation string) ([]atc.Build, error) {
+	response, err := target.Client().HTTPClient().Get(pipelineAPIURL(target, command.Resource.PipelineName, "resources", command.Resource.ResourceName, "versions", strconv.Itoa(versionID), relation))
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list builds: %s", response.Status)
+	}
+
+	var builds []atc.Build
+	err = json.NewDecoder(response.Body).Decode(&builds)
+	if err != nil {
+		return nil, err
+	}
+
+	return builds, nil
+}
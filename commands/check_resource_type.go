@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type CheckResourceTypeCommand struct {
+	ResourceType flaghelpers.ResourceFlag `short:"r" long:"resource-type" required:"true" value-name:"PIPELINE/RESOURCE-TYPE" description:"Name of a resource type to check"`
+	Version      *atc.Version             `short:"f" long:"from"                          value-name:"VERSION"                description:"Version of the resource type's image to check from, e.g. digest:sha256@..."`
+}
+
+// resourceTypeVersion is the part of a resource type, as the ATC lists them,
+// that says which version of its image is in use.
+type resourceTypeVersion struct {
+	Name    string      `json:"name"`
+	Version atc.Version `json:"version"`
+}
+
+func (command *CheckResourceTypeCommand) Execute(args []string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	pipelineName := command.ResourceType.PipelineName
+	resourceTypeName := command.ResourceType.ResourceName
+
+	client := target.Client()
+
+	resourceTypesURL := pipelineAPIURL(target, pipelineName, "resource-types")
+
+	var version atc.Version
+	if command.Version != nil {
+		version = *command.Version
+	}
+
+	found, err := checkResourceType(client, pipelineAPIURL(target, pipelineName, "resource-types", resourceTypeName, "check"), version)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return rc.NewErrNotFound("pipeline or resource type", pipelineName+"/"+resourceTypeName)
+	}
+
+	fmt.Printf("checked '%s'\n", resourceTypeName)
+
+	resourceTypes, err := listResourceTypeVersions(client, resourceTypesURL)
+	if err != nil {
+		return err
+	}
+
+	for _, resourceType := range resourceTypes {
+		if resourceType.Name == resourceTypeName && len(resourceType.Version) > 0 {
+			fmt.Printf("using version %s\n", versionString(resourceType.Version))
+		}
+	}
+
+	return nil
+}
+
+func checkResourceType(client concourse.Client, checkURL string, version atc.Version) (bool, error) {
+	body, err := json.Marshal(map[string]atc.Version{"from": version})
+	if err != nil {
+		return false, err
+	}
+
+	response, err := client.HTTPClient().Post(checkURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		message, _ := ioutil.ReadAll(response.Body)
+		return false, fmt.Errorf("check failed (%s): %s", response.Status, strings.TrimSpace(string(message)))
+	}
+}
+
+func listResourceTypeVersions(client concourse.Client, resourceTypesURL string) ([]resourceTypeVersion, error) {
+	response, err := client.HTTPClient().Get(resourceTypesURL)
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list resource types: %s", response.Status)
+	}
+
+	var resourceTypes []resourceTypeVersion
+	err = json.NewDecoder(response.Body).Decode(&resourceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	return resourceTypes, nil
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/vito/go-interact/interact"
+)
+
+type ClearTaskCacheCommand struct {
+	Job             flaghelpers.JobFlag `short:"j" long:"job"        required:"true" value-name:"PIPELINE/JOB" description:"Job whose task caches to clear"`
+	StepName        string              `short:"s" long:"step"       required:"true" value-name:"STEP"         description:"Name of the task step whose caches to clear"`
+	CachePath       string              `short:"c" long:"cache-path"                 value-name:"PATH"         description:"Clear only the cache at this path, e.g. node_modules (default: all of the step's caches)"`
+	SkipInteractive bool                `short:"n" long:"non-interactive"                                      description:"Clear the caches without confirmation"`
+}
+
+func (command *ClearTaskCacheCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	caches := "every cache"
+	if command.CachePath != "" {
+		caches = fmt.Sprintf("the cache at '%s'", command.CachePath)
+	}
+
+	fmt.Printf("!!! this will remove %s of task '%s' in job '%s'\n\n", caches, command.StepName, command.Job.JobName)
+
+	confirm := command.SkipInteractive
+	if !confirm {
+		err := interact.NewInteraction("are you sure?").Resolve(&confirm)
+		if err != nil || !confirm {
+			fmt.Println("bailing out")
+			return err
+		}
+	}
+
+	cacheURL := pipelineAPIURL(target, command.Job.PipelineName, "jobs", command.Job.JobName, "tasks", command.StepName, "cache")
+	if command.CachePath != "" {
+		cacheURL += "?" + url.Values{"cachePath": {command.CachePath}}.Encode()
+	}
+
+	request, err := http.NewRequest("DELETE", cacheURL, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := target.Client().HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return rc.NewErrNotFound("pipeline, job or step", command.Job.PipelineName+"/"+command.Job.JobName+"/"+command.StepName)
+	default:
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("failed to clear caches (%s): %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		CachesRemoved int `json:"caches_removed"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d caches removed\n", result.CachesRemoved)
+
+	return nil
+}
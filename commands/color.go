@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+func init() {
+	Fly.Color = setColor
+}
+
+func setColor(value string) error {
+	switch value {
+	case "auto":
+		// color already tells whether stdout is a terminal
+	case "always":
+		color.NoColor = false
+	case "never":
+		color.NoColor = true
+	default:
+		return fmt.Errorf("invalid --color '%s': must be auto, always or never", value)
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/ui"
+)
+
+// CommandTimeoutExitCode is what fly exits with when --command-timeout
+// elapses, as timeout(1) does.
+const CommandTimeoutExitCode = 124
+
+func init() {
+	Fly.CommandTimeout = startCommandTimeout
+}
+
+func startCommandTimeout(value string) error {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid --command-timeout '%s': %s", value, err)
+	}
+
+	if timeout <= 0 {
+		return fmt.Errorf("invalid --command-timeout '%s': must be positive", value)
+	}
+
+	time.AfterFunc(timeout, func() {
+		fmt.Fprintf(ui.Stderr, "error: fly did not finish within --command-timeout of %s\n", timeout)
+		atexit.Exit(CommandTimeoutExitCode)
+	})
+
+	return nil
+}
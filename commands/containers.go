@@ -5,12 +5,16 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/fatih/color"
 )
 
-type ContainersCommand struct{}
+type ContainersCommand struct {
+	Format   string `long:"format" value-name:"TEMPLATE" description:"Print each container with a Go template, e.g. '{{.ID}} {{.WorkerName}}'"`
+	JSONPath string `long:"jsonpath" value-name:"PATH" description:"Print the value at a JSONPath of each container, e.g. '{.id}'"`
+}
 
 func (command *ContainersCommand) Execute([]string) error {
 	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
@@ -23,11 +27,20 @@ func (command *ContainersCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
 	containers, err := target.Client().ListContainers(map[string]string{})
 	if err != nil {
 		return err
 	}
 
+	if formatter != nil {
+		return formatter.Print(os.Stdout, containers)
+	}
+
 	table := ui.Table{
 		Headers: ui.TableRow{
 			{Contents: "handle", Color: color.New(color.Bold)},
@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/archive"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/rc"
+	"github.com/tedsuo/rata"
+)
+
+type CopyCommand struct {
+	Job            flaghelpers.JobFlag `short:"j" long:"job"     value-name:"PIPELINE/JOB" description:"Name of the job whose container to copy to or from"`
+	Build          string              `short:"b" long:"build"                             description:"Build number within the job, global build ID, or PIPELINE/JOB#BUILD, if not given as BUILD:PATH"`
+	StepName       string              `short:"s" long:"step"                              description:"Name of the step whose container to copy to or from"`
+	Attempt        string              `short:"a" long:"attempt" value-name:"N[,N,...]"    description:"Attempt number of the step"`
+	PositionalArgs struct {
+		Source      string `positional-arg-name:"SOURCE"      required:"true" description:"What to copy, as a local path or BUILD:PATH in a container"`
+		Destination string `positional-arg-name:"DESTINATION" required:"true" description:"Where to copy it to, as a local path or BUILD:PATH in a container"`
+	} `positional-args:"yes"`
+}
+
+// containerPaths are paths in a build's container, e.g. 128:/tmp/report or
+// :report for the container of the build given by -b, or the latest build.
+// A local path such as C:\report isn't one, as builds are numbered.
+var containerPaths = regexp.MustCompile(`^([0-9.]*):(.+)$`)
+
+func (command *CopyCommand) Execute([]string) error {
+	src := command.PositionalArgs.Source
+	dst := command.PositionalArgs.Destination
+
+	srcMatch := containerPaths.FindStringSubmatch(src)
+	dstMatch := containerPaths.FindStringSubmatch(dst)
+
+	if (srcMatch == nil) == (dstMatch == nil) {
+		return errors.New("one of the source and destination must be in a container, as BUILD:PATH, and the other local")
+	}
+
+	containerMatch := srcMatch
+	if containerMatch == nil {
+		containerMatch = dstMatch
+	}
+
+	build := command.Build
+	if containerMatch[1] != "" {
+		if build != "" && build != containerMatch[1] {
+			return fmt.Errorf("build %s given by --build and %s given by %s differ", build, containerMatch[1], containerMatch[0])
+		}
+
+		build = containerMatch[1]
+	}
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	locator := HijackCommand{
+		Job:      command.Job,
+		Build:    build,
+		StepName: command.StepName,
+		Attempt:  command.Attempt,
+	}
+
+	containers, err := locator.getContainerIDs(target.Client())
+	if err != nil {
+		return err
+	}
+
+	chosenContainer, chosen, err := chooseContainer(containers)
+	if err != nil {
+		return err
+	}
+
+	if !chosen {
+		return nil
+	}
+
+	h := hijacker.New(target.TLSConfig(), rata.NewRequestGenerator(target.URL(), atc.Routes), target.Token())
+
+	container := hijacker.Container{
+		Handle: chosenContainer.ID,
+		User:   chosenContainer.User,
+		Dir:    chosenContainer.WorkingDirectory,
+	}
+
+	if srcMatch != nil {
+		return copyOut(h, container, srcMatch[2], dst)
+	}
+
+	return copyIn(h, container, src, dstMatch[2])
+}
+
+// copyOut copies src in the container to dst, or into dst if it's a
+// directory. It's extracted beside dst first, so that a failed copy doesn't
+// leave half of it behind.
+func copyOut(h *hijacker.Hijacker, container hijacker.Container, src string, dst string) error {
+	name := path.Base(path.Clean(src))
+	if name == "." || name == "/" || name == ".." {
+		return fmt.Errorf("cannot copy %s as a whole; copy what's in it by name", src)
+	}
+
+	final := dst
+	parent := filepath.Dir(dst)
+	if info, err := os.Stat(dst); err == nil && info.IsDir() {
+		final = filepath.Join(dst, name)
+		parent = dst
+	}
+
+	tmp, err := ioutil.TempDir(parent, ".fly-cp")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(tmp)
+
+	reader, writer := io.Pipe()
+
+	extracted := make(chan error, 1)
+	go func() {
+		err := archive.ExtractTar(reader, tmp)
+		reader.CloseWithError(err)
+		extracted <- err
+	}()
+
+	err = h.CopyFrom(container, src, writer, os.Stderr)
+	writer.CloseWithError(err)
+
+	extractErr := <-extracted
+
+	if err != nil {
+		return err
+	}
+
+	if extractErr != nil {
+		return fmt.Errorf("failed to extract %s: %s", src, extractErr)
+	}
+
+	err = os.RemoveAll(final)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(filepath.Join(tmp, name), final)
+}
+
+// copyIn copies src to dst in the container, or into dst if it's a
+// directory.
+func copyIn(h *hijacker.Hijacker, container hijacker.Container, src string, dst string) error {
+	src = filepath.Clean(src)
+
+	_, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(src)
+
+	reader, writer := io.Pipe()
+
+	compressed := make(chan error, 1)
+	go func() {
+		err := archive.Compress(writer, filepath.Dir(src), archive.Options{Files: []string{name}})
+		writer.CloseWithError(err)
+		compressed <- err
+	}()
+
+	err = h.CopyTo(container, reader, name, dst, os.Stderr)
+	reader.Close()
+
+	// a failure to read src is what made the copy fail, if anything did,
+	// unless it was only cut short by the copy failing
+	if compressErr := <-compressed; compressErr != nil && compressErr != io.ErrClosedPipe {
+		return fmt.Errorf("failed to read %s: %s", src, compressErr)
+	}
+
+	return err
+}
@@ -3,8 +3,8 @@ package commands
 import (
 	"errors"
 	"fmt"
-	"os"
 
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
@@ -53,7 +53,7 @@ func (command *DestroyTeamCommand) Execute([]string) error {
 		fmt.Printf(ui.WarningColor("could not destroy `%s`\n", teamName))
 		fmt.Println()
 		fmt.Println("either your team is not an admin or it is the last admin team")
-		os.Exit(1)
+		atexit.Exit(1)
 	default:
 		return err
 	}
@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/diffhelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/rc"
+	"github.com/fatih/color"
+)
+
+type DiffBuildsCommand struct {
+	Job    flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB" description:"Name of the job the builds belong to"`
+	Builds []string            `short:"b" long:"build" required:"true"           description:"If job is specified: build number to compare. If job not specified: build id, or PIPELINE/JOB#BUILD (specify twice)"`
+}
+
+func (command *DiffBuildsCommand) Execute([]string) error {
+	if len(command.Builds) != 2 {
+		return errors.New("exactly two builds must be given, e.g. -b 127 -b 128")
+	}
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	var logs [2][]eventstream.StepLog
+	var labels [2]string
+
+	for i, buildNameOrID := range command.Builds {
+		job, buildName, err := jobBuild(command.Job, buildNameOrID)
+		if err != nil {
+			return err
+		}
+
+		var build atc.Build
+		var exists bool
+		if job.PipelineName == "" && job.JobName == "" {
+			build, exists, err = target.Client().Build(buildName)
+		} else if buildName == "" {
+			build, err = GetBuild(target.Client(), target.Team(), job.JobName, "", job.PipelineName)
+			exists = err == nil
+		} else {
+			build, exists, err = target.Team().JobBuild(job.PipelineName, job.JobName, buildName)
+		}
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("build %s does not exist", buildNameOrID)
+		}
+
+		labels[i] = "build " + buildNameOrID
+		if job.JobName != command.Job.JobName {
+			labels[i] = fmt.Sprintf("%s/%s #%s", job.PipelineName, job.JobName, build.Name)
+		}
+
+		eventSource, err := eventstream.Events(context.Background(), target.Client(), strconv.Itoa(build.ID))
+		if err != nil {
+			return err
+		}
+
+		logs[i], err = eventstream.StepLogs(eventSource, nil)
+
+		eventSource.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	bold := color.New(color.Bold).SprintFunc()
+
+	differences := false
+	for _, step := range alignSteps(logs[0], logs[1]) {
+		if step.before.Log == step.after.Log {
+			continue
+		}
+
+		differences = true
+
+		fmt.Println(bold(step.name))
+
+		diffhelpers.Unified(
+			os.Stdout,
+			labels[0]+": "+step.name,
+			labels[1]+": "+step.name,
+			step.before.Log,
+			step.after.Log,
+		)
+
+		fmt.Println()
+	}
+
+	if !differences {
+		fmt.Println("the builds' logs are the same")
+	}
+
+	return nil
+}
+
+type alignedStep struct {
+	name   string
+	before eventstream.StepLog
+	after  eventstream.StepLog
+}
+
+// alignSteps pairs up the steps of two builds by name, in the order they
+// ran. Steps with the same name, e.g. a task run twice, are paired in order.
+// Steps only one build ran are paired with an empty log.
+func alignSteps(before []eventstream.StepLog, after []eventstream.StepLog) []alignedStep {
+	unpaired := map[string][]eventstream.StepLog{}
+	for _, step := range after {
+		unpaired[step.Name] = append(unpaired[step.Name], step)
+	}
+
+	aligned := []alignedStep{}
+	for _, step := range before {
+		pair := alignedStep{name: step.Name, before: step}
+
+		if candidates := unpaired[step.Name]; len(candidates) > 0 {
+			pair.after = candidates[0]
+			unpaired[step.Name] = candidates[1:]
+		}
+
+		aligned = append(aligned, pair)
+	}
+
+	paired := map[string]int{}
+	for _, step := range after {
+		// steps of the same name are paired first to last, so whichever are
+		// left over are the last ones
+		paired[step.Name]++
+		if paired[step.Name] <= countSteps(before, step.Name) {
+			continue
+		}
+
+		aligned = append(aligned, alignedStep{name: step.Name, after: step})
+	}
+
+	return aligned
+}
+
+func countSteps(steps []eventstream.StepLog, name string) int {
+	count := 0
+	for _, step := range steps {
+		if step.Name == name {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/setpipelinehelpers"
+	"github.com/concourse/fly/rc"
+)
+
+type DiffPipelineCommand struct {
+	Pipeline flaghelpers.PipelineFlag `short:"p"  long:"pipeline"  required:"true"  description:"Pipeline to compare"`
+	Config   atc.PathFlag             `short:"c"  long:"config"    required:"true"  description:"Pipeline configuration file"`
+
+	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in the pipeline"`
+	YAMLVar []flaghelpers.YAMLVariablePairFlag `short:"y"  long:"yaml-var"  value-name:"[NAME=YAML]"    description:"Specify a YAML value to set for a variable in the pipeline"`
+
+	VarsFrom []atc.PathFlag `short:"l"  long:"load-vars-from"  description:"Variable flag that can be used for filling in template values in configuration from a YAML file"`
+}
+
+func (command *DiffPipelineCommand) Validate() error {
+	return command.Pipeline.Validate()
+}
+
+func (command *DiffPipelineCommand) Execute(args []string) error {
+	err := command.Validate()
+	if err != nil {
+		return err
+	}
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	atcConfig := setpipelinehelpers.ATCConfig{
+		Team:         target.Team(),
+		PipelineName: string(command.Pipeline),
+	}
+
+	return atcConfig.Diff(command.Config, command.Var, command.YAMLVar, command.VarsFrom)
+}
@@ -0,0 +1,331 @@
+package commands
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/fly/version"
+	"github.com/fatih/color"
+	"github.com/gorilla/websocket"
+)
+
+// maxClockSkew is how far fly's clock may be from the ATC's before tokens
+// and build times can't be trusted.
+const maxClockSkew = time.Minute
+
+const doctorTimeout = 10 * time.Second
+
+type DoctorCommand struct{}
+
+type doctorStatus int
+
+const (
+	doctorPass doctorStatus = iota
+	doctorWarn
+	doctorSkip
+	doctorFail
+)
+
+type doctorResult struct {
+	status doctorStatus
+	detail string
+}
+
+func passed(format string, args ...interface{}) doctorResult {
+	return doctorResult{status: doctorPass, detail: fmt.Sprintf(format, args...)}
+}
+
+func warned(format string, args ...interface{}) doctorResult {
+	return doctorResult{status: doctorWarn, detail: fmt.Sprintf(format, args...)}
+}
+
+func skipped(format string, args ...interface{}) doctorResult {
+	return doctorResult{status: doctorSkip, detail: fmt.Sprintf(format, args...)}
+}
+
+func failed(format string, args ...interface{}) doctorResult {
+	return doctorResult{status: doctorFail, detail: fmt.Sprintf(format, args...)}
+}
+
+// Execute checks that fly can work with the target, printing a report to
+// paste into a support ticket, and exits 1 if any check failed.
+func (command *DoctorCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	targetURL, err := url.Parse(target.URL())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("fly %s, target %s at %s\n\n", version.Version, Fly.Target, target.URL())
+
+	info, infoDate, infoErr := doctorInfo(target)
+
+	checks := []struct {
+		name  string
+		check func() doctorResult
+	}{
+		{"target URL resolves", func() doctorResult { return checkResolves(targetURL) }},
+		{"TLS verifies", func() doctorResult { return checkTLS(target, targetURL) }},
+		{"auth is valid", func() doctorResult { return checkAuth(target) }},
+		{"websockets connect", func() doctorResult { return checkWebsocket(target, targetURL) }},
+		{"clock is in sync", func() doctorResult { return checkClockSkew(infoDate, infoErr) }},
+		{"versions match", func() doctorResult { return checkVersions(info, infoErr) }},
+	}
+
+	statusCells := map[doctorStatus]string{
+		doctorPass: ui.SucceededColor.Sprint("pass"),
+		doctorWarn: ui.StartedColor.Sprint("warn"),
+		doctorSkip: ui.PendingColor.Sprint("skip"),
+		doctorFail: ui.FailedColor.Sprint("fail"),
+	}
+
+	failures := 0
+	for _, check := range checks {
+		result := check.check()
+		if result.status == doctorFail {
+			failures++
+		}
+
+		fmt.Printf("%s  %s: %s\n", statusCells[result.status], color.New(color.Bold).Sprint(check.name), result.detail)
+	}
+
+	if failures > 0 {
+		fmt.Printf("\n%d checks failed\n", failures)
+		atexit.Exit(1)
+	}
+
+	return nil
+}
+
+// doctorInfo fetches the ATC's info, along with the time the ATC gave in
+// its response, which isn't otherwise exposed.
+func doctorInfo(target rc.Target) (atc.Info, time.Time, error) {
+	response, err := target.Client().HTTPClient().Get(strings.TrimRight(target.URL(), "/") + "/api/v1/info")
+	if err != nil {
+		return atc.Info{}, time.Time{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return atc.Info{}, time.Time{}, fmt.Errorf("unexpected response: %s", response.Status)
+	}
+
+	var info atc.Info
+	err = json.NewDecoder(response.Body).Decode(&info)
+	if err != nil {
+		return atc.Info{}, time.Time{}, err
+	}
+
+	date, _ := http.ParseTime(response.Header.Get("Date"))
+
+	return info, date, nil
+}
+
+func checkResolves(targetURL *url.URL) doctorResult {
+	addrs, err := net.LookupHost(targetURL.Hostname())
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	return passed("%s is %s", targetURL.Hostname(), strings.Join(addrs, ", "))
+}
+
+func checkTLS(target rc.Target, targetURL *url.URL) doctorResult {
+	if targetURL.Scheme != "https" {
+		return skipped("target is not https")
+	}
+
+	tlsConfig := target.TLSConfig()
+	if tlsConfig != nil && tlsConfig.InsecureSkipVerify {
+		return warned("verification is disabled for this target (--insecure)")
+	}
+
+	var config *tls.Config
+	if tlsConfig != nil {
+		config = tlsConfig.Clone()
+	} else {
+		config = &tls.Config{}
+	}
+
+	config.ServerName = targetURL.Hostname()
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: doctorTimeout}, "tcp", hostPort(targetURL), config)
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	defer conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return passed("verified")
+	}
+
+	return passed("certificate for %s verified, expires %s", certs[0].Subject.CommonName, certs[0].NotAfter.Format("2006-01-02"))
+}
+
+func checkAuth(target rc.Target) doctorResult {
+	token := target.Token()
+	if token == nil || token.Value == "" {
+		return failed("not logged in; run fly -t %s login", Fly.Target)
+	}
+
+	if expiresAt, ok := token.ExpiresAt(); ok && time.Now().After(expiresAt) {
+		return failed("token expired %s; run fly -t %s login", expiresAt.Format(time.RFC3339), Fly.Target)
+	}
+
+	_, err := target.Team().ListPipelines()
+	if err == rc.ErrUnauthorized {
+		return failed("the ATC rejected the token; run fly -t %s login", Fly.Target)
+	}
+
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	if expiresAt, ok := token.ExpiresAt(); ok {
+		return passed("logged in to team %s until %s", target.Team().Name(), expiresAt.Format(time.RFC3339))
+	}
+
+	return passed("logged in to team %s", target.Team().Name())
+}
+
+// checkWebsocket sends a websocket handshake, as fly hijack and fly watch
+// --stats do, through the proxy configured for the target, if any. The
+// handshake is for a container that doesn't exist, so it's enough that the
+// ATC answers it rather than a proxy refusing it.
+func checkWebsocket(target rc.Target, targetURL *url.URL) doctorResult {
+	via := "directly"
+
+	proxyURL, err := http.ProxyFromEnvironment(&http.Request{URL: targetURL})
+	if err != nil {
+		return failed("invalid proxy: %s", err)
+	}
+
+	if proxyURL != nil {
+		via = "through proxy " + proxyURL.Host
+	}
+
+	request, err := http.NewRequest("GET", strings.TrimRight(target.URL(), "/")+"/api/v1/containers/fly-doctor/hijack", nil)
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	if authorization, ok := target.TokenAuthorization(); ok {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	request, err = rc.PrepareWebsocketRequest(request)
+	if err != nil {
+		return failed("%s", err)
+	}
+
+	wsURL := *request.URL
+	if wsURL.Scheme == "https" {
+		wsURL.Scheme = "wss"
+	} else {
+		wsURL.Scheme = "ws"
+	}
+
+	dialer := websocket.Dialer{
+		TLSClientConfig:  target.TLSConfig(),
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: doctorTimeout,
+	}
+
+	conn, response, err := dialer.Dial(wsURL.String(), request.Header)
+	if err == nil {
+		conn.Close()
+		return passed("connected %s", via)
+	}
+
+	if response == nil {
+		return failed("could not connect %s: %s", via, err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusProxyAuthRequired, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return failed("refused %s: %s", via, response.Status)
+	}
+
+	return passed("reached the ATC %s", via)
+}
+
+func checkClockSkew(atcTime time.Time, infoErr error) doctorResult {
+	if infoErr != nil {
+		return failed("could not get the ATC's time: %s", infoErr)
+	}
+
+	if atcTime.IsZero() {
+		return skipped("the ATC gave no time")
+	}
+
+	// the ATC's time is only to the second
+	skew := time.Since(atcTime).Truncate(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > maxClockSkew {
+		return failed("fly's clock is %s off the ATC's", skew)
+	}
+
+	return passed("fly's clock is within %s of the ATC's", skew+time.Second)
+}
+
+func checkVersions(info atc.Info, infoErr error) doctorResult {
+	if infoErr != nil {
+		return failed("could not get the ATC's version: %s", infoErr)
+	}
+
+	if info.Version == version.Version {
+		return passed("fly and the ATC are both %s", info.Version)
+	}
+
+	if version.IsDev(version.Version) {
+		return warned("fly is a dev build; the ATC is %s", info.Version)
+	}
+
+	atcMajor, atcMinor, _, err := version.GetSemver(info.Version)
+	if err != nil {
+		return failed("could not parse the ATC's version %s: %s", info.Version, err)
+	}
+
+	flyMajor, flyMinor, _, err := version.GetSemver(version.Version)
+	if err != nil {
+		return failed("could not parse fly's version %s: %s", version.Version, err)
+	}
+
+	if atcMajor != flyMajor || atcMinor != flyMinor {
+		return failed("fly is %s but the ATC is %s; run fly -t %s sync", version.Version, info.Version, Fly.Target)
+	}
+
+	return warned("fly is %s but the ATC is %s", version.Version, info.Version)
+}
+
+func hostPort(targetURL *url.URL) string {
+	if targetURL.Port() != "" {
+		return targetURL.Host
+	}
+
+	if targetURL.Scheme == "https" {
+		return net.JoinHostPort(targetURL.Hostname(), "443")
+	}
+
+	return net.JoinHostPort(targetURL.Hostname(), "80")
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/setpipelinehelpers"
+)
+
+type EvalCommand struct {
+	Config atc.PathFlag `short:"c" long:"config" required:"true" description:"Pipeline configuration file"`
+
+	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in the pipeline"`
+	YAMLVar []flaghelpers.YAMLVariablePairFlag `short:"y"  long:"yaml-var"  value-name:"[NAME=YAML]"    description:"Specify a YAML value to set for a variable in the pipeline"`
+
+	VarsFrom []atc.PathFlag `short:"l"  long:"load-vars-from"  description:"Variable flag that can be used for filling in template values in configuration from a YAML file"`
+
+	DeferVar []string `long:"defer-var" value-name:"NAME" description:"Variable left for the credential manager to provide; may be a glob, e.g. aws-* (can be specified multiple times)"`
+}
+
+func (command *EvalCommand) Execute(args []string) error {
+	atcConfig := setpipelinehelpers.ATCConfig{
+		DeferredVars: command.DeferVar,
+	}
+
+	_, err := os.Stdout.Write(atcConfig.Render(command.Config, command.Var, command.YAMLVar, command.VarsFrom))
+	return err
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/concourse/fly/eventstream"
+)
+
+func init() {
+	Fly.EventReconnects = setEventReconnects
+	Fly.EventStallTimeout = setEventStallTimeout
+
+	eventstream.TargetName = func() string { return string(Fly.Target) }
+}
+
+func setEventReconnects(value string) error {
+	reconnects, err := strconv.Atoi(value)
+	if err != nil || reconnects < 0 {
+		return fmt.Errorf("invalid --event-reconnects '%s': must be a number of attempts, 0 or more", value)
+	}
+
+	eventstream.MaxReconnects = reconnects
+
+	return nil
+}
+
+func setEventStallTimeout(value string) error {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return fmt.Errorf("invalid --event-stall-timeout '%s': must be a duration, e.g. 10m", value)
+	}
+
+	eventstream.StallTimeout = timeout
+
+	return nil
+}
@@ -1,31 +1,117 @@
 package commands
 
 import (
+	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
-	"syscall"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cloudfoundry/bosh-cli/director/template"
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/archive"
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/cioutput"
 	"github.com/concourse/fly/commands/internal/executehelpers"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/githubstatus"
+	"github.com/concourse/fly/commands/internal/multibuild"
+	"github.com/concourse/fly/commands/internal/notify"
+	"github.com/concourse/fly/commands/internal/pushmetrics"
+	"github.com/concourse/fly/commands/internal/registrycreds"
+	"github.com/concourse/fly/commands/internal/statshelpers"
+	"github.com/concourse/fly/commands/internal/testreports"
+	"github.com/concourse/fly/commands/internal/tracing"
+	"github.com/concourse/fly/commands/internal/varsources"
 	"github.com/concourse/fly/config"
 	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
+	"github.com/mattn/go-isatty"
 )
 
 type ExecuteCommand struct {
-	TaskConfig     atc.PathFlag                 `short:"c" long:"config" required:"true"                description:"The task config to execute"`
-	Privileged     bool                         `short:"p" long:"privileged"                            description:"Run the task with full privileges"`
-	ExcludeIgnored bool                         `short:"x" long:"exclude-ignored"                       description:"Skip uploading .gitignored paths. This uses the file paths that are in your Git index. Make sure it's up to date!"`
-	Inputs         []flaghelpers.InputPairFlag  `short:"i" long:"input"       value-name:"NAME=PATH"    description:"An input to provide to the task (can be specified multiple times)"`
-	InputsFrom     flaghelpers.JobFlag          `short:"j" long:"inputs-from" value-name:"PIPELINE/JOB" description:"A job to base the inputs on"`
-	Outputs        []flaghelpers.OutputPairFlag `short:"o" long:"output"      value-name:"NAME=PATH"    description:"An output to fetch from the task (can be specified multiple times)"`
-	Tags           []string                     `          long:"tag"         value-name:"TAG"          description:"A tag for a specific environment (can be specified multiple times)"`
+	TaskConfigs         []atc.PathFlag                     `short:"c" long:"config" required:"true"                          description:"The task config to execute (can be specified multiple times to run builds in parallel)"`
+	Privileged          bool                               `short:"p" long:"privileged"                                      description:"Run the task with full privileges"`
+	ExcludeIgnored      bool                               `short:"x" long:"exclude-ignored"                                 description:"Skip uploading .gitignored paths. This uses the file paths that are in your Git index. Make sure it's up to date!"`
+	ExcludeSubmodules   bool                               `          long:"exclude-submodules"                              description:"Skip uploading the git submodules of inputs, which are otherwise uploaded if initialized"`
+	Exclude             []string                           `          long:"exclude"              value-name:"PATTERN"       description:"Skip uploading the paths of inputs, or the files or directories anywhere in them, matching PATTERN (can be specified multiple times)"`
+	NoDefaultExcludes   bool                               `          long:"no-default-excludes"                             description:"Upload the paths excluded by ~/.flyexclude and the exclude list of .fly.yml"`
+	Inputs              []flaghelpers.InputPairFlag        `short:"i" long:"input"                value-name:"NAME=PATH"     description:"An input to provide to the task (can be specified multiple times)"`
+	InputFromStdin      flaghelpers.StdinInputFlag         `          long:"input-from-stdin"     value-name:"NAME[=FILE]"   description:"An input to provide to the task holding a single file, called FILE or stdin, of what's piped to fly"`
+	InputsFrom          flaghelpers.JobFlag                `short:"j" long:"inputs-from"          value-name:"PIPELINE/JOB"  description:"A job to base the inputs on"`
+	SameInputsAs        int                                `          long:"same-inputs-as"       value-name:"BUILD"         description:"Use the same inputs as an earlier build executed from this machine with --record-inputs, where still cached"`
+	RecordInputs        bool                               `          long:"record-inputs"                                   description:"Keep a copy of the inputs uploaded, to execute with them again with --same-inputs-as"`
+	Outputs             []flaghelpers.OutputPairFlag       `short:"o" long:"output"               value-name:"NAME=PATH"     description:"An output to fetch from the task (can be specified multiple times)"`
+	OutputFilters       []string                           `          long:"output-filter"        value-name:"PATTERN"       description:"Fetch the task's outputs whose names match PATTERN, each into a directory named after it (can be specified multiple times)"`
+	Var                 []flaghelpers.VariablePairFlag     `short:"v" long:"var"                  value-name:"[NAME=STRING]" description:"Specify a string value to set for a ((variable)) in the task config"`
+	YAMLVar             []flaghelpers.YAMLVariablePairFlag `short:"y" long:"yaml-var"             value-name:"[NAME=YAML]"   description:"Specify a YAML value to set for a ((variable)) in the task config"`
+	VarsFrom            []atc.PathFlag                     `short:"l" long:"load-vars-from"                                  description:"Load values for ((variables)) in the task config from a YAML file"`
+	Env                 []flaghelpers.EnvPairFlag          `short:"e" long:"env"                  value-name:"KEY=VALUE"     description:"Set a param of the task, overriding the task config, the environment and --env-file (can be specified multiple times)"`
+	EnvFiles            []atc.PathFlag                     `          long:"env-file"             value-name:"PATH"          description:"Set params of the task from the KEY=VALUE lines of a .env file, overriding the task config and the environment (can be specified multiple times)"`
+	NoInheritEnv        bool                               `          long:"no-inherit-env"                                  description:"Don't override the task config's params with the environment variables of the same name"`
+	RequireVars         bool                               `          long:"require-vars"                                    description:"Fail if any ((variables)) in the task config aren't given by flags or var sources, rather than leaving them for the ATC to resolve"`
+	VaultAddr           string                             `          long:"vault-addr"           value-name:"URL"           description:"Resolve ((variables)) not given by flags from the Vault at this address"`
+	VaultPath           string                             `          long:"vault-path"           value-name:"PATH"          description:"Path prefix in Vault to look ((variables)) up under" default:"/concourse"`
+	VaultToken          string                             `          long:"vault-token"          value-name:"TOKEN"         description:"Token to authenticate with Vault" env:"VAULT_TOKEN"`
+	CredHubURL          string                             `          long:"credhub-url"          value-name:"URL"           description:"Resolve ((variables)) not given by flags from the CredHub at this address"`
+	CredHubPath         string                             `          long:"credhub-path"         value-name:"PATH"          description:"Path prefix in CredHub to look ((variables)) up under" default:"/concourse"`
+	CredHubClient       string                             `          long:"credhub-client"       value-name:"ID"            description:"UAA client ID to authenticate with CredHub" env:"CREDHUB_CLIENT"`
+	CredHubSecret       string                             `          long:"credhub-secret"       value-name:"SECRET"        description:"UAA client secret to authenticate with CredHub" env:"CREDHUB_SECRET"`
+	CredHubCACert       atc.PathFlag                       `          long:"credhub-ca-cert"      value-name:"PATH"          description:"CA certificate to verify CredHub and UAA with"`
+	AWSSecretsPrefix    string                             `          long:"aws-secrets-prefix"   value-name:"PREFIX"        description:"Resolve ((variables)) not given by flags from AWS, looking them up under this prefix"`
+	AWSSecretsSource    string                             `          long:"aws-secrets-source"   value-name:"SOURCE"        description:"AWS service to look ((variables)) up in" choice:"secretsmanager" choice:"ssm" default:"secretsmanager"`
+	NoRedact            bool                               `          long:"no-redact"                                       description:"Show the values of ((variables)) in the build's output rather than redacting them"`
+	CPULimit            uint64                             `          long:"cpu-limit"            value-name:"SHARES"        description:"Limit the task's container to this many CPU shares"`
+	MemoryLimit         flaghelpers.ByteSizeFlag           `          long:"memory-limit"         value-name:"SIZE"          description:"Limit the task's container to this much memory (e.g. 2GB)"`
+	Tags                []string                           `          long:"tag"                  value-name:"TAG"           description:"A tag for a specific environment (can be specified multiple times)"`
+	Stats               bool                               `          long:"stats"                                           description:"Show the CPU and memory usage of the build's task container while it runs"`
+	MaxLogSize          flaghelpers.ByteSizeFlag           `          long:"max-log-size"         value-name:"SIZE"          description:"Stop printing the build's logs after SIZE bytes (e.g. 100MB)"`
+	JUnitOutput         string                             `          long:"junit-output"         value-name:"PATH"          description:"Write a JUnit XML report of the build's steps to PATH once it completes"`
+	JUnitTAP            bool                               `          long:"junit-tap"                                       description:"Also report the TAP test results in the build's output in the JUnit report"`
+	DownloadParallelism int                                `          long:"download-parallelism" value-name:"N"             description:"Number of outputs to download at once (default: all of them)"`
+	JSON                bool                               `          long:"json"                                            description:"Print the build's events as lines of json rather than rendering them, with fly's own messages on stderr"`
+	Timestamps          bool                               `          long:"timestamps"                                      description:"Prefix each line of the build's logs with when it was received and how long after the build started"`
+	LogFile             string                             `          long:"log-file"             value-name:"PATH"          description:"Also write the build's events to PATH as lines of json, with when they were received, to show again with fly replay"`
+	Quiet               bool                               `          long:"quiet"                                           description:"Don't report the progress of long uploads of inputs, e.g. to keep CI logs short"`
+	UploadRate          flaghelpers.ByteRateFlag           `          long:"upload-rate"          value-name:"RATE"          description:"Limit the combined upload rate of all inputs (e.g. 5MB/s)"`
+	CompressionLevel    int                                `          long:"compression-level"    value-name:"1..9"          description:"Gzip level to compress inputs with; higher is smaller but slower (default: balanced)"`
+	Xattrs              bool                               `          long:"xattrs"                                          description:"Upload the extended attributes of the inputs' files (Linux only)"`
+	SubSecondMtimes     bool                               `          long:"subsecond-mtimes"                                description:"Keep the inputs' modification times to the nanosecond rather than the second"`
+	SpecialFiles        string                             `          long:"special-files"        value-name:"POLICY"        description:"What to do with FIFOs, sockets and devices in the inputs: leave them out with a warning, archive them, or fail" choice:"skip" choice:"preserve" choice:"fail" default:"skip"`
+	SkipUnreadable      bool                               `          long:"skip-unreadable"                                 description:"Leave files and directories of the inputs that can't be read out with a warning, rather than failing the upload"`
+	StripSpecialBits    bool                               `          long:"strip-special-bits"                              description:"Drop the setuid, setgid and sticky bits of the inputs' files"`
+	Matrix              []flaghelpers.MatrixFlag           `          long:"matrix"               value-name:"NAME=VALUES"   description:"Run a build for every combination of comma-separated VALUES (can be specified multiple times)"`
+	Worker              string                             `          long:"worker"               value-name:"NAME"          description:"Run the build on the given worker, e.g. to debug a failure specific to it (admins only)"`
+	Caches              []flaghelpers.CacheFlag            `          long:"cache"                value-name:"NAME=PATH"     description:"Keep the contents of PATH in the task between executes of it (can be specified multiple times)"`
+	ImageFromDocker     string                             `          long:"image-from-docker"    value-name:"IMAGE"         description:"Run the task in a locally built Docker image rather than its configured image"`
+	Timeout             time.Duration                      `          long:"timeout"              value-name:"DURATION"      description:"Abort the builds if they haven't finished within DURATION of starting, e.g. 30m, and exit 124"`
+	NoAbortOnInterrupt  bool                               `          long:"no-abort-on-interrupt"                           description:"On Ctrl-C, leave the builds running and only stop following them"`
+	ExpectedDuration    time.Duration                      `          long:"expected-duration"    value-name:"DURATION"      description:"Warn if your token expires sooner than this (0 to disable)" default:"1h"`
+	PushMetrics         string                             `          long:"push-metrics"         value-name:"URL"           description:"Push how the builds went to the Prometheus Pushgateway at URL once they finish"`
+	NotifyURL           string                             `          long:"notify-url"           value-name:"URL"           description:"Post to the webhook at URL once each build finishes"`
+	NotifyFormat        string                             `          long:"notify-format"        value-name:"FORMAT"        description:"Payload to post to --notify-url: the build's fields as json, or a Slack message" choice:"json" choice:"slack" default:"json"`
+	NotifyTemplate      string                             `          long:"notify-template"      value-name:"TEMPLATE"      description:"Go template of the payload to post to --notify-url instead, given the build's fields"`
+	GitHubStatus        flaghelpers.GitHubCommitFlag       `          long:"github-status"        value-name:"OWNER/REPO@SHA" description:"Post the builds' progress as statuses of the GitHub commit"`
+	GitHubContext       string                             `          long:"github-context"       value-name:"NAME"          description:"Name of the statuses posted by --github-status" default:"concourse/fly"`
+	GitHubToken         string                             `          long:"github-token"         value-name:"TOKEN"         description:"Token to post statuses to GitHub with" env:"GITHUB_TOKEN"`
+	GitHubAPI           string                             `          long:"github-api"           value-name:"URL"           description:"URL of GitHub's API, e.g. for GitHub Enterprise" env:"GITHUB_API_URL" default:"https://api.github.com"`
+	CollectTests        []string                           `          long:"collect-tests"        value-name:"OUTPUT/GLOB"   description:"Summarize the JUnit reports matching GLOB in a fetched output once the build finishes (can be specified multiple times)"`
+	CIOutput            bool                               `          long:"ci-output"                                       description:"Write the builds' IDs, URLs and statuses for later steps of the GitHub Actions or GitLab CI job running fly"`
+	ImagePullSecrets    []flaghelpers.ImagePullSecretFlag  `          long:"image-pull-secret"    value-name:"USER:PASS@REGISTRY" description:"Credentials to pull the task's image from the registry with (can be specified multiple times)"`
+	ImagePullConfig     atc.PathFlag                       `          long:"image-pull-config"    value-name:"PATH"          description:"Docker config file to take credentials to pull the task's image with from, e.g. ~/.docker/config.json"`
 }
 
 func (command *ExecuteCommand) Execute(args []string) error {
@@ -39,136 +125,1244 @@ func (command *ExecuteCommand) Execute(args []string) error {
 		return err
 	}
 
-	taskConfigFile := command.TaskConfig
-	excludeIgnored := command.ExcludeIgnored
+	target, err = ensureSessionLasts(target, command.ExpectedDuration)
+	if err != nil {
+		return err
+	}
+
+	if command.CompressionLevel != 0 && (command.CompressionLevel < gzip.BestSpeed || command.CompressionLevel > gzip.BestCompression) {
+		return fmt.Errorf("invalid compression level %d (expected 1..9)", command.CompressionLevel)
+	}
+
+	if command.SameInputsAs != 0 && (len(command.Inputs) > 0 || command.InputsFrom.PipelineName != "") {
+		return errors.New("--same-inputs-as cannot be combined with --input or --inputs-from")
+	}
+
+	// older records are only removed once the builds are done, as one of
+	// them may be what's uploaded for --same-inputs-as
+	if command.RecordInputs {
+		atexit.Register(func(int) {
+			err := executehelpers.PruneRecords(rc.InputsDir(Fly.Target))
+			if err != nil {
+				fmt.Fprintln(ui.Stderr, "failed to remove old recorded inputs:", err)
+			}
+		})
+	}
+
+	if command.InputFromStdin.Name != "" {
+		err := command.readStdinInput()
+		if err != nil {
+			return err
+		}
+	}
+
+	if command.Worker != "" {
+		if !target.Token().IsAdmin() {
+			return errors.New("only admins can run builds on a specific worker")
+		}
+
+		workerTags, err := executehelpers.WorkerTags(target.Client(), command.Worker)
+		if err != nil {
+			return err
+		}
+
+		command.Tags = append(command.Tags, workerTags...)
+	}
+
+	// patterns match directories without a trailing slash, as in target/
+	for i, pattern := range command.Exclude {
+		command.Exclude[i] = strings.TrimSuffix(pattern, "/")
+	}
+
+	if !command.NoDefaultExcludes {
+		defaultExcludes, err := rc.LoadExcludes()
+		if err != nil {
+			return err
+		}
+
+		command.Exclude = append(defaultExcludes, command.Exclude...)
+	}
+
+	sources, err := command.varSources()
+	if err != nil {
+		return err
+	}
 
-	taskConfig, err := config.LoadTaskConfig(string(taskConfigFile), args)
+	vars, err := executehelpers.Vars(command.Var, command.YAMLVar, command.VarsFrom, sources...)
 	if err != nil {
 		return err
 	}
 
+	for _, pattern := range command.CollectTests {
+		outputName := strings.SplitN(pattern, "/", 2)[0]
+
+		fetched := false
+		for _, output := range command.Outputs {
+			if output.Name == outputName {
+				fetched = true
+			}
+		}
+
+		if !fetched {
+			return fmt.Errorf("test reports '%s' must be in an output fetched with --output", pattern)
+		}
+	}
+
+	if command.GitHubStatus.SHA != "" && command.GitHubToken == "" {
+		return errors.New("--github-status needs a token to post statuses with, e.g. in GITHUB_TOKEN")
+	}
+
+	var notifier *notify.Notifier
+	if command.NotifyURL != "" {
+		notifier, err = notify.New(command.NotifyURL, command.NotifyFormat, command.NotifyTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries := executehelpers.ExpandMatrix(command.Matrix)
+
+	if len(command.TaskConfigs)*len(entries) > 1 && (len(command.Outputs) > 0 || len(command.OutputFilters) > 0) {
+		return errors.New("outputs cannot be fetched when executing several builds, as every build would write to the same paths")
+	}
+
+	if len(command.TaskConfigs)*len(entries) > 1 && command.JSON {
+		return errors.New("--json can only be used with a single build, as the events of several would be interleaved")
+	}
+
+	if len(command.TaskConfigs)*len(entries) > 1 && len(command.Caches) > 0 {
+		return errors.New("caches cannot be used when executing several builds, as every build would write to the same caches")
+	}
+
+	if len(command.TaskConfigs)*len(entries) > 1 && command.LogFile != "" {
+		return errors.New("--log-file can only be used with a single build, as the events of several would be interleaved")
+	}
+
+	var logFile *os.File
+	if command.LogFile != "" {
+		logFile, err = os.Create(command.LogFile)
+		if err != nil {
+			return err
+		}
+
+		defer logFile.Close()
+	}
+
+	var runs []*executeRun
+	for _, taskConfig := range command.TaskConfigs {
+		for _, entry := range entries {
+			run, err := command.prepare(target, string(taskConfig), entry, vars, args)
+			if err != nil {
+				return err
+			}
+
+			var label []string
+			if len(command.TaskConfigs) > 1 {
+				label = append(label, string(taskConfig))
+			}
+
+			if len(entry) > 0 {
+				label = append(label, entry.String())
+			}
+
+			run.label = strings.Join(label, " ")
+			run.logFile = logFile
+			runs = append(runs, run)
+		}
+	}
+
 	client := target.Client()
-	inputs, err := executehelpers.DetermineInputs(
-		client,
-		target.Team(),
-		taskConfig.Inputs,
-		command.Inputs,
-		command.InputsFrom,
-	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// if told to terminate while the builds are created, those created so
+	// far are aborted rather than left running
+	creating, stopCreating := signal.NotifyContext(ctx, terminationSignals...)
+
+	for i, run := range runs {
+		err := command.createBuild(creating, target, run)
+		if err != nil && creating.Err() != nil {
+			stopCreating()
+
+			fmt.Fprintf(ui.Stderr, "\naborting...\n")
+
+			for _, err := range abortRuns(ctx, client, runs[:i]) {
+				fmt.Fprintln(ui.Stderr, "failed to abort:", err)
+			}
+
+			atexit.Exit(2)
+		}
+
+		if err != nil {
+			stopCreating()
+			return err
+		}
+	}
+
+	stopCreating()
+
+	terminate := make(chan os.Signal, 1)
+
+	if command.NoAbortOnInterrupt {
+		go detachOnSignal(client, terminate, runs)
+	} else {
+		go abortOnSignal(ctx, client, terminate, runs, cancel)
+	}
+
+	signal.Notify(terminate, terminationSignals...)
+
+	timedOut := func() bool { return false }
+	if command.Timeout > 0 {
+		var streamed func()
+		timedOut, streamed = abortAfter(ctx, client, command.Timeout, runs)
+
+		for _, run := range runs {
+			run.streamed = streamed
+		}
+	}
+
+	uploadClient := client.HTTPClient()
+
+	var uploads *pushmetrics.UploadCounter
+	if command.PushMetrics != "" {
+		uploadClient, uploads = pushmetrics.CountUploads(uploadClient)
+	}
+
+	var uploader archive.Uploader
+	if command.UploadRate > 0 {
+		uploader = archive.NewThrottledUploader(uploadClient, int64(command.UploadRate))
+	} else {
+		uploader = archive.NewUploader(uploadClient)
+	}
+
+	var stats statshelpers.Hijacker
+	if command.Stats {
+		stats = newStatsHijacker(target)
+	}
+
+	var junit *eventstream.JUnitReport
+	if command.JUnitOutput != "" {
+		junit = &eventstream.JUnitReport{ParseTAP: command.JUnitTAP}
+	}
+
+	if len(runs) == 1 {
+		exitCode, err := command.run(ctx, target, uploader, stats, junit, runs[0], os.Stdout)
+		if err != nil {
+			command.postGitHubStatus(runs[0], "error", err.Error())
+			return err
+		}
+
+		runs[0].exitCode = exitCode
+
+		if junit != nil {
+			writeJUnitReport(command.JUnitOutput, junit)
+		}
+
+		command.report(client, runs, uploads, notifier)
+
+		if ctx.Err() != nil {
+			atexit.Exit(2)
+		}
+
+		if len(command.CollectTests) > 0 {
+			exitCode = command.collectTests(runs[0], exitCode)
+		}
+
+		if timedOut() {
+			atexit.Exit(CommandTimeoutExitCode)
+		}
+
+		atexit.Exit(exitCode)
+
+		return nil
+	}
+
+	// every build's events are streamed at once, each line prefixed with
+	// the build it came from
+	labels := make([]string, len(runs))
+	for i, run := range runs {
+		labels[i] = run.label
+	}
+
+	exitCodes := multibuild.Follow(os.Stdout, labels, func(i int, out io.Writer) (int, error) {
+		return command.run(ctx, target, uploader, stats, junit, runs[i], out)
+	})
+
+	for i, run := range runs {
+		run.exitCode = exitCodes[i]
+	}
+
+	if junit != nil {
+		writeJUnitReport(command.JUnitOutput, junit)
+	}
+
+	command.report(client, runs, uploads, notifier)
+
+	if ctx.Err() != nil {
+		atexit.Exit(2)
+	}
+
+	// fail with the exit status of the first build that failed
+	builds := make([]multibuild.Build, len(runs))
+	for i, run := range runs {
+		builds[i] = multibuild.Build{Label: run.label, ID: run.build.ID, ExitCode: run.exitCode}
+	}
+
+	exitCode, err := multibuild.Summarize(os.Stdout, builds, Fly.PrintTableHeaders)
 	if err != nil {
 		return err
 	}
 
+	if timedOut() {
+		atexit.Exit(CommandTimeoutExitCode)
+	}
+
+	atexit.Exit(exitCode)
+
+	return nil
+}
+
+// executeRun is a single build started by fly execute, i.e. one task config
+// with one entry of its --matrix.
+type executeRun struct {
+	label    string
+	entry    executehelpers.MatrixEntry
+	pipeline string
+
+	vars    *executehelpers.RecordedVars
+	inputs  []executehelpers.Input
+	outputs []executehelpers.Output
+	caches  []executehelpers.Cache
+	plan    atc.Plan
+
+	// replan creates the plan for the run's current inputs, outputs and
+	// caches, e.g. once they've been given fresh pipes
+	replan func() (atc.Plan, error)
+
+	buildLock sync.Mutex
+	build     atc.Build
+	url       string
+
+	exitCode int
+	duration time.Duration
+
+	// logFile is where the build's events are written, as asked by
+	// --log-file
+	logFile *os.File
+
+	// streamed, if set, is called once the build's events have all been
+	// rendered, so that --timeout stops before its outputs are fetched
+	streamed func()
+}
+
+// prepare loads the task config with the matrix entry's values and creates
+// the pipes and plan for its build. An entry's values are available to the
+// task config as ((variables)), and to the task as params.
+func (command *ExecuteCommand) prepare(
+	target rc.Target,
+	taskConfigPath string,
+	entry executehelpers.MatrixEntry,
+	vars template.Variables,
+	args []string,
+) (*executeRun, error) {
+	recordedVars := executehelpers.NewRecordedVars(template.NewMultiVars([]template.Variables{
+		entry.Variables(),
+		vars,
+	}))
+
+	span := tracing.Start("load task config", "fly.config", taskConfigPath)
+	taskConfig, hooks, err := config.LoadTask(taskConfigPath, args, recordedVars, command.RequireVars, !command.NoInheritEnv)
+	span.Fail(err)
+	span.End()
+	if err != nil {
+		return nil, err
+	}
+
+	err = command.applyEnv(&taskConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range entry {
+		if taskConfig.Params == nil {
+			taskConfig.Params = map[string]string{}
+		}
+
+		taskConfig.Params[pair.Name] = pair.Value
+	}
+
+	// limits given on the command line win over the task config's own
+	if command.CPULimit != 0 {
+		cpu := command.CPULimit
+		taskConfig.Limits.CPU = &cpu
+	}
+
+	if command.MemoryLimit != 0 {
+		memory := uint64(command.MemoryLimit)
+		taskConfig.Limits.Memory = &memory
+	}
+
+	credentials, err := command.imagePullCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(credentials) > 0 && command.ImageFromDocker == "" && taskConfig.ImageResource != nil {
+		if !registrycreds.Apply(taskConfig.ImageResource, credentials) {
+			fmt.Fprintf(ui.Stderr, "no image pull secret is for the registry of %s; pulling it without one\n", taskConfigPath)
+		}
+	}
+
+	client := target.Client()
+
+	var inputs []executehelpers.Input
+	pipeline := command.InputsFrom.PipelineName
+
+	if command.SameInputsAs != 0 {
+		pipeline, inputs, err = executehelpers.RecordedInputs(
+			client,
+			rc.InputsDir(Fly.Target),
+			command.SameInputsAs,
+			taskConfig.Inputs,
+		)
+	} else {
+		inputs, err = executehelpers.DetermineInputs(
+			client,
+			target.Team(),
+			taskConfig.Inputs,
+			command.Inputs,
+			command.InputsFrom,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the input read from stdin was archived up front
+	for i := range inputs {
+		if inputs[i].Name == command.InputFromStdin.Name {
+			inputs[i].Archive = inputs[i].Path
+			inputs[i].Path = ""
+		}
+	}
+
+	if command.ImageFromDocker != "" {
+		pipe, err := executehelpers.CreatePipe(client, "input", executehelpers.DockerImageInputName)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs = append(inputs, executehelpers.Input{
+			Name:        executehelpers.DockerImageInputName,
+			Pipe:        pipe,
+			DockerImage: command.ImageFromDocker,
+		})
+	}
+
+	outputMappings, err := executehelpers.FilterOutputs(taskConfig.Outputs, command.Outputs, command.OutputFilters)
+	if err != nil {
+		return nil, err
+	}
+
 	outputs, err := executehelpers.DetermineOutputs(
 		client,
 		taskConfig.Outputs,
-		command.Outputs,
+		outputMappings,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	plan, err := executehelpers.CreateBuildPlan(
-		target,
-		command.Privileged,
-		inputs,
-		outputs,
-		taskConfig,
-		command.Tags,
-	)
+	taskName := strings.TrimSuffix(filepath.Base(taskConfigPath), filepath.Ext(taskConfigPath))
 
+	caches, err := executehelpers.DetermineCaches(
+		client,
+		filepath.Join(rc.CacheDir(Fly.Target), target.Team().Name(), taskName),
+		command.Caches,
+		&taskConfig,
+	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var build atc.Build
-	if command.InputsFrom.PipelineName != "" {
-		build, err = target.Team().CreatePipelineBuild(command.InputsFrom.PipelineName, plan)
-	} else {
-		build, err = client.CreateBuild(plan)
+	run := &executeRun{
+		entry:    entry,
+		pipeline: pipeline,
+		vars:     recordedVars,
+		inputs:   inputs,
+		outputs:  outputs,
+		caches:   caches,
+	}
+
+	// the task file's tags are added to those given by --tag
+	tags := append(append([]string{}, command.Tags...), hooks.Tags...)
+
+	run.replan = func() (atc.Plan, error) {
+		planInputs := run.inputs
+		planOutputs := run.outputs
+		for _, cache := range run.caches {
+			planInputs = append(planInputs, cache.Input)
+			planOutputs = append(planOutputs, cache.Output)
+		}
+
+		return executehelpers.CreateBuildPlan(
+			target,
+			command.Privileged,
+			planInputs,
+			planOutputs,
+			taskConfig,
+			hooks,
+			tags,
+		)
 	}
+
+	run.plan, err = run.replan()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
+	return run, nil
+}
+
+// createBuild creates the run's build from its plan, and records its inputs
+// for --same-inputs-as.
+func (command *ExecuteCommand) createBuild(ctx context.Context, target rc.Target, run *executeRun) error {
+	client := target.Client()
+
 	clientURL, err := url.Parse(client.URL())
 	if err != nil {
 		return err
 	}
+
+	var build atc.Build
+
+	span := tracing.Start("create build")
+	build, err = executehelpers.CreateBuild(ctx, client, target.Team().Name(), run.pipeline, run.plan)
+	span.Fail(err)
+	span.End()
+	if err != nil {
+		return fmt.Errorf("could not create the build: %s", err)
+	}
+
 	buildURL, err := url.Parse(build.URL)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("executing build %d at %s \n", build.ID, clientURL.ResolveReference(buildURL))
 
-	terminate := make(chan os.Signal, 1)
+	run.buildLock.Lock()
+	run.build = build
+	run.buildLock.Unlock()
 
-	go abortOnSignal(client, terminate, build)
+	run.url = clientURL.ResolveReference(buildURL).String()
 
-	signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+	command.postGitHubStatus(run, "pending", fmt.Sprintf("build #%s started", run.build.Name))
+
+	if command.RecordInputs {
+		run.inputs, err = executehelpers.RecordInputs(rc.InputsDir(Fly.Target), run.build.ID, run.pipeline, run.inputs)
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to record the inputs of build %d: %s\n", run.build.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// currentBuild returns the run's build, which is replaced if its inputs
+// couldn't be uploaded.
+func (run *executeRun) currentBuild() atc.Build {
+	run.buildLock.Lock()
+	defer run.buildLock.Unlock()
+
+	return run.build
+}
+
+// imagePullCredentials returns the credentials given by --image-pull-secret
+// and --image-pull-config, in that order, so that those given as flags win.
+func (command *ExecuteCommand) imagePullCredentials() ([]registrycreds.Credential, error) {
+	var credentials []registrycreds.Credential
+	for _, secret := range command.ImagePullSecrets {
+		credentials = append(credentials, registrycreds.Credential{
+			Registry: secret.Registry,
+			Username: secret.Username,
+			Password: secret.Password,
+		})
+	}
+
+	if command.ImagePullConfig != "" {
+		fromConfig, err := registrycreds.LoadDockerConfig(string(command.ImagePullConfig))
+		if err != nil {
+			return nil, err
+		}
+
+		credentials = append(credentials, fromConfig...)
+	}
+
+	return credentials, nil
+}
+
+// specialFilePolicies maps the choices of --special-files to what inputs are
+// archived with.
+var specialFilePolicies = map[string]archive.SpecialFilePolicy{
+	"skip":     archive.SkipSpecialFiles,
+	"preserve": archive.PreserveSpecialFiles,
+	"fail":     archive.FailOnSpecialFiles,
+}
+
+// maxBuildAttempts is how many builds are run for a task whose inputs can't
+// be uploaded, each with fresh pipes, before giving up.
+const maxBuildAttempts = 3
+
+// errInputsNotUploaded is returned by runBuild when the build was aborted as
+// its inputs couldn't be uploaded, so that it can be run again.
+var errInputsNotUploaded = errors.New("the build's inputs could not be uploaded")
+
+// run uploads a build's inputs, renders its events to out, and downloads
+// its outputs, returning the exit status of the build. If stats is given,
+// the usage of the build's task container is sampled with it as it runs. If
+// junit is given, the build's results are added to it.
+//
+// If the inputs can't be uploaded, the ATC would wait for them forever, so
+// the build is aborted and replaced with a new one with fresh pipes instead.
+func (command *ExecuteCommand) run(
+	ctx context.Context,
+	target rc.Target,
+	uploader archive.Uploader,
+	stats statshelpers.Hijacker,
+	junit *eventstream.JUnitReport,
+	run *executeRun,
+	out io.Writer,
+) (int, error) {
+	started := time.Now()
+	defer func() {
+		run.duration = time.Since(started)
+	}()
+
+	defer func() {
+		for _, cache := range run.caches {
+			cache.Discard()
+		}
+	}()
+
+	client := target.Client()
+
+	for attempt := 1; ; attempt++ {
+		exitCode, err := command.runBuild(ctx, client, uploader, stats, junit, run, out)
+		if err != errInputsNotUploaded {
+			return exitCode, err
+		}
+
+		if attempt == maxBuildAttempts {
+			return 0, fmt.Errorf("the inputs of the build could not be uploaded after %d attempts", maxBuildAttempts)
+		}
+
+		fmt.Fprintf(out, "the inputs of build %d could not be uploaded; starting a new build with fresh pipes\n", run.build.ID)
+
+		err = executehelpers.RenewPipes(client, run.inputs, run.outputs, run.caches)
+		if err != nil {
+			return 0, err
+		}
+
+		// the log file is of the build that was finished, not those that
+		// were given up on
+		if run.logFile != nil {
+			err = truncate(run.logFile)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		run.plan, err = run.replan()
+		if err != nil {
+			return 0, err
+		}
+
+		err = command.createBuild(ctx, target, run)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (command *ExecuteCommand) runBuild(
+	ctx context.Context,
+	client concourse.Client,
+	uploader archive.Uploader,
+	stats statshelpers.Hijacker,
+	junit *eventstream.JUnitReport,
+	run *executeRun,
+	out io.Writer,
+) (int, error) {
+	// with --json, only the build's events are written to out
+	events := out
+	if command.JSON {
+		out = ui.Stderr
+	}
+
+	fmt.Fprintf(out, "executing build %d at %s \n", run.build.ID, run.url)
+
+	// uploads and downloads are stopped if the build's replaced
+	attemptCtx, cancelAttempt := context.WithCancel(ctx)
+	defer cancelAttempt()
+
+	uploadFailed := make(chan struct{})
+
+	// set if an input couldn't be read, which a new build wouldn't fix
+	var inputErr error
+
+	phases := executehelpers.StartPhases(out)
 
 	inputChan := make(chan interface{})
 	go func() {
-		for _, i := range inputs {
+		span := tracing.Start("upload inputs", "fly.build_id", strconv.Itoa(run.build.ID))
+		defer span.End()
+
+		defer close(inputChan)
+		defer phases.Uploaded()
+
+		// a corrupted input would make for a build that's worse than none,
+		// and one that wasn't uploaded at all would leave the build waiting
+		// for it forever, so either way the build's aborted
+		abortIfFailed := func(err error) bool {
+			if err == nil || ctx.Err() != nil {
+				return false
+			}
+
+			switch err.(type) {
+			case archive.ErrUploadCorrupted:
+				fmt.Fprintln(ui.Stderr, "aborting the build, since one of its inputs did not arrive intact")
+			case executehelpers.InputError:
+				inputErr = err
+			default:
+				close(uploadFailed)
+			}
+
+			err = executehelpers.AbortBuild(ctx, client, run.build.ID)
+			if err != nil {
+				fmt.Fprintln(ui.Stderr, "failed to abort:", err)
+			}
+
+			return true
+		}
+
+		archiveOpts := archive.Options{
+			CompressionLevel: command.CompressionLevel,
+			Xattrs:           command.Xattrs,
+			SubSecondMtimes:  command.SubSecondMtimes,
+			SpecialFiles:     specialFilePolicies[command.SpecialFiles],
+			SkipUnreadable:   command.SkipUnreadable,
+			StripSpecialBits: command.StripSpecialBits,
+		}
+
+		// caches aren't git repositories, so they're never filtered by
+		// --exclude-ignored
+		for _, cache := range run.caches {
+			err := executehelpers.Upload(attemptCtx, uploader, cache.Input, false, false, !command.Quiet, archiveOpts)
+			if abortIfFailed(err) {
+				return
+			}
+		}
+
+		// caches are left whole, as they're often of exactly what's excluded,
+		// e.g. node_modules
+		inputOpts := archiveOpts
+		inputOpts.Exclude = command.Exclude
+
+		for _, i := range run.inputs {
+			var err error
 			if i.Path != "" {
-				executehelpers.Upload(client, i, excludeIgnored)
+				err = executehelpers.Upload(attemptCtx, uploader, i, command.ExcludeIgnored, command.ExcludeSubmodules, !command.Quiet, inputOpts)
+			} else if i.Archive != "" {
+				err = executehelpers.UploadArchive(attemptCtx, uploader, i)
+			} else if i.DockerImage != "" {
+				err = executehelpers.UploadDockerImage(attemptCtx, uploader, i, command.CompressionLevel)
+			}
+
+			if abortIfFailed(err) {
+				return
 			}
 		}
-		close(inputChan)
 	}()
 
-	var outputChans []chan (interface{})
-	if len(outputs) > 0 {
-		for i, output := range outputs {
-			outputChans = append(outputChans, make(chan interface{}, 1))
-			go func(o executehelpers.Output, outputChan chan<- interface{}) {
-				if o.Path != "" {
-					executehelpers.Download(client, o)
-				}
+	// by the name of each output or cache that failed to download
+	var downloadErrs map[string]error
 
-				close(outputChan)
-			}(output, outputChans[i])
+	outputChan := make(chan interface{})
+	go func() {
+		outputs := run.outputs
+		for _, cache := range run.caches {
+			outputs = append(outputs, cache.Output)
 		}
+
+		downloadErrs = executehelpers.DownloadAll(attemptCtx, client, outputs, command.DownloadParallelism)
+		close(outputChan)
+	}()
+
+	var redact []string
+	if !command.NoRedact {
+		redact = run.vars.Values()
 	}
 
-	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", build.ID))
+	span := tracing.Start("connect to event stream", "fly.build_id", strconv.Itoa(run.build.ID))
+	eventSource, err := eventstream.Events(ctx, client, fmt.Sprintf("%d", run.build.ID))
+	span.Fail(err)
+	span.End()
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	if stats != nil {
+		statsCtx, stopStats := context.WithCancel(ctx)
+		defer stopStats()
+
+		go statshelpers.Poll(statsCtx, client, stats, run.build.ID, ui.Stderr)
+	}
+
+	span = tracing.Start("wait for build", "fly.build_id", strconv.Itoa(run.build.ID))
+	exitCode := eventstream.RenderWithOptions(events, eventSource, eventstream.RenderOptions{
+		MaxLogBytes:      int64(command.MaxLogSize),
+		Redact:           redact,
+		StepSummary:      true,
+		StepNames:        eventstream.StepNames(run.plan),
+		JUnit:            junit,
+		JUnitSuite:       strings.TrimSpace(fmt.Sprintf("build %d %s", run.build.ID, run.label)),
+		TaskInitializing: phases.Initializing,
+		TaskStarted:      phases.Running,
+		JSON:             command.JSON,
+		LogFile:          logFileWriter(run.logFile),
+		Timestamps:       command.Timestamps,
+	})
 	eventSource.Close()
+	finished := time.Now()
+	span.SetAttribute("fly.exit_code", strconv.Itoa(exitCode))
+	span.End()
+
+	if ctx.Err() != nil {
+		return 2, nil
+	}
+
+	select {
+	case <-uploadFailed:
+		// the aborted build's outputs will never come
+		cancelAttempt()
+		<-inputChan
+		<-outputChan
+
+		return 0, errInputsNotUploaded
+	default:
+	}
+
+	if run.streamed != nil {
+		run.streamed()
+	}
 
 	<-inputChan
 
-	if len(outputs) > 0 {
-		for _, outputChan := range outputChans {
-			<-outputChan
+	if inputErr != nil {
+		cancelAttempt()
+		<-outputChan
+
+		return 0, inputErr
+	}
+
+	fmt.Fprintf(out, "phases: %s\n", phases.Summary(finished))
+
+	<-outputChan
+
+	outputsFailed := false
+	for _, output := range run.outputs {
+		if err, failed := downloadErrs[output.Name]; failed {
+			fmt.Fprintln(ui.Stderr, err)
+			outputsFailed = true
+		}
+	}
+
+	// a failed build may not have gotten as far as filling its caches, and
+	// a cache that was only partly downloaded would replace a good one
+	if exitCode == 0 {
+		for _, cache := range run.caches {
+			if err, failed := downloadErrs[cache.Name]; failed {
+				fmt.Fprintf(ui.Stderr, "%s; keeping cache '%s' as it was\n", err, cache.Name)
+				continue
+			}
+
+			err := cache.Commit()
+			if err != nil {
+				fmt.Fprintf(ui.Stderr, "failed to save cache '%s': %s\n", cache.Name, err)
+			}
 		}
 	}
 
-	os.Exit(exitCode)
+	if exitCode == 0 && outputsFailed {
+		return 0, errors.New("the build succeeded, but its outputs could not all be downloaded")
+	}
+
+	return exitCode, nil
+}
+
+// applyEnv sets the params given by --env-file, in order, and then by --env,
+// so that the last one given wins.
+func (command *ExecuteCommand) applyEnv(taskConfig *atc.TaskConfig) error {
+	if len(command.EnvFiles) == 0 && len(command.Env) == 0 {
+		return nil
+	}
+
+	if taskConfig.Params == nil {
+		taskConfig.Params = map[string]string{}
+	}
+
+	for _, path := range command.EnvFiles {
+		env, err := config.LoadEnvFile(string(path))
+		if err != nil {
+			return fmt.Errorf("failed to read env file: %s", err)
+		}
+
+		for name, value := range env {
+			taskConfig.Params[name] = value
+		}
+	}
+
+	for _, pair := range command.Env {
+		taskConfig.Params[pair.Name] = pair.Value
+	}
 
 	return nil
 }
 
+// testReportsUncollectedExitCode is exited with when a build succeeded but
+// its test reports couldn't be collected, e.g. because they're malformed.
+const testReportsUncollectedExitCode = 65
+
+// collectTests prints a summary of the JUnit reports in the run's fetched
+// outputs given by --collect-tests, returning the exit status to exit with.
+func (command *ExecuteCommand) collectTests(run *executeRun, exitCode int) int {
+	outputPaths := map[string]string{}
+	for _, output := range run.outputs {
+		outputPaths[output.Name] = output.Path
+	}
+
+	summary, err := testreports.Collect(outputPaths, command.CollectTests)
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to collect test reports: %s\n", err)
+
+		if exitCode == 0 {
+			return testReportsUncollectedExitCode
+		}
+
+		return exitCode
+	}
+
+	fmt.Println()
+	fmt.Printf("tests: %d passed, %d failed, %d skipped (%d reports)\n", summary.Passed, summary.Failed, summary.Skipped, summary.Reports)
+
+	for _, failure := range summary.Failures {
+		fmt.Printf("  %s %s\n", ui.FailedColor.Sprint("failed"), failure)
+	}
+
+	return exitCode
+}
+
+// report pushes metrics of and notifies about the finished builds, as asked
+// by --push-metrics, --notify-url, --github-status and --ci-output. Failing
+// to is only warned about, as the builds themselves are done with.
+func (command *ExecuteCommand) report(client concourse.Client, runs []*executeRun, uploads *pushmetrics.UploadCounter, notifier *notify.Notifier) {
+	if uploads == nil && notifier == nil && command.GitHubStatus.SHA == "" && !command.CIOutput {
+		return
+	}
+
+	var metrics []pushmetrics.Build
+	var results []cioutput.Build
+	for _, run := range runs {
+		build := finishedBuild(client, run.build.ID, run.exitCode)
+
+		name := ""
+		if len(runs) > 1 {
+			name = run.label
+		}
+
+		metrics = append(metrics, pushmetrics.Build{
+			Name:       name,
+			ID:         run.build.ID,
+			Status:     build.Status,
+			ExitStatus: run.exitCode,
+			Duration:   run.duration,
+		})
+
+		results = append(results, cioutput.Build{ID: run.build.ID, URL: run.url, Status: build.Status})
+
+		command.postGitHubStatus(run, githubstatus.State(build.Status), fmt.Sprintf("build #%s %s", run.build.Name, build.Status))
+
+		if notifier != nil {
+			err := notifier.Notify(notify.Build{
+				ID:         run.build.ID,
+				Name:       run.build.Name,
+				Team:       run.build.TeamName,
+				Pipeline:   run.pipeline,
+				Job:        run.build.JobName,
+				Status:     build.Status,
+				ExitStatus: run.exitCode,
+				URL:        run.url,
+				Duration:   run.duration.Seconds(),
+			})
+			if err != nil {
+				fmt.Fprintf(ui.Stderr, "failed to notify %s: %s\n", command.NotifyURL, err)
+			}
+		}
+	}
+
+	if uploads != nil {
+		err := pushmetrics.Push(command.PushMetrics, runs[0].pipeline, command.InputsFrom.JobName, metrics, uploads.Bytes())
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to push metrics: %s\n", err)
+		}
+	}
+
+	if command.CIOutput {
+		err := cioutput.Write(results)
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to write CI output: %s\n", err)
+		}
+	}
+}
+
+// postGitHubStatus posts the state of a run's build as a status of the
+// commit given by --github-status, if any.
+func (command *ExecuteCommand) postGitHubStatus(run *executeRun, state string, description string) {
+	if command.GitHubStatus.SHA == "" {
+		return
+	}
+
+	reporter := githubstatus.Reporter{
+		API:   command.GitHubAPI,
+		Token: command.GitHubToken,
+		Owner: command.GitHubStatus.Owner,
+		Repo:  command.GitHubStatus.Repo,
+		SHA:   command.GitHubStatus.SHA,
+	}
+
+	statusContext := command.GitHubContext
+	if run.label != "" {
+		statusContext += " (" + run.label + ")"
+	}
+
+	err := reporter.Post(githubstatus.Status{
+		State:       state,
+		TargetURL:   run.url,
+		Description: description,
+		Context:     statusContext,
+	})
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to post status of %s: %s\n", command.GitHubStatus, err)
+	}
+}
+
+// varSources returns the external sources, e.g. Vault, to resolve ((vars))
+// from that aren't given by flags.
+func (command *ExecuteCommand) varSources() ([]template.Variables, error) {
+	var sources []template.Variables
+
+	if command.VaultAddr != "" {
+		sources = append(sources, varsources.NewVault(command.VaultAddr, command.VaultPath, command.VaultToken))
+	}
+
+	if command.CredHubURL != "" {
+		var caCert []byte
+		if command.CredHubCACert != "" {
+			var err error
+			caCert, err = ioutil.ReadFile(string(command.CredHubCACert))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		credhub, err := varsources.NewCredHub(command.CredHubURL, command.CredHubPath, command.CredHubClient, command.CredHubSecret, caCert)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, credhub)
+	}
+
+	if command.AWSSecretsPrefix != "" {
+		awsSession, err := varsources.NewAWSSession()
+		if err != nil {
+			return nil, err
+		}
+
+		if command.AWSSecretsSource == "ssm" {
+			sources = append(sources, varsources.NewParameterStore(ssm.New(awsSession), command.AWSSecretsPrefix))
+		} else {
+			sources = append(sources, varsources.NewSecretsManager(secretsmanager.New(awsSession), command.AWSSecretsPrefix))
+		}
+	}
+
+	return sources, nil
+}
+
 func abortOnSignal(
+	ctx context.Context,
 	client concourse.Client,
 	terminate <-chan os.Signal,
-	build atc.Build,
+	runs []*executeRun,
+	cancel context.CancelFunc,
 ) {
 	<-terminate
 
 	fmt.Fprintf(ui.Stderr, "\naborting...\n")
 
-	err := client.AbortBuild(strconv.Itoa(build.ID))
-	if err != nil {
-		fmt.Fprintln(ui.Stderr, "failed to abort:", err)
-		return
-	}
+	// aborting may hang too, so it's given up on if told to terminate again
+	go func() {
+		for _, err := range abortRuns(ctx, client, runs) {
+			if ctx.Err() == nil {
+				fmt.Fprintln(ui.Stderr, "failed to abort:", err)
+			}
+		}
+	}()
 
-	// if told to terminate again, exit immediately
+	// if told to terminate again, stop everything and exit immediately
 	<-terminate
 	fmt.Fprintln(ui.Stderr, "exiting immediately")
-	os.Exit(2)
+	cancel()
+}
+
+// abortAfter aborts the builds once the timeout elapses, as if interrupted,
+// unless their events have all been streamed by then; streamed is to be
+// called once for each build when they have. timedOut says whether the
+// builds were aborted, so that fly can exit as timeout(1) does rather than
+// with the builds' own statuses.
+func abortAfter(ctx context.Context, client concourse.Client, timeout time.Duration, runs []*executeRun) (timedOut func() bool, streamed func()) {
+	var (
+		lock      sync.Mutex
+		pending   = len(runs)
+		stopped   bool
+		timerDone bool
+	)
+
+	timer := time.AfterFunc(timeout, func() {
+		lock.Lock()
+		if stopped {
+			lock.Unlock()
+			return
+		}
+
+		timerDone = true
+		lock.Unlock()
+
+		fmt.Fprintf(ui.Stderr, "\ntimed out after %s; aborting...\n", timeout)
+
+		for _, err := range abortRuns(ctx, client, runs) {
+			fmt.Fprintln(ui.Stderr, "failed to abort:", err)
+		}
+	})
+
+	timedOut = func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+
+		return timerDone
+	}
+
+	streamed = func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		pending--
+		if pending == 0 && !timerDone {
+			stopped = true
+			timer.Stop()
+		}
+	}
+
+	return timedOut, streamed
+}
+
+// abortRuns aborts every run's build, even if one of them can't be, and
+// returns why those that couldn't weren't.
+func abortRuns(ctx context.Context, client concourse.Client, runs []*executeRun) []error {
+	var errs []error
+
+	for _, run := range runs {
+		build := run.currentBuild()
+
+		err := executehelpers.AbortBuild(ctx, client, build.ID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("build %d: %s", build.ID, err))
+		}
+	}
+
+	return errs
+}
+
+// detachOnSignal leaves the builds running when told to terminate, telling
+// the user where to follow them instead.
+func detachOnSignal(client concourse.Client, terminate <-chan os.Signal, runs []*executeRun) {
+	<-terminate
+
+	fmt.Fprintf(ui.Stderr, "\ndetached, leaving the builds running; their outputs won't be fetched\n")
+
+	for _, run := range runs {
+		fmt.Fprintf(ui.Stderr, "    %s\n", buildURL(client, run.currentBuild()))
+	}
+
+	atexit.Exit(2)
+}
+
+// readStdinInput archives what's piped to fly, adding it to the inputs to
+// upload to every build.
+func (command *ExecuteCommand) readStdinInput() error {
+	name := command.InputFromStdin.Name
+
+	if command.SameInputsAs != 0 {
+		return errors.New("--same-inputs-as cannot be combined with --input-from-stdin")
+	}
+
+	for _, input := range command.Inputs {
+		if input.Name == name {
+			return fmt.Errorf("input '%s' is given by both --input and --input-from-stdin", name)
+		}
+	}
+
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		return fmt.Errorf("--input-from-stdin needs what to upload as '%s' piped to fly", name)
+	}
+
+	file := command.InputFromStdin.File
+	if file == "" {
+		file = executehelpers.StdinFileName
+	}
+
+	archivePath, err := executehelpers.ArchiveStdin(os.Stdin, file, command.CompressionLevel)
+	if err != nil {
+		return fmt.Errorf("failed to read input '%s' from stdin: %s", name, err)
+	}
+
+	atexit.Register(func(int) {
+		os.RemoveAll(filepath.Dir(archivePath))
+	})
+
+	command.Inputs = append(command.Inputs, flaghelpers.InputPairFlag{
+		Name: name,
+		Path: archivePath,
+	})
+
+	return nil
+}
+
+func truncate(file *os.File) error {
+	err := file.Truncate(0)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
+
+// logFileWriter keeps a missing log file from being a non-nil writer.
+func logFileWriter(file *os.File) io.Writer {
+	if file == nil {
+		return nil
+	}
+
+	return file
 }
@@ -0,0 +1,10 @@
+package commands
+
+import "github.com/concourse/fly/commands/internal/atexit"
+
+// Exit finishes up anything fly does on the way out, e.g. writing the
+// profile requested with --profile or recording the audit log, and exits
+// with the given code.
+func Exit(code int) {
+	atexit.Exit(code)
+}
@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type ExportPipelinesCommand struct {
+	Output string `short:"o" long:"output" required:"true" value-name:"DIR" description:"Directory to write the pipelines' configs and manifest to"`
+	All    bool   `short:"a" long:"all"                                     description:"Export the pipelines of every team you can see, not just the target's team"`
+}
+
+type exportManifest struct {
+	Target     string           `yaml:"target"`
+	ExportedAt time.Time        `yaml:"exported_at"`
+	Pipelines  []exportedConfig `yaml:"pipelines"`
+}
+
+type exportedConfig struct {
+	Team          string `yaml:"team"`
+	Name          string `yaml:"name"`
+	File          string `yaml:"file"`
+	ConfigVersion string `yaml:"config_version"`
+	Paused        bool   `yaml:"paused"`
+	Public        bool   `yaml:"public"`
+	Invalid       bool   `yaml:"invalid,omitempty"`
+}
+
+func (command *ExportPipelinesCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	client := target.Client()
+
+	var pipelines []atc.Pipeline
+	if command.All {
+		pipelines, err = client.ListPipelines()
+	} else {
+		pipelines, err = target.Team().ListPipelines()
+	}
+	if err != nil {
+		return err
+	}
+
+	manifest := exportManifest{
+		Target:     client.URL(),
+		ExportedAt: time.Now().UTC(),
+		Pipelines:  []exportedConfig{},
+	}
+
+	for _, pipeline := range pipelines {
+		teamName := pipeline.TeamName
+		if teamName == "" {
+			teamName = target.Team().Name()
+		}
+
+		exported, err := exportPipeline(client.Team(teamName), pipeline, command.Output)
+		if err != nil {
+			return fmt.Errorf("failed to export pipeline %s/%s: %s", teamName, pipeline.Name, err)
+		}
+
+		manifest.Pipelines = append(manifest.Pipelines, exported)
+
+		fmt.Printf("exported %s\n", exported.File)
+	}
+
+	payload, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(command.Output, "manifest.yml"), payload, 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote manifest of %d pipelines to %s\n", len(manifest.Pipelines), filepath.Join(command.Output, "manifest.yml"))
+
+	return nil
+}
+
+// exportPipeline writes the config of a pipeline to TEAM/PIPELINE.yml under
+// dir, so that each team's directory can be given straight to set-pipelines.
+// A config the ATC considers invalid is written as the ATC gives it.
+func exportPipeline(team concourse.Team, pipeline atc.Pipeline, dir string) (exportedConfig, error) {
+	exported := exportedConfig{
+		Team:   team.Name(),
+		Name:   pipeline.Name,
+		File:   filepath.Join(team.Name(), pipeline.Name+".yml"),
+		Paused: pipeline.Paused,
+		Public: pipeline.Public,
+	}
+
+	config, rawConfig, version, found, err := team.PipelineConfig(pipeline.Name)
+
+	var payload []byte
+	if err != nil {
+		if _, ok := err.(concourse.PipelineConfigError); !ok {
+			return exportedConfig{}, err
+		}
+
+		exported.Invalid = true
+
+		displayhelpers.PrintWarningHeader()
+		fmt.Fprintf(ui.Stderr, "the config of pipeline %s/%s is invalid, so it was exported as-is\n", team.Name(), pipeline.Name)
+
+		var rawFields map[string]interface{}
+		err = json.Unmarshal([]byte(rawConfig), &rawFields)
+		if err != nil {
+			return exportedConfig{}, err
+		}
+
+		payload, err = yaml.Marshal(rawFields)
+	} else if !found {
+		return exportedConfig{}, rc.NewErrNotFound("pipeline", pipeline.Name)
+	} else {
+		payload, err = yaml.Marshal(config)
+	}
+	if err != nil {
+		return exportedConfig{}, err
+	}
+
+	exported.ConfigVersion = version
+
+	err = os.MkdirAll(filepath.Join(dir, team.Name()), 0755)
+	if err != nil {
+		return exportedConfig{}, err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(dir, exported.File), payload, 0644)
+	if err != nil {
+		return exportedConfig{}, err
+	}
+
+	return exported, nil
+}
@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/concourse/fly/rc"
+	"github.com/jessevdk/go-flags"
+	"github.com/mattn/go-isatty"
+)
+
+// fanOutCommands are the commands that can be run against several targets
+// at once with --targets or --all-targets.
+var fanOutCommands = []string{"set-pipeline", "trigger-job", "pipelines"}
+
+type fanOut struct {
+	targets    []string
+	allTargets bool
+	parallel   bool
+
+	// args are the rest of fly's args, to run against each target
+	args []string
+}
+
+// RunFanOut runs the subcommand in args against each target given by
+// --targets or --all-targets, returning whether it did. Each line of output
+// is prefixed with the target it's from, and the exit code is the highest
+// of any target's.
+func RunFanOut(parser *flags.Parser, args []string) (bool, int, error) {
+	fan, name, err := parseFanOut(parser, args)
+	if err != nil {
+		return true, 1, err
+	}
+
+	if fan == nil {
+		return false, 0, nil
+	}
+
+	if !isFanOutCommand(parser, name) {
+		return true, 1, fmt.Errorf("--targets and --all-targets only work with %s", strings.Join(fanOutCommands, ", "))
+	}
+
+	targets := fan.targets
+	if fan.allTargets {
+		flyYAML, err := rc.LoadTargets()
+		if err != nil {
+			return true, 1, err
+		}
+
+		targets = nil
+		for targetName := range flyYAML.Targets {
+			targets = append(targets, string(targetName))
+		}
+
+		sort.Strings(targets)
+	}
+
+	if len(targets) == 0 {
+		return true, 1, fmt.Errorf("no targets to run %s against", name)
+	}
+
+	// set-pipeline would otherwise fail to ask for confirmation, and bail
+	// out as if it had been refused
+	if fan.parallel && isNamed(parser.Find("set-pipeline"), name) && !nonInteractive(fan.args) {
+		return true, 1, fmt.Errorf("--parallel needs -n for set-pipeline, as the targets can't be given stdin to confirm it")
+	}
+
+	// run one at a time, a target's prompts are shown as they're written so
+	// that they can be answered
+	interactive := !fan.parallel && isatty.IsTerminal(os.Stdin.Fd())
+
+	fly, err := os.Executable()
+	if err != nil {
+		return true, 1, err
+	}
+
+	var outputLock sync.Mutex
+	codes := make([]int, len(targets))
+
+	run := func(i int, stdin io.Reader) {
+		stdout := &prefixWriter{prefix: "[" + targets[i] + "] ", dst: os.Stdout, lock: &outputLock, immediate: interactive}
+		stderr := &prefixWriter{prefix: "[" + targets[i] + "] ", dst: os.Stderr, lock: &outputLock, immediate: interactive}
+
+		cmd := exec.Command(fly, append([]string{"-t", targets[i]}, fan.args...)...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		codes[i] = exitCode(cmd.Run(), stderr)
+
+		stdout.Flush()
+		stderr.Flush()
+	}
+
+	if fan.parallel {
+		// the targets can't share stdin, e.g. to confirm set-pipeline,
+		// so none of them get it
+		wg := new(sync.WaitGroup)
+		for i := range targets {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				run(i, nil)
+			}(i)
+		}
+
+		wg.Wait()
+	} else {
+		for i := range targets {
+			run(i, os.Stdin)
+		}
+	}
+
+	code := 0
+	var failed []string
+	for i, targetCode := range codes {
+		if targetCode != 0 {
+			failed = append(failed, targets[i])
+		}
+
+		if targetCode > code {
+			code = targetCode
+		}
+	}
+
+	if len(failed) > 0 {
+		return true, code, fmt.Errorf("%s failed against %d of %d targets: %s", name, len(failed), len(targets), strings.Join(failed, ", "))
+	}
+
+	return true, 0, nil
+}
+
+// parseFanOut takes the fan-out options out of fly's own options in args,
+// returning nil if there are none, and the name of the subcommand.
+func parseFanOut(parser *flags.Parser, args []string) (*fanOut, string, error) {
+	name, rest, target := findSubcommand(parser, args)
+	if name == "" {
+		return nil, "", nil
+	}
+
+	own := args[:len(args)-len(rest)-1]
+
+	fan := &fanOut{}
+	found := false
+
+	var remaining []string
+	for i := 0; i < len(own); i++ {
+		arg := own[i]
+
+		switch {
+		case arg == "--targets":
+			if i+1 >= len(own) {
+				return nil, "", fmt.Errorf("--targets needs a comma-separated list of targets")
+			}
+
+			i++
+			fan.targets = splitTargets(own[i])
+			found = true
+		case strings.HasPrefix(arg, "--targets="):
+			fan.targets = splitTargets(strings.TrimPrefix(arg, "--targets="))
+			found = true
+		case arg == "--all-targets":
+			fan.allTargets = true
+			found = true
+		case arg == "--parallel":
+			fan.parallel = true
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+
+	if !found {
+		if fan.parallel {
+			return nil, "", fmt.Errorf("--parallel only works with --targets or --all-targets")
+		}
+
+		return nil, "", nil
+	}
+
+	if target != "" {
+		return nil, "", fmt.Errorf("-t can't be given with --targets or --all-targets")
+	}
+
+	if fan.allTargets && len(fan.targets) > 0 {
+		return nil, "", fmt.Errorf("--targets and --all-targets can't both be given")
+	}
+
+	fan.args = append(append(remaining, name), rest...)
+
+	return fan, name, nil
+}
+
+func nonInteractive(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if arg == "-n" || arg == "--non-interactive" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitTargets(list string) []string {
+	var targets []string
+	for _, target := range strings.Split(list, ",") {
+		target = strings.TrimSpace(target)
+		if target != "" {
+			targets = append(targets, target)
+		}
+	}
+
+	return targets
+}
+
+func isFanOutCommand(parser *flags.Parser, name string) bool {
+	for _, fanOutCommand := range fanOutCommands {
+		if isNamed(parser.Find(fanOutCommand), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isNamed(command *flags.Command, name string) bool {
+	if command == nil {
+		return false
+	}
+
+	if command.Name == name {
+		return true
+	}
+
+	for _, alias := range command.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func exitCode(err error, stderr io.Writer) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus()
+		}
+
+		return 1
+	}
+
+	fmt.Fprintf(stderr, "error: %s\n", err)
+
+	return 1
+}
+
+// prefixWriter writes each line with a prefix, holding on to the last line
+// until it's complete so that lines from concurrent writers aren't mixed.
+type prefixWriter struct {
+	prefix string
+	dst    io.Writer
+	lock   *sync.Mutex
+
+	// immediate writes incomplete lines straight away, e.g. prompts, for
+	// when there are no concurrent writers
+	immediate bool
+
+	partial []byte
+	midLine bool
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	if w.immediate {
+		err := w.write(p)
+		if err != nil {
+			return 0, err
+		}
+
+		return len(p), nil
+	}
+
+	w.partial = append(w.partial, p...)
+
+	end := bytes.LastIndexByte(w.partial, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+
+	lines := w.partial[:end+1]
+	w.partial = append([]byte(nil), w.partial[end+1:]...)
+
+	err := w.write(lines)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Flush ends the last line even if it's incomplete.
+func (w *prefixWriter) Flush() error {
+	if len(w.partial) == 0 && !w.midLine {
+		return nil
+	}
+
+	lines := append(w.partial, '\n')
+	w.partial = nil
+
+	return w.write(lines)
+}
+
+func (w *prefixWriter) write(lines []byte) error {
+	var prefixed bytes.Buffer
+	for _, line := range bytes.SplitAfter(lines, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		if !w.midLine {
+			prefixed.WriteString(w.prefix)
+		}
+
+		prefixed.Write(line)
+		w.midLine = line[len(line)-1] != '\n'
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	_, err := w.dst.Write(prefixed.Bytes())
+	return err
+}
@@ -5,18 +5,43 @@ import "github.com/concourse/fly/rc"
 type FlyCommand struct {
 	Help HelpCommand `command:"help" description:"Print this help message"`
 
-	Target  rc.TargetName  `short:"t" long:"target" description:"Concourse target name"`
+	Target  rc.TargetName  `short:"t" long:"target" env:"FLY_TARGET" description:"Concourse target name"`
 	Targets TargetsCommand `command:"targets" alias:"ts" description:"List saved targets"`
 
+	FanOutTargets string `long:"targets"     value-name:"TARGET,..." description:"Run set-pipeline, trigger-job or pipelines against each of these targets"`
+	AllTargets    bool   `long:"all-targets"                         description:"Run set-pipeline, trigger-job or pipelines against every saved target"`
+	Parallel      bool   `long:"parallel"                            description:"Run against the targets of --targets or --all-targets at the same time, without stdin"`
+
+	SetDefaults SetDefaultsCommand `command:"set-defaults" alias:"sdf" description:"Set the pipeline and job commands use for the target when not given -p or -j"`
+
 	Version func() `short:"v" long:"version" description:"Print the version of Fly and exit"`
 
 	Verbose bool `long:"verbose" description:"Print API requests and responses"`
 
+	Profile func(string) error `long:"profile" hidden:"true" value-name:"cpu|mem|trace" description:"Write a profile of this run to the working directory"`
+
 	PrintTableHeaders bool `long:"print-table-headers" description:"Print table headers even for redirected output"`
 
+	Offline bool `long:"offline" description:"Fail rather than reach the network, e.g. to be sure a pre-commit hook works anywhere"`
+
+	NoCache bool `long:"no-cache" description:"Don't answer workers, pipelines or jobs from the responses cached for a few seconds"`
+
+	Color func(string) error `long:"color" value-name:"auto|always|never" description:"Whether to color output: only when writing to a terminal (default), always, or never"`
+
+	LogFormat func(string) error `long:"log-format" value-name:"text|logfmt|json" description:"Write every line of output as a logfmt or json record, e.g. for a log aggregator"`
+
+	EventReconnects   func(string) error `long:"event-reconnects" value-name:"N" description:"Give up on a build's output after failing to reconnect to it N times in a row, then poll its status until it finishes (default: 5)"`
+	EventStallTimeout func(string) error `long:"event-stall-timeout" value-name:"DURATION" description:"Poll a build's status if nothing came of its output for this long, in case it was dropped silently, e.g. by a proxy; 0 to turn off (default: 5m)"`
+
+	APIRetries       func(string) error `long:"api-retries" value-name:"N" description:"Retry requests to the target that fail transiently, e.g. with a 502 from a load balancer, up to N times (default: 3)"`
+	APIRetryInterval func(string) error `long:"api-retry-interval" value-name:"DURATION" description:"Wait this long before retrying a request to the target, doubling with every retry (default: 1s)"`
+
+	CommandTimeout func(string) error `long:"command-timeout" value-name:"DURATION" description:"Give up and exit 124 if the command hasn't finished within this long, e.g. 10m; unlike a build's timeout, this covers all of fly's run"`
+
 	Login  LoginCommand  `command:"login" alias:"l" description:"Authenticate with the target"`
 	Logout LogoutCommand `command:"logout" alias:"o" description:"Release authentication with the target"`
 	Sync   SyncCommand   `command:"sync"  alias:"s" description:"Download and replace the current fly from the target"`
+	Doctor DoctorCommand `command:"doctor" alias:"doc" description:"Check that fly can reach and work with the target, e.g. for a support ticket"`
 
 	Teams       TeamsCommand       `command:"teams" alias:"t" description:"List the configured teams"`
 	SetTeam     SetTeamCommand     `command:"set-team"  alias:"st" description:"Create or modify a team to have the given credentials"`
@@ -26,18 +51,27 @@ type FlyCommand struct {
 
 	Execute ExecuteCommand `command:"execute" alias:"e" description:"Execute a one-off build using local bits"`
 	Watch   WatchCommand   `command:"watch"   alias:"w" description:"Stream a build's output"`
+	Replay  ReplayCommand  `command:"replay"            description:"Show a build's output again from the file written by --log-file"`
 
-	Containers ContainersCommand `command:"containers" alias:"cs" description:"Print the active containers"`
-	Hijack     HijackCommand     `command:"hijack"     alias:"intercept" alias:"i" description:"Execute a command in a container"`
+	Containers    ContainersCommand    `command:"containers" alias:"cs" description:"Print the active containers"`
+	Hijack        HijackCommand        `command:"hijack"     alias:"intercept" alias:"i" description:"Execute a command in a container"`
+	Copy          CopyCommand          `command:"cp"         alias:"copy" description:"Copy files into or out of a build's container"`
+	PortForward   PortForwardCommand   `command:"port-forward" alias:"pf" description:"Forward a local port to a port in a build's container"`
+	PullArtifacts PullArtifactsCommand `command:"pull-artifacts" alias:"pa" description:"Download the outputs of a job's latest successful build"`
 
 	Jobs       JobsCommand       `command:"jobs"      alias:"js" description:"List the jobs in the pipelines"`
 	PauseJob   PauseJobCommand   `command:"pause-job" alias:"pj" description:"Pause a job"`
 	UnpauseJob UnpauseJobCommand `command:"unpause-job" alias:"uj" description:"Unpause a job"`
 
+	ClearTaskCache ClearTaskCacheCommand `command:"clear-task-cache" alias:"ctc" description:"Clear the caches of a job's task step"`
+
 	Pipelines        PipelinesCommand        `command:"pipelines"         alias:"ps" description:"List the configured pipelines"`
 	DestroyPipeline  DestroyPipelineCommand  `command:"destroy-pipeline"  alias:"dp" description:"Destroy a pipeline"`
+	ExportPipelines  ExportPipelinesCommand  `command:"export-pipelines"  alias:"xp" description:"Export every pipeline's configuration to a directory"`
+	ImportPipelines  ImportPipelinesCommand  `command:"import-pipelines"  alias:"ip" description:"Restore the pipelines exported to a directory by export-pipelines"`
 	GetPipeline      GetPipelineCommand      `command:"get-pipeline"      alias:"gp" description:"Get a pipeline's current configuration"`
 	SetPipeline      SetPipelineCommand      `command:"set-pipeline"      alias:"sp" description:"Create or update a pipeline's configuration"`
+	SetPipelines     SetPipelinesCommand     `command:"set-pipelines"     alias:"sps" description:"Create or update every pipeline configured in a directory"`
 	PausePipeline    PausePipelineCommand    `command:"pause-pipeline"    alias:"pp" description:"Pause a pipeline"`
 	UnpausePipeline  UnpausePipelineCommand  `command:"unpause-pipeline"  alias:"up" description:"Un-pause a pipeline"`
 	ExposePipeline   ExposePipelineCommand   `command:"expose-pipeline"   alias:"ep" description:"Make a pipeline publicly viewable"`
@@ -45,15 +79,30 @@ type FlyCommand struct {
 	RenamePipeline   RenamePipelineCommand   `command:"rename-pipeline"   alias:"rp" description:"Rename a pipeline"`
 	ValidatePipeline ValidatePipelineCommand `command:"validate-pipeline" alias:"vp" description:"Validate a pipeline config"`
 	FormatPipeline   FormatPipelineCommand   `command:"format-pipeline"   alias:"fp" description:"Format a pipeline config"`
-
-	CheckResource   CheckResourceCommand   `command:"check-resource"    alias:"cr" description:"Check a resource"`
-	PauseResource   PauseResourceCommand   `command:"pause-resource"    alias:"pr" description:"Pause a resource"`
-	UnpauseResource UnpauseResourceCommand `command:"unpause-resource"  alias:"ur" description:"Unpause a resource"`
-
-	Builds     BuildsCommand     `command:"builds"      alias:"bs" description:"List builds data"`
-	AbortBuild AbortBuildCommand `command:"abort-build" alias:"ab" description:"Abort a build"`
-
-	TriggerJob TriggerJobCommand `command:"trigger-job" alias:"tj" description:"Start a job in a pipeline"`
+	DiffPipeline     DiffPipelineCommand     `command:"diff-pipeline"     alias:"dfp" description:"Compare a pipeline's configuration with a local config"`
+	Eval             EvalCommand             `command:"eval"              alias:"ev" description:"Print a pipeline config with its vars interpolated, without setting it"`
+	LintPipeline     LintPipelineCommand     `command:"lint-pipeline"     alias:"lp" description:"Check a pipeline config for likely mistakes"`
+	PipelineGraph    PipelineGraphCommand    `command:"pipeline-graph"    alias:"pg" description:"Print the graph of a pipeline's resources and jobs for Graphviz"`
+
+	CheckResource          CheckResourceCommand          `command:"check-resource"    alias:"cr" description:"Check a resource"`
+	CheckResourceType      CheckResourceTypeCommand      `command:"check-resource-type" alias:"crt" description:"Check a resource type's image"`
+	EnableResourceVersion  EnableResourceVersionCommand  `command:"enable-resource-version"  alias:"erv" description:"Enable a version of a resource"`
+	DisableResourceVersion DisableResourceVersionCommand `command:"disable-resource-version" alias:"drv" description:"Disable a version of a resource, so that builds never use it"`
+	Causality              CausalityCommand              `command:"causality" alias:"cau" description:"List the builds that produced and used a version of a resource"`
+	PauseResource          PauseResourceCommand          `command:"pause-resource"    alias:"pr" description:"Pause a resource"`
+	UnpauseResource        UnpauseResourceCommand        `command:"unpause-resource"  alias:"ur" description:"Unpause a resource"`
+
+	Builds          BuildsCommand          `command:"builds"           alias:"bs"  description:"List builds data"`
+	JobStats        JobStatsCommand        `command:"job-stats"        alias:"jst" description:"Summarize the build history of a job"`
+	JobStatus       JobStatusCommand       `command:"job-status"       alias:"jss" description:"Print the status of a job's latest finished build, exiting 0 if it succeeded, 1 if it failed, or 2 otherwise"`
+	DiffBuilds      DiffBuildsCommand      `command:"diff-builds"      alias:"db"  description:"Compare the logs of two builds step by step"`
+	GetBuildPlan    GetBuildPlanCommand    `command:"get-build-plan"   alias:"gbp" description:"Print the plan of a build as a tree of its steps"`
+	BuildContainers BuildContainersCommand `command:"build-containers" alias:"bc"  description:"List the containers of a build's steps"`
+	Logs            LogsCommand            `command:"logs"             alias:"lg"  description:"Print the log of a finished build"`
+	AbortBuild      AbortBuildCommand      `command:"abort-build"      alias:"ab" alias:"abort" description:"Abort a build"`
+
+	TriggerJob TriggerJobCommand `command:"trigger-job"  alias:"tj"  description:"Start a job in a pipeline"`
+	WaitForJob WaitForJobCommand `command:"wait-for-job" alias:"wfj" description:"Wait for the next build of a job to finish, exiting with its status"`
 
 	Volumes VolumesCommand `command:"volumes" alias:"vs" description:"List the active volumes"`
 
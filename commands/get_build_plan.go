@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type GetBuildPlanCommand struct {
+	Job   flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB" description:"Name of the job the build belongs to"`
+	Build string              `short:"b" long:"build" required:"true"           description:"If job is specified: build number. If job not specified: build id, or PIPELINE/JOB#BUILD (the latest build if #BUILD is left out)"`
+	JSON  bool                `          long:"json"                            description:"Print the plan as json, as given by the ATC"`
+}
+
+func (command *GetBuildPlanCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	job, buildName, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
+	var team concourse.Team
+	if job.JobName != "" {
+		team = target.Team()
+	}
+
+	build, err := GetBuild(target.Client(), team, job.JobName, buildName, job.PipelineName)
+	if err != nil {
+		return err
+	}
+
+	buildPlan, found, err := target.Client().BuildPlan(build.ID)
+	if err != nil {
+		return err
+	}
+
+	if !found || buildPlan.Plan == nil {
+		return rc.NewErrNotFound("build plan", "")
+	}
+
+	if command.JSON {
+		payload, err := json.Marshal(buildPlan)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Printf("%s\n", payload)
+		return err
+	}
+
+	// the public plan leaves out anything sensitive, e.g. sources and
+	// params, but otherwise has the shape of a plan
+	var plan atc.Plan
+	err = json.Unmarshal(*buildPlan.Plan, &plan)
+	if err != nil {
+		return fmt.Errorf("malformed build plan: %s", err)
+	}
+
+	renderPlan(os.Stdout, plan, 0)
+
+	return nil
+}
+
+func renderPlan(dst io.Writer, plan atc.Plan, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	switch {
+	case plan.Get != nil:
+		fmt.Fprintf(dst, "%sget: %s%s\n", indent, plan.Get.Name, resourceSuffix(plan.Get.Name, plan.Get.Resource, plan.Get.Type))
+		if plan.Get.Version != nil {
+			fmt.Fprintf(dst, "%s  version: %s\n", indent, versionString(*plan.Get.Version))
+		}
+
+	case plan.DependentGet != nil:
+		fmt.Fprintf(dst, "%sget: %s%s\n", indent, plan.DependentGet.Name, resourceSuffix(plan.DependentGet.Name, plan.DependentGet.Resource, plan.DependentGet.Type))
+		fmt.Fprintf(dst, "%s  version: (whatever the put produces)\n", indent)
+
+	case plan.Put != nil:
+		fmt.Fprintf(dst, "%sput: %s%s\n", indent, plan.Put.Name, resourceSuffix(plan.Put.Name, plan.Put.Resource, plan.Put.Type))
+
+	case plan.Task != nil:
+		privileged := ""
+		if plan.Task.Privileged {
+			privileged = " (privileged)"
+		}
+
+		fmt.Fprintf(dst, "%stask: %s%s\n", indent, plan.Task.Name, privileged)
+
+	case plan.Aggregate != nil:
+		fmt.Fprintf(dst, "%saggregate:\n", indent)
+		for _, step := range *plan.Aggregate {
+			renderPlan(dst, step, depth+1)
+		}
+
+	case plan.Do != nil:
+		fmt.Fprintf(dst, "%sdo:\n", indent)
+		for _, step := range *plan.Do {
+			renderPlan(dst, step, depth+1)
+		}
+
+	case plan.Retry != nil:
+		fmt.Fprintf(dst, "%sretry:\n", indent)
+		for _, step := range *plan.Retry {
+			renderPlan(dst, step, depth+1)
+		}
+
+	case plan.OnSuccess != nil:
+		renderPlan(dst, plan.OnSuccess.Step, depth)
+		fmt.Fprintf(dst, "%son success:\n", indent)
+		renderPlan(dst, plan.OnSuccess.Next, depth+1)
+
+	case plan.OnFailure != nil:
+		renderPlan(dst, plan.OnFailure.Step, depth)
+		fmt.Fprintf(dst, "%son failure:\n", indent)
+		renderPlan(dst, plan.OnFailure.Next, depth+1)
+
+	case plan.Ensure != nil:
+		renderPlan(dst, plan.Ensure.Step, depth)
+		fmt.Fprintf(dst, "%sensure:\n", indent)
+		renderPlan(dst, plan.Ensure.Next, depth+1)
+
+	case plan.Try != nil:
+		fmt.Fprintf(dst, "%stry:\n", indent)
+		renderPlan(dst, plan.Try.Step, depth+1)
+
+	case plan.Timeout != nil:
+		fmt.Fprintf(dst, "%stimeout %s:\n", indent, plan.Timeout.Duration)
+		renderPlan(dst, plan.Timeout.Step, depth+1)
+
+	default:
+		fmt.Fprintf(dst, "%s(unknown step)\n", indent)
+	}
+}
+
+// resourceSuffix notes the resource a step uses, if it isn't named after it.
+func resourceSuffix(name string, resource string, resourceType string) string {
+	switch {
+	case resource != "" && resource != name:
+		return fmt.Sprintf(" (%s resource %s)", resourceType, resource)
+	case resourceType != "":
+		return fmt.Sprintf(" (%s)", resourceType)
+	default:
+		return ""
+	}
+}
+
+func versionString(version atc.Version) string {
+	payload, err := json.Marshal(version)
+	if err != nil {
+		return fmt.Sprintf("%v", version)
+	}
+
+	return string(payload)
+}
@@ -3,10 +3,20 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/commands/internal/statshelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
+	"github.com/tedsuo/rata"
 )
 
 func GetBuild(client concourse.Client, team concourse.Team, jobName string, buildNameOrID string, pipelineName string) (atc.Build, error) {
@@ -26,7 +36,7 @@ func GetBuild(client concourse.Client, team concourse.Team, jobName string, buil
 		}
 
 		if !found {
-			return atc.Build{}, errors.New("build not found")
+			return atc.Build{}, rc.NewErrNotFound("build", "")
 		}
 
 		return build, nil
@@ -38,7 +48,7 @@ func GetBuild(client concourse.Client, team concourse.Team, jobName string, buil
 		}
 
 		if !found {
-			return atc.Build{}, errors.New("job not found")
+			return atc.Build{}, rc.NewErrNotFound("job", "")
 		}
 
 		if job.NextBuild != nil {
@@ -80,3 +90,158 @@ func SliceItoa(slice []int) string {
 	}
 	return strSlice
 }
+
+// newStatsHijacker returns a hijacker for sampling the usage of a target's
+// containers with statshelpers.
+func newStatsHijacker(target rc.Target) statshelpers.Hijacker {
+	return hijacker.New(target.TLSConfig(), rata.NewRequestGenerator(target.URL(), atc.Routes), target.Token())
+}
+
+// writeJUnitReport writes a JUnit report to path. A report that can't be
+// written is only warned about, as it shouldn't change the outcome of the
+// build it reports on.
+func writeJUnitReport(path string, report *eventstream.JUnitReport) {
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to write JUnit report: %s\n", err)
+		return
+	}
+
+	defer file.Close()
+
+	err = report.WriteReport(file)
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to write JUnit report: %s\n", err)
+	}
+}
+
+// finishedBuild returns a build that fly has watched to the end, as the ATC
+// reports it, or as told by its exit status if the ATC can't be asked.
+func finishedBuild(client concourse.Client, buildID int, exitCode int) atc.Build {
+	build, found, err := client.Build(strconv.Itoa(buildID))
+	if err == nil && found {
+		return build
+	}
+
+	build = atc.Build{ID: buildID, Name: strconv.Itoa(buildID), Status: string(atc.StatusFailed)}
+	if exitCode == 0 {
+		build.Status = string(atc.StatusSucceeded)
+	}
+
+	return build
+}
+
+// buildURL returns the URL of a build's page in the web UI.
+func buildURL(client concourse.Client, build atc.Build) string {
+	path := build.URL
+	if path == "" {
+		path = "/builds/" + strconv.Itoa(build.ID)
+	}
+
+	return strings.TrimRight(client.URL(), "/") + path
+}
+
+// pipelineAPIURL returns the URL of a pipeline's API endpoint that the
+// client has no method for, e.g. pipelineAPIURL(target, "p", "resources",
+// "r", "versions").
+func pipelineAPIURL(target rc.Target, pipelineName string, path ...string) string {
+	segments := []string{"api", "v1", "teams", target.Team().Name(), "pipelines", pipelineName}
+	segments = append(segments, path...)
+
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.TrimRight(target.Client().URL(), "/") + "/" + strings.Join(segments, "/")
+}
+
+func buildStatusCell(status string) ui.TableCell {
+	cell := ui.TableCell{Contents: status}
+
+	switch status {
+	case "pending":
+		cell.Color = ui.PendingColor
+	case "started":
+		cell.Color = ui.StartedColor
+	case "succeeded":
+		cell.Color = ui.SucceededColor
+	case "failed":
+		cell.Color = ui.FailedColor
+	case "errored":
+		cell.Color = ui.ErroredColor
+	case "aborted":
+		cell.Color = ui.AbortedColor
+	case "paused":
+		cell.Color = ui.PausedColor
+	}
+
+	return cell
+}
+
+// defaultPipeline returns pipelineName, or if it's empty, the default
+// pipeline from .fly.yml or the target.
+func defaultPipeline(pipelineName string) (string, error) {
+	if pipelineName != "" {
+		return pipelineName, nil
+	}
+
+	defaults, err := rc.LoadDefaults(Fly.Target)
+	if err != nil {
+		return "", err
+	}
+
+	if defaults.Pipeline == "" {
+		return "", fmt.Errorf("no pipeline given; pass -p, or set a default with set-defaults or %s", rc.DefaultsFileName)
+	}
+
+	return defaults.Pipeline, nil
+}
+
+// defaultJob returns job, or if it's empty, the default pipeline and job
+// from .fly.yml or the target. found is false if there's no default job.
+func defaultJob(job flaghelpers.JobFlag) (flaghelpers.JobFlag, bool, error) {
+	if job.JobName != "" {
+		return job, true, nil
+	}
+
+	defaults, err := rc.LoadDefaults(Fly.Target)
+	if err != nil {
+		return job, false, err
+	}
+
+	if defaults.Pipeline == "" || defaults.Job == "" {
+		return job, false, nil
+	}
+
+	return flaghelpers.JobFlag{PipelineName: defaults.Pipeline, JobName: defaults.Job}, true, nil
+}
+
+// jobBuild splits a build given as PIPELINE/JOB#BUILD, or PIPELINE/JOB for
+// the job's latest build, into the job and the build's name, which is empty
+// for the latest build. Any other build is returned as is, along with job.
+func jobBuild(job flaghelpers.JobFlag, build string) (flaghelpers.JobFlag, string, error) {
+	if !strings.Contains(build, "/") {
+		return job, build, nil
+	}
+
+	if job.JobName != "" {
+		return job, "", fmt.Errorf("build %s names its job, so --job cannot be given as well", build)
+	}
+
+	jobName, buildName := build, ""
+	if i := strings.LastIndex(build, "#"); i != -1 {
+		jobName, buildName = build[:i], build[i+1:]
+
+		if buildName == "" {
+			return job, "", fmt.Errorf("build %s has no number after the #", build)
+		}
+	}
+
+	var buildJob flaghelpers.JobFlag
+	err := buildJob.UnmarshalFlag(jobName)
+	if err != nil {
+		return job, "", err
+	}
+
+	return buildJob, buildName, nil
+}
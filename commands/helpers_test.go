@@ -6,6 +6,7 @@ import (
 
 	"github.com/concourse/atc"
 	. "github.com/concourse/fly/commands"
+	"github.com/concourse/fly/rc"
 	"github.com/concourse/go-concourse/concourse"
 	fakes "github.com/concourse/go-concourse/concourse/concoursefakes"
 
@@ -58,6 +59,7 @@ var _ = Describe("Helper Functions", func() {
 				It("returns an error", func() {
 					_, err := GetBuild(client, nil, "", expectedBuildID, "")
 					Expect(err).To(MatchError("build not found"))
+					Expect(err).To(BeAssignableToTypeOf(rc.ErrNotFound{}))
 				})
 			})
 		})
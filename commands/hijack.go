@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
 	"github.com/concourse/fly/commands/internal/hijacker"
@@ -23,11 +24,12 @@ import (
 type HijackCommand struct {
 	Job            flaghelpers.JobFlag      `short:"j" long:"job"   value-name:"PIPELINE/JOB"   description:"Name of a job to hijack"`
 	Check          flaghelpers.ResourceFlag `short:"c" long:"check" value-name:"PIPELINE/CHECK" description:"Name of a resource's checking container to hijack"`
-	Build          string                   `short:"b" long:"build"                             description:"Build number within the job, or global build ID"`
+	Build          string                   `short:"b" long:"build"                             description:"Build number within the job, global build ID, or PIPELINE/JOB#BUILD"`
 	StepName       string                   `short:"s" long:"step"                              description:"Name of step to hijack (e.g. build, unit, resource name)"`
 	Attempt        string                   `short:"a" long:"attempt" value-name:"N[,N,...]"    description:"Attempt number of step to hijack."`
+	User           string                   `short:"u" long:"user"    value-name:"USER"         description:"User to run the command as (default: the container's user)"`
 	PositionalArgs struct {
-		Command []string `positional-arg-name:"command" description:"The command to run in the container (default: bash)"`
+		Command []string `positional-arg-name:"command" description:"The command to run in the container (default: bash); put it after -- if it has flags of its own"`
 	} `positional-args:"yes"`
 }
 
@@ -47,52 +49,13 @@ func (command *HijackCommand) Execute([]string) error {
 		return err
 	}
 
-	var chosenContainer atc.Container
-	if len(containers) == 0 {
-		displayhelpers.Failf("no containers matched your search parameters!\n\nthey may have expired if your build hasn't recently finished.")
-	} else if len(containers) > 1 {
-		var choices []interact.Choice
-		for _, container := range containers {
-			var infos []string
-
-			if container.BuildID != 0 {
-				if container.JobName != "" {
-					infos = append(infos, fmt.Sprintf("build #%s", container.BuildName))
-				} else {
-					infos = append(infos, fmt.Sprintf("build id: %d", container.BuildID))
-				}
-			}
-
-			if container.StepName != "" {
-				infos = append(infos, fmt.Sprintf("step: %s", container.StepName))
-			}
-
-			if container.ResourceName != "" {
-				infos = append(infos, fmt.Sprintf("resource: %s", container.ResourceName))
-			}
-
-			infos = append(infos, fmt.Sprintf("type: %s", container.Type))
-
-			if container.Attempt != "" {
-				infos = append(infos, fmt.Sprintf("attempt: %s", container.Attempt))
-			}
-
-			choices = append(choices, interact.Choice{
-				Display: strings.Join(infos, ", "),
-				Value:   container,
-			})
-		}
-
-		err = interact.NewInteraction("choose a container", choices...).Resolve(&chosenContainer)
-		if err == io.EOF {
-			return nil
-		}
+	chosenContainer, chosen, err := chooseContainer(containers)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
-	} else {
-		chosenContainer = containers[0]
+	if !chosen {
+		return nil
 	}
 
 	privileged := true
@@ -112,18 +75,23 @@ func (command *HijackCommand) Execute([]string) error {
 
 	path, args := remoteCommand(command.PositionalArgs.Command)
 
+	user := chosenContainer.User
+	if command.User != "" {
+		user = command.User
+	}
+
 	spec := atc.HijackProcessSpec{
 		Path: path,
 		Args: args,
 		Env:  []string{"TERM=" + os.Getenv("TERM")},
-		User: chosenContainer.User,
+		User: user,
 		Dir:  chosenContainer.WorkingDirectory,
 
 		Privileged: privileged,
 		TTY:        ttySpec,
 	}
 
-	result, err := func() (int, error) { // so the term.Restore() can run before the os.Exit()
+	result, err := func() (int, error) { // so the term.Restore() can run before the atexit.Exit()
 		var in io.Reader
 
 		if pty.IsTerminal() {
@@ -154,22 +122,26 @@ func (command *HijackCommand) Execute([]string) error {
 		return err
 	}
 
-	os.Exit(result)
+	atexit.Exit(result)
 
 	return nil
 }
 
 func (command *HijackCommand) getContainerIDs(client concourse.Client) ([]atc.Container, error) {
+	job, buildNameOrID, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return nil, err
+	}
+
 	var pipelineName string
-	if command.Job.PipelineName != "" {
-		pipelineName = command.Job.PipelineName
+	if job.PipelineName != "" {
+		pipelineName = job.PipelineName
 	} else {
 		pipelineName = command.Check.PipelineName
 	}
 
-	buildNameOrID := command.Build
 	stepName := command.StepName
-	jobName := command.Job.JobName
+	jobName := job.JobName
 	check := command.Check.ResourceName
 	attempt := command.Attempt
 
@@ -196,6 +168,60 @@ func (command *HijackCommand) getContainerIDs(client concourse.Client) ([]atc.Co
 	return containers, nil
 }
 
+// chooseContainer returns the only container, or the one the user chooses of
+// several. chosen is false if the user gave up choosing.
+func chooseContainer(containers []atc.Container) (atc.Container, bool, error) {
+	var chosenContainer atc.Container
+	if len(containers) == 0 {
+		displayhelpers.Failf("no containers matched your search parameters!\n\nthey may have expired if your build hasn't recently finished.")
+	} else if len(containers) > 1 {
+		var choices []interact.Choice
+		for _, container := range containers {
+			var infos []string
+
+			if container.BuildID != 0 {
+				if container.JobName != "" {
+					infos = append(infos, fmt.Sprintf("build #%s", container.BuildName))
+				} else {
+					infos = append(infos, fmt.Sprintf("build id: %d", container.BuildID))
+				}
+			}
+
+			if container.StepName != "" {
+				infos = append(infos, fmt.Sprintf("step: %s", container.StepName))
+			}
+
+			if container.ResourceName != "" {
+				infos = append(infos, fmt.Sprintf("resource: %s", container.ResourceName))
+			}
+
+			infos = append(infos, fmt.Sprintf("type: %s", container.Type))
+
+			if container.Attempt != "" {
+				infos = append(infos, fmt.Sprintf("attempt: %s", container.Attempt))
+			}
+
+			choices = append(choices, interact.Choice{
+				Display: strings.Join(infos, ", "),
+				Value:   container,
+			})
+		}
+
+		err := interact.NewInteraction("choose a container", choices...).Resolve(&chosenContainer)
+		if err == io.EOF {
+			return atc.Container{}, false, nil
+		}
+
+		if err != nil {
+			return atc.Container{}, false, err
+		}
+	} else {
+		chosenContainer = containers[0]
+	}
+
+	return chosenContainer, true, nil
+}
+
 func remoteCommand(argv []string) (string, []string) {
 	var path string
 	var args []string
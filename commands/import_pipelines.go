@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/setpipelinehelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type ImportPipelinesCommand struct {
+	SkipInteractive bool `short:"n" long:"non-interactive" description:"Skips interactions, uses default values"`
+	DryRun          bool `          long:"dry-run"         description:"Only show the changes importing would make"`
+
+	PositionalArgs struct {
+		Dir string `positional-arg-name:"DIR" required:"true" description:"Directory written by export-pipelines"`
+	} `positional-args:"yes"`
+}
+
+func (command *ImportPipelinesCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	dir := command.PositionalArgs.Dir
+
+	payload, err := ioutil.ReadFile(filepath.Join(dir, "manifest.yml"))
+	if err != nil {
+		return fmt.Errorf("could not read manifest: %s", err)
+	}
+
+	var manifest exportManifest
+	err = yaml.Unmarshal(payload, &manifest)
+	if err != nil {
+		return fmt.Errorf("could not parse manifest: %s", err)
+	}
+
+	// pipelines are imported a team at a time, in the order of the manifest
+	teamNames := []string{}
+	teamPipelines := map[string][]exportedConfig{}
+	for _, pipeline := range manifest.Pipelines {
+		if _, seen := teamPipelines[pipeline.Team]; !seen {
+			teamNames = append(teamNames, pipeline.Team)
+		}
+
+		teamPipelines[pipeline.Team] = append(teamPipelines[pipeline.Team], pipeline)
+	}
+
+	for _, teamName := range teamNames {
+		if len(teamNames) > 1 {
+			fmt.Printf("team %s:\n", teamName)
+		}
+
+		team := target.Client().Team(teamName)
+
+		pipelines := []setpipelinehelpers.PipelineFile{}
+		for _, pipeline := range teamPipelines[teamName] {
+			pipelines = append(pipelines, setpipelinehelpers.PipelineFile{
+				Name:   pipeline.Name,
+				Config: atc.PathFlag(filepath.Join(dir, pipeline.File)),
+			})
+		}
+
+		atcConfig := setpipelinehelpers.ATCConfig{
+			Team:            team,
+			SkipInteraction: command.SkipInteractive,
+			DryRun:          command.DryRun,
+		}
+
+		applied, err := atcConfig.SetAll(pipelines, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		if !applied {
+			continue
+		}
+
+		for _, pipeline := range teamPipelines[teamName] {
+			err := restorePipelineState(team, pipeline)
+			if err != nil {
+				return fmt.Errorf("failed to restore the state of pipeline %s/%s: %s", teamName, pipeline.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// restorePipelineState pauses or unpauses, and exposes or hides, a pipeline
+// as it was when it was exported.
+func restorePipelineState(team concourse.Team, pipeline exportedConfig) error {
+	pause, paused := team.UnpausePipeline, "unpaused"
+	if pipeline.Paused {
+		pause, paused = team.PausePipeline, "paused"
+	}
+
+	expose, public := team.HidePipeline, "hidden"
+	if pipeline.Public {
+		expose, public = team.ExposePipeline, "exposed"
+	}
+
+	for _, apply := range []func(string) (bool, error){pause, expose} {
+		found, err := apply(pipeline.Name)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return rc.NewErrNotFound("pipeline", pipeline.Name)
+		}
+	}
+
+	fmt.Printf("pipeline %s is %s and %s\n", pipeline.Name, paused, public)
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package atexit
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	hooksLock sync.Mutex
+	hooks     []func(code int)
+)
+
+// Register adds a hook to run, with the exit code, before fly exits. Hooks
+// run in the reverse order they were registered.
+func Register(hook func(code int)) {
+	hooksLock.Lock()
+	hooks = append(hooks, hook)
+	hooksLock.Unlock()
+}
+
+// Exit runs the registered hooks and exits with the given code. Commands
+// must use this rather than os.Exit, or the hooks are skipped.
+func Exit(code int) {
+	hooksLock.Lock()
+	registered := hooks
+	hooks = nil
+	hooksLock.Unlock()
+
+	for i := len(registered) - 1; i >= 0; i-- {
+		registered[i](code)
+	}
+
+	os.Exit(code)
+}
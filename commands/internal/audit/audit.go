@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// Entry records a single fly invocation.
+type Entry struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration_ns"`
+	User     string        `json:"user"`
+	Host     string        `json:"host"`
+	Target   string        `json:"target,omitempty"`
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+}
+
+// Open returns the audit log at destination: either "syslog" for the local
+// syslog daemon, or the path of a file to append to.
+func Open(destination string) (io.WriteCloser, error) {
+	if destination == "syslog" {
+		return openSyslog()
+	}
+
+	return os.OpenFile(destination, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+}
+
+// Record writes entry to log as a single line of JSON.
+func Record(log io.Writer, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = log.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,62 @@
+package audit_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/audit"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit", func() {
+	var (
+		tmpDir  string
+		logPath string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-audit")
+		Expect(err).NotTo(HaveOccurred())
+
+		logPath = filepath.Join(tmpDir, "audit.log")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	It("appends an entry per line to the file", func() {
+		entry := audit.Entry{
+			Time:     time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC),
+			Duration: 2 * time.Second,
+			User:     "some-user",
+			Host:     "some-host",
+			Target:   "some-target",
+			Command:  "execute",
+			ExitCode: 1,
+		}
+
+		for i := 0; i < 2; i++ {
+			log, err := audit.Open(logPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(audit.Record(log, entry)).To(Succeed())
+			Expect(log.Close()).To(Succeed())
+		}
+
+		contents, err := ioutil.ReadFile(logPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		line := `{"time":"2017-03-01T12:00:00Z","duration_ns":2000000000,"user":"some-user","host":"some-host","target":"some-target","command":"execute","exit_code":1}` + "\n"
+		Expect(string(contents)).To(Equal(line + line))
+
+		info, err := os.Stat(logPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+})
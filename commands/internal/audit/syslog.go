@@ -0,0 +1,12 @@
+// +build !windows
+
+package audit
+
+import (
+	"io"
+	"log/syslog"
+)
+
+func openSyslog() (io.WriteCloser, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_USER, "fly")
+}
@@ -0,0 +1,12 @@
+// +build windows
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+func openSyslog() (io.WriteCloser, error) {
+	return nil, errors.New("syslog is not supported on windows; specify a file instead")
+}
@@ -0,0 +1,105 @@
+package cioutput
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// GitLabDotenvFile is the file results are written to in GitLab CI, to be
+// passed to later jobs as a dotenv report artifact.
+const GitLabDotenvFile = "fly.env"
+
+var ErrNotInCI = errors.New("not running in GitHub Actions or GitLab CI")
+
+// Build is a finished build, as written out for later steps of the CI job.
+type Build struct {
+	ID     int
+	URL    string
+	Status string
+}
+
+// Write writes the builds' IDs, URLs and statuses to the files the CI
+// system fly is running in passes on to later steps. Several builds' keys
+// are numbered from 1, e.g. build_id_2.
+func Write(builds []Build) error {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		outputs := values(builds, "build_id", "build_url", "build_status")
+
+		err := appendTo(os.Getenv("GITHUB_OUTPUT"), outputs)
+		if err != nil {
+			return err
+		}
+
+		env := values(builds, "CONCOURSE_BUILD_ID", "CONCOURSE_BUILD_URL", "CONCOURSE_BUILD_STATUS")
+
+		err = appendTo(os.Getenv("GITHUB_ENV"), env)
+		if err != nil {
+			return err
+		}
+
+		return nil
+
+	case os.Getenv("GITLAB_CI") == "true":
+		dir := os.Getenv("CI_PROJECT_DIR")
+		if dir == "" {
+			dir = "."
+		}
+
+		env := values(builds, "CONCOURSE_BUILD_ID", "CONCOURSE_BUILD_URL", "CONCOURSE_BUILD_STATUS")
+
+		err := appendTo(filepath.Join(dir, GitLabDotenvFile), env)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	return ErrNotInCI
+}
+
+func values(builds []Build, idKey string, urlKey string, statusKey string) []string {
+	var lines []string
+	for i, build := range builds {
+		suffix := ""
+		if len(builds) > 1 {
+			suffix = "_" + strconv.Itoa(i+1)
+		}
+
+		lines = append(lines,
+			idKey+suffix+"="+strconv.Itoa(build.ID),
+			urlKey+suffix+"="+singleLine(build.URL),
+			statusKey+suffix+"="+singleLine(build.Status),
+		)
+	}
+
+	return lines
+}
+
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", " ").Replace(value)
+}
+
+func appendTo(path string, lines []string) error {
+	if path == "" {
+		return errors.New("the CI system gave no file to write to")
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(file, strings.Join(lines, "\n"))
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,13 @@
+package cioutput_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCIOutput(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CI Output Suite")
+}
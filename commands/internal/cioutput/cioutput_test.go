@@ -0,0 +1,106 @@
+package cioutput_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/commands/internal/cioutput"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Write", func() {
+	var (
+		dir string
+		env map[string]string
+	)
+
+	setEnv := func(key string, value string) {
+		if _, saved := env[key]; !saved {
+			env[key] = os.Getenv(key)
+		}
+
+		os.Setenv(key, value)
+	}
+
+	readFile := func(name string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fly-ci-output")
+		Expect(err).NotTo(HaveOccurred())
+
+		env = map[string]string{}
+
+		setEnv("GITHUB_ACTIONS", "")
+		setEnv("GITLAB_CI", "")
+	})
+
+	AfterEach(func() {
+		for key, value := range env {
+			os.Setenv(key, value)
+		}
+
+		os.RemoveAll(dir)
+	})
+
+	Context("in GitHub Actions", func() {
+		BeforeEach(func() {
+			setEnv("GITHUB_ACTIONS", "true")
+			setEnv("GITHUB_OUTPUT", filepath.Join(dir, "output"))
+			setEnv("GITHUB_ENV", filepath.Join(dir, "env"))
+
+			Expect(ioutil.WriteFile(filepath.Join(dir, "output"), []byte("earlier=output\n"), 0644)).To(Succeed())
+		})
+
+		It("appends the build to the output and env files", func() {
+			err := cioutput.Write([]cioutput.Build{
+				{ID: 42, URL: "https://ci.example.com/builds/42", Status: "succeeded"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(readFile("output")).To(Equal("earlier=output\nbuild_id=42\nbuild_url=https://ci.example.com/builds/42\nbuild_status=succeeded\n"))
+			Expect(readFile("env")).To(Equal("CONCOURSE_BUILD_ID=42\nCONCOURSE_BUILD_URL=https://ci.example.com/builds/42\nCONCOURSE_BUILD_STATUS=succeeded\n"))
+		})
+
+		It("numbers the keys of several builds", func() {
+			err := cioutput.Write([]cioutput.Build{
+				{ID: 1, URL: "https://ci.example.com/builds/1", Status: "succeeded"},
+				{ID: 2, URL: "https://ci.example.com/builds/2", Status: "failed"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(readFile("output")).To(ContainSubstring("build_id_1=1\n"))
+			Expect(readFile("output")).To(ContainSubstring("build_status_2=failed\n"))
+		})
+	})
+
+	Context("in GitLab CI", func() {
+		BeforeEach(func() {
+			setEnv("GITLAB_CI", "true")
+			setEnv("CI_PROJECT_DIR", dir)
+		})
+
+		It("writes the build to a dotenv file in the project", func() {
+			err := cioutput.Write([]cioutput.Build{
+				{ID: 42, URL: "https://ci.example.com/builds/42", Status: "failed"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(readFile(cioutput.GitLabDotenvFile)).To(Equal("CONCOURSE_BUILD_ID=42\nCONCOURSE_BUILD_URL=https://ci.example.com/builds/42\nCONCOURSE_BUILD_STATUS=failed\n"))
+		})
+	})
+
+	Context("outside of CI", func() {
+		It("fails", func() {
+			err := cioutput.Write([]cioutput.Build{{ID: 42}})
+			Expect(err).To(Equal(cioutput.ErrNotInCI))
+		})
+	})
+})
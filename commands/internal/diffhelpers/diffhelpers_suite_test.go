@@ -0,0 +1,13 @@
+package diffhelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestDiffhelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diffhelpers Suite")
+}
@@ -0,0 +1,146 @@
+package diffhelpers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aryann/difflib"
+	"github.com/mgutz/ansi"
+)
+
+// Context is how many unchanged lines are shown around each change.
+const Context = 3
+
+// Unified writes the differences between a and b as a unified diff, with
+// removed lines in red and added lines in green. Nothing is written if they
+// are the same.
+func Unified(to io.Writer, fromLabel string, toLabel string, a string, b string) {
+	records := difflib.Diff(splitLines(a), splitLines(b))
+
+	hunks := hunksOf(records)
+	if len(hunks) == 0 {
+		return
+	}
+
+	fmt.Fprintf(to, "%s\n", ansi.Color("--- "+fromLabel, "red"))
+	fmt.Fprintf(to, "%s\n", ansi.Color("+++ "+toLabel, "green"))
+
+	for _, hunk := range hunks {
+		hunk.render(to, records)
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type hunk struct {
+	// start and end index the records in the hunk
+	start int
+	end   int
+
+	// fromLine and toLine are the 1-based lines of a and b the hunk starts at
+	fromLine int
+	toLine   int
+}
+
+func hunksOf(records []difflib.DiffRecord) []hunk {
+	var hunks []hunk
+
+	// lines of a and b seen before each record
+	fromLines := make([]int, len(records)+1)
+	toLines := make([]int, len(records)+1)
+	for i, record := range records {
+		fromLines[i+1] = fromLines[i]
+		toLines[i+1] = toLines[i]
+
+		if record.Delta != difflib.RightOnly {
+			fromLines[i+1]++
+		}
+
+		if record.Delta != difflib.LeftOnly {
+			toLines[i+1]++
+		}
+	}
+
+	for i, record := range records {
+		if record.Delta == difflib.Common {
+			continue
+		}
+
+		start := i - Context
+		if start < 0 {
+			start = 0
+		}
+
+		end := i + 1 + Context
+		if end > len(records) {
+			end = len(records)
+		}
+
+		// changes whose context touches are shown as one hunk
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+			continue
+		}
+
+		hunks = append(hunks, hunk{
+			start:    start,
+			end:      end,
+			fromLine: fromLines[start] + 1,
+			toLine:   toLines[start] + 1,
+		})
+	}
+
+	return hunks
+}
+
+func (hunk hunk) render(to io.Writer, records []difflib.DiffRecord) {
+	fromCount := 0
+	toCount := 0
+	for _, record := range records[hunk.start:hunk.end] {
+		if record.Delta != difflib.RightOnly {
+			fromCount++
+		}
+
+		if record.Delta != difflib.LeftOnly {
+			toCount++
+		}
+	}
+
+	fmt.Fprintf(to, "%s\n", ansi.Color(fmt.Sprintf(
+		"@@ -%s +%s @@",
+		hunkRange(hunk.fromLine, fromCount),
+		hunkRange(hunk.toLine, toCount),
+	), "cyan"))
+
+	for _, record := range records[hunk.start:hunk.end] {
+		switch record.Delta {
+		case difflib.LeftOnly:
+			fmt.Fprintf(to, "%s\n", ansi.Color("-"+record.Payload, "red"))
+		case difflib.RightOnly:
+			fmt.Fprintf(to, "%s\n", ansi.Color("+"+record.Payload, "green"))
+		case difflib.Common:
+			fmt.Fprintf(to, " %s\n", record.Payload)
+		}
+	}
+}
+
+// hunkRange formats a range as diff does: an empty range is given by the
+// line before it.
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		line--
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
@@ -0,0 +1,61 @@
+package diffhelpers_test
+
+import (
+	"bytes"
+
+	"github.com/concourse/fly/commands/internal/diffhelpers"
+	"github.com/mgutz/ansi"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Unified", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		ansi.DisableColors(true)
+		out = new(bytes.Buffer)
+	})
+
+	AfterEach(func() {
+		ansi.DisableColors(false)
+	})
+
+	It("writes the changes in hunks with a few lines of context", func() {
+		a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		b := "1\ntwo\n3\n4\n5\n6\n7\n8\n9\n10\n12\n13\n"
+
+		diffhelpers.Unified(out, "build 1", "build 2", a, b)
+
+		Expect(out.String()).To(Equal(`--- build 1
++++ build 2
+@@ -1,5 +1,5 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+@@ -8,5 +8,5 @@
+ 8
+ 9
+ 10
+-11
+ 12
++13
+`))
+	})
+
+	It("writes additions to an empty log", func() {
+		diffhelpers.Unified(out, "build 1", "build 2", "", "hello\n")
+
+		Expect(out.String()).To(Equal("--- build 1\n+++ build 2\n@@ -0,0 +1 @@\n+hello\n"))
+	})
+
+	It("writes nothing when there are no changes", func() {
+		diffhelpers.Unified(out, "build 1", "build 2", "same\n", "same\n")
+
+		Expect(out.String()).To(BeEmpty())
+	})
+})
@@ -2,8 +2,8 @@ package displayhelpers
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/ui"
 )
 
@@ -19,7 +19,7 @@ func PrintWarningHeader() {
 
 func Failf(message string, args ...interface{}) {
 	fmt.Fprintf(ui.Stderr, message+"\n", args...)
-	os.Exit(1)
+	atexit.Exit(1)
 }
 
 func FailWithErrorf(message string, err error, args ...interface{}) {
@@ -1,9 +1,12 @@
 package executehelpers
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/config"
 	"github.com/concourse/fly/rc"
 )
 
@@ -12,19 +15,20 @@ func CreateBuildPlan(
 	privileged bool,
 	inputs []Input,
 	outputs []Output,
-	config atc.TaskConfig,
+	taskConfig atc.TaskConfig,
+	hooks config.TaskHooks,
 	tags []string,
 ) (atc.Plan, error) {
 	fact := atc.NewPlanFactory(time.Now().Unix())
 
-	if err := config.Validate(); err != nil {
+	if err := taskConfig.Validate(); err != nil {
 		return atc.Plan{}, err
 	}
 
 	buildInputs := atc.AggregatePlan{}
 	for _, input := range inputs {
 		var getPlan atc.GetPlan
-		if input.Path != "" {
+		if input.Path != "" || input.DockerImage != "" || input.Archive != "" {
 			source := atc.Source{
 				"uri": input.Pipe.ReadURL,
 			}
@@ -65,13 +69,43 @@ func CreateBuildPlan(
 	taskPlan := fact.NewPlan(atc.TaskPlan{
 		Name:       "one-off",
 		Privileged: privileged,
-		Config:     &config,
+		Config:     &taskConfig,
 	})
 
 	if len(tags) != 0 {
 		taskPlan.Task.Tags = tags
 	}
 
+	for _, input := range inputs {
+		if input.DockerImage != "" {
+			taskPlan.Task.ImageArtifactName = input.Name
+		}
+	}
+
+	if hooks.OnSuccess != nil {
+		hookPlan, err := createHookPlan(fact, *hooks.OnSuccess, tags)
+		if err != nil {
+			return atc.Plan{}, fmt.Errorf("invalid on_success hook: %s", err)
+		}
+
+		taskPlan = fact.NewPlan(atc.OnSuccessPlan{
+			Step: taskPlan,
+			Next: hookPlan,
+		})
+	}
+
+	if hooks.OnFailure != nil {
+		hookPlan, err := createHookPlan(fact, *hooks.OnFailure, tags)
+		if err != nil {
+			return atc.Plan{}, fmt.Errorf("invalid on_failure hook: %s", err)
+		}
+
+		taskPlan = fact.NewPlan(atc.OnFailurePlan{
+			Step: taskPlan,
+			Next: hookPlan,
+		})
+	}
+
 	buildOutputs := atc.AggregatePlan{}
 	for _, output := range outputs {
 		source := atc.Source{
@@ -121,3 +155,51 @@ func CreateBuildPlan(
 
 	return plan, nil
 }
+
+// createHookPlan translates a hook of an executed task into a plan. Without
+// a pipeline there are no resources to get or put, so hooks are limited to
+// tasks, which can use the build's inputs and the task's outputs.
+func createHookPlan(fact atc.PlanFactory, step atc.PlanConfig, tags []string) (atc.Plan, error) {
+	if step.Do != nil {
+		do := atc.DoPlan{}
+		for _, substep := range *step.Do {
+			plan, err := createHookPlan(fact, substep, tags)
+			if err != nil {
+				return atc.Plan{}, err
+			}
+
+			do = append(do, plan)
+		}
+
+		return fact.NewPlan(do), nil
+	}
+
+	if step.Task == "" {
+		return atc.Plan{}, errors.New("only task steps (or a do of them) can be run as hooks of an executed task")
+	}
+
+	if step.TaskConfig == nil && step.TaskConfigPath == "" {
+		return atc.Plan{}, fmt.Errorf("task '%s' needs a config or file", step.Task)
+	}
+
+	if step.TaskConfig != nil {
+		if err := step.TaskConfig.Validate(); err != nil {
+			return atc.Plan{}, fmt.Errorf("task '%s': %s", step.Task, err)
+		}
+	}
+
+	if len(step.Tags) != 0 {
+		tags = step.Tags
+	}
+
+	return fact.NewPlan(atc.TaskPlan{
+		Name:          step.Task,
+		Privileged:    step.Privileged,
+		Tags:          tags,
+		ConfigPath:    step.TaskConfigPath,
+		Config:        step.TaskConfig,
+		Params:        step.Params,
+		InputMapping:  step.InputMapping,
+		OutputMapping: step.OutputMapping,
+	}), nil
+}
@@ -0,0 +1,129 @@
+package executehelpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// Cache is a directory a task reuses across executes, e.g. for downloaded
+// dependencies. The ATC doesn't keep volumes around for one-off builds, so
+// its contents are kept locally between builds: uploaded as an input, and
+// fetched back as an output into a staging directory that replaces them
+// once the build is done.
+type Cache struct {
+	Name string
+	Dir  string
+
+	Input  Input
+	Output Output
+}
+
+// DetermineCaches creates the pipes for the given caches, keeping their
+// contents under dir, and adds them to the task config as inputs and
+// outputs at their paths.
+func DetermineCaches(
+	client concourse.Client,
+	dir string,
+	cacheFlags []flaghelpers.CacheFlag,
+	taskConfig *atc.TaskConfig,
+) ([]Cache, error) {
+	caches := []Cache{}
+
+	for _, flag := range cacheFlags {
+		for _, input := range taskConfig.Inputs {
+			if input.Name == flag.Name {
+				return nil, fmt.Errorf("cache '%s' has the same name as an input of the task", flag.Name)
+			}
+		}
+
+		for _, output := range taskConfig.Outputs {
+			if output.Name == flag.Name {
+				return nil, fmt.Errorf("cache '%s' has the same name as an output of the task", flag.Name)
+			}
+		}
+
+		cacheDir := filepath.Join(dir, flag.Name)
+
+		err := os.MkdirAll(cacheDir, 0755)
+		if err != nil {
+			return nil, err
+		}
+
+		// staged alongside the cache so that it can be renamed into place
+		stagingDir, err := ioutil.TempDir(dir, "."+flag.Name+"-")
+		if err != nil {
+			return nil, err
+		}
+
+		inputPipe, err := CreatePipe(client, "cache", flag.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		outputPipe, err := CreatePipe(client, "cache", flag.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		taskConfig.Inputs = append(taskConfig.Inputs, atc.TaskInputConfig{
+			Name: flag.Name,
+			Path: flag.Path,
+		})
+
+		taskConfig.Outputs = append(taskConfig.Outputs, atc.TaskOutputConfig{
+			Name: flag.Name,
+			Path: flag.Path,
+		})
+
+		caches = append(caches, Cache{
+			Name: flag.Name,
+			Dir:  cacheDir,
+			Input: Input{
+				Name: flag.Name,
+				Path: cacheDir,
+				Pipe: inputPipe,
+			},
+			Output: Output{
+				Name: flag.Name,
+				Path: stagingDir,
+				Pipe: outputPipe,
+			},
+		})
+	}
+
+	return caches, nil
+}
+
+// Commit replaces the cache's contents with those fetched from the build.
+func (cache Cache) Commit() error {
+	old := cache.Dir + ".old"
+
+	err := os.RemoveAll(old)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(cache.Dir, old)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(cache.Output.Path, cache.Dir)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(old)
+}
+
+// Discard throws away whatever was fetched from the build, leaving the
+// cache as it was.
+func (cache Cache) Discard() error {
+	return os.RemoveAll(cache.Output.Path)
+}
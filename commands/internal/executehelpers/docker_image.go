@@ -0,0 +1,110 @@
+package executehelpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/concourse/fly/archive"
+	"github.com/concourse/fly/ui"
+)
+
+// DockerImageInputName is the name of the input that an image exported
+// with --image-from-docker is uploaded as.
+const DockerImageInputName = "fly-docker-image"
+
+// UploadDockerImage exports the root filesystem of a locally built image
+// with the docker CLI and uploads it to the input's pipe as an image
+// artifact, returning errors as Upload does.
+func UploadDockerImage(ctx context.Context, uploader archive.Uploader, input Input, compressionLevel int) error {
+	metadata, err := inspectDockerImage(input.DockerImage)
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not inspect docker image: %s", err)}
+	}
+
+	container, err := docker("create", input.DockerImage)
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not create container to export docker image from: %s", err)}
+	}
+
+	defer docker("rm", container)
+
+	export := exec.CommandContext(ctx, "docker", "export", container)
+	export.Stderr = ui.Stderr
+
+	rootfs, err := export.StdoutPipe()
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not export docker image: %s", err)}
+	}
+
+	err = export.Start()
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not export docker image: %s", err)}
+	}
+
+	uploadErr := uploader.UploadImage(ctx, input.Pipe.WriteURL, rootfs, metadata, archive.Options{
+		CompressionLevel: compressionLevel,
+	})
+	if uploadErr != nil {
+		fmt.Fprintln(ui.Stderr, "upload request failed:", uploadErr)
+	}
+
+	// drain whatever wasn't read so that docker can exit
+	io.Copy(ioutil.Discard, rootfs)
+
+	err = export.Wait()
+
+	if uploadErr != nil {
+		return uploadErr
+	}
+
+	// the image was cut short, so what was uploaded is no use either
+	if err != nil && ctx.Err() == nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not export docker image: %s", err)}
+	}
+
+	return nil
+}
+
+func inspectDockerImage(image string) (archive.ImageMetadata, error) {
+	output, err := docker("image", "inspect", "--format", "{{json .Config}}", image)
+	if err != nil {
+		return archive.ImageMetadata{}, err
+	}
+
+	var config struct {
+		Env  []string
+		User string
+	}
+
+	err = json.Unmarshal([]byte(output), &config)
+	if err != nil {
+		return archive.ImageMetadata{}, err
+	}
+
+	return archive.ImageMetadata{
+		Env:  config.Env,
+		User: config.User,
+	}, nil
+}
+
+func docker(args ...string) (string, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
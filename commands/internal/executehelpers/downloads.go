@@ -1,32 +1,71 @@
 package executehelpers
 
 import (
+	"context"
 	"fmt"
-	"net/http"
+	"sync"
 
-	"github.com/concourse/fly/ui"
-	"github.com/concourse/go-archive/tgzfs"
+	"github.com/concourse/fly/archive"
 	"github.com/concourse/go-concourse/concourse"
 )
 
-func Download(client concourse.Client, output Output) {
-	path := output.Path
-	pipe := output.Pipe
+// DownloadAll downloads outputs concurrently, with at most parallelism
+// downloads in flight at once, and returns once all of them are done, with
+// the errors of those that failed by name. A parallelism of zero or less
+// downloads every output at once.
+func DownloadAll(ctx context.Context, client concourse.Client, outputs []Output, parallelism int) map[string]error {
+	if parallelism <= 0 || parallelism > len(outputs) {
+		parallelism = len(outputs)
+	}
 
-	response, err := client.HTTPClient().Get(pipe.ReadURL)
-	if err != nil {
-		fmt.Fprintln(ui.Stderr, "download request failed:", err)
+	sem := make(chan struct{}, parallelism)
+
+	errsL := new(sync.Mutex)
+	errs := map[string]error{}
+
+	wg := new(sync.WaitGroup)
+	for _, output := range outputs {
+		if output.Path == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(output Output) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			err := Download(ctx, client, output)
+			if err != nil {
+				errsL.Lock()
+				errs[output.Name] = err
+				errsL.Unlock()
+			}
+		}(output)
 	}
 
-	defer response.Body.Close()
+	wg.Wait()
 
-	if response.StatusCode != http.StatusOK {
-		fmt.Fprintln(ui.Stderr, badResponseError("downloading bits", response))
-		panic("unexpected-response-code")
+	return errs
+}
+
+// Download fetches an output from its pipe into its path, which may be left
+// with only part of it if this fails.
+func Download(ctx context.Context, client concourse.Client, output Output) error {
+	downloader := archive.NewDownloader(client.HTTPClient())
+
+	tarStream, err := downloader.Stream(ctx, output.Pipe.ReadURL)
+	if err != nil {
+		return fmt.Errorf("could not download output '%s': %s", output.Name, err)
 	}
 
-	err = tgzfs.Extract(response.Body, path)
+	defer tarStream.Close()
+
+	err = archive.ExtractTar(tarStream, output.Path)
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("could not download output '%s': %s", output.Name, err)
 	}
+
+	return nil
 }
@@ -17,6 +17,17 @@ type Input struct {
 	Path string
 	Pipe atc.Pipe
 
+	// DockerImage is a local image to upload as the task's image, rather
+	// than a directory.
+	DockerImage string
+
+	// Archive is a recorded copy of the bits uploaded for an earlier build,
+	// to upload as-is rather than a directory.
+	Archive string
+
+	// Record is where to keep a copy of the bits as they are uploaded.
+	Record string
+
 	BuildInput atc.BuildInput
 }
 
@@ -95,7 +106,7 @@ func GenerateLocalInputs(client concourse.Client, inputMappings []flaghelpers.In
 		inputName := i.Name
 		absPath := i.Path
 
-		pipe, err := client.CreatePipe()
+		pipe, err := CreatePipe(client, "input", inputName)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,56 @@
+package executehelpers
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+)
+
+// MatrixEntry is one combination of a build matrix's values.
+type MatrixEntry []flaghelpers.VariablePairFlag
+
+// ExpandMatrix returns every combination of the given dimensions' values,
+// varying the last dimension fastest. With no dimensions, it returns a
+// single empty entry.
+func ExpandMatrix(dimensions []flaghelpers.MatrixFlag) []MatrixEntry {
+	entries := []MatrixEntry{{}}
+
+	for _, dimension := range dimensions {
+		expanded := make([]MatrixEntry, 0, len(entries)*len(dimension.Values))
+
+		for _, entry := range entries {
+			for _, value := range dimension.Values {
+				combination := make(MatrixEntry, len(entry), len(entry)+1)
+				copy(combination, entry)
+
+				expanded = append(expanded, append(combination, flaghelpers.VariablePairFlag{
+					Name:  dimension.Name,
+					Value: value,
+				}))
+			}
+		}
+
+		entries = expanded
+	}
+
+	return entries
+}
+
+func (entry MatrixEntry) Variables() template.StaticVariables {
+	vars := template.StaticVariables{}
+	for _, pair := range entry {
+		vars[pair.Name] = pair.Value
+	}
+
+	return vars
+}
+
+func (entry MatrixEntry) String() string {
+	pairs := make([]string, len(entry))
+	for i, pair := range entry {
+		pairs[i] = pair.Name + "=" + pair.Value
+	}
+
+	return strings.Join(pairs, ",")
+}
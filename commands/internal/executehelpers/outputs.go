@@ -2,6 +2,7 @@ package executehelpers
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 
 	"github.com/concourse/atc"
@@ -41,7 +42,7 @@ func DetermineOutputs(
 			return nil, err
 		}
 
-		pipe, err := client.CreatePipe()
+		pipe, err := CreatePipe(client, "output", outputName)
 		if err != nil {
 			return nil, err
 		}
@@ -55,3 +56,44 @@ func DetermineOutputs(
 
 	return outputs, nil
 }
+
+// FilterOutputs adds the task's outputs whose names match any of the
+// patterns to the given mappings, each to a directory named after it in the
+// working directory, unless it's mapped already.
+func FilterOutputs(taskOutputs []atc.TaskOutputConfig, outputMappings []flaghelpers.OutputPairFlag, patterns []string) ([]flaghelpers.OutputPairFlag, error) {
+	if len(patterns) == 0 {
+		return outputMappings, nil
+	}
+
+	mapped := map[string]bool{}
+	for _, mapping := range outputMappings {
+		mapped[mapping.Name] = true
+	}
+
+	matched := false
+	for _, output := range taskOutputs {
+		for _, pattern := range patterns {
+			match, err := path.Match(pattern, output.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output filter '%s': %s", pattern, err)
+			}
+
+			if !match {
+				continue
+			}
+
+			matched = true
+
+			if !mapped[output.Name] {
+				outputMappings = append(outputMappings, flaghelpers.OutputPairFlag{Name: output.Name, Path: output.Name})
+				mapped[output.Name] = true
+			}
+		}
+	}
+
+	if !matched {
+		return nil, fmt.Errorf("none of the task's outputs match the output filters %v", patterns)
+	}
+
+	return outputMappings, nil
+}
@@ -0,0 +1,102 @@
+package executehelpers
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Phases tracks how long a build run by fly execute spends in each of its
+// phases: uploading its inputs, waiting for a worker to run it on, and
+// running it, so that it's clear whether slowness is down to the network,
+// scheduling, or the build itself.
+//
+// Uploads happen alongside the build's output, which can only be written
+// to from one goroutine, so phases are announced on the output the build's
+// events are rendered to rather than as uploads finish.
+type Phases struct {
+	lock      sync.Mutex
+	started   time.Time
+	uploaded  time.Time
+	scheduled bool
+	running   time.Time
+}
+
+// StartPhases begins the uploading phase, announcing it on out.
+func StartPhases(out io.Writer) *Phases {
+	announcePhase(out, "uploading inputs...")
+	return &Phases{started: time.Now()}
+}
+
+// Uploaded ends the uploading phase; the build then waits for a worker.
+func (phases *Phases) Uploaded() {
+	phases.lock.Lock()
+	defer phases.lock.Unlock()
+
+	phases.uploaded = time.Now()
+}
+
+// Initializing announces that the build's waiting for a worker as its first
+// task initializes.
+func (phases *Phases) Initializing(dst io.Writer) {
+	phases.lock.Lock()
+	defer phases.lock.Unlock()
+
+	if !phases.scheduled {
+		phases.scheduled = true
+		announcePhase(dst, "waiting for worker...")
+	}
+}
+
+// Running marks the start of the build's first task, which announces the
+// running phase itself.
+func (phases *Phases) Running(dst io.Writer) {
+	phases.lock.Lock()
+	defer phases.lock.Unlock()
+
+	if phases.running.IsZero() {
+		phases.running = time.Now()
+	}
+}
+
+// Summary describes how long each phase took, given when the build
+// finished.
+func (phases *Phases) Summary(finished time.Time) string {
+	phases.lock.Lock()
+	defer phases.lock.Unlock()
+
+	uploaded := phases.uploaded
+	if uploaded.IsZero() || uploaded.After(finished) {
+		uploaded = finished
+	}
+
+	// the build only waits for a worker once its inputs are there
+	running := phases.running
+	if running.IsZero() {
+		running = finished
+	} else if running.Before(uploaded) {
+		running = uploaded
+	}
+
+	return fmt.Sprintf(
+		"uploading %s, waiting for worker %s, running %s",
+		roundDuration(uploaded.Sub(phases.started)),
+		roundDuration(running.Sub(uploaded)),
+		roundDuration(finished.Sub(running)),
+	)
+}
+
+func announcePhase(dst io.Writer, message string) {
+	fmt.Fprintln(dst, color.New(color.Faint).Sprint(message))
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+
+	return d.Round(100 * time.Millisecond)
+}
@@ -0,0 +1,65 @@
+package executehelpers
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// RenewPipes replaces the pipes of the given inputs, outputs and caches with
+// fresh ones, e.g. for a new build to take the place of one whose inputs
+// couldn't be uploaded. A pipe is only read once, so the old ones are of no
+// use to the new build. Inputs taken from a pipeline have no pipe, and are
+// left as they are.
+func RenewPipes(client concourse.Client, inputs []Input, outputs []Output, caches []Cache) error {
+	for i := range inputs {
+		if inputs[i].Pipe.ID == "" {
+			continue
+		}
+
+		pipe, err := CreatePipe(client, "input", inputs[i].Name)
+		if err != nil {
+			return err
+		}
+
+		inputs[i].Pipe = pipe
+	}
+
+	for i := range outputs {
+		pipe, err := CreatePipe(client, "output", outputs[i].Name)
+		if err != nil {
+			return err
+		}
+
+		outputs[i].Pipe = pipe
+	}
+
+	for i := range caches {
+		inputPipe, err := CreatePipe(client, "cache", caches[i].Name)
+		if err != nil {
+			return err
+		}
+
+		outputPipe, err := CreatePipe(client, "cache", caches[i].Name)
+		if err != nil {
+			return err
+		}
+
+		caches[i].Input.Pipe = inputPipe
+		caches[i].Output.Pipe = outputPipe
+	}
+
+	return nil
+}
+
+// CreatePipe creates a pipe for the named input, output or cache, saying
+// which one it was for if it couldn't be.
+func CreatePipe(client concourse.Client, kind string, name string) (atc.Pipe, error) {
+	pipe, err := client.CreatePipe()
+	if err != nil {
+		return atc.Pipe{}, fmt.Errorf("could not create the pipe for %s '%s': %s", kind, name, err)
+	}
+
+	return pipe, nil
+}
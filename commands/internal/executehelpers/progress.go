@@ -0,0 +1,138 @@
+package executehelpers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/concourse/fly/ui"
+)
+
+// ProgressInterval is how often the progress of an input's upload is
+// reported. Uploads that finish sooner aren't reported at all.
+var ProgressInterval = 5 * time.Second
+
+// uploadProgress reports on stderr how far along an input's upload is: how
+// much of its files have been archived, out of their size as found when
+// walking the input, and how much has been sent after compression, and how
+// fast.
+type uploadProgress struct {
+	name    string
+	started time.Time
+
+	lock     sync.Mutex
+	archived int64
+	total    int64
+	sent     int64
+	walked   bool
+	reported bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func startProgress(name string) *uploadProgress {
+	progress := &uploadProgress{
+		name:    name,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go progress.report()
+
+	return progress
+}
+
+// Archived is given as the archive's Progress.
+func (progress *uploadProgress) Archived(archived int64, total int64) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	progress.archived = archived
+	progress.total = total
+	progress.walked = true
+}
+
+// Write counts the compressed bytes sent; it's given as the archive's Tee.
+func (progress *uploadProgress) Write(p []byte) (int, error) {
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	progress.sent += int64(len(p))
+
+	return len(p), nil
+}
+
+// Finish stops reporting, summing the upload up if it was reported on.
+func (progress *uploadProgress) Finish(err error) {
+	close(progress.stop)
+	<-progress.done
+
+	progress.lock.Lock()
+	defer progress.lock.Unlock()
+
+	if !progress.reported || err != nil {
+		return
+	}
+
+	elapsed := time.Since(progress.started)
+
+	fmt.Fprintf(
+		ui.Stderr,
+		"uploaded %s: %s in %s (%s/s)\n",
+		progress.name,
+		ui.FormatBytes(uint64(progress.sent)),
+		roundDuration(elapsed),
+		ui.FormatBytes(progress.rate(elapsed)),
+	)
+}
+
+func (progress *uploadProgress) report() {
+	defer close(progress.done)
+
+	ticker := time.NewTicker(ProgressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			progress.lock.Lock()
+			progress.reported = true
+			if progress.walked {
+				fmt.Fprintf(
+					ui.Stderr,
+					"uploading %s: %d%% of %s archived, %s sent (%s/s)\n",
+					progress.name,
+					progress.percent(),
+					ui.FormatBytes(uint64(progress.total)),
+					ui.FormatBytes(uint64(progress.sent)),
+					ui.FormatBytes(progress.rate(time.Since(progress.started))),
+				)
+			} else {
+				fmt.Fprintf(ui.Stderr, "uploading %s: finding the files to archive\n", progress.name)
+			}
+			progress.lock.Unlock()
+
+		case <-progress.stop:
+			return
+		}
+	}
+}
+
+// the total's only an estimate, e.g. files may grow while they're archived
+func (progress *uploadProgress) percent() int64 {
+	if progress.total == 0 || progress.archived >= progress.total {
+		return 100
+	}
+
+	return progress.archived * 100 / progress.total
+}
+
+func (progress *uploadProgress) rate(elapsed time.Duration) uint64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return uint64(float64(progress.sent) / elapsed.Seconds())
+}
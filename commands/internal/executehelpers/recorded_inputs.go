@@ -0,0 +1,207 @@
+package executehelpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// RecordedBuildsToKeep is how many builds' inputs are kept per target for
+// --same-inputs-as; older ones are removed by PruneRecords.
+const RecordedBuildsToKeep = 10
+
+const recordFileName = "inputs.json"
+
+type inputRecord struct {
+	// Pipeline is the pipeline the build was created in, if its inputs came
+	// from one of its jobs.
+	Pipeline string          `json:"pipeline,omitempty"`
+	Inputs   []recordedInput `json:"inputs"`
+}
+
+type recordedInput struct {
+	Name string `json:"name"`
+
+	// Archive is the file, alongside the record, holding a copy of the bits
+	// that were uploaded for the input.
+	Archive string `json:"archive,omitempty"`
+
+	BuildInput *atc.BuildInput `json:"build_input,omitempty"`
+}
+
+// RecordInputs remembers the inputs of a build under dir, so that another
+// build can be run with them by RecordedInputs. The returned inputs have
+// Record set to where a copy of their bits should be kept as they are
+// uploaded.
+//
+// An image from --image-from-docker isn't recorded, as it can just be given
+// again.
+func RecordInputs(dir string, buildID int, pipeline string, inputs []Input) ([]Input, error) {
+	buildDir := filepath.Join(dir, strconv.Itoa(buildID))
+
+	err := os.MkdirAll(buildDir, 0700)
+	if err != nil {
+		return inputs, err
+	}
+
+	record := inputRecord{Pipeline: pipeline}
+
+	recorded := make([]Input, len(inputs))
+	for i, input := range inputs {
+		recorded[i] = input
+
+		if input.DockerImage != "" {
+			continue
+		}
+
+		if input.Path != "" || input.Archive != "" {
+			archive := input.Name + ".tgz"
+			recorded[i].Record = filepath.Join(buildDir, archive)
+
+			record.Inputs = append(record.Inputs, recordedInput{
+				Name:    input.Name,
+				Archive: archive,
+			})
+		} else {
+			buildInput := input.BuildInput
+
+			record.Inputs = append(record.Inputs, recordedInput{
+				Name:       input.Name,
+				BuildInput: &buildInput,
+			})
+		}
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return inputs, err
+	}
+
+	// job inputs carry their resource's source, so the record is kept as
+	// private as the token is
+	err = ioutil.WriteFile(filepath.Join(buildDir, recordFileName), payload, 0600)
+	if err != nil {
+		return inputs, err
+	}
+
+	return recorded, nil
+}
+
+// RecordedInputs returns the task's inputs as they were given to an earlier
+// build recorded by RecordInputs, along with the pipeline the build was
+// created in, if any. Inputs whose bits were uploaded are given fresh pipes
+// to upload the recorded copies through.
+func RecordedInputs(
+	client concourse.Client,
+	dir string,
+	buildID int,
+	taskInputs []atc.TaskInputConfig,
+) (string, []Input, error) {
+	buildDir := filepath.Join(dir, strconv.Itoa(buildID))
+
+	payload, err := ioutil.ReadFile(filepath.Join(buildDir, recordFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil, fmt.Errorf(
+				"the inputs of build %d are not cached; only those of the last %d builds executed from this machine with --record-inputs are kept",
+				buildID,
+				RecordedBuildsToKeep,
+			)
+		}
+
+		return "", nil, err
+	}
+
+	var record inputRecord
+	err = json.Unmarshal(payload, &record)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed record of the inputs of build %d: %s", buildID, err)
+	}
+
+	recordedInputs := map[string]recordedInput{}
+	for _, input := range record.Inputs {
+		recordedInputs[input.Name] = input
+	}
+
+	inputs := []Input{}
+	for _, taskInput := range taskInputs {
+		recorded, found := recordedInputs[taskInput.Name]
+		if !found {
+			return "", nil, fmt.Errorf("build %d had no input `%s`", buildID, taskInput.Name)
+		}
+
+		if recorded.BuildInput != nil {
+			inputs = append(inputs, Input{
+				Name:       recorded.Name,
+				BuildInput: *recorded.BuildInput,
+			})
+
+			continue
+		}
+
+		archive := filepath.Join(buildDir, recorded.Archive)
+		if _, err := os.Stat(archive); err != nil {
+			return "", nil, fmt.Errorf("the bits uploaded for input `%s` of build %d are not cached", recorded.Name, buildID)
+		}
+
+		pipe, err := CreatePipe(client, "input", recorded.Name)
+		if err != nil {
+			return "", nil, err
+		}
+
+		inputs = append(inputs, Input{
+			Name:    recorded.Name,
+			Archive: archive,
+			Pipe:    pipe,
+		})
+	}
+
+	return record.Pipeline, inputs, nil
+}
+
+// PruneRecords removes all but the last RecordedBuildsToKeep builds' records.
+// It mustn't run while a record may still be uploaded, e.g. for
+// --same-inputs-as, or be written.
+func PruneRecords(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var buildIDs []int
+	for _, entry := range entries {
+		buildID, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		buildIDs = append(buildIDs, buildID)
+	}
+
+	if len(buildIDs) <= RecordedBuildsToKeep {
+		return nil
+	}
+
+	// build IDs only go up, so the newest builds sort last
+	sort.Ints(buildIDs)
+
+	for _, buildID := range buildIDs[:len(buildIDs)-RecordedBuildsToKeep] {
+		err := os.RemoveAll(filepath.Join(dir, strconv.Itoa(buildID)))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package executehelpers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/tedsuo/rata"
+)
+
+// CreateBuild creates a one-off build of the plan, or one of the team's
+// pipeline if it's given, as client.CreateBuild and team.CreatePipelineBuild
+// do, but gives up once ctx is done.
+func CreateBuild(ctx context.Context, client concourse.Client, teamName string, pipeline string, plan atc.Plan) (atc.Build, error) {
+	body, err := json.Marshal(plan)
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	route := atc.CreateBuild
+	params := rata.Params{}
+	if pipeline != "" {
+		route = atc.CreatePipelineBuild
+		params = rata.Params{"team_name": teamName, "pipeline_name": pipeline}
+	}
+
+	request, err := rata.NewRequestGenerator(client.URL(), atc.Routes).CreateRequest(route, params, bytes.NewReader(body))
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.HTTPClient().Do(request.WithContext(ctx))
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	defer response.Body.Close()
+
+	err = checkBuildResponse(response)
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	var build atc.Build
+	err = json.NewDecoder(response.Body).Decode(&build)
+	if err != nil {
+		return atc.Build{}, err
+	}
+
+	return build, nil
+}
+
+// AbortBuild aborts the build as client.AbortBuild does, but gives up once
+// ctx is done.
+func AbortBuild(ctx context.Context, client concourse.Client, buildID int) error {
+	request, err := rata.NewRequestGenerator(client.URL(), atc.Routes).CreateRequest(atc.AbortBuild, rata.Params{"build_id": strconv.Itoa(buildID)}, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.HTTPClient().Do(request.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	return checkBuildResponse(response)
+}
+
+func checkBuildResponse(response *http.Response) error {
+	switch {
+	case response.StatusCode == http.StatusUnauthorized:
+		return concourse.ErrUnauthorized
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("%s: %s", response.Status, strings.TrimSpace(string(message)))
+	default:
+		return nil
+	}
+}
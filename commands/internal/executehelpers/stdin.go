@@ -0,0 +1,87 @@
+package executehelpers
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/archive"
+)
+
+// StdinFileName is what the file of an input read from stdin is called,
+// unless it's given a name.
+const StdinFileName = "stdin"
+
+// ArchiveStdin reads stdin to the end and archives it as a single file
+// called name, returning the path of the archive, to be uploaded as-is. It
+// has to be read up front, as a file in an archive needs its size before
+// its contents. The archive is in a directory of its own, which the caller
+// removes once the builds are done with it.
+func ArchiveStdin(stdin io.Reader, name string, compressionLevel int) (string, error) {
+	dir, err := ioutil.TempDir("", "fly-stdin-")
+	if err != nil {
+		return "", err
+	}
+
+	archivePath, err := archiveFile(dir, stdin, name, compressionLevel)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return archivePath, nil
+}
+
+func archiveFile(dir string, contents io.Reader, name string, compressionLevel int) (string, error) {
+	src := filepath.Join(dir, "src")
+
+	err := os.Mkdir(src, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeFile(filepath.Join(src, name), contents)
+	if err != nil {
+		return "", err
+	}
+
+	archivePath := filepath.Join(dir, "input.tgz")
+
+	err = writeArchive(archivePath, src, compressionLevel)
+	if err != nil {
+		return "", err
+	}
+
+	return archivePath, os.RemoveAll(src)
+}
+
+func writeFile(path string, src io.Reader) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, src)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeArchive(path string, src string, compressionLevel int) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = archive.Compress(file, src, archive.Options{CompressionLevel: compressionLevel})
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,123 @@
+package executehelpers
+
+import (
+	"bufio"
+	"bytes"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type submodule struct {
+	// Path is slash-separated and relative to the directory it was found in.
+	Path        string
+	Initialized bool
+}
+
+// e.g. " 1f2e3d4c vendor/lib (v1.2.0)", or "-1f2e3d4c vendor/lib" when it
+// isn't initialized
+var submoduleStatus = regexp.MustCompile(`^([ +\-U])([0-9a-f]+) (.+?)(?: \(.*\))?$`)
+
+// gitSubmodules returns the submodules of the git work tree at dir, including
+// those nested in initialized submodules. A dir that isn't in a git work
+// tree, or without git to ask, has none.
+func gitSubmodules(dir string) ([]submodule, error) {
+	isWorkTree := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	isWorkTree.Dir = dir
+
+	out, err := isWorkTree.Output()
+	if err != nil || strings.TrimSpace(string(out)) != "true" {
+		return nil, nil
+	}
+
+	return listSubmodules(dir, "")
+}
+
+func listSubmodules(dir string, prefix string) ([]submodule, error) {
+	status := exec.Command("git", "submodule", "status")
+	status.Dir = dir
+
+	out, err := status.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var submodules []submodule
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		match := submoduleStatus.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+
+		// submodules of the repository outside of dir aren't uploaded
+		if strings.HasPrefix(match[3], "../") {
+			continue
+		}
+
+		sub := submodule{
+			Path:        path.Join(prefix, match[3]),
+			Initialized: match[1] != "-",
+		}
+
+		submodules = append(submodules, sub)
+
+		if sub.Initialized {
+			nested, err := listSubmodules(filepath.Join(dir, filepath.FromSlash(match[3])), sub.Path)
+			if err != nil {
+				return nil, err
+			}
+
+			submodules = append(submodules, nested...)
+		}
+	}
+
+	return submodules, scanner.Err()
+}
+
+// withSubmoduleFiles replaces the entries of initialized submodules in files,
+// as listed by git ls-files, with the files of the submodules themselves.
+func withSubmoduleFiles(dir string, files []string, submodules []submodule) ([]string, error) {
+	initialized := map[string]bool{}
+	for _, sub := range submodules {
+		if sub.Initialized {
+			initialized[sub.Path] = true
+		}
+	}
+
+	var expanded []string
+	for _, file := range files {
+		if !initialized[file] {
+			expanded = append(expanded, file)
+			continue
+		}
+
+		subDir := filepath.Join(dir, filepath.FromSlash(file))
+
+		subFiles, err := getGitFiles(subDir)
+		if err != nil {
+			return nil, err
+		}
+
+		var nested []submodule
+		for _, sub := range submodules {
+			if strings.HasPrefix(sub.Path, file+"/") {
+				nested = append(nested, submodule{Path: strings.TrimPrefix(sub.Path, file+"/"), Initialized: sub.Initialized})
+			}
+		}
+
+		subFiles, err = withSubmoduleFiles(subDir, subFiles, nested)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, subFile := range subFiles {
+			expanded = append(expanded, path.Join(file, subFile))
+		}
+	}
+
+	return expanded, nil
+}
@@ -3,17 +3,40 @@ package executehelpers
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"net/http"
+	"os"
 	"os/exec"
+	"path/filepath"
 
+	"github.com/concourse/fly/archive"
+	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
-	"github.com/concourse/go-archive/tgzfs"
-	"github.com/concourse/go-concourse/concourse"
 )
 
-func Upload(client concourse.Client, input Input, excludeIgnored bool) {
+// IgnoreFileName is the file at the root of an input listing the patterns of
+// paths to leave out of its upload, one per line.
+const IgnoreFileName = ".flyignore"
+
+// InputError is returned when an input couldn't be read to upload it, e.g.
+// its ignored files couldn't be listed, so uploading it to a new build
+// wouldn't help.
+type InputError struct {
+	Input string
+	Err   error
+}
+
+func (err InputError) Error() string {
+	return fmt.Sprintf("could not upload input '%s': %s", err.Input, err.Err)
+}
+
+// Upload uploads an input's directory, archived with the given options plus
+// the files to leave out, reporting the upload's progress every
+// ProgressInterval if showProgress is set. An InputError is returned if the
+// input couldn't be read, and the upload's own error, reported on stderr,
+// if it failed, so that a corrupted upload can be acted on.
+func Upload(ctx context.Context, uploader archive.Uploader, input Input, excludeIgnored bool, excludeSubmodules bool, showProgress bool, opts archive.Options) error {
 	path := input.Path
 	pipe := input.Pipe
 
@@ -23,34 +46,134 @@ func Upload(client concourse.Client, input Input, excludeIgnored bool) {
 	if excludeIgnored {
 		files, err = getGitFiles(path)
 		if err != nil {
-			fmt.Fprintln(ui.Stderr, "could not determine ignored files:", err)
-			return
+			return InputError{Input: input.Name, Err: fmt.Errorf("could not determine ignored files: %s", err)}
+		}
+	}
+
+	submodules, err := gitSubmodules(path)
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not determine submodules: %s", err)}
+	}
+
+	var excludePaths []string
+	if excludeSubmodules {
+		for _, sub := range submodules {
+			excludePaths = append(excludePaths, sub.Path)
 		}
 	} else {
-		files = []string{"."}
+		for _, sub := range submodules {
+			if !sub.Initialized {
+				fmt.Fprintf(ui.Stderr, "submodule %s of input %s is not initialized, so it will be empty; run git submodule update --init\n", sub.Path, input.Name)
+			}
+		}
+
+		// submodules are listed by git ls-files as a whole, so their own
+		// ignored files are left out by listing them separately
+		if excludeIgnored {
+			files, err = withSubmoduleFiles(path, files, submodules)
+			if err != nil {
+				return InputError{Input: input.Name, Err: fmt.Errorf("could not determine ignored files: %s", err)}
+			}
+		}
+	}
+
+	// what's listed may be secret, so it's not uploaded without knowing
+	ignored, err := rc.ReadExcludesFile(filepath.Join(path, IgnoreFileName))
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not read its %s: %s", IgnoreFileName, err)}
+	}
+
+	opts.Files = files
+	opts.Exclude = append(append([]string{}, opts.Exclude...), ignored...)
+	opts.ExcludePaths = excludePaths
+	opts.Skipped = func(relPath string, reason error) {
+		fmt.Fprintf(ui.Stderr, "skipping %s in input %s: %s\n", relPath, input.Name, reason)
+	}
+
+	record, err := createRecord(input)
+	if err != nil {
+		return err
+	}
+
+	if record != nil {
+		defer record.Close()
+		opts.Tee = record
+	}
+
+	var progress *uploadProgress
+	if showProgress {
+		progress = startProgress(input.Name)
+		opts.Progress = progress.Archived
+
+		if opts.Tee != nil {
+			opts.Tee = io.MultiWriter(opts.Tee, progress)
+		} else {
+			opts.Tee = progress
+		}
 	}
 
-	archiveStream, archiveWriter := io.Pipe()
+	err = uploader.Upload(ctx, pipe.WriteURL, path, opts)
 
-	go func() {
-		archiveWriter.CloseWithError(tgzfs.Compress(archiveWriter, path, files...))
-	}()
+	if progress != nil {
+		progress.Finish(err)
+	}
 
-	upload, err := http.NewRequest("PUT", pipe.WriteURL, archiveStream)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(ui.Stderr, "upload request failed:", err)
+		discardRecord(record)
 	}
 
-	response, err := client.HTTPClient().Do(upload)
+	return err
+}
+
+// UploadArchive uploads the recorded bits of an input as-is, returning
+// errors as Upload does.
+func UploadArchive(ctx context.Context, uploader archive.Uploader, input Input) error {
+	src, err := os.Open(input.Archive)
+	if err != nil {
+		return InputError{Input: input.Name, Err: fmt.Errorf("could not open its recording: %s", err)}
+	}
+
+	defer src.Close()
+
+	var body io.Reader = src
+
+	record, err := createRecord(input)
+	if err != nil {
+		return err
+	}
+
+	if record != nil {
+		defer record.Close()
+		body = io.TeeReader(src, record)
+	}
+
+	err = uploader.UploadArchive(ctx, input.Pipe.WriteURL, body)
 	if err != nil {
 		fmt.Fprintln(ui.Stderr, "upload request failed:", err)
-		return
+		discardRecord(record)
+	}
+
+	return err
+}
+
+func createRecord(input Input) (*os.File, error) {
+	if input.Record == "" {
+		return nil, nil
+	}
+
+	record, err := os.OpenFile(input.Record, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, InputError{Input: input.Name, Err: fmt.Errorf("could not record it: %s", err)}
 	}
 
-	defer response.Body.Close()
+	return record, nil
+}
 
-	if response.StatusCode != http.StatusOK {
-		fmt.Fprintln(ui.Stderr, badResponseError("uploading bits", response))
+// an incomplete copy can't be used again, so it's better not kept at all
+func discardRecord(record *os.File) {
+	if record != nil {
+		os.Remove(record.Name())
 	}
 }
 
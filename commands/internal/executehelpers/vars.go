@@ -0,0 +1,109 @@
+package executehelpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Vars builds the variables for interpolating a task config from -v, -y,
+// and -l flags, falling back to the given sources (e.g. Vault). Flags take
+// precedence over files, later files over earlier ones, and files over
+// sources.
+func Vars(
+	variables []flaghelpers.VariablePairFlag,
+	yamlVariables []flaghelpers.YAMLVariablePairFlag,
+	variablesFiles []atc.PathFlag,
+	sources ...template.Variables,
+) (template.Variables, error) {
+	flagVars := template.StaticVariables{}
+	for _, f := range variables {
+		flagVars[f.Name] = f.Value
+	}
+
+	for _, f := range yamlVariables {
+		flagVars[f.Name] = f.Value
+	}
+
+	vars := []template.Variables{flagVars}
+	for i := len(variablesFiles) - 1; i >= 0; i-- {
+		path := string(variablesFiles[i])
+
+		payload, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read template variables file (%s): %s", path, err)
+		}
+
+		var staticVars template.StaticVariables
+		err = yaml.Unmarshal(payload, &staticVars)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse template variables file (%s): %s", path, err)
+		}
+
+		vars = append(vars, staticVars)
+	}
+
+	vars = append(vars, sources...)
+
+	return template.NewMultiVars(vars), nil
+}
+
+// RecordedVars wraps template.Variables, remembering every string value
+// resolved through it so that they can be redacted from the build's output.
+type RecordedVars struct {
+	template.Variables
+
+	valuesL sync.Mutex
+	values  []string
+}
+
+func NewRecordedVars(vars template.Variables) *RecordedVars {
+	return &RecordedVars{Variables: vars}
+}
+
+func (vars *RecordedVars) Get(def template.VariableDefinition) (interface{}, bool, error) {
+	value, found, err := vars.Variables.Get(def)
+	if err != nil || !found {
+		return value, found, err
+	}
+
+	vars.valuesL.Lock()
+	vars.values = appendStrings(vars.values, value)
+	vars.valuesL.Unlock()
+
+	return value, found, nil
+}
+
+// appendStrings appends the non-empty strings in value, including those
+// nested in maps, e.g. the fields of a secret picked out with ((var.field)).
+func appendStrings(values []string, value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			values = append(values, v)
+		}
+	case map[string]interface{}:
+		for _, nested := range v {
+			values = appendStrings(values, nested)
+		}
+	case map[interface{}]interface{}:
+		for _, nested := range v {
+			values = appendStrings(values, nested)
+		}
+	}
+
+	return values
+}
+
+// Values returns the string values resolved so far.
+func (vars *RecordedVars) Values() []string {
+	vars.valuesL.Lock()
+	defer vars.valuesL.Unlock()
+
+	return append([]string{}, vars.values...)
+}
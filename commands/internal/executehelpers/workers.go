@@ -0,0 +1,74 @@
+package executehelpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// WorkerTags returns the tags that place a build's steps on the named worker
+// and no other. Steps can only be placed by tags, so this fails unless the
+// worker has tags that no other worker has all of.
+func WorkerTags(client concourse.Client, name string) ([]string, error) {
+	workers, err := client.ListWorkers()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	found := false
+
+	for _, worker := range workers {
+		if worker.Name == name {
+			tags = worker.Tags
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, rc.NewErrNotFound("worker", name)
+	}
+
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("worker '%s' has no tags, so builds can't be placed on it alone", name)
+	}
+
+	var others []string
+	for _, worker := range workers {
+		if worker.Name != name && hasAllTags(worker.Tags, tags) {
+			others = append(others, worker.Name)
+		}
+	}
+
+	if len(others) > 0 {
+		return nil, fmt.Errorf(
+			"worker '%s' can't be targeted alone: its tags (%s) are shared with %s",
+			name,
+			strings.Join(tags, ", "),
+			strings.Join(others, ", "),
+		)
+	}
+
+	return tags, nil
+}
+
+func hasAllTags(have []string, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if h == w {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,70 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"G", 1 << 30},
+	{"M", 1 << 20},
+	{"K", 1 << 10},
+	{"B", 1},
+}
+
+// ByteSizeFlag is a number of bytes, given either as a plain number or with
+// a K, M, or G (optionally followed by B) suffix, e.g. 512, 10KB, or 5M.
+type ByteSizeFlag int64
+
+func (size *ByteSizeFlag) UnmarshalFlag(value string) error {
+	bytes, err := parseByteSize(value)
+	if err != nil {
+		return err
+	}
+
+	*size = ByteSizeFlag(bytes)
+
+	return nil
+}
+
+func parseByteSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+
+	for _, unit := range byteSizeUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSpace(strings.TrimSuffix(number, unit.suffix))
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (expected e.g. 512, 10KB, or 5MB)", value)
+	}
+
+	return int64(n * float64(multiplier)), nil
+}
+
+// ByteRateFlag is a number of bytes per second, given as a ByteSizeFlag
+// optionally followed by /s, e.g. 5MB/s.
+type ByteRateFlag int64
+
+func (rate *ByteRateFlag) UnmarshalFlag(value string) error {
+	bytes, err := parseByteSize(strings.TrimSuffix(strings.TrimSpace(value), "/s"))
+	if err != nil {
+		return fmt.Errorf("invalid rate '%s' (expected e.g. 512KB/s or 5MB/s)", value)
+	}
+
+	*rate = ByteRateFlag(bytes)
+
+	return nil
+}
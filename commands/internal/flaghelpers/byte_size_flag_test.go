@@ -0,0 +1,69 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ByteSizeFlag", func() {
+	var size ByteSizeFlag
+
+	BeforeEach(func() {
+		size = 0
+	})
+
+	It("parses plain byte counts", func() {
+		Expect(size.UnmarshalFlag("512")).To(Succeed())
+		Expect(int64(size)).To(Equal(int64(512)))
+	})
+
+	It("parses unit suffixes", func() {
+		Expect(size.UnmarshalFlag("10KB")).To(Succeed())
+		Expect(int64(size)).To(Equal(int64(10 * 1024)))
+
+		Expect(size.UnmarshalFlag("5MB")).To(Succeed())
+		Expect(int64(size)).To(Equal(int64(5 * 1024 * 1024)))
+
+		Expect(size.UnmarshalFlag("2g")).To(Succeed())
+		Expect(int64(size)).To(Equal(int64(2 * 1024 * 1024 * 1024)))
+	})
+
+	It("parses fractional sizes", func() {
+		Expect(size.UnmarshalFlag("1.5K")).To(Succeed())
+		Expect(int64(size)).To(Equal(int64(1536)))
+	})
+
+	Context("when the size is not a number", func() {
+		It("returns an error", func() {
+			err := size.UnmarshalFlag("lots")
+			Expect(err).To(MatchError("invalid size 'lots' (expected e.g. 512, 10KB, or 5MB)"))
+		})
+	})
+})
+
+var _ = Describe("ByteRateFlag", func() {
+	var rate ByteRateFlag
+
+	BeforeEach(func() {
+		rate = 0
+	})
+
+	It("parses sizes per second", func() {
+		Expect(rate.UnmarshalFlag("5MB/s")).To(Succeed())
+		Expect(int64(rate)).To(Equal(int64(5 * 1024 * 1024)))
+	})
+
+	It("allows the per-second suffix to be omitted", func() {
+		Expect(rate.UnmarshalFlag("512K")).To(Succeed())
+		Expect(int64(rate)).To(Equal(int64(512 * 1024)))
+	})
+
+	Context("when the rate is not a number", func() {
+		It("returns an error", func() {
+			err := rate.UnmarshalFlag("fast")
+			Expect(err).To(MatchError("invalid rate 'fast' (expected e.g. 512KB/s or 5MB/s)"))
+		})
+	})
+})
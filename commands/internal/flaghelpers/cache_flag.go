@@ -0,0 +1,23 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+type CacheFlag struct {
+	Name string
+	Path string
+}
+
+func (pair *CacheFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "=", 2)
+	if len(vs) != 2 || vs[0] == "" || vs[1] == "" {
+		return fmt.Errorf("invalid cache '%s' (must be name=path)", value)
+	}
+
+	pair.Name = vs[0]
+	pair.Path = vs[1]
+
+	return nil
+}
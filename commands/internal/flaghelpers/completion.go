@@ -1,8 +1,11 @@
 package flaghelpers
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/concourse/go-concourse/concourse"
 	"github.com/jessevdk/go-flags"
 
 	"github.com/concourse/fly/rc"
@@ -29,3 +32,70 @@ func parseFlags() flyCommand {
 
 	return fly
 }
+
+// completionTeam returns the team of the target given on the command line
+// being completed, if it can be reached. Its listings go through the
+// response cache, so completing several arguments in a row is quick.
+func completionTeam() (concourse.Team, bool) {
+	fly := parseFlags()
+
+	target, err := rc.LoadTarget(fly.Target, false)
+	if err != nil {
+		return nil, false
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return nil, false
+	}
+
+	return target.Team(), true
+}
+
+// completePipelines completes the names of the team's pipelines, each
+// followed by suffix.
+func completePipelines(team concourse.Team, match string, suffix string) []flags.Completion {
+	comps := []flags.Completion{}
+
+	pipelines, err := team.ListPipelines()
+	if err != nil {
+		return comps
+	}
+
+	for _, pipeline := range pipelines {
+		if strings.HasPrefix(pipeline.Name, match) {
+			comps = append(comps, flags.Completion{Item: pipeline.Name + suffix})
+		}
+	}
+
+	return comps
+}
+
+// completeInPipeline completes PIPELINE/NAME arguments: the team's pipelines
+// until a slash is typed, and then the names list returns for the pipeline.
+func completeInPipeline(match string, list func(team concourse.Team, pipelineName string) ([]string, error)) []flags.Completion {
+	team, ok := completionTeam()
+	if !ok {
+		return []flags.Completion{}
+	}
+
+	vs := strings.SplitN(match, "/", 2)
+	if len(vs) == 1 {
+		return completePipelines(team, vs[0], "/")
+	}
+
+	comps := []flags.Completion{}
+
+	names, err := list(team, vs[0])
+	if err != nil {
+		return comps
+	}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, vs[1]) {
+			comps = append(comps, flags.Completion{Item: fmt.Sprintf("%s/%s", vs[0], name)})
+		}
+	}
+
+	return comps
+}
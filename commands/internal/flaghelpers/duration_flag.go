@@ -0,0 +1,45 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var longDurationUnits = []struct {
+	suffix     string
+	multiplier time.Duration
+}{
+	{"w", 7 * 24 * time.Hour},
+	{"d", 24 * time.Hour},
+}
+
+// DurationFlag is a time.Duration that can also be given in days or weeks,
+// e.g. 30d or 2w, for spans too long to comfortably give in hours.
+type DurationFlag time.Duration
+
+func (duration *DurationFlag) UnmarshalFlag(value string) error {
+	trimmed := strings.TrimSpace(value)
+
+	for _, unit := range longDurationUnits {
+		if strings.HasSuffix(trimmed, unit.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSuffix(trimmed, unit.suffix), 64)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid duration '%s' (expected e.g. 12h, 30d, or 2w)", value)
+			}
+
+			*duration = DurationFlag(n * float64(unit.multiplier))
+			return nil
+		}
+	}
+
+	parsed, err := time.ParseDuration(trimmed)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("invalid duration '%s' (expected e.g. 12h, 30d, or 2w)", value)
+	}
+
+	*duration = DurationFlag(parsed)
+
+	return nil
+}
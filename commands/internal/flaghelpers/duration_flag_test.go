@@ -0,0 +1,41 @@
+package flaghelpers_test
+
+import (
+	"time"
+
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DurationFlag", func() {
+	var duration DurationFlag
+
+	BeforeEach(func() {
+		duration = 0
+	})
+
+	It("parses Go durations", func() {
+		Expect(duration.UnmarshalFlag("1h30m")).To(Succeed())
+		Expect(time.Duration(duration)).To(Equal(90 * time.Minute))
+	})
+
+	It("parses days and weeks", func() {
+		Expect(duration.UnmarshalFlag("30d")).To(Succeed())
+		Expect(time.Duration(duration)).To(Equal(30 * 24 * time.Hour))
+
+		Expect(duration.UnmarshalFlag("2w")).To(Succeed())
+		Expect(time.Duration(duration)).To(Equal(14 * 24 * time.Hour))
+
+		Expect(duration.UnmarshalFlag("1.5d")).To(Succeed())
+		Expect(time.Duration(duration)).To(Equal(36 * time.Hour))
+	})
+
+	Context("when the duration is invalid", func() {
+		It("returns an error", func() {
+			Expect(duration.UnmarshalFlag("forever")).To(MatchError("invalid duration 'forever' (expected e.g. 12h, 30d, or 2w)"))
+			Expect(duration.UnmarshalFlag("-3d")).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,25 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvPairFlag is a param given to a task as KEY=VALUE, like an environment
+// variable. The value may be empty.
+type EnvPairFlag struct {
+	Name  string
+	Value string
+}
+
+func (pair *EnvPairFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "=", 2)
+	if len(vs) != 2 || vs[0] == "" {
+		return fmt.Errorf("invalid env '%s' (must be KEY=VALUE)", value)
+	}
+
+	pair.Name = vs[0]
+	pair.Value = vs[1]
+
+	return nil
+}
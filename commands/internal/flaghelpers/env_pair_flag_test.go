@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnvPairFlag", func() {
+	var pair EnvPairFlag
+
+	BeforeEach(func() {
+		pair = EnvPairFlag{}
+	})
+
+	It("splits on the first =", func() {
+		Expect(pair.UnmarshalFlag("URL=http://example.com/?a=b")).To(Succeed())
+		Expect(pair).To(Equal(EnvPairFlag{Name: "URL", Value: "http://example.com/?a=b"}))
+	})
+
+	It("allows an empty value", func() {
+		Expect(pair.UnmarshalFlag("EMPTY=")).To(Succeed())
+		Expect(pair).To(Equal(EnvPairFlag{Name: "EMPTY", Value: ""}))
+	})
+
+	Context("when there's no key", func() {
+		It("returns an error", func() {
+			Expect(pair.UnmarshalFlag("NOVALUE")).To(MatchError("invalid env 'NOVALUE' (must be KEY=VALUE)"))
+			Expect(pair.UnmarshalFlag("=value")).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,31 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var githubCommit = regexp.MustCompile(`^([^/@\s]+)/([^/@\s]+)@([0-9a-fA-F]{7,40})$`)
+
+type GitHubCommitFlag struct {
+	Owner string
+	Repo  string
+	SHA   string
+}
+
+func (commit *GitHubCommitFlag) UnmarshalFlag(value string) error {
+	match := githubCommit.FindStringSubmatch(value)
+	if match == nil {
+		return fmt.Errorf("invalid commit '%s' (must be owner/repo@sha)", value)
+	}
+
+	commit.Owner = match[1]
+	commit.Repo = match[2]
+	commit.SHA = match[3]
+
+	return nil
+}
+
+func (commit GitHubCommitFlag) String() string {
+	return commit.Owner + "/" + commit.Repo + "@" + commit.SHA
+}
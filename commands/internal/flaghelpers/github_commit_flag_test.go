@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GitHubCommitFlag", func() {
+	It("parses the owner, repo and sha", func() {
+		commit := &GitHubCommitFlag{}
+
+		err := commit.UnmarshalFlag("concourse/fly@4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*commit).To(Equal(GitHubCommitFlag{
+			Owner: "concourse",
+			Repo:  "fly",
+			SHA:   "4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+		}))
+	})
+
+	for _, value := range []string{"concourse/fly", "fly@4b825dc", "concourse/fly@main", "concourse/fly/x@4b825dc"} {
+		value := value
+
+		It("rejects "+value, func() {
+			commit := &GitHubCommitFlag{}
+
+			err := commit.UnmarshalFlag(value)
+			Expect(err).To(MatchError("invalid commit '" + value + "' (must be owner/repo@sha)"))
+		})
+	}
+})
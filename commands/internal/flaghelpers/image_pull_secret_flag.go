@@ -0,0 +1,31 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ImagePullSecretFlag struct {
+	Username string
+	Password string
+	Registry string
+}
+
+func (secret *ImagePullSecretFlag) UnmarshalFlag(value string) error {
+	// the password may well have an @ of its own, but the registry won't
+	at := strings.LastIndex(value, "@")
+	if at < 0 {
+		return fmt.Errorf("invalid image pull secret (must be user:pass@registry)")
+	}
+
+	credentials := strings.SplitN(value[:at], ":", 2)
+	if len(credentials) != 2 || credentials[0] == "" || value[at+1:] == "" {
+		return fmt.Errorf("invalid image pull secret (must be user:pass@registry)")
+	}
+
+	secret.Username = credentials[0]
+	secret.Password = credentials[1]
+	secret.Registry = value[at+1:]
+
+	return nil
+}
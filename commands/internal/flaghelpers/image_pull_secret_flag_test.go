@@ -0,0 +1,33 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImagePullSecretFlag", func() {
+	It("parses the username, password and registry", func() {
+		secret := &ImagePullSecretFlag{}
+
+		err := secret.UnmarshalFlag("some-user:p@ss:word@registry.example.com:5000")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*secret).To(Equal(ImagePullSecretFlag{
+			Username: "some-user",
+			Password: "p@ss:word",
+			Registry: "registry.example.com:5000",
+		}))
+	})
+
+	for _, value := range []string{"some-user:pass", "some-user@registry.example.com", ":pass@registry.example.com", "some-user:pass@"} {
+		value := value
+
+		It("rejects "+value, func() {
+			secret := &ImagePullSecretFlag{}
+
+			err := secret.UnmarshalFlag(value)
+			Expect(err).To(MatchError("invalid image pull secret (must be user:pass@registry)"))
+		})
+	}
+})
@@ -2,13 +2,10 @@ package flaghelpers
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/concourse/go-concourse/concourse"
 	"github.com/jessevdk/go-flags"
-
-	"github.com/concourse/fly/rc"
 )
 
 type JobFlag struct {
@@ -38,45 +35,17 @@ func (job *JobFlag) UnmarshalFlag(value string) error {
 }
 
 func (flag *JobFlag) Complete(match string) []flags.Completion {
-	fly := parseFlags()
-
-	target, err := rc.LoadTarget(fly.Target, false)
-	if err != nil {
-		return []flags.Completion{}
-	}
-
-	err = target.Validate()
-	if err != nil {
-		return []flags.Completion{}
-	}
-
-	team := target.Team()
-	comps := []flags.Completion{}
-	vs := strings.SplitN(match, "/", 2)
-
-	if len(vs) == 1 {
-		pipelines, err := team.ListPipelines()
-		if err != nil {
-			return comps
-		}
-
-		for _, pipeline := range pipelines {
-			if strings.HasPrefix(pipeline.Name, vs[0]) {
-				comps = append(comps, flags.Completion{Item: pipeline.Name + "/"})
-			}
-		}
-	} else if len(vs) == 2 {
-		jobs, err := team.ListJobs(vs[0])
+	return completeInPipeline(match, func(team concourse.Team, pipelineName string) ([]string, error) {
+		jobs, err := team.ListJobs(pipelineName)
 		if err != nil {
-			return comps
+			return nil, err
 		}
 
-		for _, job := range jobs {
-			if strings.HasPrefix(job.Name, vs[1]) {
-				comps = append(comps, flags.Completion{Item: fmt.Sprintf("%s/%s", vs[0], job.Name)})
-			}
+		names := make([]string, len(jobs))
+		for i, job := range jobs {
+			names[i] = job.Name
 		}
-	}
 
-	return comps
+		return names, nil
+	})
 }
@@ -0,0 +1,32 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatrixFlag is one dimension of a build matrix, given as NAME=VALUE1,VALUE2.
+type MatrixFlag struct {
+	Name   string
+	Values []string
+}
+
+func (flag *MatrixFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "=", 2)
+	if len(vs) != 2 || vs[0] == "" {
+		return fmt.Errorf("invalid matrix '%s' (must be name=value1,value2,...)", value)
+	}
+
+	flag.Name = vs[0]
+	flag.Values = nil
+
+	for _, v := range strings.Split(vs[1], ",") {
+		if v == "" {
+			return fmt.Errorf("invalid matrix '%s' (values must not be empty)", value)
+		}
+
+		flag.Values = append(flag.Values, v)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatrixFlag", func() {
+	var flag MatrixFlag
+
+	BeforeEach(func() {
+		flag = MatrixFlag{}
+	})
+
+	It("parses a name and comma-separated values", func() {
+		Expect(flag.UnmarshalFlag("GOVERSION=1.21,1.22")).To(Succeed())
+		Expect(flag).To(Equal(MatrixFlag{
+			Name:   "GOVERSION",
+			Values: []string{"1.21", "1.22"},
+		}))
+	})
+
+	It("parses a single value", func() {
+		Expect(flag.UnmarshalFlag("OS=linux")).To(Succeed())
+		Expect(flag.Values).To(Equal([]string{"linux"}))
+	})
+
+	Context("when there is no name", func() {
+		It("returns an error", func() {
+			Expect(flag.UnmarshalFlag("=linux")).To(MatchError("invalid matrix '=linux' (must be name=value1,value2,...)"))
+		})
+	})
+
+	Context("when there are no values", func() {
+		It("returns an error", func() {
+			Expect(flag.UnmarshalFlag("OS")).To(MatchError("invalid matrix 'OS' (must be name=value1,value2,...)"))
+			Expect(flag.UnmarshalFlag("OS=linux,")).To(MatchError("invalid matrix 'OS=linux,' (values must not be empty)"))
+		})
+	})
+})
@@ -5,8 +5,6 @@ import (
 	"strings"
 
 	"github.com/jessevdk/go-flags"
-
-	"github.com/concourse/fly/rc"
 )
 
 type PipelineFlag string
@@ -19,29 +17,10 @@ func (flag *PipelineFlag) Validate() error {
 }
 
 func (flag *PipelineFlag) Complete(match string) []flags.Completion {
-	fly := parseFlags()
-
-	target, err := rc.LoadTarget(fly.Target, false)
-	if err != nil {
-		return []flags.Completion{}
-	}
-
-	err = target.Validate()
-	if err != nil {
+	team, ok := completionTeam()
+	if !ok {
 		return []flags.Completion{}
 	}
 
-	pipelines, err := target.Team().ListPipelines()
-	if err != nil {
-		return []flags.Completion{}
-	}
-
-	comps := []flags.Completion{}
-	for _, pipeline := range pipelines {
-		if strings.HasPrefix(pipeline.Name, match) {
-			comps = append(comps, flags.Completion{Item: pipeline.Name})
-		}
-	}
-
-	return comps
+	return completePipelines(team, match, "")
 }
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/concourse/go-concourse/concourse"
+	"github.com/jessevdk/go-flags"
 )
 
 type ResourceFlag struct {
@@ -32,3 +33,21 @@ func (resource *ResourceFlag) UnmarshalFlag(value string) error {
 
 	return nil
 }
+
+// Complete completes resource names from the pipeline's config, as there's
+// no listing of a pipeline's resources.
+func (flag *ResourceFlag) Complete(match string) []flags.Completion {
+	return completeInPipeline(match, func(team concourse.Team, pipelineName string) ([]string, error) {
+		config, _, _, found, err := team.PipelineConfig(pipelineName)
+		if err != nil || !found {
+			return nil, err
+		}
+
+		names := make([]string, len(config.Resources))
+		for i, resource := range config.Resources {
+			names[i] = resource.Name
+		}
+
+		return names, nil
+	})
+}
@@ -0,0 +1,32 @@
+package flaghelpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StdinInputFlag names an input to read from stdin, as NAME or NAME=FILE,
+// where FILE is what the file in the input is called.
+type StdinInputFlag struct {
+	Name string
+	File string
+}
+
+func (flag *StdinInputFlag) UnmarshalFlag(value string) error {
+	vs := strings.SplitN(value, "=", 2)
+	if vs[0] == "" || (len(vs) == 2 && vs[1] == "") {
+		return fmt.Errorf("invalid stdin input '%s' (must be NAME or NAME=FILE)", value)
+	}
+
+	flag.Name = vs[0]
+
+	if len(vs) == 2 {
+		if strings.ContainsAny(vs[1], `/\`) || vs[1] == "." || vs[1] == ".." {
+			return fmt.Errorf("invalid stdin input '%s' (FILE must be a file name, not a path)", value)
+		}
+
+		flag.File = vs[1]
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package flaghelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/flaghelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StdinInputFlag", func() {
+	var flag StdinInputFlag
+
+	BeforeEach(func() {
+		flag = StdinInputFlag{}
+	})
+
+	It("takes the input's name", func() {
+		Expect(flag.UnmarshalFlag("data")).To(Succeed())
+		Expect(flag).To(Equal(StdinInputFlag{Name: "data"}))
+	})
+
+	It("takes the file's name after an =", func() {
+		Expect(flag.UnmarshalFlag("data=data.json")).To(Succeed())
+		Expect(flag).To(Equal(StdinInputFlag{Name: "data", File: "data.json"}))
+	})
+
+	Context("when the name is missing", func() {
+		It("returns an error", func() {
+			Expect(flag.UnmarshalFlag("=data.json")).To(MatchError("invalid stdin input '=data.json' (must be NAME or NAME=FILE)"))
+			Expect(flag.UnmarshalFlag("data=")).To(HaveOccurred())
+		})
+	})
+
+	Context("when the file is a path", func() {
+		It("returns an error", func() {
+			Expect(flag.UnmarshalFlag("data=sub/data.json")).To(MatchError("invalid stdin input 'data=sub/data.json' (FILE must be a file name, not a path)"))
+			Expect(flag.UnmarshalFlag("data=..")).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package formathelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestFormatHelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Format Helpers Suite")
+}
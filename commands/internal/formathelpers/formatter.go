@@ -0,0 +1,113 @@
+package formathelpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// Formatter prints each item of a listing with a Go template, or as the
+// values at a JSONPath within it, rather than as a table.
+type Formatter struct {
+	template *template.Template
+	path     jsonPath
+}
+
+var funcs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
+
+// New returns a Formatter for the template or JSONPath given, or nil if
+// neither is, in which case the listing is printed as usual.
+func New(format string, path string) (*Formatter, error) {
+	switch {
+	case format != "" && path != "":
+		return nil, errors.New("only one of --format and --jsonpath can be given")
+
+	case format != "":
+		parsed, err := template.New("format").Funcs(funcs).Option("missingkey=error").Parse(format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid format: %s", err)
+		}
+
+		return &Formatter{template: parsed}, nil
+
+	case path != "":
+		parsed, err := parseJSONPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Formatter{path: parsed}, nil
+	}
+
+	return nil, nil
+}
+
+// Print prints each of the items, a slice, on a line of its own.
+func (formatter *Formatter) Print(w io.Writer, items interface{}) error {
+	list := reflect.ValueOf(items)
+	if list.Kind() != reflect.Slice {
+		return formatter.printItem(w, items)
+	}
+
+	for i := 0; i < list.Len(); i++ {
+		err := formatter.printItem(w, list.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (formatter *Formatter) printItem(w io.Writer, item interface{}) error {
+	if formatter.template != nil {
+		line := new(bytes.Buffer)
+
+		err := formatter.template.Execute(line, item)
+		if err != nil {
+			return fmt.Errorf("failed to format: %s", err)
+		}
+
+		_, err = fmt.Fprintln(w, strings.TrimRight(line.String(), "\n"))
+		return err
+	}
+
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		return err
+	}
+
+	var values []string
+	for _, value := range formatter.path.find(decoded) {
+		values = append(values, jsonValueString(value))
+	}
+
+	_, err = fmt.Fprintln(w, strings.Join(values, " "))
+	return err
+}
+
+// jsonValueString prints strings as they are and anything else as json.
+func jsonValueString(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
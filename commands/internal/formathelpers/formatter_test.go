@@ -0,0 +1,111 @@
+package formathelpers_test
+
+import (
+	"bytes"
+
+	"github.com/concourse/fly/commands/internal/formathelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type build struct {
+	ID       int      `json:"id"`
+	Status   string   `json:"status"`
+	Duration string   `json:"duration"`
+	Inputs   []input  `json:"inputs,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type input struct {
+	Name string `json:"name"`
+}
+
+var _ = Describe("Formatter", func() {
+	var (
+		builds []build
+		out    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		builds = []build{
+			{ID: 1, Status: "succeeded", Duration: "1m0s", Inputs: []input{{Name: "repo"}, {Name: "image"}}},
+			{ID: 2, Status: "failed", Duration: "2m0s", Tags: []string{"linux"}},
+		}
+
+		out = new(bytes.Buffer)
+	})
+
+	It("is nil when given neither a format nor a jsonpath", func() {
+		formatter, err := formathelpers.New("", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(formatter).To(BeNil())
+	})
+
+	It("can't be given both", func() {
+		_, err := formathelpers.New("{{.Status}}", "{.status}")
+		Expect(err).To(MatchError("only one of --format and --jsonpath can be given"))
+	})
+
+	Describe("with a template", func() {
+		It("prints each item with it", func() {
+			formatter, err := formathelpers.New("{{.Status}} {{.Duration}}", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatter.Print(out, builds)).To(Succeed())
+			Expect(out.String()).To(Equal("succeeded 1m0s\nfailed 2m0s\n"))
+		})
+
+		It("can print values as json", func() {
+			formatter, err := formathelpers.New("{{.ID}} {{json .Tags}}", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatter.Print(out, builds)).To(Succeed())
+			Expect(out.String()).To(Equal("1 null\n2 [\"linux\"]\n"))
+		})
+
+		It("fails on an invalid template", func() {
+			_, err := formathelpers.New("{{.Status", "")
+			Expect(err).To(MatchError(ContainSubstring("invalid format")))
+		})
+
+		It("fails on fields the items don't have", func() {
+			formatter, err := formathelpers.New("{{.Nope}}", "")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(formatter.Print(out, builds)).To(MatchError(ContainSubstring("failed to format")))
+		})
+	})
+
+	Describe("with a jsonpath", func() {
+		for _, example := range []struct {
+			path   string
+			output string
+		}{
+			{"{.status}", "succeeded\nfailed\n"},
+			{"$.id", "1\n2\n"},
+			{"duration", "1m0s\n2m0s\n"},
+			{"{.inputs[*].name}", "repo image\n\n"},
+			{"{.inputs[-1].name}", "image\n\n"},
+			{"{.tags}", "\n[\"linux\"]\n"},
+		} {
+			example := example
+
+			It("prints "+example.path+" of each item", func() {
+				formatter, err := formathelpers.New("", example.path)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(formatter.Print(out, builds)).To(Succeed())
+				Expect(out.String()).To(Equal(example.output))
+			})
+		}
+
+		It("fails on an invalid path", func() {
+			_, err := formathelpers.New("", "{.inputs[x]}")
+			Expect(err).To(MatchError("invalid jsonpath '{.inputs[x]}': invalid index 'x'"))
+
+			_, err = formathelpers.New("", "{.inputs[0}")
+			Expect(err).To(MatchError("invalid jsonpath '{.inputs[0}': unclosed ["))
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package formathelpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is the subset of JSONPath that picks fields out of a single
+// item: fields by name, elements by index, and every element with [*], as
+// in {.inputs[*].name}.
+type jsonPath []pathStep
+
+type pathStep struct {
+	field string
+	index int
+	all   bool
+	array bool
+}
+
+func parseJSONPath(path string) (jsonPath, error) {
+	expr := strings.TrimSpace(path)
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}")
+	expr = strings.TrimPrefix(expr, "$")
+
+	var steps jsonPath
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+
+			end := strings.IndexAny(expr, ".[")
+			if end < 0 {
+				end = len(expr)
+			}
+
+			if end == 0 {
+				return nil, fmt.Errorf("invalid jsonpath '%s': expected a field name", path)
+			}
+
+			steps = append(steps, pathStep{field: expr[:end]})
+			expr = expr[end:]
+
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid jsonpath '%s': unclosed [", path)
+			}
+
+			subscript := expr[1:end]
+			expr = expr[end+1:]
+
+			if subscript == "*" {
+				steps = append(steps, pathStep{array: true, all: true})
+				continue
+			}
+
+			index, err := strconv.Atoi(subscript)
+			if err != nil {
+				return nil, fmt.Errorf("invalid jsonpath '%s': invalid index '%s'", path, subscript)
+			}
+
+			steps = append(steps, pathStep{array: true, index: index})
+
+		default:
+			// a leading field needn't start with a dot, e.g. status
+			if len(steps) == 0 {
+				expr = "." + expr
+				continue
+			}
+
+			return nil, fmt.Errorf("invalid jsonpath '%s': unexpected '%c'", path, expr[0])
+		}
+	}
+
+	return steps, nil
+}
+
+// find returns the values at the path, of which there may be several
+// because of [*], or none if a field or element isn't there.
+func (path jsonPath) find(value interface{}) []interface{} {
+	values := []interface{}{value}
+
+	for _, step := range path {
+		var next []interface{}
+
+		for _, value := range values {
+			switch {
+			case !step.array:
+				if object, ok := value.(map[string]interface{}); ok {
+					if field, found := object[step.field]; found {
+						next = append(next, field)
+					}
+				}
+
+			case step.all:
+				if array, ok := value.([]interface{}); ok {
+					next = append(next, array...)
+				}
+
+			default:
+				if array, ok := value.([]interface{}); ok {
+					index := step.index
+					if index < 0 {
+						index += len(array)
+					}
+
+					if index >= 0 && index < len(array) {
+						next = append(next, array[index])
+					}
+				}
+			}
+		}
+
+		values = next
+	}
+
+	return values
+}
@@ -0,0 +1,90 @@
+package githubstatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const postTimeout = 10 * time.Second
+
+// GitHub rejects statuses with longer descriptions.
+const maxDescriptionLength = 140
+
+// Reporter posts statuses of a commit to GitHub.
+type Reporter struct {
+	// API is the URL of GitHub's API, e.g. https://api.github.com, or
+	// https://github.example.com/api/v3 for GitHub Enterprise.
+	API   string
+	Token string
+
+	Owner string
+	Repo  string
+	SHA   string
+}
+
+// Status is a commit status, as documented at
+// https://docs.github.com/en/rest/commits/statuses.
+type Status struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Context     string `json:"context"`
+}
+
+// State returns the commit status state for a build's status.
+func State(buildStatus string) string {
+	switch buildStatus {
+	case "pending", "started":
+		return "pending"
+	case "succeeded":
+		return "success"
+	case "failed":
+		return "failure"
+	default:
+		return "error"
+	}
+}
+
+func (reporter Reporter) Post(status Status) error {
+	if len(status.Description) > maxDescriptionLength {
+		status.Description = status.Description[:maxDescriptionLength-3] + "..."
+	}
+
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	statusURL := strings.TrimRight(reporter.API, "/") + "/repos/" + url.PathEscape(reporter.Owner) + "/" + url.PathEscape(reporter.Repo) + "/statuses/" + url.PathEscape(reporter.SHA)
+
+	request, err := http.NewRequest("POST", statusURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/vnd.github+json")
+	request.Header.Set("Authorization", "token "+reporter.Token)
+
+	client := &http.Client{Timeout: postTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("github responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package githubstatus_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestGitHubStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "GitHub Status Suite")
+}
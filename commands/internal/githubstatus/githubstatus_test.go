@@ -0,0 +1,73 @@
+package githubstatus_test
+
+import (
+	"net/http"
+
+	"github.com/concourse/fly/commands/internal/githubstatus"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reporter", func() {
+	var (
+		github   *ghttp.Server
+		reporter githubstatus.Reporter
+	)
+
+	BeforeEach(func() {
+		github = ghttp.NewServer()
+
+		reporter = githubstatus.Reporter{
+			API:   github.URL() + "/api/v3/",
+			Token: "some-token",
+			Owner: "concourse",
+			Repo:  "fly",
+			SHA:   "4b825dc",
+		}
+	})
+
+	AfterEach(func() {
+		github.Close()
+	})
+
+	It("posts the status of the commit", func() {
+		github.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v3/repos/concourse/fly/statuses/4b825dc"),
+				ghttp.VerifyHeaderKV("Authorization", "token some-token"),
+				ghttp.VerifyJSON(`{
+					"state": "pending",
+					"target_url": "https://ci.example.com/builds/42",
+					"description": "build #42 started",
+					"context": "concourse/fly"
+				}`),
+				ghttp.RespondWith(http.StatusCreated, `{}`),
+			),
+		)
+
+		err := reporter.Post(githubstatus.Status{
+			State:       "pending",
+			TargetURL:   "https://ci.example.com/builds/42",
+			Description: "build #42 started",
+			Context:     "concourse/fly",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fails when github does", func() {
+		github.AppendHandlers(ghttp.RespondWith(http.StatusUnauthorized, `{"message": "Bad credentials"}`))
+
+		err := reporter.Post(githubstatus.Status{State: "success", Context: "concourse/fly"})
+		Expect(err).To(MatchError(`github responded with 401 Unauthorized: {"message": "Bad credentials"}`))
+	})
+
+	It("maps build statuses to states", func() {
+		Expect(githubstatus.State("started")).To(Equal("pending"))
+		Expect(githubstatus.State("succeeded")).To(Equal("success"))
+		Expect(githubstatus.State("failed")).To(Equal("failure"))
+		Expect(githubstatus.State("errored")).To(Equal("error"))
+		Expect(githubstatus.State("aborted")).To(Equal("error"))
+	})
+})
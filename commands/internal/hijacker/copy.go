@@ -0,0 +1,98 @@
+package hijacker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/concourse/atc"
+)
+
+// copyInScript extracts a gzipped tarball of one file or directory from
+// stdin to $1: into it if it's a directory, or otherwise as it, by way of a
+// temporary directory beside it so that nothing is half-replaced on failure.
+const copyInScript = `set -e
+dst="$1"
+name="$2"
+if [ -d "$dst" ]; then
+  exec tar -xzf - -C "$dst"
+fi
+parent="$(dirname "$dst")"
+mkdir -p "$parent"
+tmp="$(mktemp -d "$parent/.fly-cp.XXXXXX")"
+trap 'rm -rf "$tmp"' EXIT
+tar -xzf - -C "$tmp"
+rm -rf "$dst"
+mv "$tmp/$name" "$dst"
+`
+
+// Container is where to run processes to copy files in or out of.
+type Container struct {
+	Handle string
+	User   string
+
+	// Dir is what relative paths are relative to.
+	Dir string
+}
+
+// CopyFrom writes a tar stream of the file or directory at src in the
+// container to dst, with its base name as the stream's top-level entry.
+func (h *Hijacker) CopyFrom(container Container, src string, dst io.Writer, stderr io.Writer) error {
+	src = path.Clean(src)
+
+	spec := atc.HijackProcessSpec{
+		Path: "tar",
+		Args: []string{"-cf", "-", "-C", path.Dir(src), path.Base(src)},
+		User: container.User,
+		Dir:  container.Dir,
+	}
+
+	return h.copy(container, spec, ProcessIO{In: &bytes.Buffer{}, Out: dst, Err: stderr})
+}
+
+// CopyTo extracts a gzipped tar stream read from src, whose top-level entry
+// is named name, to dst in the container.
+func (h *Hijacker) CopyTo(container Container, src io.Reader, name string, dst string, stderr io.Writer) error {
+	spec := atc.HijackProcessSpec{
+		Path: "sh",
+		Args: []string{"-c", copyInScript, "sh", path.Clean(dst), name},
+		User: container.User,
+		Dir:  container.Dir,
+	}
+
+	return h.copy(container, spec, ProcessIO{In: src, Out: ioutil.Discard, Err: stderr})
+}
+
+func (h *Hijacker) copy(container Container, spec atc.HijackProcessSpec, pio ProcessIO) error {
+	exitStatus, err := h.Hijack(container.Handle, spec, pio)
+	if err != nil {
+		return err
+	}
+
+	if exitStatus != 0 {
+		return fmt.Errorf("%s exited with status %d in the container", spec.Path, exitStatus)
+	}
+
+	return nil
+}
+
+// ReadFile returns the contents of the file at path in the container.
+func (h *Hijacker) ReadFile(container Container, path string, stderr io.Writer) ([]byte, error) {
+	spec := atc.HijackProcessSpec{
+		Path: "cat",
+		Args: []string{path},
+		User: container.User,
+		Dir:  container.Dir,
+	}
+
+	contents := new(bytes.Buffer)
+
+	err := h.copy(container, spec, ProcessIO{In: &bytes.Buffer{}, Out: contents, Err: stderr})
+	if err != nil {
+		return nil, err
+	}
+
+	return contents.Bytes(), nil
+}
@@ -0,0 +1,47 @@
+package hijacker
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/concourse/atc"
+)
+
+// forwardScript connects its stdin and stdout to port $1 of the container,
+// with whichever of nc, socat or bash the container has.
+const forwardScript = `port="$1"
+if command -v nc >/dev/null 2>&1; then
+  exec nc 127.0.0.1 "$port"
+fi
+if command -v socat >/dev/null 2>&1; then
+  exec socat - "TCP:127.0.0.1:$port"
+fi
+if command -v bash >/dev/null 2>&1; then
+  exec bash -c 'exec 3<>"/dev/tcp/127.0.0.1/$1" && { cat <&3 & cat >&3; wait; }' bash "$port"
+fi
+echo "cannot connect to port $port: the container has none of nc, socat or bash" >&2
+exit 127
+`
+
+// Forward connects conn to port in the container until either side closes
+// the connection.
+func (h *Hijacker) Forward(container Container, port int, conn io.ReadWriter, stderr io.Writer) error {
+	spec := atc.HijackProcessSpec{
+		Path: "sh",
+		Args: []string{"-c", forwardScript, "sh", strconv.Itoa(port)},
+		User: container.User,
+		Dir:  container.Dir,
+	}
+
+	exitStatus, err := h.Hijack(container.Handle, spec, ProcessIO{In: conn, Out: conn, Err: stderr})
+	if err != nil {
+		return err
+	}
+
+	if exitStatus != 0 {
+		return fmt.Errorf("connecting to port %d exited with status %d in the container", port, exitStatus)
+	}
+
+	return nil
+}
@@ -24,7 +24,7 @@ type ProcessIO struct {
 }
 
 type Hijacker struct {
-	tlsConfig        *tls.Config
+	transport        Transport
 	requestGenerator *rata.RequestGenerator
 	token            *rc.TargetToken
 
@@ -33,7 +33,7 @@ type Hijacker struct {
 
 func New(tlsConfig *tls.Config, requestGenerator *rata.RequestGenerator, token *rc.TargetToken) *Hijacker {
 	return &Hijacker{
-		tlsConfig:        tlsConfig,
+		transport:        NewWebsocketTransport(tlsConfig),
 		requestGenerator: requestGenerator,
 		token:            token,
 		interval:         10 * time.Second,
@@ -44,17 +44,17 @@ func (h *Hijacker) SetHeartbeatInterval(interval time.Duration) {
 	h.interval = interval
 }
 
+func (h *Hijacker) SetTransport(transport Transport) {
+	h.transport = transport
+}
+
 func (h *Hijacker) Hijack(handle string, spec atc.HijackProcessSpec, pio ProcessIO) (int, error) {
 	url, header, err := h.hijackRequestParts(handle)
 	if err != nil {
 		return -1, err
 	}
 
-	dialer := websocket.Dialer{
-		TLSClientConfig: h.tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
-	}
-	conn, _, err := dialer.Dial(url, header)
+	conn, err := h.transport.Dial(url, header)
 	if err != nil {
 		return -1, err
 	}
@@ -94,6 +94,11 @@ func (h *Hijacker) hijackRequestParts(handle string) (string, http.Header, error
 		hijackReq.Header.Add("Authorization", h.token.Type+" "+h.token.Value)
 	}
 
+	hijackReq, err := rc.PrepareWebsocketRequest(hijackReq)
+	if err != nil {
+		return "", nil, err
+	}
+
 	wsUrl := hijackReq.URL
 
 	var found bool
@@ -105,7 +110,7 @@ func (h *Hijacker) hijackRequestParts(handle string) (string, http.Header, error
 	return wsUrl.String(), hijackReq.Header, nil
 }
 
-func (h *Hijacker) handleOutput(conn *websocket.Conn, pio ProcessIO) int {
+func (h *Hijacker) handleOutput(conn Conn, pio ProcessIO) int {
 	var exitStatus int
 	for {
 		var output atc.HijackOutput
@@ -132,7 +137,7 @@ func (h *Hijacker) handleOutput(conn *websocket.Conn, pio ProcessIO) int {
 	return exitStatus
 }
 
-func (h *Hijacker) handleInput(conn *websocket.Conn, inputs <-chan atc.HijackInput, finished chan struct{}) {
+func (h *Hijacker) handleInput(conn Conn, inputs <-chan atc.HijackInput, finished chan struct{}) {
 	ticker := time.NewTicker(h.interval)
 	defer ticker.Stop()
 
@@ -0,0 +1,46 @@
+package hijacker
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Conn is a bidirectional connection to a hijacked process.
+type Conn interface {
+	ReadJSON(v interface{}) error
+	WriteJSON(v interface{}) error
+	WriteControl(messageType int, data []byte, deadline time.Time) error
+	Close() error
+}
+
+// Transport dials a connection to a hijacked process.
+type Transport interface {
+	Dial(url string, header http.Header) (Conn, error)
+}
+
+type websocketTransport struct {
+	tlsConfig *tls.Config
+}
+
+// NewWebsocketTransport returns the default transport, which speaks the
+// ATC's websocket hijack protocol.
+func NewWebsocketTransport(tlsConfig *tls.Config) Transport {
+	return websocketTransport{tlsConfig: tlsConfig}
+}
+
+func (transport websocketTransport) Dial(url string, header http.Header) (Conn, error) {
+	dialer := websocket.Dialer{
+		TLSClientConfig: transport.tlsConfig,
+		Proxy:           http.ProxyFromEnvironment,
+	}
+
+	conn, _, err := dialer.Dial(url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
@@ -0,0 +1,210 @@
+// Package httpcache keeps responses to slow listing requests on disk for a
+// short while, so that e.g. a shell prompt can run fly pipelines often
+// without waiting on a distant ATC each time.
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Rule caches the responses to GET requests for paths matching Path for TTL.
+type Rule struct {
+	Path *regexp.Regexp
+	TTL  time.Duration
+}
+
+// Transport serves responses from the cache in Dir while they're fresh, and
+// once they're stale revalidates them with their ETag. Any other request
+// that succeeds empties the cache, as it may have changed what's listed.
+type Transport struct {
+	Base  http.RoundTripper
+	Dir   string
+	Rules []Rule
+
+	// Now is when responses are stored and checked for freshness; it
+	// defaults to time.Now.
+	Now func() time.Time
+}
+
+type entry struct {
+	StoredAt time.Time   `json:"stored_at"`
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+func (transport *Transport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != "GET" && request.Method != "HEAD" {
+		response, err := transport.Base.RoundTrip(request)
+		if err == nil && response.StatusCode < 400 {
+			os.RemoveAll(transport.Dir)
+		}
+
+		return response, err
+	}
+
+	rule, found := transport.rule(request)
+	if !found || request.Method != "GET" || request.Header.Get("Range") != "" {
+		return transport.Base.RoundTrip(request)
+	}
+
+	path := filepath.Join(transport.Dir, key(request)+".json")
+
+	cached, found := load(path)
+	if found && transport.now().Sub(cached.StoredAt) < rule.TTL {
+		return cached.response(request), nil
+	}
+
+	etag := ""
+	if found {
+		etag = cached.Header.Get("ETag")
+	}
+
+	if etag != "" {
+		request = cloneRequest(request)
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := transport.Base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotModified && etag != "" {
+		response.Body.Close()
+
+		cached.StoredAt = transport.now()
+		store(path, cached)
+
+		return cached.response(request), nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return response, nil
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	store(path, entry{
+		StoredAt: transport.now(),
+		Status:   response.StatusCode,
+		Header:   response.Header,
+		Body:     body,
+	})
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return response, nil
+}
+
+func (transport *Transport) rule(request *http.Request) (Rule, bool) {
+	for _, rule := range transport.Rules {
+		if rule.Path.MatchString(request.URL.Path) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+func (transport *Transport) now() time.Time {
+	if transport.Now != nil {
+		return transport.Now()
+	}
+
+	return time.Now()
+}
+
+// key identifies a request by its URL and credentials, so that users or
+// teams sharing a home directory never see each other's responses.
+func key(request *http.Request) string {
+	sum := sha256.Sum256([]byte(request.URL.String() + "\n" + request.Header.Get("Authorization")))
+	return hex.EncodeToString(sum[:])
+}
+
+func load(path string) (entry, bool) {
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return entry{}, false
+	}
+
+	var cached entry
+	err = json.Unmarshal(payload, &cached)
+	if err != nil {
+		return entry{}, false
+	}
+
+	return cached, true
+}
+
+// store writes the entry to path, or doesn't; the cache only ever saves
+// time, so failing to write to it isn't worth failing the command for.
+func store(path string, cached entry) {
+	payload, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".entry")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(payload)
+	tmp.Close()
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (cached entry) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.Status, http.StatusText(cached.Status)),
+		StatusCode:    cached.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        cached.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       request,
+	}
+}
+
+func cloneRequest(request *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *request
+
+	clone.Header = http.Header{}
+	for name, values := range request.Header {
+		clone.Header[name] = values
+	}
+
+	return clone
+}
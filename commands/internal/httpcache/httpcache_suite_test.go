@@ -0,0 +1,13 @@
+package httpcache_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestHTTPCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "HTTPCache Suite")
+}
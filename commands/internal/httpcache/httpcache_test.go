@@ -0,0 +1,189 @@
+package httpcache_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/httpcache"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Transport", func() {
+	var (
+		server    *ghttp.Server
+		cacheDir  string
+		now       time.Time
+		transport *httpcache.Transport
+		client    *http.Client
+	)
+
+	get := func(path string) string {
+		response, err := client.Get(server.URL() + path)
+		Expect(err).NotTo(HaveOccurred())
+
+		defer response.Body.Close()
+
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+
+		return string(body)
+	}
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+
+		var err error
+		cacheDir, err = ioutil.TempDir("", "fly-http-cache")
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		transport = &httpcache.Transport{
+			Base:  http.DefaultTransport,
+			Dir:   cacheDir,
+			Rules: []httpcache.Rule{{Path: regexp.MustCompile(`^/api/v1/workers$`), TTL: 10 * time.Second}},
+			Now:   func() time.Time { return now },
+		}
+
+		client = &http.Client{Transport: transport}
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(cacheDir)
+	})
+
+	Context("when the response is fresh", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(200, "workers"),
+			)
+		})
+
+		It("serves it from the cache without a request", func() {
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+
+			now = now.Add(5 * time.Second)
+
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when the response is stale", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(200, "workers", http.Header{"ETag": {`"v1"`}}),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+					ghttp.RespondWith(304, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("If-None-Match", `"v1"`),
+					ghttp.RespondWith(200, "more workers", http.Header{"ETag": {`"v2"`}}),
+				),
+			)
+		})
+
+		It("revalidates it with its ETag", func() {
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+
+			now = now.Add(time.Minute)
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+
+			now = now.Add(time.Minute)
+			Expect(get("/api/v1/workers")).To(Equal("more workers"))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+		})
+	})
+
+	Context("when the path isn't cached", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(200, "builds"),
+				ghttp.RespondWith(200, "builds"),
+			)
+		})
+
+		It("always makes the request", func() {
+			get("/api/v1/builds")
+			get("/api/v1/builds")
+
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when the credentials differ", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(200, "main's workers"),
+				ghttp.RespondWith(200, "other's workers"),
+			)
+		})
+
+		It("doesn't share responses", func() {
+			Expect(get("/api/v1/workers")).To(Equal("main's workers"))
+
+			request, err := http.NewRequest("GET", server.URL()+"/api/v1/workers", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			request.Header.Set("Authorization", "Bearer other")
+
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := ioutil.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("other's workers"))
+		})
+	})
+
+	Context("when a request changes something", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(200, "workers"),
+				ghttp.RespondWith(200, ""),
+				ghttp.RespondWith(200, "fewer workers"),
+			)
+		})
+
+		It("empties the cache", func() {
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+
+			request, err := http.NewRequest("PUT", server.URL()+"/api/v1/workers/some-worker/prune", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(get("/api/v1/workers")).To(Equal("fewer workers"))
+		})
+	})
+
+	Context("when the request fails", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(500, "oops"),
+				ghttp.RespondWith(200, "workers"),
+			)
+		})
+
+		It("doesn't cache the failure", func() {
+			response, err := client.Get(server.URL() + "/api/v1/workers")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(500))
+			response.Body.Close()
+
+			Expect(get("/api/v1/workers")).To(Equal("workers"))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package logformat
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+// Start makes every line fly writes to stdout and stderr a record in the
+// given format (text, logfmt, or json), so that its output can be shipped
+// to a log aggregator. Lines written to stdout are at the info level, and
+// those to stderr at the error level. Text leaves the output as it is.
+//
+// Any output still on its way is written when fly exits through
+// atexit.Exit.
+func Start(format string) error {
+	switch format {
+	case "text":
+		return nil
+	case "logfmt", "json":
+	default:
+		return fmt.Errorf("unknown log format '%s' (expected text, logfmt, or json)", format)
+	}
+
+	if ui.StructuredOutput {
+		return fmt.Errorf("log format already given")
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	lock := new(sync.Mutex)
+
+	stdout := ui.NewRecordWriter(os.Stdout, format, "info", lock)
+	stderr := ui.NewRecordWriter(os.Stderr, format, "error", lock)
+
+	// everything written to stdout, however it's written, goes through the
+	// pipe and comes out as records
+	os.Stdout = writer
+	color.Output = writer
+	color.NoColor = true
+
+	ui.Stderr = stderr
+	ui.StructuredOutput = true
+
+	drained := make(chan struct{})
+	go func() {
+		io.Copy(stdout, reader)
+		stdout.Flush()
+		close(drained)
+	}()
+
+	atexit.Register(func(int) {
+		writer.Close()
+		<-drained
+
+		stderr.Flush()
+	})
+
+	return nil
+}
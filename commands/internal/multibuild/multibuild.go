@@ -0,0 +1,95 @@
+// Package multibuild follows several builds at once, e.g. those of fly
+// execute --matrix or fly watch of a glob of jobs, prefixing each line of
+// their output with the build it came from, and summarizes how they went.
+package multibuild
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+// Build is how one of the builds went.
+type Build struct {
+	Label    string
+	ID       int
+	ExitCode int
+}
+
+// Follow calls follow for each of the labelled builds at once, with a writer
+// prefixing each line with the build's label, and returns the exit statuses
+// it returned. A build that couldn't be followed exits 255, with the error
+// written to its output.
+func Follow(dst io.Writer, labels []string, follow func(i int, out io.Writer) (int, error)) []int {
+	mux := ui.NewMultiplexer(dst)
+	exitCodes := make([]int, len(labels))
+
+	wg := new(sync.WaitGroup)
+	for i, label := range labels {
+		wg.Add(1)
+		go func(i int, label string, prefixColor *color.Color) {
+			defer wg.Done()
+
+			out := mux.Writer(prefixColor.Sprintf("[%s]", label) + " ")
+			defer out.Flush()
+
+			exitCode, err := follow(i, out)
+			if err != nil {
+				fmt.Fprintf(out, "error: %s\n", err)
+				exitCode = 255
+			}
+
+			exitCodes[i] = exitCode
+		}(i, label, ui.PrefixColors[i%len(ui.PrefixColors)])
+	}
+
+	wg.Wait()
+
+	return exitCodes
+}
+
+// Summarize renders a table of how each build went to dst, returning the
+// exit status of the first build that failed.
+func Summarize(dst io.Writer, builds []Build, printTableHeaders bool) (int, error) {
+	exitCode := 0
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "build", Color: color.New(color.Bold)},
+			{Contents: "id", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+			{Contents: "exit status", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, build := range builds {
+		status := ui.TableCell{Contents: "succeeded", Color: ui.SucceededColor}
+		if build.ExitCode != 0 {
+			status = ui.TableCell{Contents: "failed", Color: ui.FailedColor}
+
+			if exitCode == 0 {
+				exitCode = build.ExitCode
+			}
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: build.Label},
+			{Contents: strconv.Itoa(build.ID)},
+			status,
+			{Contents: strconv.Itoa(build.ExitCode)},
+		})
+	}
+
+	fmt.Fprintln(dst)
+
+	err := table.Render(dst, printTableHeaders)
+	if err != nil {
+		return 0, err
+	}
+
+	return exitCode, nil
+}
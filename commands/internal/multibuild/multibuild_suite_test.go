@@ -0,0 +1,13 @@
+package multibuild_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMultibuild(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Multibuild Suite")
+}
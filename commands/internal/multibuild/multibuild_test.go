@@ -0,0 +1,57 @@
+package multibuild_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/concourse/fly/commands/internal/multibuild"
+	"github.com/fatih/color"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Follow", func() {
+	BeforeEach(func() {
+		color.NoColor = true
+	})
+
+	It("prefixes each build's output with its label, and returns their exit statuses", func() {
+		out := new(bytes.Buffer)
+
+		exitCodes := multibuild.Follow(out, []string{"a", "b", "c"}, func(i int, out io.Writer) (int, error) {
+			if i == 2 {
+				return 0, errors.New("no such build")
+			}
+
+			fmt.Fprintf(out, "line from build %d\n", i)
+			return i, nil
+		})
+
+		Expect(exitCodes).To(Equal([]int{0, 1, 255}))
+		Expect(out.String()).To(ContainSubstring("[a] line from build 0\n"))
+		Expect(out.String()).To(ContainSubstring("[b] line from build 1\n"))
+		Expect(out.String()).To(ContainSubstring("[c] error: no such build\n"))
+	})
+})
+
+var _ = Describe("Summarize", func() {
+	It("lists every build, and returns the exit status of the first that failed", func() {
+		out := new(bytes.Buffer)
+
+		exitCode, err := multibuild.Summarize(out, []multibuild.Build{
+			{Label: "a", ID: 1, ExitCode: 0},
+			{Label: "b", ID: 2, ExitCode: 3},
+			{Label: "c", ID: 3, ExitCode: 1},
+		}, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exitCode).To(Equal(3))
+
+		Expect(out.String()).To(ContainSubstring("build"))
+		Expect(out.String()).To(MatchRegexp(`a\s+1\s+succeeded\s+0`))
+		Expect(out.String()).To(MatchRegexp(`b\s+2\s+failed\s+3`))
+		Expect(out.String()).To(MatchRegexp(`c\s+3\s+failed\s+1`))
+	})
+})
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const notifyTimeout = 10 * time.Second
+
+// Build is a finished build, as given to the payload's template.
+type Build struct {
+	ID         int     `json:"build_id"`
+	Name       string  `json:"build_name"`
+	Team       string  `json:"team,omitempty"`
+	Pipeline   string  `json:"pipeline,omitempty"`
+	Job        string  `json:"job,omitempty"`
+	Status     string  `json:"status"`
+	ExitStatus int     `json:"exit_status"`
+	URL        string  `json:"url"`
+	Duration   float64 `json:"duration_seconds"`
+}
+
+// Notifier posts a payload describing each finished build to a webhook.
+type Notifier struct {
+	url      string
+	template *template.Template
+}
+
+var funcs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+}
+
+var slackTemplate = `{"text": {{ printf "%s #%s %s: <%s|view build>" .Title .Name .Status .URL | json }}}`
+
+// New returns a Notifier posting to the URL in the given format: json, the
+// build's fields as they are, or slack, a message for a Slack incoming
+// webhook. A Go template, given the build, overrides the format.
+func New(url string, format string, payloadTemplate string) (*Notifier, error) {
+	if url == "" {
+		return nil, errors.New("no URL to notify")
+	}
+
+	notifier := &Notifier{url: url}
+
+	if payloadTemplate == "" && format == "slack" {
+		payloadTemplate = slackTemplate
+	}
+
+	if payloadTemplate != "" {
+		parsed, err := template.New("payload").Funcs(funcs).Parse(payloadTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notification template: %s", err)
+		}
+
+		notifier.template = parsed
+	}
+
+	return notifier, nil
+}
+
+// Title is what the build is of, e.g. main/unit, or one-off.
+func (build Build) Title() string {
+	if build.Job == "" {
+		return "one-off"
+	}
+
+	return build.Pipeline + "/" + build.Job
+}
+
+// Notify posts the payload for the build.
+func (notifier *Notifier) Notify(build Build) error {
+	payload := new(bytes.Buffer)
+
+	if notifier.template != nil {
+		err := notifier.template.Execute(payload, build)
+		if err != nil {
+			return fmt.Errorf("failed to render notification: %s", err)
+		}
+	} else {
+		err := json.NewEncoder(payload).Encode(build)
+		if err != nil {
+			return err
+		}
+	}
+
+	request, err := http.NewRequest("POST", notifier.url, payload)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: notifyTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("webhook responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/concourse/fly/commands/internal/notify"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifier", func() {
+	var (
+		webhook *ghttp.Server
+		posted  string
+		build   notify.Build
+	)
+
+	BeforeEach(func() {
+		posted = ""
+		webhook = ghttp.NewServer()
+
+		build = notify.Build{
+			ID:         42,
+			Name:       "7",
+			Team:       "main",
+			Pipeline:   "some-pipeline",
+			Job:        "unit",
+			Status:     "failed",
+			ExitStatus: 1,
+			URL:        "https://ci.example.com/builds/42",
+			Duration:   90,
+		}
+	})
+
+	AfterEach(func() {
+		webhook.Close()
+	})
+
+	recordPost := func(w http.ResponseWriter, r *http.Request) {
+		payload, err := ioutil.ReadAll(r.Body)
+		Expect(err).NotTo(HaveOccurred())
+		posted = string(payload)
+	}
+
+	It("posts the build's fields as json", func() {
+		webhook.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/hook"),
+				ghttp.VerifyHeaderKV("Content-Type", "application/json"),
+				recordPost,
+			),
+		)
+
+		notifier, err := notify.New(webhook.URL()+"/hook", "json", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = notifier.Notify(build)
+		Expect(err).NotTo(HaveOccurred())
+
+		var fields map[string]interface{}
+		Expect(json.Unmarshal([]byte(posted), &fields)).To(Succeed())
+		Expect(fields).To(Equal(map[string]interface{}{
+			"build_id":         42.0,
+			"build_name":       "7",
+			"team":             "main",
+			"pipeline":         "some-pipeline",
+			"job":              "unit",
+			"status":           "failed",
+			"exit_status":      1.0,
+			"url":              "https://ci.example.com/builds/42",
+			"duration_seconds": 90.0,
+		}))
+	})
+
+	It("posts a Slack message", func() {
+		webhook.AppendHandlers(recordPost)
+
+		notifier, err := notify.New(webhook.URL(), "slack", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = notifier.Notify(build)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(posted).To(MatchJSON(`{"text": "some-pipeline/unit #7 failed: <https://ci.example.com/builds/42|view build>"}`))
+	})
+
+	It("renders the template, quoting values with json", func() {
+		webhook.AppendHandlers(recordPost)
+
+		notifier, err := notify.New(webhook.URL(), "slack", `{"summary": {{ printf "%s \"%s\"" .Title .Status | json }}, "id": {{ .ID }}}`)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = notifier.Notify(build)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(posted).To(MatchJSON(`{"summary": "some-pipeline/unit \"failed\"", "id": 42}`))
+	})
+
+	It("titles one-off builds as such", func() {
+		Expect(notify.Build{ID: 1}.Title()).To(Equal("one-off"))
+	})
+
+	It("fails on an invalid template", func() {
+		_, err := notify.New(webhook.URL(), "json", "{{ .Nope")
+		Expect(err).To(MatchError(ContainSubstring("invalid notification template")))
+	})
+
+	It("fails when the webhook does", func() {
+		webhook.AppendHandlers(ghttp.RespondWith(http.StatusForbidden, "no"))
+
+		notifier, err := notify.New(webhook.URL(), "json", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = notifier.Notify(build)
+		Expect(err).To(MatchError("webhook responded with 403 Forbidden: no"))
+	})
+})
@@ -0,0 +1,95 @@
+package pipelinehelpers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+)
+
+// WriteDot writes the graph of a pipeline's resources and jobs in Graphviz's
+// DOT language. Resources a job gets are drawn as edges into the job, or as
+// edges from the jobs they must have passed through; resources a job puts
+// are drawn as edges out of it. Edges that don't trigger the job are dashed.
+func WriteDot(dst io.Writer, name string, config atc.Config) error {
+	graph := &dotGraph{seen: map[string]bool{}}
+
+	for _, resource := range config.Resources {
+		graph.line("%s [label=%s, shape=ellipse];", resourceNode(resource.Name), strconv.Quote(resource.Name))
+	}
+
+	for _, job := range config.Jobs {
+		graph.line("%s [label=%s, shape=box, style=filled, fillcolor=lightgrey];", jobNode(job.Name), strconv.Quote(job.Name))
+	}
+
+	for _, job := range config.Jobs {
+		for _, step := range JobSteps(job) {
+			switch {
+			case step.Get != "":
+				resource := stepResource(step.Get, step.Resource)
+
+				if len(step.Passed) == 0 {
+					graph.edge(resourceNode(resource), jobNode(job.Name), "", !step.Trigger)
+					continue
+				}
+
+				for _, passed := range step.Passed {
+					graph.edge(jobNode(passed), jobNode(job.Name), resource, !step.Trigger)
+				}
+
+			case step.Put != "":
+				graph.edge(jobNode(job.Name), resourceNode(stepResource(step.Put, step.Resource)), "", false)
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(dst, "digraph %s {\n  rankdir=LR;\n%s}\n", strconv.Quote(name), graph.buf.String())
+	return err
+}
+
+type dotGraph struct {
+	buf  bytes.Buffer
+	seen map[string]bool
+}
+
+func (graph *dotGraph) line(format string, args ...interface{}) {
+	fmt.Fprintf(&graph.buf, "  "+format+"\n", args...)
+}
+
+// edge adds an edge unless the same edge has already been added, e.g. for a
+// resource that a job gets twice.
+func (graph *dotGraph) edge(from string, to string, label string, dashed bool) {
+	attrs := []string{}
+	if label != "" {
+		attrs = append(attrs, "label="+strconv.Quote(label))
+	}
+
+	if dashed {
+		attrs = append(attrs, "style=dashed")
+	}
+
+	edge := from + " -> " + to
+	if len(attrs) > 0 {
+		edge += " [" + strings.Join(attrs, ", ") + "]"
+	}
+
+	edge += ";"
+
+	if graph.seen[edge] {
+		return
+	}
+
+	graph.seen[edge] = true
+	graph.line("%s", edge)
+}
+
+func resourceNode(name string) string {
+	return strconv.Quote("resource:" + name)
+}
+
+func jobNode(name string) string {
+	return strconv.Quote("job:" + name)
+}
@@ -0,0 +1,59 @@
+package pipelinehelpers_test
+
+import (
+	"bytes"
+
+	"github.com/concourse/atc"
+	. "github.com/concourse/fly/commands/internal/pipelinehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteDot", func() {
+	It("writes the resources and jobs of a pipeline as a graph", func() {
+		config := atc.Config{
+			Resources: atc.ResourceConfigs{
+				{Name: "repo"},
+				{Name: "image"},
+			},
+			Jobs: atc.JobConfigs{
+				{
+					Name: "unit",
+					Plan: atc.PlanSequence{
+						{Get: "repo", Trigger: true},
+						{Task: "test"},
+					},
+				},
+				{
+					Name: "deploy",
+					Plan: atc.PlanSequence{
+						{
+							Aggregate: &atc.PlanSequence{
+								{Get: "repo", Passed: []string{"unit"}, Trigger: true},
+								{Get: "base", Resource: "image"},
+							},
+						},
+						{Put: "image"},
+					},
+				},
+			},
+		}
+
+		dot := new(bytes.Buffer)
+		Expect(WriteDot(dot, "main", config)).To(Succeed())
+
+		Expect(dot.String()).To(Equal(`digraph "main" {
+  rankdir=LR;
+  "resource:repo" [label="repo", shape=ellipse];
+  "resource:image" [label="image", shape=ellipse];
+  "job:unit" [label="unit", shape=box, style=filled, fillcolor=lightgrey];
+  "job:deploy" [label="deploy", shape=box, style=filled, fillcolor=lightgrey];
+  "resource:repo" -> "job:unit";
+  "job:unit" -> "job:deploy" [label="repo"];
+  "resource:image" -> "job:deploy" [style=dashed];
+  "job:deploy" -> "resource:image";
+}
+`))
+	})
+})
@@ -0,0 +1,132 @@
+package pipelinehelpers
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc"
+)
+
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (severity Severity) String() string {
+	switch severity {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// LintIssue is something about a pipeline that's valid but likely to be a
+// mistake.
+type LintIssue struct {
+	Severity Severity
+	Message  string
+}
+
+// Lint checks a pipeline config for problems that schema validation doesn't
+// catch, e.g. resources no job uses. Issues are returned most severe first,
+// and otherwise in the order of the config.
+func Lint(config atc.Config) []LintIssue {
+	issues := []LintIssue{}
+
+	jobNames := map[string]bool{}
+	for _, job := range config.Jobs {
+		jobNames[job.Name] = true
+	}
+
+	usedResources := map[string]bool{}
+
+	for _, job := range config.Jobs {
+		triggered := false
+		taskNames := map[string]int{}
+		duplicateTasks := []string{}
+
+		for _, step := range JobSteps(job) {
+			switch {
+			case step.Get != "":
+				resource := stepResource(step.Get, step.Resource)
+				usedResources[resource] = true
+
+				if step.Trigger {
+					triggered = true
+				}
+
+				for _, passed := range step.Passed {
+					if !jobNames[passed] {
+						issues = append(issues, LintIssue{
+							Severity: SeverityError,
+							Message:  fmt.Sprintf("job %s: get %s is passed through job %s, which does not exist", job.Name, step.Get, passed),
+						})
+					}
+				}
+
+				if step.Version != nil && step.Version.Every && len(step.Passed) > 0 {
+					issues = append(issues, LintIssue{
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("job %s: get %s uses version: every with passed constraints, which only considers versions that passed through each of those jobs and can be slow to schedule", job.Name, step.Get),
+					})
+				}
+
+			case step.Put != "":
+				usedResources[stepResource(step.Put, step.Resource)] = true
+
+			case step.Task != "":
+				taskNames[step.Task]++
+				if taskNames[step.Task] == 2 {
+					duplicateTasks = append(duplicateTasks, step.Task)
+				}
+			}
+		}
+
+		for _, name := range duplicateTasks {
+			issues = append(issues, LintIssue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("job %s: task %s appears %d times, so its builds can't tell the steps apart", job.Name, name, taskNames[name]),
+			})
+		}
+
+		if !triggered {
+			issues = append(issues, LintIssue{
+				Severity: SeverityInfo,
+				Message:  fmt.Sprintf("job %s has no trigger: true inputs, so it only runs when triggered by hand", job.Name),
+			})
+		}
+	}
+
+	for _, resource := range config.Resources {
+		if !usedResources[resource.Name] {
+			issues = append(issues, LintIssue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("resource %s is not used by any job", resource.Name),
+			})
+		}
+	}
+
+	sorted := []LintIssue{}
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		for _, issue := range issues {
+			if issue.Severity == severity {
+				sorted = append(sorted, issue)
+			}
+		}
+	}
+
+	return sorted
+}
+
+func stepResource(name string, resource string) string {
+	if resource != "" {
+		return resource
+	}
+
+	return name
+}
@@ -0,0 +1,85 @@
+package pipelinehelpers_test
+
+import (
+	"github.com/concourse/atc"
+	. "github.com/concourse/fly/commands/internal/pipelinehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Lint", func() {
+	var config atc.Config
+
+	BeforeEach(func() {
+		config = atc.Config{
+			Resources: atc.ResourceConfigs{
+				{Name: "repo"},
+				{Name: "image"},
+			},
+			Jobs: atc.JobConfigs{
+				{
+					Name: "unit",
+					Plan: atc.PlanSequence{
+						{Get: "repo", Trigger: true},
+						{Task: "test"},
+					},
+				},
+				{
+					Name: "build",
+					Plan: atc.PlanSequence{
+						{Get: "repo", Passed: []string{"unit"}, Trigger: true},
+						{Put: "image"},
+					},
+				},
+			},
+		}
+	})
+
+	It("finds nothing wrong with a tidy pipeline", func() {
+		Expect(Lint(config)).To(BeEmpty())
+	})
+
+	It("flags passed constraints on jobs that do not exist", func() {
+		config.Jobs[1].Plan[0].Passed = []string{"unti"}
+
+		Expect(Lint(config)).To(Equal([]LintIssue{
+			{Severity: SeverityError, Message: "job build: get repo is passed through job unti, which does not exist"},
+		}))
+	})
+
+	It("flags resources no job uses", func() {
+		config.Resources = append(config.Resources, atc.ResourceConfig{Name: "slack"})
+
+		Expect(Lint(config)).To(Equal([]LintIssue{
+			{Severity: SeverityWarning, Message: "resource slack is not used by any job"},
+		}))
+	})
+
+	It("flags tasks that appear more than once in a job, including in hooks", func() {
+		config.Jobs[0].Plan[1].Ensure = &atc.PlanConfig{Task: "test"}
+
+		Expect(Lint(config)).To(Equal([]LintIssue{
+			{Severity: SeverityWarning, Message: "job unit: task test appears 2 times, so its builds can't tell the steps apart"},
+		}))
+	})
+
+	It("flags version: every with passed constraints", func() {
+		config.Jobs[1].Plan[0].Version = &atc.VersionConfig{Every: true}
+
+		issues := Lint(config)
+		Expect(issues).To(HaveLen(1))
+		Expect(issues[0].Severity).To(Equal(SeverityWarning))
+		Expect(issues[0].Message).To(ContainSubstring("job build: get repo uses version: every with passed constraints"))
+	})
+
+	It("notes jobs without a trigger, after more severe issues", func() {
+		config.Jobs[1].Plan[0].Trigger = false
+		config.Resources = append(config.Resources, atc.ResourceConfig{Name: "slack"})
+
+		Expect(Lint(config)).To(Equal([]LintIssue{
+			{Severity: SeverityWarning, Message: "resource slack is not used by any job"},
+			{Severity: SeverityInfo, Message: "job build has no trigger: true inputs, so it only runs when triggered by hand"},
+		}))
+	})
+})
@@ -0,0 +1,13 @@
+package pipelinehelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPipelinehelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pipelinehelpers Suite")
+}
@@ -0,0 +1,51 @@
+package pipelinehelpers
+
+import "github.com/concourse/atc"
+
+// JobSteps returns every step of a job, in order: those of its plan,
+// including steps nested in do, aggregate and try steps and in their hooks,
+// followed by those of the job's own hooks.
+func JobSteps(job atc.JobConfig) []atc.PlanConfig {
+	steps := Steps(job.Plan)
+
+	for _, hook := range []*atc.PlanConfig{job.Success, job.Failure, job.Ensure} {
+		if hook != nil {
+			steps = append(steps, step(*hook)...)
+		}
+	}
+
+	return steps
+}
+
+// Steps returns every step of a plan, including nested ones.
+func Steps(plan atc.PlanSequence) []atc.PlanConfig {
+	steps := []atc.PlanConfig{}
+	for _, config := range plan {
+		steps = append(steps, step(config)...)
+	}
+
+	return steps
+}
+
+func step(config atc.PlanConfig) []atc.PlanConfig {
+	steps := []atc.PlanConfig{}
+
+	switch {
+	case config.Do != nil:
+		steps = append(steps, Steps(*config.Do)...)
+	case config.Aggregate != nil:
+		steps = append(steps, Steps(*config.Aggregate)...)
+	case config.Try != nil:
+		steps = append(steps, step(*config.Try)...)
+	default:
+		steps = append(steps, config)
+	}
+
+	for _, hook := range []*atc.PlanConfig{config.Success, config.Failure, config.Ensure} {
+		if hook != nil {
+			steps = append(steps, step(*hook)...)
+		}
+	}
+
+	return steps
+}
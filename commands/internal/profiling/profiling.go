@@ -0,0 +1,102 @@
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/ui"
+)
+
+var (
+	activeLock sync.Mutex
+	active     *profile
+)
+
+type profile struct {
+	kind string
+	file *os.File
+}
+
+// Start begins collecting a profile of the given kind (cpu, mem, or trace)
+// for the rest of the run, written to a file in the working directory when
+// fly exits through atexit.Exit or Stop is called.
+func Start(kind string) error {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	if active != nil {
+		return fmt.Errorf("already collecting a %s profile", active.kind)
+	}
+
+	var fileName string
+	switch kind {
+	case "cpu":
+		fileName = "fly.cpu.pprof"
+	case "mem":
+		fileName = "fly.mem.pprof"
+	case "trace":
+		fileName = "fly.trace"
+	default:
+		return fmt.Errorf("unknown profile '%s' (expected cpu, mem, or trace)", kind)
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case "cpu":
+		err = pprof.StartCPUProfile(file)
+	case "mem":
+		runtime.MemProfileRate = 4096
+	case "trace":
+		err = trace.Start(file)
+	}
+
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	active = &profile{kind: kind, file: file}
+
+	atexit.Register(func(int) { Stop() })
+
+	return nil
+}
+
+// Stop finishes the profile started by Start, if any.
+func Stop() {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+
+	if active == nil {
+		return
+	}
+
+	var err error
+	switch active.kind {
+	case "cpu":
+		pprof.StopCPUProfile()
+	case "mem":
+		runtime.GC()
+		err = pprof.WriteHeapProfile(active.file)
+	case "trace":
+		trace.Stop()
+	}
+
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to write %s profile: %s\n", active.kind, err)
+	} else {
+		fmt.Fprintf(ui.Stderr, "wrote %s profile to %s\n", active.kind, active.file.Name())
+	}
+
+	active.file.Close()
+	active = nil
+}
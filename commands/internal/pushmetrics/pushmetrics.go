@@ -0,0 +1,195 @@
+package pushmetrics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const pushTimeout = 10 * time.Second
+
+// Build is how a build went, as pushed to the Pushgateway.
+type Build struct {
+	// Name tells the builds of one push apart, e.g. by their --matrix
+	// entries. It may be empty if there's only one.
+	Name string
+
+	ID         int
+	Status     string
+	ExitStatus int
+	Duration   time.Duration
+}
+
+// Push replaces the metrics grouped under the given pipeline and job in the
+// Pushgateway with those of the builds and the bytes uploaded for them.
+// One-off builds have no pipeline or job.
+func Push(gateway string, pipeline string, job string, builds []Build, uploadedBytes int64) error {
+	if job == "" {
+		job = "one-off"
+	}
+
+	payload := new(bytes.Buffer)
+	writeMetrics(payload, builds, uploadedBytes)
+
+	pushURL := strings.TrimRight(gateway, "/") + "/metrics/job/" + groupingValue("job", job) + "/pipeline/" + groupingValue("pipeline", pipeline)
+
+	request, err := http.NewRequest("PUT", pushURL, payload)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: pushTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("pushgateway responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// groupingValue encodes the value of a label of the grouping key as a path
+// segment, in base64 if it has slashes or is empty, which can't be given
+// as they are.
+func groupingValue(label string, value string) string {
+	if value == "" || strings.Contains(value, "/") {
+		encoded := base64.URLEncoding.EncodeToString([]byte(value))
+		if encoded == "" {
+			encoded = "="
+		}
+
+		return label + "@base64/" + encoded
+	}
+
+	return url.PathEscape(value)
+}
+
+type sample struct {
+	labels map[string]string
+	value  string
+}
+
+func writeMetrics(w io.Writer, builds []Build, uploadedBytes int64) {
+	var durations, exitStatuses, statuses, ids []sample
+
+	for _, build := range builds {
+		labels := map[string]string{}
+		if build.Name != "" {
+			labels["build"] = build.Name
+		}
+
+		durations = append(durations, sample{labels, strconv.FormatFloat(build.Duration.Seconds(), 'f', -1, 64)})
+		exitStatuses = append(exitStatuses, sample{labels, strconv.Itoa(build.ExitStatus)})
+		ids = append(ids, sample{labels, strconv.Itoa(build.ID)})
+
+		statusLabels := map[string]string{"status": build.Status}
+		for k, v := range labels {
+			statusLabels[k] = v
+		}
+
+		statuses = append(statuses, sample{statusLabels, "1"})
+	}
+
+	writeMetric(w, "fly_build_duration_seconds", "How long the build took, as watched by fly.", durations)
+	writeMetric(w, "fly_build_exit_status", "The exit status fly exited with for the build.", exitStatuses)
+	writeMetric(w, "fly_build_status", "The status the build finished with, as its status label.", statuses)
+	writeMetric(w, "fly_build_id", "The ID of the build.", ids)
+	writeMetric(w, "fly_upload_bytes", "The number of bytes of inputs uploaded for the builds.", []sample{
+		{map[string]string{}, strconv.FormatInt(uploadedBytes, 10)},
+	})
+}
+
+func writeMetric(w io.Writer, name string, help string, samples []sample) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+
+	for _, sample := range samples {
+		fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(sample.labels), sample.value)
+	}
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := []string{}
+	for name := range labels {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	pairs := []string{}
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+labelValueEscaper.Replace(labels[name])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// UploadCounter counts the bytes of the request bodies sent through it.
+type UploadCounter struct {
+	transport http.RoundTripper
+	bytes     int64
+}
+
+// CountUploads returns a copy of the client whose uploads are counted.
+func CountUploads(client *http.Client) (*http.Client, *UploadCounter) {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	counter := &UploadCounter{transport: transport}
+
+	counted := *client
+	counted.Transport = counter
+
+	return &counted, counter
+}
+
+func (counter *UploadCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		counted := request.WithContext(request.Context())
+		counted.Body = &countedBody{ReadCloser: request.Body, bytes: &counter.bytes}
+		request = counted
+	}
+
+	return counter.transport.RoundTrip(request)
+}
+
+func (counter *UploadCounter) Bytes() int64 {
+	return atomic.LoadInt64(&counter.bytes)
+}
+
+type countedBody struct {
+	io.ReadCloser
+	bytes *int64
+}
+
+func (body *countedBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	atomic.AddInt64(body.bytes, int64(n))
+	return n, err
+}
@@ -0,0 +1,13 @@
+package pushmetrics_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestPushMetrics(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Push Metrics Suite")
+}
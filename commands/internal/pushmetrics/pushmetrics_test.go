@@ -0,0 +1,100 @@
+package pushmetrics_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/pushmetrics"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Push", func() {
+	var (
+		gateway *ghttp.Server
+		pushed  string
+	)
+
+	BeforeEach(func() {
+		pushed = ""
+		gateway = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		gateway.Close()
+	})
+
+	recordPush := func(w http.ResponseWriter, r *http.Request) {
+		payload, err := ioutil.ReadAll(r.Body)
+		Expect(err).NotTo(HaveOccurred())
+		pushed = string(payload)
+	}
+
+	It("replaces the metrics grouped under the pipeline and job", func() {
+		gateway.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/metrics/job/unit/pipeline/main"),
+				recordPush,
+			),
+		)
+
+		err := pushmetrics.Push(gateway.URL(), "main", "unit", []pushmetrics.Build{
+			{ID: 42, Status: "failed", ExitStatus: 3, Duration: 90 * time.Second},
+		}, 2048)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pushed).To(ContainSubstring("# TYPE fly_build_duration_seconds gauge\nfly_build_duration_seconds 90\n"))
+		Expect(pushed).To(ContainSubstring("fly_build_exit_status 3\n"))
+		Expect(pushed).To(ContainSubstring(`fly_build_status{status="failed"} 1` + "\n"))
+		Expect(pushed).To(ContainSubstring("fly_build_id 42\n"))
+		Expect(pushed).To(ContainSubstring("fly_upload_bytes 2048\n"))
+	})
+
+	It("labels builds by name and groups one-off builds by themselves", func() {
+		gateway.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("PUT", "/metrics/job/one-off/pipeline@base64/="),
+				recordPush,
+			),
+		)
+
+		err := pushmetrics.Push(gateway.URL(), "", "", []pushmetrics.Build{
+			{Name: `GO="1.8"`, ID: 1, Status: "succeeded"},
+			{Name: "GO=1.9", ID: 2, Status: "errored", ExitStatus: 2},
+		}, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pushed).To(ContainSubstring(`fly_build_status{build="GO=\"1.8\"",status="succeeded"} 1`))
+		Expect(pushed).To(ContainSubstring(`fly_build_status{build="GO=1.9",status="errored"} 1`))
+	})
+
+	It("fails when the gateway rejects the metrics", func() {
+		gateway.AppendHandlers(ghttp.RespondWith(http.StatusBadRequest, "bad metrics"))
+
+		err := pushmetrics.Push(gateway.URL(), "main", "unit", nil, 0)
+		Expect(err).To(MatchError(ContainSubstring("bad metrics")))
+	})
+})
+
+var _ = Describe("CountUploads", func() {
+	It("counts the bytes of the requests sent", func() {
+		server := ghttp.NewServer()
+		defer server.Close()
+
+		server.AppendHandlers(ghttp.RespondWith(http.StatusOK, nil), ghttp.RespondWith(http.StatusOK, nil))
+
+		client, counter := pushmetrics.CountUploads(&http.Client{})
+
+		for _, body := range []string{"hello", "world!"} {
+			response, err := client.Post(server.URL(), "text/plain", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+		}
+
+		Expect(counter.Bytes()).To(Equal(int64(11)))
+	})
+})
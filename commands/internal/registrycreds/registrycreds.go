@@ -0,0 +1,142 @@
+package registrycreds
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/concourse/atc"
+)
+
+// Credential authenticates with a registry to pull images from it.
+type Credential struct {
+	Registry string
+	Username string
+	Password string
+}
+
+const dockerHub = "docker.io"
+
+// LoadDockerConfig reads the credentials of a Docker config file, e.g.
+// ~/.docker/config.json, as written by docker login. Credentials kept by a
+// credential helper aren't in the file, so can't be read.
+func LoadDockerConfig(path string) ([]Credential, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+
+	err = json.Unmarshal(contents, &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker config '%s': %s", path, err)
+	}
+
+	var credentials []Credential
+	for registry, auth := range config.Auths {
+		credential := Credential{
+			Registry: registry,
+			Username: auth.Username,
+			Password: auth.Password,
+		}
+
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid auth for '%s' in docker config '%s': %s", registry, path, err)
+			}
+
+			pair := strings.SplitN(string(decoded), ":", 2)
+			if len(pair) != 2 {
+				return nil, fmt.Errorf("invalid auth for '%s' in docker config '%s': must be user:pass", registry, path)
+			}
+
+			credential.Username, credential.Password = pair[0], pair[1]
+		}
+
+		if credential.Username == "" {
+			continue
+		}
+
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, nil
+}
+
+// Apply gives the task's image resource the credential for the registry its
+// repository is in, if any, returning whether it did. Only docker-image and
+// registry-image resources take credentials this way.
+func Apply(image *atc.ImageResource, credentials []Credential) bool {
+	if image == nil || (image.Type != "docker-image" && image.Type != "registry-image") {
+		return false
+	}
+
+	repository, _ := image.Source["repository"].(string)
+	if repository == "" {
+		return false
+	}
+
+	registry := RegistryOf(repository)
+
+	for _, credential := range credentials {
+		if normalize(credential.Registry) != registry {
+			continue
+		}
+
+		source := atc.Source{}
+		for key, value := range image.Source {
+			source[key] = value
+		}
+
+		source["username"] = credential.Username
+		source["password"] = credential.Password
+
+		image.Source = source
+
+		return true
+	}
+
+	return false
+}
+
+// RegistryOf returns the registry an image repository is in, e.g.
+// registry.example.com for registry.example.com/team/app, or docker.io for
+// ubuntu.
+func RegistryOf(repository string) string {
+	segments := strings.SplitN(repository, "/", 2)
+	if len(segments) == 2 && (strings.ContainsAny(segments[0], ".:") || segments[0] == "localhost") {
+		return normalize(segments[0])
+	}
+
+	return dockerHub
+}
+
+// normalize returns a registry as it's named in a repository, whether it
+// was given as such or as a URL, e.g. https://index.docker.io/v1/.
+func normalize(registry string) string {
+	if strings.Contains(registry, "://") {
+		if parsed, err := url.Parse(registry); err == nil {
+			registry = parsed.Host
+		}
+	}
+
+	registry = strings.ToLower(strings.TrimSuffix(registry, "/"))
+
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return dockerHub
+	}
+
+	return registry
+}
@@ -0,0 +1,13 @@
+package registrycreds_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRegistryCreds(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Registry Creds Suite")
+}
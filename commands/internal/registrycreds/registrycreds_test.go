@@ -0,0 +1,110 @@
+package registrycreds_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/registrycreds"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registry credentials", func() {
+	Describe("LoadDockerConfig", func() {
+		var dir string
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "fly-registry-creds")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("reads the credentials of each registry", func() {
+			path := filepath.Join(dir, "config.json")
+			Expect(ioutil.WriteFile(path, []byte(`{
+				"auths": {
+					"https://index.docker.io/v1/": {"auth": "aHViLXVzZXI6aHViLXBhc3M="},
+					"registry.example.com": {"username": "some-user", "password": "some-pass"},
+					"helped.example.com": {}
+				},
+				"credsStore": "desktop"
+			}`), 0644)).To(Succeed())
+
+			credentials, err := registrycreds.LoadDockerConfig(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(credentials).To(ConsistOf(
+				registrycreds.Credential{Registry: "https://index.docker.io/v1/", Username: "hub-user", Password: "hub-pass"},
+				registrycreds.Credential{Registry: "registry.example.com", Username: "some-user", Password: "some-pass"},
+			))
+		})
+
+		It("fails on a config that isn't json", func() {
+			path := filepath.Join(dir, "config.json")
+			Expect(ioutil.WriteFile(path, []byte(`auths: {}`), 0644)).To(Succeed())
+
+			_, err := registrycreds.LoadDockerConfig(path)
+			Expect(err).To(MatchError(ContainSubstring("invalid docker config '" + path + "'")))
+		})
+	})
+
+	Describe("Apply", func() {
+		credentials := []registrycreds.Credential{
+			{Registry: "https://index.docker.io/v1/", Username: "hub-user", Password: "hub-pass"},
+			{Registry: "registry.example.com:5000", Username: "some-user", Password: "some-pass"},
+		}
+
+		It("gives the image the credential of its registry", func() {
+			image := &atc.ImageResource{
+				Type:   "registry-image",
+				Source: atc.Source{"repository": "registry.example.com:5000/team/app", "tag": "1.2"},
+			}
+
+			Expect(registrycreds.Apply(image, credentials)).To(BeTrue())
+			Expect(image.Source).To(Equal(atc.Source{
+				"repository": "registry.example.com:5000/team/app",
+				"tag":        "1.2",
+				"username":   "some-user",
+				"password":   "some-pass",
+			}))
+		})
+
+		It("treats repositories without a registry as on Docker Hub", func() {
+			image := &atc.ImageResource{
+				Type:   "docker-image",
+				Source: atc.Source{"repository": "concourse/fly"},
+			}
+
+			Expect(registrycreds.Apply(image, credentials)).To(BeTrue())
+			Expect(image.Source["username"]).To(Equal("hub-user"))
+		})
+
+		It("leaves images of other registries and types alone", func() {
+			image := &atc.ImageResource{
+				Type:   "docker-image",
+				Source: atc.Source{"repository": "quay.io/team/app"},
+			}
+
+			Expect(registrycreds.Apply(image, credentials)).To(BeFalse())
+			Expect(image.Source).NotTo(HaveKey("username"))
+
+			Expect(registrycreds.Apply(&atc.ImageResource{Type: "s3", Source: atc.Source{"repository": "ubuntu"}}, credentials)).To(BeFalse())
+			Expect(registrycreds.Apply(nil, credentials)).To(BeFalse())
+		})
+	})
+
+	Describe("RegistryOf", func() {
+		It("finds the registry of a repository", func() {
+			Expect(registrycreds.RegistryOf("ubuntu")).To(Equal("docker.io"))
+			Expect(registrycreds.RegistryOf("concourse/fly")).To(Equal("docker.io"))
+			Expect(registrycreds.RegistryOf("localhost/app")).To(Equal("localhost"))
+			Expect(registrycreds.RegistryOf("Registry.Example.com/team/app")).To(Equal("registry.example.com"))
+		})
+	})
+})
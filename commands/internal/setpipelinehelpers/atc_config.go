@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"regexp"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -12,6 +13,8 @@ import (
 	"github.com/concourse/atc/web"
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/pipelinehelpers"
+	"github.com/concourse/fly/commands/internal/tracing"
 	temp "github.com/concourse/fly/template"
 	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse"
@@ -20,11 +23,21 @@ import (
 	"github.com/vito/go-interact/interact"
 )
 
+var anchorCycleRegex = regexp.MustCompile(`anchor '([^']*)' value contains itself`)
+
 type ATCConfig struct {
 	PipelineName        string
 	Team                concourse.Team
 	WebRequestGenerator *rata.RequestGenerator
 	SkipInteraction     bool
+
+	// DeferredVars are vars left for the credential manager to resolve, and
+	// CheckVars fails rather than warns when a var is missing or unused.
+	DeferredVars []string
+	CheckVars    bool
+
+	// DryRun only shows the changes that would be made.
+	DryRun bool
 }
 
 func (atcConfig ATCConfig) ApplyConfigInteraction() bool {
@@ -78,8 +91,105 @@ func (atcConfig ATCConfig) Validate(
 	return nil
 }
 
+func (atcConfig ATCConfig) Lint(
+	configPath atc.PathFlag,
+	templateVariables []flaghelpers.VariablePairFlag,
+	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
+	templateVariablesFiles []atc.PathFlag,
+	strict bool,
+) error {
+	newConfig := atcConfig.newConfig(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables, true)
+
+	var new atc.Config
+	if err := yaml.Unmarshal([]byte(newConfig), &new); err != nil {
+		return err
+	}
+
+	issues := pipelinehelpers.Lint(new)
+
+	counts := map[pipelinehelpers.Severity]int{}
+	for _, issue := range issues {
+		counts[issue.Severity]++
+
+		severityColor := ui.PendingColor
+		switch issue.Severity {
+		case pipelinehelpers.SeverityError:
+			severityColor = ui.FailedColor
+		case pipelinehelpers.SeverityWarning:
+			severityColor = ui.StartedColor
+		}
+
+		fmt.Printf("%s: %s\n", severityColor.Sprint(issue.Severity), issue.Message)
+	}
+
+	if counts[pipelinehelpers.SeverityError] > 0 || (strict && counts[pipelinehelpers.SeverityWarning] > 0) {
+		fmt.Println("")
+		displayhelpers.Failf("%d errors, %d warnings", counts[pipelinehelpers.SeverityError], counts[pipelinehelpers.SeverityWarning])
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("looks good")
+	}
+
+	return nil
+}
+
+// Render returns a config with its vars interpolated and its YAML anchors
+// resolved, as it would be sent to the ATC. Vars that aren't given are left
+// as they are for the credential manager, and warned about.
+func (atcConfig ATCConfig) Render(
+	configPath atc.PathFlag,
+	templateVariables []flaghelpers.VariablePairFlag,
+	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
+	templateVariablesFiles []atc.PathFlag,
+) []byte {
+	newConfig := atcConfig.newConfig(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables, false)
+
+	atcConfig.checkVars(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables)
+
+	return newConfig
+}
+
+// Diff prints the differences between the pipeline as it's configured and a
+// local config, failing if there are any.
+func (atcConfig ATCConfig) Diff(
+	configPath atc.PathFlag,
+	templateVariables []flaghelpers.VariablePairFlag,
+	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
+	templateVariablesFiles []atc.PathFlag,
+) error {
+	newConfig := atcConfig.newConfig(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables, false)
+
+	existingConfig, _, _, found, err := atcConfig.Team.PipelineConfig(atcConfig.PipelineName)
+	if err != nil {
+		return err
+	}
+
+	var new atc.Config
+	err = yaml.Unmarshal([]byte(newConfig), &new)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		diff(atc.Config{}, new)
+		displayhelpers.Failf("\npipeline '%s' does not exist", atcConfig.PipelineName)
+	}
+
+	if diff(existingConfig, new) {
+		displayhelpers.Failf("\npipeline '%s' differs from %s", atcConfig.PipelineName, configPath)
+	}
+
+	fmt.Printf("pipeline '%s' matches %s\n", atcConfig.PipelineName, configPath)
+
+	return nil
+}
+
 func (atcConfig ATCConfig) Set(configPath atc.PathFlag, templateVariables []flaghelpers.VariablePairFlag, yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag, templateVariablesFiles []atc.PathFlag) error {
 	newConfig := atcConfig.newConfig(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables, false)
+
+	atcConfig.checkVars(configPath, templateVariablesFiles, templateVariables, yamlTemplateVariables)
+
 	existingConfig, _, existingConfigVersion, _, err := atcConfig.Team.PipelineConfig(atcConfig.PipelineName)
 	errorMessages := []string{}
 	if err != nil {
@@ -107,11 +217,14 @@ func (atcConfig ATCConfig) Set(configPath atc.PathFlag, templateVariables []flag
 		return nil
 	}
 
+	span := tracing.Start("set pipeline", "fly.pipeline", atcConfig.PipelineName)
 	created, updated, warnings, err := atcConfig.Team.CreateOrUpdatePipelineConfig(
 		atcConfig.PipelineName,
 		existingConfigVersion,
 		newConfig,
 	)
+	span.Fail(err)
+	span.End()
 	if err != nil {
 		return err
 	}
@@ -131,7 +244,10 @@ func (atcConfig ATCConfig) newConfig(
 	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
 	allowEmpty bool,
 ) []byte {
-	evaluatedConfig, err := ioutil.ReadFile(string(configPath))
+	span := tracing.Start("load pipeline config", "fly.config", string(configPath))
+	defer span.End()
+
+	evaluatedConfig, err := readConfig(configPath)
 	if err != nil {
 		displayhelpers.FailWithErrorf("could not read config file", err)
 	}
@@ -161,6 +277,72 @@ func (atcConfig ATCConfig) newConfig(
 	return evaluatedConfig
 }
 
+func (atcConfig ATCConfig) checkVars(
+	configPath atc.PathFlag,
+	templateVariablesFiles []atc.PathFlag,
+	templateVariables []flaghelpers.VariablePairFlag,
+	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
+) {
+	config, err := readConfig(configPath)
+	if err != nil {
+		displayhelpers.FailWithErrorf("could not read config file", err)
+	}
+
+	given := []string{}
+	for _, f := range templateVariables {
+		given = append(given, f.Name)
+	}
+
+	for _, f := range yamlTemplateVariables {
+		given = append(given, f.Name)
+	}
+
+	for _, path := range templateVariablesFiles {
+		payload, err := ioutil.ReadFile(string(path))
+		if err != nil {
+			displayhelpers.FailWithErrorf("could not read template variables file (%s)", err, string(path))
+		}
+
+		var fileVars map[string]interface{}
+		err = yaml.Unmarshal(payload, &fileVars)
+		if err != nil {
+			displayhelpers.FailWithErrorf("could not parse template variables file (%s)", err, string(path))
+		}
+
+		for name := range fileVars {
+			given = append(given, name)
+		}
+	}
+
+	check := CheckVars(config, given, atcConfig.DeferredVars)
+	if check.OK() {
+		return
+	}
+
+	fmt.Fprintln(ui.Stderr, "")
+	displayhelpers.PrintWarningHeader()
+
+	if len(check.Missing) > 0 {
+		fmt.Fprintln(ui.Stderr, "vars not given with -v, -y or -l (the credential manager must provide these):")
+		for _, name := range check.Missing {
+			fmt.Fprintf(ui.Stderr, "  - %s\n", name)
+		}
+	}
+
+	if len(check.Unused) > 0 {
+		fmt.Fprintln(ui.Stderr, "vars given but never used by the pipeline:")
+		for _, name := range check.Unused {
+			fmt.Fprintf(ui.Stderr, "  - %s\n", name)
+		}
+	}
+
+	fmt.Fprintln(ui.Stderr, "")
+
+	if atcConfig.CheckVars {
+		displayhelpers.Failf("vars do not match the pipeline config; use --defer-var for vars the credential manager provides")
+	}
+}
+
 func (atcConfig ATCConfig) resolveTemplates(configPayload []byte, paramPayloads [][]byte, variables []flaghelpers.VariablePairFlag, yamlVariables []flaghelpers.YAMLVariablePairFlag) ([]byte, error) {
 	tpl := template.NewTemplate(configPayload)
 
@@ -186,8 +368,14 @@ func (atcConfig ATCConfig) resolveTemplates(configPayload []byte, paramPayloads
 		vars = append(vars, staticVars)
 	}
 
+	// evaluating parses and re-marshals the whole config, so YAML anchors,
+	// aliases and merge keys are always resolved here rather than by the ATC
 	bytes, err := tpl.Evaluate(template.NewMultiVars(vars), nil, template.EvaluateOpts{})
 	if err != nil {
+		if match := anchorCycleRegex.FindStringSubmatch(err.Error()); match != nil {
+			return nil, fmt.Errorf("YAML anchor '%s' refers to itself, so it can never be resolved", match[1])
+		}
+
 		return nil, err
 	}
 
@@ -275,7 +463,16 @@ func (atcConfig ATCConfig) showHelpfulMessage(created bool, updated bool) {
 	}
 }
 
-func diff(existingConfig atc.Config, newConfig atc.Config) {
+func differs(existingConfig atc.Config, newConfig atc.Config) bool {
+	return len(diffIndices(GroupIndex(existingConfig.Groups), GroupIndex(newConfig.Groups))) > 0 ||
+		len(diffIndices(ResourceIndex(existingConfig.Resources), ResourceIndex(newConfig.Resources))) > 0 ||
+		len(diffIndices(ResourceTypeIndex(existingConfig.ResourceTypes), ResourceTypeIndex(newConfig.ResourceTypes))) > 0 ||
+		len(diffIndices(JobIndex(existingConfig.Jobs), JobIndex(newConfig.Jobs))) > 0
+}
+
+// diff prints the differences between two configs, returning whether there
+// were any.
+func diff(existingConfig atc.Config, newConfig atc.Config) bool {
 	indent := gexec.NewPrefixedWriter("  ", os.Stdout)
 
 	groupDiffs := diffIndices(GroupIndex(existingConfig.Groups), GroupIndex(newConfig.Groups))
@@ -313,4 +510,6 @@ func diff(existingConfig atc.Config, newConfig atc.Config) {
 			diff.Render(indent, "job")
 		}
 	}
+
+	return len(groupDiffs) > 0 || len(resourceDiffs) > 0 || len(resourceTypeDiffs) > 0 || len(jobDiffs) > 0
 }
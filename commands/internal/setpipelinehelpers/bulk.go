@@ -0,0 +1,210 @@
+package setpipelinehelpers
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/tracing"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+const varsFileSuffix = ".vars.yml"
+
+// PipelineFile is a pipeline config found in a directory of them, along with
+// the vars files to interpolate it with.
+type PipelineFile struct {
+	Name      string
+	Config    atc.PathFlag
+	VarsFiles []atc.PathFlag
+}
+
+// DiscoverPipelines finds the pipeline configs in a directory. Each NAME.yml
+// (or NAME.yaml) is the config of the pipeline NAME, interpolated with the
+// given vars files followed by NAME.vars.yml, if there is one.
+func DiscoverPipelines(dir string, varsFiles []atc.PathFlag) ([]PipelineFile, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pipelines := []PipelineFile{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(fileName, varsFileSuffix) {
+			continue
+		}
+
+		ext := filepath.Ext(fileName)
+		if ext != ".yml" && ext != ".yaml" {
+			continue
+		}
+
+		name := strings.TrimSuffix(fileName, ext)
+
+		pipeline := PipelineFile{
+			Name:      name,
+			Config:    atc.PathFlag(filepath.Join(dir, fileName)),
+			VarsFiles: append([]atc.PathFlag{}, varsFiles...),
+		}
+
+		pipelineVars := filepath.Join(dir, name+varsFileSuffix)
+		if _, err := os.Stat(pipelineVars); err == nil {
+			pipeline.VarsFiles = append(pipeline.VarsFiles, atc.PathFlag(pipelineVars))
+		}
+
+		pipelines = append(pipelines, pipeline)
+	}
+
+	return pipelines, nil
+}
+
+type pendingPipeline struct {
+	name   string
+	config []byte
+
+	exists          bool
+	existingConfig  []byte
+	existingVersion string
+}
+
+// SetAll shows the changes to every pipeline at once and, once confirmed,
+// applies them. Every config is rendered before anything is applied, and if
+// applying one fails, the pipelines already applied are put back as they
+// were. It returns whether the pipelines are now as configured, i.e. that
+// this wasn't a dry run and wasn't bailed out of.
+func (atcConfig ATCConfig) SetAll(
+	pipelines []PipelineFile,
+	templateVariables []flaghelpers.VariablePairFlag,
+	yamlTemplateVariables []flaghelpers.YAMLVariablePairFlag,
+) (bool, error) {
+	pending := []pendingPipeline{}
+	unchanged := 0
+
+	for _, pipeline := range pipelines {
+		newConfig := atcConfig.newConfig(pipeline.Config, pipeline.VarsFiles, templateVariables, yamlTemplateVariables, false)
+
+		var new atc.Config
+		err := yaml.Unmarshal(newConfig, &new)
+		if err != nil {
+			return false, fmt.Errorf("invalid config for pipeline '%s': %s", pipeline.Name, err)
+		}
+
+		existingConfig, rawConfig, existingVersion, found, configErr := atcConfig.Team.PipelineConfig(pipeline.Name)
+		if configErr != nil {
+			if _, ok := configErr.(concourse.PipelineConfigError); !ok {
+				return false, configErr
+			}
+		}
+
+		change := pendingPipeline{
+			name:            pipeline.Name,
+			config:          newConfig,
+			exists:          found,
+			existingVersion: existingVersion,
+		}
+
+		if configErr != nil {
+			// the existing config is invalid, so put back exactly what the
+			// ATC gave us if we have to
+			change.existingConfig = []byte(rawConfig)
+		} else {
+			change.existingConfig, err = yaml.Marshal(existingConfig)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		if found && configErr == nil && !differs(existingConfig, new) {
+			unchanged++
+			continue
+		}
+
+		header := "pipeline %s has changed:"
+		if !found {
+			header = "pipeline %s is new:"
+		}
+
+		fmt.Println(color.New(color.Bold).Sprintf(header, pipeline.Name))
+		diff(existingConfig, new)
+		fmt.Println("")
+
+		pending = append(pending, change)
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("no changes to apply")
+		return !atcConfig.DryRun, nil
+	}
+
+	fmt.Printf("%d pipelines to apply, %d unchanged\n", len(pending), unchanged)
+
+	if atcConfig.DryRun {
+		return false, nil
+	}
+
+	if !atcConfig.ApplyConfigInteraction() {
+		fmt.Println("bailing out")
+		return false, nil
+	}
+
+	for i, change := range pending {
+		span := tracing.Start("set pipeline", "fly.pipeline", change.name)
+		_, _, warnings, err := atcConfig.Team.CreateOrUpdatePipelineConfig(change.name, change.existingVersion, change.config)
+		span.Fail(err)
+		span.End()
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to apply pipeline '%s': %s\n", change.name, err)
+			atcConfig.rollBack(pending[:i])
+			displayhelpers.Failf("failed to apply pipelines")
+		}
+
+		if len(warnings) > 0 {
+			atcConfig.showWarnings(warnings)
+		}
+	}
+
+	for _, change := range pending {
+		if change.exists {
+			fmt.Printf("pipeline %s updated\n", change.name)
+		} else {
+			fmt.Printf("pipeline %s created (it is currently paused)\n", change.name)
+		}
+	}
+
+	return true, nil
+}
+
+// rollBack restores pipelines that have already been applied: new ones are
+// destroyed, and others are set back to their previous configs.
+func (atcConfig ATCConfig) rollBack(applied []pendingPipeline) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		change := applied[i]
+
+		var err error
+		if change.exists {
+			var version string
+			_, _, version, _, err = atcConfig.Team.PipelineConfig(change.name)
+			if err == nil {
+				_, _, _, err = atcConfig.Team.CreateOrUpdatePipelineConfig(change.name, version, change.existingConfig)
+			}
+		} else {
+			_, err = atcConfig.Team.DeletePipeline(change.name)
+		}
+
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to roll back pipeline '%s', which must be fixed by hand: %s\n", change.name, err)
+		} else {
+			fmt.Fprintf(ui.Stderr, "rolled back pipeline '%s'\n", change.name)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package setpipelinehelpers_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	. "github.com/concourse/fly/commands/internal/setpipelinehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiscoverPipelines", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "fly-pipelines")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"deploy.yml", "deploy.vars.yml", "main.yaml", "README.md"} {
+			err := ioutil.WriteFile(filepath.Join(dir, name), []byte("---\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		err = os.Mkdir(filepath.Join(dir, "tasks.yml"), 0755)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("finds each pipeline's config and vars", func() {
+		pipelines, err := DiscoverPipelines(dir, []atc.PathFlag{"common.yml"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pipelines).To(Equal([]PipelineFile{
+			{
+				Name:      "deploy",
+				Config:    atc.PathFlag(filepath.Join(dir, "deploy.yml")),
+				VarsFiles: []atc.PathFlag{"common.yml", atc.PathFlag(filepath.Join(dir, "deploy.vars.yml"))},
+			},
+			{
+				Name:      "main",
+				Config:    atc.PathFlag(filepath.Join(dir, "main.yaml")),
+				VarsFiles: []atc.PathFlag{"common.yml"},
+			},
+		}))
+	})
+})
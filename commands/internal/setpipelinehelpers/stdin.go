@@ -0,0 +1,33 @@
+package setpipelinehelpers
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/concourse/atc"
+)
+
+// StdinPath is the config path that reads the config from stdin, e.g. one
+// generated by another tool.
+const StdinPath = "-"
+
+var (
+	readStdin   sync.Once
+	stdinConfig []byte
+	stdinErr    error
+)
+
+// readConfig reads a config file, or stdin given StdinPath. Stdin can only be
+// read once, so its contents are kept for every later read.
+func readConfig(configPath atc.PathFlag) ([]byte, error) {
+	if configPath != StdinPath {
+		return ioutil.ReadFile(string(configPath))
+	}
+
+	readStdin.Do(func() {
+		stdinConfig, stdinErr = ioutil.ReadAll(os.Stdin)
+	})
+
+	return stdinConfig, stdinErr
+}
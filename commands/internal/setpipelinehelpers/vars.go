@@ -0,0 +1,102 @@
+package setpipelinehelpers
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	varRegex    = regexp.MustCompile(`\(\((!?[-/\.\w\pL]+)\)\)`)
+	oldVarRegex = regexp.MustCompile(`\{\{([-\w\p{L}]+)\}\}`)
+)
+
+// VarsCheck is the result of comparing the vars a pipeline config refers to
+// with the vars given when setting it.
+type VarsCheck struct {
+	// Missing are vars the config refers to that were neither given nor
+	// deferred to the credential manager.
+	Missing []string
+
+	// Unused are vars that were given but the config never refers to.
+	Unused []string
+}
+
+func (check VarsCheck) OK() bool {
+	return len(check.Missing) == 0 && len(check.Unused) == 0
+}
+
+// ReferencedVars returns the names of the vars a config refers to, in either
+// the ((var)) or the deprecated {{var}} style. A reference to a field of a
+// var, e.g. ((creds.password)), counts as a reference to the var itself.
+func ReferencedVars(config []byte) []string {
+	names := map[string]bool{}
+
+	for _, match := range varRegex.FindAllSubmatch(config, -1) {
+		name := strings.TrimPrefix(string(match[1]), "!")
+		name = strings.SplitN(name, ".", 2)[0]
+		names[name] = true
+	}
+
+	for _, match := range oldVarRegex.FindAllSubmatch(config, -1) {
+		names[string(match[1])] = true
+	}
+
+	return sortedNames(names)
+}
+
+// CheckVars compares the vars referred to by a config with those given.
+// Deferred vars are left for the credential manager to resolve when the
+// pipeline runs, and may be globs, e.g. aws-*.
+func CheckVars(config []byte, given []string, deferred []string) VarsCheck {
+	referenced := map[string]bool{}
+	for _, name := range ReferencedVars(config) {
+		referenced[name] = true
+	}
+
+	givenNames := map[string]bool{}
+	for _, name := range given {
+		givenNames[name] = true
+	}
+
+	missing := map[string]bool{}
+	for name := range referenced {
+		if !givenNames[name] && !isDeferred(name, deferred) {
+			missing[name] = true
+		}
+	}
+
+	unused := map[string]bool{}
+	for name := range givenNames {
+		if !referenced[name] {
+			unused[name] = true
+		}
+	}
+
+	return VarsCheck{
+		Missing: sortedNames(missing),
+		Unused:  sortedNames(unused),
+	}
+}
+
+func isDeferred(name string, deferred []string) bool {
+	for _, pattern := range deferred {
+		if matched, _ := path.Match(pattern, name); matched || pattern == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+
+	sort.Strings(sorted)
+
+	return sorted
+}
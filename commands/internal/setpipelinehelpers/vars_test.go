@@ -0,0 +1,51 @@
+package setpipelinehelpers_test
+
+import (
+	. "github.com/concourse/fly/commands/internal/setpipelinehelpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Vars", func() {
+	config := []byte(`
+resources:
+- name: repo
+  type: {{repo-type}}
+  source:
+    uri: ((repo-uri))
+    private_key: ((deploy-key.private_key))
+    access_key_id: ((aws-access-key))
+    secret_access_key: ((aws-secret-key))
+    branch: ((!branch))
+`)
+
+	Describe("ReferencedVars", func() {
+		It("returns the vars referred to in either style", func() {
+			Expect(ReferencedVars(config)).To(Equal([]string{
+				"aws-access-key",
+				"aws-secret-key",
+				"branch",
+				"deploy-key",
+				"repo-type",
+				"repo-uri",
+			}))
+		})
+	})
+
+	Describe("CheckVars", func() {
+		It("reports vars that are missing and vars that are never used", func() {
+			check := CheckVars(config, []string{"repo-type", "repo_uri", "deploy-key", "branch"}, []string{"aws-*"})
+
+			Expect(check.OK()).To(BeFalse())
+			Expect(check.Missing).To(Equal([]string{"repo-uri"}))
+			Expect(check.Unused).To(Equal([]string{"repo_uri"}))
+		})
+
+		It("is ok when every var is given or deferred and used", func() {
+			check := CheckVars(config, []string{"repo-type", "repo-uri", "branch"}, []string{"aws-*", "deploy-key"})
+
+			Expect(check.OK()).To(BeTrue())
+		})
+	})
+})
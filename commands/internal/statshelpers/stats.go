@@ -0,0 +1,197 @@
+package statshelpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+// PollInterval is how often the usage of a build's containers is sampled.
+var PollInterval = 5 * time.Second
+
+//go:generate counterfeiter . Hijacker
+
+type Hijacker interface {
+	Hijack(handle string, spec atc.HijackProcessSpec, pio hijacker.ProcessIO) (int, error)
+}
+
+// Usage is a sample of a container's resource usage.
+type Usage struct {
+	// CPU is the CPU time the container has used in total.
+	CPU time.Duration
+
+	Memory uint64
+
+	// MemoryLimit is zero if the container's memory isn't limited.
+	MemoryLimit uint64
+
+	At time.Time
+}
+
+// the ATC has no API for container stats, so they're read from the
+// container's own cgroups, for either cgroup v1 or v2
+const readCgroups = `
+read_if() { [ -r "$2" ] && echo "$1 $(cat "$2")"; }
+read_if memory /sys/fs/cgroup/memory/memory.usage_in_bytes || read_if memory /sys/fs/cgroup/memory.current
+read_if memory_limit /sys/fs/cgroup/memory/memory.limit_in_bytes || read_if memory_limit /sys/fs/cgroup/memory.max
+read_if cpu_ns /sys/fs/cgroup/cpuacct/cpuacct.usage || read_if cpu_ns /sys/fs/cgroup/cpu,cpuacct/cpuacct.usage ||
+  { [ -r /sys/fs/cgroup/cpu.stat ] && echo "cpu_us $(sed -n 's/^usage_usec //p' /sys/fs/cgroup/cpu.stat)"; }
+true
+`
+
+// cgroup v1 reports an unlimited container's memory limit as a number close
+// to the largest int64
+const unlimitedMemory = 1 << 62
+
+// Sample reads the resource usage of a container by running a shell in it,
+// so it only works for containers whose image has one.
+func Sample(h Hijacker, container atc.Container) (Usage, error) {
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	exitStatus, err := h.Hijack(container.ID, atc.HijackProcessSpec{
+		Path: "sh",
+		Args: []string{"-c", readCgroups},
+		User: container.User,
+	}, hijacker.ProcessIO{
+		In:  strings.NewReader(""),
+		Out: stdout,
+		Err: stderr,
+	})
+	if err != nil {
+		return Usage{}, err
+	}
+
+	if exitStatus != 0 {
+		return Usage{}, fmt.Errorf("reading cgroups exited %d: %s", exitStatus, strings.TrimSpace(stderr.String()))
+	}
+
+	usage, err := parseUsage(stdout.String())
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage.At = time.Now()
+
+	return usage, nil
+}
+
+func parseUsage(output string) (Usage, error) {
+	var usage Usage
+
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// cgroup v2 reports an unlimited memory limit as "max"
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "memory":
+			usage.Memory = value
+		case "memory_limit":
+			if value < unlimitedMemory {
+				usage.MemoryLimit = value
+			}
+		case "cpu_ns":
+			usage.CPU = time.Duration(value)
+		case "cpu_us":
+			usage.CPU = time.Duration(value) * time.Microsecond
+		default:
+			continue
+		}
+
+		found = true
+	}
+
+	if !found {
+		return Usage{}, errors.New("no cgroup stats found in the container")
+	}
+
+	return usage, nil
+}
+
+// FormatUsage describes a sample of usage. The CPU is given as a percentage
+// of a core used since the previous sample, if there was one.
+func FormatUsage(previous Usage, current Usage) string {
+	cpu := "--"
+	if !previous.At.IsZero() && current.At.After(previous.At) {
+		used := current.CPU - previous.CPU
+		elapsed := current.At.Sub(previous.At)
+		cpu = fmt.Sprintf("%d%%", int64(used)*100/int64(elapsed))
+	}
+
+	memory := ui.FormatBytes(current.Memory)
+	if current.MemoryLimit != 0 {
+		memory = fmt.Sprintf(
+			"%s / %s (%d%%)",
+			memory,
+			ui.FormatBytes(current.MemoryLimit),
+			current.Memory*100/current.MemoryLimit,
+		)
+	}
+
+	return fmt.Sprintf("cpu %s  memory %s", cpu, memory)
+}
+
+// Poll samples the usage of a build's task containers every PollInterval,
+// writing a line for each to dst, until ctx is done. A container whose usage
+// can't be read is reported once and then skipped.
+func Poll(ctx context.Context, client concourse.Client, h Hijacker, buildID int, dst io.Writer) {
+	faint := color.New(color.Faint).SprintfFunc()
+
+	previous := map[string]Usage{}
+	unavailable := map[string]bool{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(PollInterval):
+		}
+
+		containers, err := client.ListContainers(map[string]string{
+			"build_id": strconv.Itoa(buildID),
+		})
+		if err != nil {
+			continue
+		}
+
+		for _, container := range containers {
+			if container.Type != "task" || unavailable[container.ID] {
+				continue
+			}
+
+			usage, err := Sample(h, container)
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				fmt.Fprintln(dst, faint("[stats] build %d %s: unavailable: %s", buildID, container.StepName, err))
+				unavailable[container.ID] = true
+				continue
+			}
+
+			fmt.Fprintln(dst, faint("[stats] build %d %s: %s", buildID, container.StepName, FormatUsage(previous[container.ID], usage)))
+			previous[container.ID] = usage
+		}
+	}
+}
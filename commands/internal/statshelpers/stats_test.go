@@ -0,0 +1,135 @@
+package statshelpers_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/commands/internal/statshelpers"
+	"github.com/concourse/fly/commands/internal/statshelpers/statshelpersfakes"
+)
+
+var _ = Describe("Stats", func() {
+	Describe("Sample", func() {
+		var (
+			fakeHijacker *statshelpersfakes.FakeHijacker
+			output       string
+		)
+
+		BeforeEach(func() {
+			fakeHijacker = new(statshelpersfakes.FakeHijacker)
+			fakeHijacker.HijackStub = func(handle string, spec atc.HijackProcessSpec, pio hijacker.ProcessIO) (int, error) {
+				pio.Out.Write([]byte(output))
+				return 0, nil
+			}
+		})
+
+		sample := func() (statshelpers.Usage, error) {
+			return statshelpers.Sample(fakeHijacker, atc.Container{ID: "some-handle", User: "some-user"})
+		}
+
+		It("reads the container's cgroups with a shell, as its user", func() {
+			output = "memory 1024\n"
+
+			_, err := sample()
+			Expect(err).NotTo(HaveOccurred())
+
+			handle, spec, _ := fakeHijacker.HijackArgsForCall(0)
+			Expect(handle).To(Equal("some-handle"))
+			Expect(spec.Path).To(Equal("sh"))
+			Expect(spec.User).To(Equal("some-user"))
+		})
+
+		Context("with cgroup v1", func() {
+			BeforeEach(func() {
+				output = "memory 536870912\nmemory_limit 9223372036854771712\ncpu_ns 1500000000\n"
+			})
+
+			It("parses the usage, treating a huge limit as none", func() {
+				usage, err := sample()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(usage.Memory).To(Equal(uint64(536870912)))
+				Expect(usage.MemoryLimit).To(BeZero())
+				Expect(usage.CPU).To(Equal(1500 * time.Millisecond))
+				Expect(usage.At).NotTo(BeZero())
+			})
+		})
+
+		Context("with cgroup v2", func() {
+			BeforeEach(func() {
+				output = "memory 1024\nmemory_limit 2048\ncpu_us 2500\n"
+			})
+
+			It("parses the usage", func() {
+				usage, err := sample()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(usage.MemoryLimit).To(Equal(uint64(2048)))
+				Expect(usage.CPU).To(Equal(2500 * time.Microsecond))
+			})
+		})
+
+		Context("when the container has no cgroup stats", func() {
+			BeforeEach(func() {
+				output = ""
+			})
+
+			It("errors", func() {
+				_, err := sample()
+				Expect(err).To(MatchError("no cgroup stats found in the container"))
+			})
+		})
+
+		Context("when the shell fails", func() {
+			BeforeEach(func() {
+				fakeHijacker.HijackStub = func(handle string, spec atc.HijackProcessSpec, pio hijacker.ProcessIO) (int, error) {
+					pio.Err.Write([]byte("sh: not found\n"))
+					return 127, nil
+				}
+			})
+
+			It("errors with its output", func() {
+				_, err := sample()
+				Expect(err).To(MatchError("reading cgroups exited 127: sh: not found"))
+			})
+		})
+
+		Context("when hijacking fails", func() {
+			BeforeEach(func() {
+				fakeHijacker.HijackStub = nil
+				fakeHijacker.HijackReturns(0, errors.New("nope"))
+			})
+
+			It("errors", func() {
+				_, err := sample()
+				Expect(err).To(MatchError("nope"))
+			})
+		})
+	})
+
+	Describe("FormatUsage", func() {
+		now := time.Now()
+
+		It("gives the CPU used since the previous sample as a percentage of a core", func() {
+			previous := statshelpers.Usage{CPU: time.Second, At: now}
+			current := statshelpers.Usage{CPU: 4 * time.Second, At: now.Add(2 * time.Second), Memory: 512 * 1024 * 1024}
+
+			Expect(statshelpers.FormatUsage(previous, current)).To(Equal("cpu 150%  memory 512.0MiB"))
+		})
+
+		It("omits the CPU for the first sample", func() {
+			current := statshelpers.Usage{CPU: time.Second, At: now, Memory: 100}
+
+			Expect(statshelpers.FormatUsage(statshelpers.Usage{}, current)).To(Equal("cpu --  memory 100B"))
+		})
+
+		It("shows the memory against its limit", func() {
+			current := statshelpers.Usage{At: now, Memory: 1536 * 1024 * 1024, MemoryLimit: 2 * 1024 * 1024 * 1024}
+
+			Expect(statshelpers.FormatUsage(statshelpers.Usage{}, current)).To(Equal("cpu --  memory 1.5GiB / 2.0GiB (75%)"))
+		})
+	})
+})
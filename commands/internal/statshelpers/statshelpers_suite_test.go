@@ -0,0 +1,13 @@
+package statshelpers_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestStatshelpers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Statshelpers Suite")
+}
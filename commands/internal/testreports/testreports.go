@@ -0,0 +1,144 @@
+package testreports
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Summary is how the tests of every collected JUnit report went.
+type Summary struct {
+	Reports int
+	Passed  int
+	Failed  int
+	Skipped int
+
+	// Failures names each test that failed or errored, e.g. as
+	// suite/class/test.
+	Failures []string
+}
+
+// Collect aggregates the JUnit reports matching the patterns. Each pattern
+// is the name of an output followed by a glob within it, e.g.
+// results/reports/*.xml, and outputs are found by name in outputPaths.
+func Collect(outputPaths map[string]string, patterns []string) (Summary, error) {
+	var summary Summary
+
+	for _, pattern := range patterns {
+		paths, err := Glob(outputPaths, pattern)
+		if err != nil {
+			return summary, err
+		}
+
+		if len(paths) == 0 {
+			return summary, fmt.Errorf("no test reports match '%s'", pattern)
+		}
+
+		for _, path := range paths {
+			err := summary.add(path)
+			if err != nil {
+				return summary, fmt.Errorf("failed to parse test report '%s': %s", path, err)
+			}
+		}
+	}
+
+	return summary, nil
+}
+
+// Glob returns the files matching a pattern of an output's name followed by
+// a glob within it.
+func Glob(outputPaths map[string]string, pattern string) ([]string, error) {
+	segments := strings.SplitN(filepath.ToSlash(pattern), "/", 2)
+	if len(segments) != 2 || segments[1] == "" {
+		return nil, fmt.Errorf("invalid test report pattern '%s' (must be output-name/glob)", pattern)
+	}
+
+	outputPath, found := outputPaths[segments[0]]
+	if !found {
+		return nil, fmt.Errorf("test reports '%s' are not in a fetched output", pattern)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(outputPath, filepath.FromSlash(segments[1])))
+	if err != nil {
+		return nil, fmt.Errorf("invalid test report pattern '%s': %s", pattern, err)
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string    `xml:"name,attr"`
+	ClassName string    `xml:"classname,attr"`
+	Failure   *struct{} `xml:"failure"`
+	Error     *struct{} `xml:"error"`
+	Skipped   *struct{} `xml:"skipped"`
+}
+
+func (summary *Summary) add(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	// the root is either a <testsuites> or a lone <testsuite>, both of
+	// which are read the same way
+	var root junitSuite
+	err = xml.NewDecoder(file).Decode(&root)
+	if err != nil {
+		return err
+	}
+
+	summary.Reports++
+	summary.addSuite(nil, root)
+
+	return nil
+}
+
+func (summary *Summary) addSuite(parents []string, suite junitSuite) {
+	names := parents
+	if suite.Name != "" {
+		names = append(append([]string{}, parents...), suite.Name)
+	}
+
+	for _, nested := range suite.Suites {
+		summary.addSuite(names, nested)
+	}
+
+	for _, testCase := range suite.Cases {
+		switch {
+		case testCase.Failure != nil || testCase.Error != nil:
+			summary.Failed++
+
+			name := append(append([]string{}, names...), testCase.ClassName, testCase.Name)
+			summary.Failures = append(summary.Failures, strings.Join(nonEmpty(name), "/"))
+		case testCase.Skipped != nil:
+			summary.Skipped++
+		default:
+			summary.Passed++
+		}
+	}
+}
+
+func nonEmpty(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,13 @@
+package testreports_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestTestReports(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Test Reports Suite")
+}
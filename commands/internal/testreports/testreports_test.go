@@ -0,0 +1,90 @@
+package testreports_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/commands/internal/testreports"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Collect", func() {
+	var (
+		outputDir   string
+		outputPaths map[string]string
+	)
+
+	writeReport := func(name string, contents string) {
+		path := filepath.Join(outputDir, "reports", name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = ioutil.TempDir("", "fly-test-reports")
+		Expect(err).NotTo(HaveOccurred())
+
+		outputPaths = map[string]string{"results": outputDir}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(outputDir)
+	})
+
+	It("aggregates the test cases of every matching report", func() {
+		writeReport("unit.xml", `<?xml version="1.0"?>
+<testsuites>
+  <testsuite name="unit">
+    <testcase classname="parser" name="parses"/>
+    <testcase classname="parser" name="rejects">
+      <failure message="nope"/>
+    </testcase>
+    <testcase classname="parser" name="someday"><skipped/></testcase>
+  </testsuite>
+</testsuites>`)
+
+		writeReport("integration.xml", `<testsuite name="integration">
+  <testcase name="connects"><error message="boom"/></testcase>
+  <testcase name="disconnects"/>
+</testsuite>`)
+
+		writeReport("notes.txt", "not a report")
+
+		summary, err := testreports.Collect(outputPaths, []string{"results/reports/*.xml"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(summary).To(Equal(testreports.Summary{
+			Reports:  2,
+			Passed:   2,
+			Failed:   2,
+			Skipped:  1,
+			Failures: []string{"integration/connects", "unit/parser/rejects"},
+		}))
+	})
+
+	It("fails when a report can't be parsed", func() {
+		writeReport("broken.xml", `<testsuite><testcase`)
+
+		_, err := testreports.Collect(outputPaths, []string{"results/reports/*.xml"})
+		Expect(err).To(MatchError(ContainSubstring("failed to parse test report '" + filepath.Join(outputDir, "reports", "broken.xml") + "'")))
+	})
+
+	It("fails when no reports match", func() {
+		_, err := testreports.Collect(outputPaths, []string{"results/reports/*.xml"})
+		Expect(err).To(MatchError("no test reports match 'results/reports/*.xml'"))
+	})
+
+	It("fails when the pattern isn't in a fetched output", func() {
+		_, err := testreports.Collect(outputPaths, []string{"other/*.xml"})
+		Expect(err).To(MatchError("test reports 'other/*.xml' are not in a fetched output"))
+	})
+
+	It("fails when the pattern has no glob within the output", func() {
+		_, err := testreports.Collect(outputPaths, []string{"results"})
+		Expect(err).To(MatchError("invalid test report pattern 'results' (must be output-name/glob)"))
+	})
+})
@@ -0,0 +1,183 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportTimeout = 5 * time.Second
+
+// Exporter sends spans to a collector over OTLP/HTTP, encoded as json.
+type Exporter struct {
+	// Endpoint is the URL spans are posted to, e.g.
+	// http://localhost:4318/v1/traces.
+	Endpoint string
+
+	// Headers are added to each request, e.g. to authenticate.
+	Headers map[string]string
+
+	// ServiceName is the name of the service the spans are from.
+	ServiceName string
+}
+
+func (exporter Exporter) Export(spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(exporter.request(spans))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", exporter.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range exporter.Headers {
+		request.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: exportTimeout}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("collector responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// ParseHeaders parses headers given as in OTEL_EXPORTER_OTLP_HEADERS, i.e.
+// comma-separated key=value pairs.
+func ParseHeaders(headers string) map[string]string {
+	parsed := map[string]string{}
+
+	for _, pair := range strings.Split(headers, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			continue
+		}
+
+		parsed[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return parsed
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+const (
+	spanKindInternal = 1
+
+	statusOK    = 1
+	statusError = 2
+)
+
+func (exporter Exporter) request(spans []*Span) otlpRequest {
+	serviceName := exporter.ServiceName
+	if serviceName == "" {
+		serviceName = "fly"
+	}
+
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/concourse/fly"}}
+
+	for _, span := range spans {
+		encoded := otlpSpan{
+			TraceID:           span.traceID,
+			SpanID:            span.spanID,
+			ParentSpanID:      span.parentID,
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.end.UnixNano(), 10),
+			Attributes:        attributes(span.attributes),
+			Status:            otlpStatus{Code: statusOK},
+		}
+
+		if span.err != nil {
+			encoded.Status = otlpStatus{Code: statusError, Message: span.err.Error()}
+		}
+
+		scope.Spans = append(scope.Spans, encoded)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource:   otlpResource{Attributes: attributes([]Attribute{{Key: "service.name", Value: serviceName}})},
+				ScopeSpans: []otlpScopeSpans{scope},
+			},
+		},
+	}
+}
+
+func attributes(attrs []Attribute) []otlpAttribute {
+	var encoded []otlpAttribute
+	for _, attr := range attrs {
+		encoded = append(encoded, otlpAttribute{Key: attr.Key, Value: otlpValue{StringValue: attr.Value}})
+	}
+
+	return encoded
+}
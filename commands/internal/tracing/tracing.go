@@ -0,0 +1,184 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	activeLock sync.Mutex
+	active     *Tracer
+)
+
+// Tracer collects the spans of a run of fly, all of them children of a
+// root span covering the whole run.
+type Tracer struct {
+	root *Span
+
+	lock  sync.Mutex
+	spans []*Span
+}
+
+// Span is an operation fly traces, e.g. uploading a build's inputs. A nil
+// Span does nothing, so operations can be traced whether or not tracing is
+// enabled.
+type Span struct {
+	tracer *Tracer
+
+	traceID  string
+	spanID   string
+	parentID string
+
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes []Attribute
+
+	err error
+}
+
+// Attribute is a key and value describing a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Enable begins tracing the run, with a root span of the given name. If
+// traceParent is a W3C traceparent, e.g. from a CI system that traces its
+// jobs, the root span is a child of the span it names.
+func Enable(name string, traceParent string) *Tracer {
+	traceID, parentID := parseTraceParent(traceParent)
+	if traceID == "" {
+		traceID = randomID(16)
+	}
+
+	tracer := &Tracer{}
+	tracer.root = &Span{
+		tracer:   tracer,
+		traceID:  traceID,
+		spanID:   randomID(8),
+		parentID: parentID,
+		name:     name,
+		start:    time.Now(),
+	}
+
+	activeLock.Lock()
+	active = tracer
+	activeLock.Unlock()
+
+	return tracer
+}
+
+// Start begins a span of the given name, with attributes given as pairs of
+// keys and values. It returns nil if tracing isn't enabled.
+func Start(name string, attributes ...string) *Span {
+	activeLock.Lock()
+	tracer := active
+	activeLock.Unlock()
+
+	if tracer == nil {
+		return nil
+	}
+
+	span := &Span{
+		tracer:   tracer,
+		traceID:  tracer.root.traceID,
+		spanID:   randomID(8),
+		parentID: tracer.root.spanID,
+		name:     name,
+		start:    time.Now(),
+	}
+
+	for i := 0; i+1 < len(attributes); i += 2 {
+		span.SetAttribute(attributes[i], attributes[i+1])
+	}
+
+	return span
+}
+
+func (span *Span) SetName(name string) {
+	if span == nil {
+		return
+	}
+
+	span.name = name
+}
+
+func (span *Span) SetAttribute(key string, value string) {
+	if span == nil {
+		return
+	}
+
+	span.attributes = append(span.attributes, Attribute{Key: key, Value: value})
+}
+
+// Fail marks the span as having failed with the given error, if any.
+func (span *Span) Fail(err error) {
+	if span == nil || err == nil {
+		return
+	}
+
+	span.err = err
+}
+
+// End finishes the span. Only spans that have ended are exported.
+func (span *Span) End() {
+	if span == nil {
+		return
+	}
+
+	span.end = time.Now()
+
+	span.tracer.lock.Lock()
+	span.tracer.spans = append(span.tracer.spans, span)
+	span.tracer.lock.Unlock()
+}
+
+// Root is the span covering the whole run.
+func (tracer *Tracer) Root() *Span {
+	return tracer.root
+}
+
+// Finish ends the root span, stops tracing, and returns every span that has
+// ended, the root span last.
+func (tracer *Tracer) Finish() []*Span {
+	tracer.root.End()
+
+	activeLock.Lock()
+	if active == tracer {
+		active = nil
+	}
+	activeLock.Unlock()
+
+	tracer.lock.Lock()
+	defer tracer.lock.Unlock()
+
+	return append([]*Span{}, tracer.spans...)
+}
+
+// parseTraceParent returns the trace and parent span IDs of a W3C
+// traceparent, e.g. 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01,
+// or empty strings if it isn't one.
+func parseTraceParent(traceParent string) (string, string) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+
+	for _, id := range parts[1:3] {
+		if _, err := hex.DecodeString(id); err != nil || strings.Trim(id, "0") == "" {
+			return "", ""
+		}
+	}
+
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2])
+}
+
+func randomID(bytes int) string {
+	id := make([]byte, bytes)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,128 @@
+package tracing_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/concourse/fly/commands/internal/tracing"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type exportedSpan struct {
+	TraceID      string `json:"traceId"`
+	SpanID       string `json:"spanId"`
+	ParentSpanID string `json:"parentSpanId"`
+	Name         string `json:"name"`
+	Attributes   []struct {
+		Key   string `json:"key"`
+		Value struct {
+			StringValue string `json:"stringValue"`
+		} `json:"value"`
+	} `json:"attributes"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type exportRequest struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Spans []exportedSpan `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+var _ = Describe("Tracing", func() {
+	var (
+		collector *ghttp.Server
+		exported  exportRequest
+	)
+
+	BeforeEach(func() {
+		exported = exportRequest{}
+
+		collector = ghttp.NewServer()
+		collector.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/traces"),
+				ghttp.VerifyHeaderKV("Content-Type", "application/json"),
+				ghttp.VerifyHeaderKV("Authorization", "Bearer some-token"),
+				func(w http.ResponseWriter, r *http.Request) {
+					payload, err := ioutil.ReadAll(r.Body)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(json.Unmarshal(payload, &exported)).To(Succeed())
+				},
+			),
+		)
+	})
+
+	AfterEach(func() {
+		collector.Close()
+	})
+
+	export := func(tracer *tracing.Tracer) []exportedSpan {
+		exporter := tracing.Exporter{
+			Endpoint: collector.URL() + "/v1/traces",
+			Headers:  tracing.ParseHeaders("Authorization=Bearer some-token,malformed"),
+		}
+
+		Expect(exporter.Export(tracer.Finish())).To(Succeed())
+		Expect(exported.ResourceSpans).To(HaveLen(1))
+		Expect(exported.ResourceSpans[0].ScopeSpans).To(HaveLen(1))
+
+		return exported.ResourceSpans[0].ScopeSpans[0].Spans
+	}
+
+	It("exports the spans that ended as children of the run's span", func() {
+		tracer := tracing.Enable("fly", "")
+
+		upload := tracing.Start("upload inputs", "fly.build_id", "42")
+		upload.Fail(errors.New("connection reset"))
+		upload.End()
+
+		tracing.Start("never ended")
+
+		spans := export(tracer)
+		Expect(spans).To(HaveLen(2))
+
+		root := spans[1]
+		Expect(root.Name).To(Equal("fly"))
+		Expect(root.TraceID).To(HaveLen(32))
+		Expect(root.ParentSpanID).To(BeEmpty())
+		Expect(root.Status.Code).To(Equal(1))
+
+		Expect(spans[0].Name).To(Equal("upload inputs"))
+		Expect(spans[0].TraceID).To(Equal(root.TraceID))
+		Expect(spans[0].ParentSpanID).To(Equal(root.SpanID))
+		Expect(spans[0].Attributes).To(HaveLen(1))
+		Expect(spans[0].Attributes[0].Key).To(Equal("fly.build_id"))
+		Expect(spans[0].Attributes[0].Value.StringValue).To(Equal("42"))
+		Expect(spans[0].Status.Code).To(Equal(2))
+		Expect(spans[0].Status.Message).To(Equal("connection reset"))
+	})
+
+	It("continues the trace of a traceparent", func() {
+		tracer := tracing.Enable("fly", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+
+		spans := export(tracer)
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].TraceID).To(Equal("0af7651916cd43dd8448eb211c80319c"))
+		Expect(spans[0].ParentSpanID).To(Equal("b7ad6b7169203331"))
+	})
+
+	It("does nothing once tracing has finished", func() {
+		tracing.Enable("fly", "").Finish()
+
+		span := tracing.Start("too late")
+		Expect(span).To(BeNil())
+
+		span.SetAttribute("some", "attribute")
+		span.End()
+	})
+})
@@ -0,0 +1,109 @@
+package varsources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// NewAWSSession returns a session configured from the standard AWS
+// credential chain: the environment, the shared config and credentials
+// files, and instance or task roles.
+func NewAWSSession() (*session.Session, error) {
+	return session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+//go:generate counterfeiter . SecretsManagerAPI
+
+type SecretsManagerAPI interface {
+	GetSecretValue(*secretsmanager.GetSecretValueInput) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManager resolves ((vars)) from AWS Secrets Manager, looking each
+// var up by the secret name Prefix/name. Secrets holding a JSON object
+// resolve to its fields, so that ((name.field)) can pick one out.
+type SecretsManager struct {
+	API    SecretsManagerAPI
+	Prefix string
+}
+
+func NewSecretsManager(api SecretsManagerAPI, prefix string) *SecretsManager {
+	return &SecretsManager{API: api, Prefix: strings.TrimRight(prefix, "/")}
+}
+
+func (manager *SecretsManager) Get(def template.VariableDefinition) (interface{}, bool, error) {
+	output, err := manager.API.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(manager.Prefix + "/" + def.Name),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == secretsmanager.ErrCodeResourceNotFoundException {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to get ((%s)) from secrets manager: %s", def.Name, err)
+	}
+
+	if output.SecretString == nil {
+		return string(output.SecretBinary), true, nil
+	}
+
+	var fields map[string]interface{}
+	if json.Unmarshal([]byte(*output.SecretString), &fields) == nil {
+		return fields, true, nil
+	}
+
+	return *output.SecretString, true, nil
+}
+
+func (manager *SecretsManager) List() ([]template.VariableDefinition, error) {
+	// vars are looked up on demand; there's no need to enumerate them
+	return nil, nil
+}
+
+//go:generate counterfeiter . SSMAPI
+
+type SSMAPI interface {
+	GetParameter(*ssm.GetParameterInput) (*ssm.GetParameterOutput, error)
+}
+
+// ParameterStore resolves ((vars)) from AWS SSM Parameter Store, looking
+// each var up by the parameter name Prefix/name. SecureString parameters
+// are decrypted.
+type ParameterStore struct {
+	API    SSMAPI
+	Prefix string
+}
+
+func NewParameterStore(api SSMAPI, prefix string) *ParameterStore {
+	return &ParameterStore{API: api, Prefix: strings.TrimRight(prefix, "/")}
+}
+
+func (store *ParameterStore) Get(def template.VariableDefinition) (interface{}, bool, error) {
+	output, err := store.API.GetParameter(&ssm.GetParameterInput{
+		Name:           aws.String(store.Prefix + "/" + def.Name),
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		if awsErr, ok := err.(awserr.Error); ok && awsErr.Code() == ssm.ErrCodeParameterNotFound {
+			return nil, false, nil
+		}
+
+		return nil, false, fmt.Errorf("failed to get ((%s)) from parameter store: %s", def.Name, err)
+	}
+
+	return aws.StringValue(output.Parameter.Value), true, nil
+}
+
+func (store *ParameterStore) List() ([]template.VariableDefinition, error) {
+	// vars are looked up on demand; there's no need to enumerate them
+	return nil, nil
+}
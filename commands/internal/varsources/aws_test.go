@@ -0,0 +1,114 @@
+package varsources_test
+
+import (
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/concourse/fly/commands/internal/varsources"
+	"github.com/concourse/fly/commands/internal/varsources/varsourcesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecretsManager", func() {
+	var (
+		api     *varsourcesfakes.FakeSecretsManagerAPI
+		manager *varsources.SecretsManager
+	)
+
+	BeforeEach(func() {
+		api = new(varsourcesfakes.FakeSecretsManagerAPI)
+		manager = varsources.NewSecretsManager(api, "/concourse/main/")
+	})
+
+	It("looks the secret up under the prefix", func() {
+		api.GetSecretValueReturns(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String("hunter2"),
+		}, nil)
+
+		value, found, err := manager.Get(template.VariableDefinition{Name: "password"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("hunter2"))
+
+		Expect(aws.StringValue(api.GetSecretValueArgsForCall(0).SecretId)).To(Equal("/concourse/main/password"))
+	})
+
+	It("resolves secrets holding JSON objects to their fields", func() {
+		api.GetSecretValueReturns(&secretsmanager.GetSecretValueOutput{
+			SecretString: aws.String(`{"username":"admin","password":"hunter2"}`),
+		}, nil)
+
+		value, found, err := manager.Get(template.VariableDefinition{Name: "creds"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(map[string]interface{}{"username": "admin", "password": "hunter2"}))
+	})
+
+	Context("when the secret does not exist", func() {
+		BeforeEach(func() {
+			api.GetSecretValueReturns(nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "nope", nil))
+		})
+
+		It("is not found", func() {
+			_, found, err := manager.Get(template.VariableDefinition{Name: "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("when the lookup fails", func() {
+		BeforeEach(func() {
+			api.GetSecretValueReturns(nil, errors.New("no credentials"))
+		})
+
+		It("returns an error", func() {
+			_, _, err := manager.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).To(MatchError("failed to get ((password)) from secrets manager: no credentials"))
+		})
+	})
+})
+
+var _ = Describe("ParameterStore", func() {
+	var (
+		api   *varsourcesfakes.FakeSSMAPI
+		store *varsources.ParameterStore
+	)
+
+	BeforeEach(func() {
+		api = new(varsourcesfakes.FakeSSMAPI)
+		store = varsources.NewParameterStore(api, "/concourse/main")
+	})
+
+	It("looks the decrypted parameter up under the prefix", func() {
+		api.GetParameterReturns(&ssm.GetParameterOutput{
+			Parameter: &ssm.Parameter{Value: aws.String("hunter2")},
+		}, nil)
+
+		value, found, err := store.Get(template.VariableDefinition{Name: "password"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("hunter2"))
+
+		input := api.GetParameterArgsForCall(0)
+		Expect(aws.StringValue(input.Name)).To(Equal("/concourse/main/password"))
+		Expect(aws.BoolValue(input.WithDecryption)).To(BeTrue())
+	})
+
+	Context("when the parameter does not exist", func() {
+		BeforeEach(func() {
+			api.GetParameterReturns(nil, awserr.New(ssm.ErrCodeParameterNotFound, "nope", nil))
+		})
+
+		It("is not found", func() {
+			_, found, err := store.Get(template.VariableDefinition{Name: "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,283 @@
+package varsources
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// CredHub resolves ((vars)) from CredHub, looking each var up by the name
+// PathPrefix/name. It authenticates against the UAA that CredHub advertises
+// using client credentials, authenticating again once the token expires or
+// CredHub rejects it.
+type CredHub struct {
+	URL          string
+	PathPrefix   string
+	ClientID     string
+	ClientSecret string
+
+	Client *http.Client
+
+	tokenL       sync.Mutex
+	token        string
+	tokenExpires time.Time
+}
+
+// tokens are renewed a little before they expire, so that they don't
+// expire on the way to CredHub
+const tokenExpiryLeeway = 30 * time.Second
+
+// WrappingTransport is a transport that wraps another, as fly wraps
+// http.DefaultTransport to refuse requests with --offline. CredHub's
+// transport is built on http.DefaultTransport by wrapping a copy of the
+// transport it wraps the same way.
+type WrappingTransport interface {
+	http.RoundTripper
+
+	Base() http.RoundTripper
+	WithBase(base http.RoundTripper) http.RoundTripper
+}
+
+func NewCredHub(credhubURL string, pathPrefix string, clientID string, clientSecret string, caCert []byte) (*CredHub, error) {
+	transport := http.DefaultTransport
+
+	if len(caCert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("CredHub CA cert not valid")
+		}
+
+		var err error
+		transport, err = withRootCAs(transport, pool)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &CredHub{
+		URL:          strings.TrimRight(credhubURL, "/"),
+		PathPrefix:   "/" + strings.Trim(pathPrefix, "/"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+
+		Client: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: transport,
+		},
+	}, nil
+}
+
+func withRootCAs(transport http.RoundTripper, pool *x509.CertPool) (http.RoundTripper, error) {
+	switch transport := transport.(type) {
+	case *http.Transport:
+		clone := transport.Clone()
+		if clone.TLSClientConfig == nil {
+			clone.TLSClientConfig = &tls.Config{}
+		}
+
+		clone.TLSClientConfig.RootCAs = pool
+
+		return clone, nil
+
+	case WrappingTransport:
+		base, err := withRootCAs(transport.Base(), pool)
+		if err != nil {
+			return nil, err
+		}
+
+		return transport.WithBase(base), nil
+
+	default:
+		return nil, fmt.Errorf("cannot trust the CredHub CA cert with transport %T", transport)
+	}
+}
+
+type credhubCredentials struct {
+	Data []struct {
+		Value interface{} `json:"value"`
+	} `json:"data"`
+}
+
+func (credhub *CredHub) Get(def template.VariableDefinition) (interface{}, bool, error) {
+	token, err := credhub.accessToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	response, err := credhub.getCurrent(def.Name, token)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if response.StatusCode == http.StatusUnauthorized {
+		// the token may have been revoked, or expired sooner than UAA said
+		response.Body.Close()
+
+		credhub.forgetToken(token)
+
+		token, err = credhub.accessToken()
+		if err != nil {
+			return nil, false, err
+		}
+
+		response, err = credhub.getCurrent(def.Name, token)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("credhub returned %s for ((%s))", response.Status, def.Name)
+	}
+
+	var credentials credhubCredentials
+	err = json.NewDecoder(response.Body).Decode(&credentials)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse credhub response for ((%s)): %s", def.Name, err)
+	}
+
+	if len(credentials.Data) == 0 {
+		return nil, false, nil
+	}
+
+	return credentials.Data[0].Value, true, nil
+}
+
+func (credhub *CredHub) getCurrent(name string, token string) (*http.Response, error) {
+	query := url.Values{
+		"name":    {credhub.PathPrefix + "/" + name},
+		"current": {"true"},
+	}
+
+	request, err := http.NewRequest("GET", credhub.URL+"/api/v1/data?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	response, err := credhub.Client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach credhub: %s", err)
+	}
+
+	return response, nil
+}
+
+func (credhub *CredHub) List() ([]template.VariableDefinition, error) {
+	// vars are looked up on demand; there's no need to enumerate them
+	return nil, nil
+}
+
+func (credhub *CredHub) accessToken() (string, error) {
+	credhub.tokenL.Lock()
+	defer credhub.tokenL.Unlock()
+
+	if credhub.token != "" && (credhub.tokenExpires.IsZero() || time.Now().Before(credhub.tokenExpires)) {
+		return credhub.token, nil
+	}
+
+	authURL, err := credhub.authServerURL()
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+
+	request, err := http.NewRequest("POST", authURL+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.SetBasicAuth(url.QueryEscape(credhub.ClientID), url.QueryEscape(credhub.ClientSecret))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := credhub.Client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach UAA: %s", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("failed to authenticate with UAA: %s: %s", response.Status, body)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse UAA token: %s", err)
+	}
+
+	credhub.token = token.AccessToken
+
+	credhub.tokenExpires = time.Time{}
+	if token.ExpiresIn > 0 {
+		credhub.tokenExpires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryLeeway)
+	}
+
+	return credhub.token, nil
+}
+
+// forgetToken forgets the token CredHub rejected, unless another Get has
+// already replaced it.
+func (credhub *CredHub) forgetToken(token string) {
+	credhub.tokenL.Lock()
+	defer credhub.tokenL.Unlock()
+
+	if credhub.token == token {
+		credhub.token = ""
+	}
+}
+
+func (credhub *CredHub) authServerURL() (string, error) {
+	response, err := credhub.Client.Get(credhub.URL + "/info")
+	if err != nil {
+		return "", fmt.Errorf("failed to reach credhub: %s", err)
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("credhub returned %s for its info", response.Status)
+	}
+
+	var info struct {
+		AuthServer struct {
+			URL string `json:"url"`
+		} `json:"auth-server"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&info)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse credhub info: %s", err)
+	}
+
+	if info.AuthServer.URL == "" {
+		return "", errors.New("credhub did not advertise an auth server")
+	}
+
+	return strings.TrimRight(info.AuthServer.URL, "/"), nil
+}
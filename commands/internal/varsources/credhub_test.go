@@ -0,0 +1,266 @@
+package varsources_test
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/concourse/fly/commands/internal/varsources"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("CredHub", func() {
+	var (
+		credhubServer *ghttp.Server
+		uaaServer     *ghttp.Server
+
+		credhub *varsources.CredHub
+	)
+
+	BeforeEach(func() {
+		credhubServer = ghttp.NewServer()
+		uaaServer = ghttp.NewServer()
+
+		var err error
+		credhub, err = varsources.NewCredHub(credhubServer.URL(), "/concourse/main", "some-client", "some-secret", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		credhubServer.RouteToHandler("GET", "/info", ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+			"auth-server": map[string]string{"url": uaaServer.URL()},
+		}))
+
+		uaaServer.RouteToHandler("POST", "/oauth/token", ghttp.CombineHandlers(
+			ghttp.VerifyBasicAuth("some-client", "some-secret"),
+			ghttp.VerifyFormKV("grant_type", "client_credentials"),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]string{
+				"access_token": "some-token",
+				"token_type":   "bearer",
+			}),
+		))
+	})
+
+	AfterEach(func() {
+		credhubServer.Close()
+		uaaServer.Close()
+	})
+
+	Context("when the credential exists", func() {
+		BeforeEach(func() {
+			credhubServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fconcourse%2Fmain%2Fpassword"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer some-token"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": []map[string]interface{}{
+							{"type": "password", "value": "hunter2"},
+						},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fconcourse%2Fmain%2Fadmin"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer some-token"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": []map[string]interface{}{
+							{"type": "user", "value": map[string]string{"username": "admin", "password": "hunter3"}},
+						},
+					}),
+				),
+			)
+		})
+
+		It("resolves to its current value, authenticating only once", func() {
+			value, found, err := credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+
+			value, found, err = credhub.Get(template.VariableDefinition{Name: "admin"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal(map[string]interface{}{"username": "admin", "password": "hunter3"}))
+
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when the credential does not exist", func() {
+		BeforeEach(func() {
+			credhubServer.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"error":"not found"}`),
+			)
+		})
+
+		It("is not found", func() {
+			_, found, err := credhub.Get(template.VariableDefinition{Name: "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("when UAA rejects the client", func() {
+		BeforeEach(func() {
+			uaaServer.RouteToHandler("POST", "/oauth/token", ghttp.RespondWith(http.StatusUnauthorized, "bad credentials"))
+		})
+
+		It("returns an error", func() {
+			_, _, err := credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).To(MatchError("failed to authenticate with UAA: 401 Unauthorized: bad credentials"))
+		})
+	})
+
+	Context("when the token expires", func() {
+		BeforeEach(func() {
+			uaaServer.RouteToHandler("POST", "/oauth/token", ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"access_token": "some-token",
+				"token_type":   "bearer",
+				"expires_in":   1,
+			}))
+
+			credhubServer.RouteToHandler("GET", "/api/v1/data", ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"data": []map[string]interface{}{
+					{"type": "password", "value": "hunter2"},
+				},
+			}))
+		})
+
+		It("authenticates again", func() {
+			_, _, err := credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+		})
+	})
+
+	Context("when CredHub rejects the token", func() {
+		BeforeEach(func() {
+			tokens := 0
+			uaaServer.RouteToHandler("POST", "/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+				tokens++
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]string{
+					"access_token": fmt.Sprintf("token-%d", tokens),
+					"token_type":   "bearer",
+				})(w, r)
+			})
+
+			credhubServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyHeaderKV("Authorization", "Bearer token-1"),
+					ghttp.RespondWith(http.StatusUnauthorized, `{"error":"invalid_token"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/data", "current=true&name=%2Fconcourse%2Fmain%2Fpassword"),
+					ghttp.VerifyHeaderKV("Authorization", "Bearer token-2"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": []map[string]interface{}{
+							{"type": "password", "value": "hunter2"},
+						},
+					}),
+				),
+			)
+		})
+
+		It("authenticates again and retries", func() {
+			value, found, err := credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+
+			Expect(uaaServer.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		Context("again", func() {
+			BeforeEach(func() {
+				credhubServer.SetHandler(1, ghttp.RespondWith(http.StatusUnauthorized, `{"error":"invalid_token"}`))
+			})
+
+			It("returns an error", func() {
+				_, _, err := credhub.Get(template.VariableDefinition{Name: "password"})
+				Expect(err).To(MatchError("credhub returned 401 Unauthorized for ((password))"))
+			})
+		})
+	})
+
+	Context("when given a CA cert", func() {
+		var (
+			tlsServer *ghttp.Server
+
+			defaultTransport http.RoundTripper
+			wrapped          int
+		)
+
+		BeforeEach(func() {
+			tlsServer = ghttp.NewTLSServer()
+
+			tlsServer.RouteToHandler("GET", "/info", ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"auth-server": map[string]string{"url": uaaServer.URL()},
+			}))
+
+			tlsServer.AppendHandlers(
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"data": []map[string]interface{}{
+						{"type": "password", "value": "hunter2"},
+					},
+				}),
+			)
+
+			defaultTransport = http.DefaultTransport
+
+			wrapped = 0
+			http.DefaultTransport = &countingTransport{base: defaultTransport, count: &wrapped}
+		})
+
+		AfterEach(func() {
+			http.DefaultTransport = defaultTransport
+			tlsServer.Close()
+		})
+
+		It("trusts it, with requests still going through what wraps the default transport", func() {
+			caCert := pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: tlsServer.HTTPTestServer.Certificate().Raw,
+			})
+
+			credhub, err := varsources.NewCredHub(tlsServer.URL(), "/concourse/main", "some-client", "some-secret", caCert)
+			Expect(err).NotTo(HaveOccurred())
+
+			value, found, err := credhub.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+
+			Expect(wrapped).To(Equal(3))
+		})
+	})
+
+	Context("when the CA cert is not valid", func() {
+		It("returns an error", func() {
+			_, err := varsources.NewCredHub(credhubServer.URL(), "/concourse", "some-client", "some-secret", []byte("bogus"))
+			Expect(err).To(MatchError("CredHub CA cert not valid"))
+		})
+	})
+})
+
+type countingTransport struct {
+	base  http.RoundTripper
+	count *int
+}
+
+func (transport *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	*transport.count++
+	return transport.base.RoundTrip(request)
+}
+
+func (transport *countingTransport) Base() http.RoundTripper {
+	return transport.base
+}
+
+func (transport *countingTransport) WithBase(base http.RoundTripper) http.RoundTripper {
+	return &countingTransport{base: base, count: transport.count}
+}
@@ -0,0 +1,13 @@
+package varsources_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestVarSources(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Var Sources Suite")
+}
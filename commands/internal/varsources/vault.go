@@ -0,0 +1,155 @@
+package varsources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// Vault resolves ((vars)) from a Vault KV secrets engine, looking each var
+// up at PathPrefix/name. A secret with a single "value" field resolves to
+// that field; otherwise it resolves to all of its fields, so that
+// ((name.field)) can pick one out.
+//
+// Secrets of KV version 2 are read under data/ after their mount, so, as the
+// vault CLI does, Vault is asked which engine the secret is mounted under.
+type Vault struct {
+	Addr       string
+	PathPrefix string
+	Token      string
+
+	Client *http.Client
+
+	mountsLock sync.Mutex
+	mounts     []vaultMount
+}
+
+func NewVault(addr string, pathPrefix string, token string) *Vault {
+	return &Vault{
+		Addr:       strings.TrimRight(addr, "/"),
+		PathPrefix: "/" + strings.Trim(pathPrefix, "/"),
+		Token:      token,
+
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type vaultSecret struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+type vaultMount struct {
+	Path    string            `json:"path"`
+	Options map[string]string `json:"options"`
+}
+
+func (mount vaultMount) versioned() bool {
+	return mount.Options["version"] == "2"
+}
+
+func (vault *Vault) Get(def template.VariableDefinition) (interface{}, bool, error) {
+	secretPath := strings.TrimLeft(vault.PathPrefix+"/"+def.Name, "/")
+
+	mount := vault.mountOf(secretPath)
+	if mount.versioned() {
+		secretPath = mount.Path + "data/" + strings.TrimPrefix(secretPath, mount.Path)
+	}
+
+	response, err := vault.get(secretPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reach vault: %s", err)
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("vault returned %s for ((%s))", response.Status, def.Name)
+	}
+
+	var secret vaultSecret
+	err = json.NewDecoder(response.Body).Decode(&secret)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse vault response for ((%s)): %s", def.Name, err)
+	}
+
+	// KV version 2 nests the secret's fields under another "data" key,
+	// next to its metadata
+	if mount.versioned() {
+		nested, _ := secret.Data["data"].(map[string]interface{})
+		if nested == nil {
+			// the secret's latest version was deleted
+			return nil, false, nil
+		}
+
+		secret.Data = nested
+	}
+
+	if value, found := secret.Data["value"]; found && len(secret.Data) == 1 {
+		return value, true, nil
+	}
+
+	return secret.Data, true, nil
+}
+
+// mountOf returns the secrets engine mounted at the start of secretPath. If
+// Vault can't say, e.g. as it's too old to have KV version 2, the secret is
+// read as from version 1.
+func (vault *Vault) mountOf(secretPath string) vaultMount {
+	vault.mountsLock.Lock()
+	defer vault.mountsLock.Unlock()
+
+	for _, mount := range vault.mounts {
+		if strings.HasPrefix(secretPath, mount.Path) {
+			return mount
+		}
+	}
+
+	response, err := vault.get("sys/internal/ui/mounts/" + secretPath)
+	if err != nil {
+		return vaultMount{}
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return vaultMount{}
+	}
+
+	var found struct {
+		Data vaultMount `json:"data"`
+	}
+
+	err = json.NewDecoder(response.Body).Decode(&found)
+	if err != nil || found.Data.Path == "" {
+		return vaultMount{}
+	}
+
+	vault.mounts = append(vault.mounts, found.Data)
+
+	return found.Data
+}
+
+func (vault *Vault) get(path string) (*http.Response, error) {
+	request, err := http.NewRequest("GET", vault.Addr+"/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("X-Vault-Token", vault.Token)
+
+	return vault.Client.Do(request)
+}
+
+func (vault *Vault) List() ([]template.VariableDefinition, error) {
+	// vars are looked up on demand; there's no need to enumerate them
+	return nil, nil
+}
@@ -0,0 +1,176 @@
+package varsources_test
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/concourse/fly/commands/internal/varsources"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Vault", func() {
+	var (
+		server *ghttp.Server
+		vault  *varsources.Vault
+
+		kvVersion string
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		vault = varsources.NewVault(server.URL()+"/", "concourse/main/", "some-token")
+
+		kvVersion = "1"
+
+		server.RouteToHandler("GET", regexp.MustCompile(`^/v1/sys/internal/ui/mounts/`), func(w http.ResponseWriter, r *http.Request) {
+			ghttp.VerifyHeaderKV("X-Vault-Token", "some-token")(w, r)
+			ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{
+					"path":    "concourse/",
+					"type":    "kv",
+					"options": map[string]string{"version": kvVersion},
+				},
+			})(w, r)
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Context("when the secret has a single value", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/concourse/main/password"),
+					ghttp.VerifyHeaderKV("X-Vault-Token", "some-token"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{"value": "hunter2"},
+					}),
+				),
+			)
+		})
+
+		It("resolves to the value", func() {
+			value, found, err := vault.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+		})
+	})
+
+	Context("when the secret is stored in a KV version 2 engine", func() {
+		BeforeEach(func() {
+			kvVersion = "2"
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/concourse/data/main/creds"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{
+							"data":     map[string]interface{}{"username": "admin", "password": "hunter2"},
+							"metadata": map[string]interface{}{"version": 3},
+						},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/concourse/data/main/password"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{
+							"data":     map[string]interface{}{"value": "hunter2"},
+							"metadata": map[string]interface{}{"version": 1},
+						},
+					}),
+				),
+			)
+		})
+
+		It("reads it from under data/ after the mount, resolving to its fields", func() {
+			value, found, err := vault.Get(template.VariableDefinition{Name: "creds"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal(map[string]interface{}{"username": "admin", "password": "hunter2"}))
+
+			value, found, err = vault.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+		})
+	})
+
+	Context("when the secret of a KV version 1 engine has data and metadata fields", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/concourse/main/creds"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{
+							"data":     map[string]interface{}{"nested": "value"},
+							"metadata": "some-metadata",
+						},
+					}),
+				),
+			)
+		})
+
+		It("resolves to those fields", func() {
+			value, found, err := vault.Get(template.VariableDefinition{Name: "creds"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(HaveKeyWithValue("metadata", "some-metadata"))
+		})
+	})
+
+	Context("when vault can't say what's mounted", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("GET", regexp.MustCompile(`^/v1/sys/internal/ui/mounts/`), ghttp.RespondWith(http.StatusNotFound, ""))
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/v1/concourse/main/password"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+						"data": map[string]interface{}{"value": "hunter2"},
+					}),
+				),
+			)
+		})
+
+		It("reads the secret as from KV version 1", func() {
+			value, found, err := vault.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(value).To(Equal("hunter2"))
+		})
+	})
+
+	Context("when the secret does not exist", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusNotFound, `{"errors":[]}`),
+			)
+		})
+
+		It("is not found", func() {
+			_, found, err := vault.Get(template.VariableDefinition{Name: "missing"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("when vault rejects the token", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusForbidden, `{"errors":["permission denied"]}`),
+			)
+		})
+
+		It("returns an error", func() {
+			_, _, err := vault.Get(template.VariableDefinition{Name: "password"})
+			Expect(err).To(MatchError("vault returned 403 Forbidden for ((password))"))
+		})
+	})
+})
@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/fatih/color"
+)
+
+type JobStatsCommand struct {
+	Job   flaghelpers.JobFlag      `short:"j" long:"job"   required:"true" value-name:"PIPELINE/JOB" description:"Name of a job to compute statistics for"`
+	Since flaghelpers.DurationFlag `short:"s" long:"since" default:"30d"   value-name:"DURATION"     description:"Only consider builds started within this long ago (e.g. 12h, 30d)"`
+}
+
+func (command *JobStatsCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-time.Duration(command.Since)).Unix()
+
+	var builds []atc.Build
+
+	// builds are listed newest first, so stop at the first one that's older
+	// than the cutoff
+	page := &concourse.Page{Limit: 100}
+	for page != nil {
+		pageBuilds, pagination, found, err := target.Team().JobBuilds(command.Job.PipelineName, command.Job.JobName, *page)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			displayhelpers.Failf("pipeline/job not found")
+		}
+
+		page = pagination.Next
+
+		for _, build := range pageBuilds {
+			if build.StartTime != 0 && build.StartTime < cutoff {
+				page = nil
+				break
+			}
+
+			builds = append(builds, build)
+		}
+	}
+
+	stats := computeJobStats(builds)
+
+	bold := color.New(color.Bold)
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "statistic", Color: bold},
+			{Contents: "value", Color: bold},
+		},
+		Data: []ui.TableRow{
+			{{Contents: "builds"}, {Contents: strconv.Itoa(stats.finished)}},
+			{{Contents: "succeeded"}, {Contents: strconv.Itoa(stats.succeeded)}},
+			{{Contents: "failed"}, {Contents: strconv.Itoa(stats.failed)}},
+			{{Contents: "aborted"}, {Contents: strconv.Itoa(stats.aborted)}},
+			{{Contents: "success rate"}, stats.successRateCell()},
+			{{Contents: "mean duration"}, durationCell(stats.meanDuration)},
+			{{Contents: "95p duration"}, durationCell(stats.p95Duration)},
+			{{Contents: "longest failure streak"}, {Contents: strconv.Itoa(stats.longestStreak)}},
+			{{Contents: "current failure streak"}, stats.currentStreakCell()},
+		},
+	}
+
+	fmt.Printf("builds of %s/%s started in the last %s\n\n", command.Job.PipelineName, command.Job.JobName, time.Duration(command.Since))
+
+	return table.Render(os.Stdout, Fly.PrintTableHeaders)
+}
+
+type jobStats struct {
+	finished  int
+	succeeded int
+	failed    int
+	aborted   int
+
+	meanDuration time.Duration
+	p95Duration  time.Duration
+
+	longestStreak int
+	currentStreak int
+}
+
+// computeJobStats summarizes builds, given newest first. Errored builds
+// count as failures. Aborted builds are counted, but neither break nor
+// extend a failure streak, and aren't in the success rate or durations.
+func computeJobStats(builds []atc.Build) jobStats {
+	var stats jobStats
+	var durations []time.Duration
+
+	streak := 0
+
+	// walk from the oldest build, so that streaks are in the order they ran
+	for i := len(builds) - 1; i >= 0; i-- {
+		build := builds[i]
+
+		switch atc.BuildStatus(build.Status) {
+		case atc.StatusSucceeded:
+			stats.succeeded++
+			streak = 0
+		case atc.StatusFailed, atc.StatusErrored:
+			stats.failed++
+			streak++
+			if streak > stats.longestStreak {
+				stats.longestStreak = streak
+			}
+		case atc.StatusAborted:
+			stats.aborted++
+			stats.finished++
+			continue
+		default:
+			// still running
+			continue
+		}
+
+		stats.finished++
+
+		if build.StartTime != 0 && build.EndTime >= build.StartTime {
+			durations = append(durations, time.Duration(build.EndTime-build.StartTime)*time.Second)
+		}
+	}
+
+	// whatever streak the newest builds are in is still going
+	stats.currentStreak = streak
+
+	if len(durations) > 0 {
+		var total time.Duration
+		for _, duration := range durations {
+			total += duration
+		}
+
+		stats.meanDuration = total / time.Duration(len(durations))
+
+		sort.Sort(durationsAscending(durations))
+
+		// nearest-rank percentile
+		rank := (95*len(durations) + 99) / 100
+		stats.p95Duration = durations[rank-1]
+	}
+
+	return stats
+}
+
+func (stats jobStats) successRateCell() ui.TableCell {
+	decided := stats.succeeded + stats.failed
+	if decided == 0 {
+		return ui.TableCell{Contents: "n/a"}
+	}
+
+	rate := float64(stats.succeeded) * 100 / float64(decided)
+
+	cell := ui.TableCell{Contents: fmt.Sprintf("%.1f%%", rate), Color: ui.SucceededColor}
+	if stats.failed > stats.succeeded {
+		cell.Color = ui.FailedColor
+	}
+
+	return cell
+}
+
+func (stats jobStats) currentStreakCell() ui.TableCell {
+	cell := ui.TableCell{Contents: strconv.Itoa(stats.currentStreak)}
+	if stats.currentStreak > 0 {
+		cell.Color = ui.FailedColor
+	}
+
+	return cell
+}
+
+func durationCell(duration time.Duration) ui.TableCell {
+	if duration == 0 {
+		return ui.TableCell{Contents: "n/a"}
+	}
+
+	return ui.TableCell{Contents: duration.String()}
+}
+
+type durationsAscending []time.Duration
+
+func (d durationsAscending) Len() int           { return len(d) }
+func (d durationsAscending) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+func (d durationsAscending) Less(i, j int) bool { return d[i] < d[j] }
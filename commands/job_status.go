@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+)
+
+type JobStatusCommand struct {
+	Job flaghelpers.JobFlag `short:"j" long:"job" required:"true" value-name:"PIPELINE/JOB" description:"Name of a job to get the status of"`
+}
+
+// Execute prints the status of the job's latest finished build and exits 0
+// if it succeeded, 1 if it failed, or 2 if it errored, was aborted, or the
+// job has never finished a build, so that scripts and monitoring checks can
+// probe a job in one call.
+func (command *JobStatusCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	job, found, err := target.Team().Job(command.Job.PipelineName, command.Job.JobName)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return rc.NewErrNotFound("pipeline or job", command.Job.PipelineName+"/"+command.Job.JobName)
+	}
+
+	if job.FinishedBuild == nil {
+		fmt.Println("n/a")
+		atexit.Exit(2)
+	}
+
+	build := job.FinishedBuild
+	fmt.Printf("%s (%s/%s #%s)\n", build.Status, command.Job.PipelineName, command.Job.JobName, build.Name)
+
+	switch atc.BuildStatus(build.Status) {
+	case atc.StatusSucceeded:
+		atexit.Exit(0)
+	case atc.StatusFailed:
+		atexit.Exit(1)
+	default:
+		atexit.Exit(2)
+	}
+
+	return nil
+}
@@ -1,20 +1,49 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
 	"github.com/fatih/color"
 )
 
 type JobsCommand struct {
-	Pipeline string `short:"p" long:"pipeline" required:"true" description:"Get jobs in this pipeline"`
+	Pipeline     string `short:"p" long:"pipeline"                                       description:"Get jobs in this pipeline, by default the one in .fly.yml or set with set-defaults"`
+	JSON         bool   `          long:"json"                                           description:"Print the jobs as json, with their latest and next builds and how their recent builds went"`
+	RecentBuilds int    `          long:"recent-builds" default:"10"     value-name:"N" description:"Number of recent builds of each job to count in the json"`
+	Format       string `          long:"format"                         value-name:"TEMPLATE" description:"Print each job with a Go template, e.g. '{{.Name}} {{.Paused}}'"`
+	JSONPath     string `          long:"jsonpath"                       value-name:"PATH" description:"Print the value at a JSONPath of each job, e.g. '{.finished_build.status}'"`
+}
+
+// jobSummary is a job as printed by --json: the job as given by the ATC,
+// whose finished_build and next_build are its latest and next builds, along
+// with counts of how its recent builds went.
+type jobSummary struct {
+	atc.Job
+
+	Recent recentBuilds `json:"recent_builds"`
+}
+
+type recentBuilds struct {
+	Finished  int `json:"finished"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Aborted   int `json:"aborted"`
 }
 
 func (command *JobsCommand) Execute([]string) error {
-	pipelineName := command.Pipeline
+	pipelineName, err := defaultPipeline(command.Pipeline)
+	if err != nil {
+		return err
+	}
+
+	command.Pipeline = pipelineName
 
 	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
 	if err != nil {
@@ -26,6 +55,11 @@ func (command *JobsCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
 	var headers []string
 	var jobs []atc.Job
 
@@ -35,6 +69,14 @@ func (command *JobsCommand) Execute([]string) error {
 		return err
 	}
 
+	if command.JSON {
+		return command.printJSON(target.Team(), jobs)
+	}
+
+	if formatter != nil {
+		return formatter.Print(os.Stdout, jobs)
+	}
+
 	table := ui.Table{Headers: ui.TableRow{}}
 	for _, h := range headers {
 		table.Headers = append(table.Headers, ui.TableCell{Contents: h, Color: color.New(color.Bold)})
@@ -54,27 +96,9 @@ func (command *JobsCommand) Execute([]string) error {
 
 		row = append(row, pausedColumn)
 
-		var statusColumn ui.TableCell
+		statusColumn := ui.TableCell{Contents: "n/a"}
 		if p.FinishedBuild != nil {
-			statusColumn.Contents = p.FinishedBuild.Status
-			switch p.FinishedBuild.Status {
-			case "pending":
-				statusColumn.Color = ui.PendingColor
-			case "started":
-				statusColumn.Color = ui.StartedColor
-			case "succeeded":
-				statusColumn.Color = ui.SucceededColor
-			case "failed":
-				statusColumn.Color = ui.FailedColor
-			case "errored":
-				statusColumn.Color = ui.ErroredColor
-			case "aborted":
-				statusColumn.Color = ui.AbortedColor
-			case "paused":
-				statusColumn.Color = ui.PausedColor
-			}
-		} else {
-			statusColumn.Contents = "n/a"
+			statusColumn = buildStatusCell(p.FinishedBuild.Status)
 		}
 		row = append(row, statusColumn)
 
@@ -83,3 +107,39 @@ func (command *JobsCommand) Execute([]string) error {
 
 	return table.Render(os.Stdout, Fly.PrintTableHeaders)
 }
+
+func (command *JobsCommand) printJSON(team concourse.Team, jobs []atc.Job) error {
+	summaries := []jobSummary{}
+	for _, job := range jobs {
+		summary := jobSummary{Job: job}
+
+		if command.RecentBuilds > 0 {
+			builds, _, found, err := team.JobBuilds(command.Pipeline, job.Name, concourse.Page{Limit: command.RecentBuilds})
+			if err != nil {
+				return err
+			}
+
+			if !found {
+				return fmt.Errorf("job '%s' not found", job.Name)
+			}
+
+			stats := computeJobStats(builds)
+			summary.Recent = recentBuilds{
+				Finished:  stats.finished,
+				Succeeded: stats.succeeded,
+				Failed:    stats.failed,
+				Aborted:   stats.aborted,
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	payload, err := json.Marshal(summaries)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Printf("%s\n", payload)
+	return err
+}
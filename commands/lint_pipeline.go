@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/setpipelinehelpers"
+)
+
+type LintPipelineCommand struct {
+	Config atc.PathFlag `short:"c" long:"config" required:"true"        description:"Pipeline configuration file"`
+	Strict bool         `short:"s" long:"strict"                        description:"Fail on warnings as well as errors"`
+
+	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in the pipeline"`
+	YAMLVar []flaghelpers.YAMLVariablePairFlag `short:"y"  long:"yaml-var"  value-name:"[NAME=YAML]"    description:"Specify a YAML value to set for a variable in the pipeline"`
+
+	VarsFrom []atc.PathFlag `short:"l"  long:"load-vars-from"  description:"Variable flag that can be used for filling in template values in configuration from a YAML file"`
+}
+
+func (command *LintPipelineCommand) Execute(args []string) error {
+	atcConfig := setpipelinehelpers.ATCConfig{}
+	return atcConfig.Lint(command.Config, command.Var, command.YAMLVar, command.VarsFrom, command.Strict)
+}
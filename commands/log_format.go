@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/concourse/fly/commands/internal/logformat"
+
+func init() {
+	Fly.LogFormat = logformat.Start
+}
@@ -15,11 +15,11 @@ import (
 )
 
 type LoginCommand struct {
-	ATCURL   string       `short:"c" long:"concourse-url" description:"Concourse URL to authenticate with"`
+	ATCURL   string       `short:"c" long:"concourse-url" env:"FLY_ATC_URL" description:"Concourse URL to authenticate with"`
 	Insecure bool         `short:"k" long:"insecure" description:"Skip verification of the endpoint's SSL certificate"`
-	Username string       `short:"u" long:"username" description:"Username for basic auth"`
-	Password string       `short:"p" long:"password" description:"Password for basic auth"`
-	TeamName string       `short:"n" long:"team-name" description:"Team to authenticate with"`
+	Username string       `short:"u" long:"username" env:"FLY_USERNAME" description:"Username for basic auth"`
+	Password string       `short:"p" long:"password" env:"FLY_PASSWORD" description:"Password for basic auth"`
+	TeamName string       `short:"n" long:"team-name" env:"FLY_TEAM" description:"Team to authenticate with"`
 	CACert   atc.PathFlag `long:"ca-cert" description:"Path to Concourse PEM-encoded CA certificate file."`
 }
 
@@ -40,6 +40,11 @@ func (command *LoginCommand) Execute(args []string) error {
 		caCert = string(caCertBytes)
 	}
 
+	tlsPolicy, err := rc.LoadTLSPolicy(Fly.Target)
+	if err != nil {
+		return err
+	}
+
 	if command.ATCURL != "" {
 		if command.TeamName == "" {
 			command.TeamName = atc.DefaultTeamName
@@ -51,6 +56,7 @@ func (command *LoginCommand) Execute(args []string) error {
 			command.TeamName,
 			command.Insecure,
 			caCert,
+			tlsPolicy,
 			Fly.Verbose,
 		)
 	} else {
@@ -101,6 +107,7 @@ func (command *LoginCommand) Execute(args []string) error {
 				command.TeamName,
 				command.Insecure,
 				target.CACert(),
+				tlsPolicy,
 				Fly.Verbose,
 			)
 			if err != nil {
@@ -139,7 +146,7 @@ func (command *LoginCommand) Execute(args []string) error {
 	}
 
 	client := target.Client()
-	token, err := command.loginWith(chosenMethod, client, caCert, target.Client().URL())
+	token, err := command.loginWith(chosenMethod, client, caCert, tlsPolicy, target.Client().URL())
 	if err != nil {
 		return err
 	}
@@ -176,6 +183,7 @@ func (command *LoginCommand) loginWith(
 	method atc.AuthMethod,
 	client concourse.Client,
 	caCert string,
+	tlsPolicy rc.TLSPolicy,
 	targetUrl string,
 ) (*atc.AuthToken, error) {
 	var token atc.AuthToken
@@ -245,6 +253,7 @@ func (command *LoginCommand) loginWith(
 			username,
 			password,
 			caCert,
+			tlsPolicy,
 			Fly.Verbose,
 		)
 		if err != nil {
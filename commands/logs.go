@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type LogsCommand struct {
+	Job    flaghelpers.JobFlag `short:"j" long:"job"     value-name:"PIPELINE/JOB" description:"Name of the job the build belongs to"`
+	Build  string              `short:"b" long:"build"   required:"true"           description:"If job is specified: build number. If job not specified: build id, or PIPELINE/JOB#BUILD (the latest build if #BUILD is left out)"`
+	Step   string              `short:"s" long:"step"    value-name:"NAME"         description:"Only print the output of this step, e.g. unit or task: unit"`
+	NoANSI bool                `          long:"no-ansi"                           description:"Strip colors and other terminal escape codes from the output"`
+	Output string              `short:"o" long:"output"  value-name:"PATH"         description:"Write the log to PATH rather than stdout"`
+}
+
+func (command *LogsCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	job, buildName, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
+	var team concourse.Team
+	if job.JobName != "" {
+		team = target.Team()
+	}
+
+	client := target.Client()
+
+	build, err := GetBuild(client, team, job.JobName, buildName, job.PipelineName)
+	if err != nil {
+		return err
+	}
+
+	switch atc.BuildStatus(build.Status) {
+	case atc.StatusPending, atc.StatusStarted:
+		return fmt.Errorf("build %d has not finished; use fly watch to follow it", build.ID)
+	}
+
+	names := map[string]string{}
+
+	// without the plan, steps are still named by what they run
+	buildPlan, found, err := client.BuildPlan(build.ID)
+	if err == nil && found && buildPlan.Plan != nil {
+		var plan atc.Plan
+		if json.Unmarshal(*buildPlan.Plan, &plan) == nil {
+			names = eventstream.StepNames(plan)
+		}
+	}
+
+	eventSource, err := eventstream.Events(context.Background(), client, strconv.Itoa(build.ID))
+	if err != nil {
+		return err
+	}
+
+	steps, err := eventstream.StepLogs(eventSource, names)
+
+	eventSource.Close()
+
+	if err != nil {
+		return err
+	}
+
+	if command.Step != "" {
+		step, found := findStepLog(steps, command.Step)
+		if !found {
+			return fmt.Errorf("build %d has no step named '%s'", build.ID, command.Step)
+		}
+
+		steps = []eventstream.StepLog{step}
+	}
+
+	var dst io.Writer = os.Stdout
+	if command.Output != "" {
+		file, err := os.Create(command.Output)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		dst = file
+	}
+
+	for _, step := range steps {
+		log := step.Log
+		if command.NoANSI {
+			log = stripANSI(log)
+		}
+
+		if command.Step == "" {
+			header := "\x1b[1m==> " + step.Name + "\x1b[0m"
+			if command.NoANSI {
+				header = "==> " + step.Name
+			}
+
+			fmt.Fprintln(dst, header)
+		}
+
+		_, err := io.WriteString(dst, log)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findStepLog finds a step by its name, which may be given without its type,
+// e.g. unit for task: unit.
+func findStepLog(steps []eventstream.StepLog, name string) (eventstream.StepLog, bool) {
+	for _, step := range steps {
+		if step.Name == name {
+			return step, true
+		}
+	}
+
+	for _, step := range steps {
+		parts := strings.SplitN(step.Name, ": ", 2)
+		if len(parts) == 2 && parts[1] == name {
+			return step, true
+		}
+	}
+
+	return eventstream.StepLog{}, false
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
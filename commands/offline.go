@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrOffline is the error of every request fly would make with --offline.
+var ErrOffline = errors.New("refusing to reach the network with --offline")
+
+// OfflineMiddleware refuses every request once --offline is given, so that
+// commands that would reach the network fail at their first request.
+func OfflineMiddleware(base http.RoundTripper) http.RoundTripper {
+	return offlineTransport{base: base}
+}
+
+type offlineTransport struct {
+	base http.RoundTripper
+}
+
+func (transport offlineTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if Fly.Offline {
+		if request.Body != nil {
+			request.Body.Close()
+		}
+
+		return nil, ErrOffline
+	}
+
+	return transport.base.RoundTrip(request)
+}
+
+// Base and WithBase let a copy of the wrapped transport be configured
+// differently, e.g. to trust another CA, and still be refused with --offline.
+func (transport offlineTransport) Base() http.RoundTripper {
+	return transport.base
+}
+
+func (transport offlineTransport) WithBase(base http.RoundTripper) http.RoundTripper {
+	return offlineTransport{base: base}
+}
+
+// IsOffline returns whether err is from a request refused by --offline.
+func IsOffline(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	return err == ErrOffline
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/pipelinehelpers"
+	"github.com/concourse/fly/rc"
+)
+
+type PipelineGraphCommand struct {
+	Pipeline flaghelpers.PipelineFlag `short:"p" long:"pipeline" required:"true"  description:"Pipeline to graph"`
+	Format   string                   `short:"f" long:"format"   default:"dot"    choice:"dot" choice:"svg" description:"Print the graph in Graphviz's DOT language, or rendered as SVG by Graphviz's dot"`
+}
+
+func (command *PipelineGraphCommand) Validate() error {
+	return command.Pipeline.Validate()
+}
+
+func (command *PipelineGraphCommand) Execute([]string) error {
+	err := command.Validate()
+	if err != nil {
+		return err
+	}
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	pipelineName := string(command.Pipeline)
+
+	config, _, _, found, err := target.Team().PipelineConfig(pipelineName)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return rc.NewErrNotFound("pipeline", pipelineName)
+	}
+
+	if command.Format == "dot" {
+		return pipelinehelpers.WriteDot(os.Stdout, pipelineName, config)
+	}
+
+	dot := new(bytes.Buffer)
+	err = pipelinehelpers.WriteDot(dot, pipelineName, config)
+	if err != nil {
+		return err
+	}
+
+	return renderSVG(os.Stdout, dot)
+}
+
+func renderSVG(dst io.Writer, dot io.Reader) error {
+	dotPath, err := exec.LookPath("dot")
+	if err != nil {
+		return fmt.Errorf("rendering SVG needs Graphviz's dot on your PATH; use --format dot and render it elsewhere")
+	}
+
+	cmd := exec.Command(dotPath, "-Tsvg")
+	cmd.Stdin = dot
+	cmd.Stdout = dst
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -1,16 +1,22 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
 	"os"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/fatih/color"
 )
 
 type PipelinesCommand struct {
-	All bool `short:"a"  long:"all" description:"Show all pipelines"`
+	All      bool   `short:"a"  long:"all" description:"Show all pipelines"`
+	AllTeams bool   `long:"all-teams" description:"Show every team's pipelines, public or not (admins only)"`
+	Format   string `long:"format" value-name:"TEMPLATE" description:"Print each pipeline with a Go template, e.g. '{{.Name}} {{.Paused}}'"`
+	JSONPath string `long:"jsonpath" value-name:"PATH" description:"Print the value at a JSONPath of each pipeline, e.g. '{.name}'"`
 }
 
 func (command *PipelinesCommand) Execute([]string) error {
@@ -24,10 +30,26 @@ func (command *PipelinesCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
+	if command.All && command.AllTeams {
+		return errors.New("--all and --all-teams can't both be given")
+	}
+
 	var headers []string
 	var pipelines []atc.Pipeline
 
-	if command.All {
+	if command.AllTeams {
+		if !target.Token().IsAdmin() {
+			return errors.New("only admins can list the pipelines of every team")
+		}
+
+		pipelines, err = command.allTeamsPipelines(target)
+		headers = []string{"name", "team", "paused", "public"}
+	} else if command.All {
 		pipelines, err = target.Client().ListPipelines()
 		headers = []string{"name", "team", "paused", "public"}
 	} else {
@@ -38,6 +60,10 @@ func (command *PipelinesCommand) Execute([]string) error {
 		return err
 	}
 
+	if formatter != nil {
+		return formatter.Print(os.Stdout, pipelines)
+	}
+
 	table := ui.Table{Headers: ui.TableRow{}}
 	for _, h := range headers {
 		table.Headers = append(table.Headers, ui.TableCell{Contents: h, Color: color.New(color.Bold)})
@@ -62,7 +88,7 @@ func (command *PipelinesCommand) Execute([]string) error {
 
 		row := ui.TableRow{}
 		row = append(row, ui.TableCell{Contents: p.Name})
-		if command.All {
+		if command.All || command.AllTeams {
 			row = append(row, ui.TableCell{Contents: p.TeamName})
 		}
 		row = append(row, pausedColumn)
@@ -73,3 +99,27 @@ func (command *PipelinesCommand) Execute([]string) error {
 
 	return table.Render(os.Stdout, Fly.PrintTableHeaders)
 }
+
+func (command *PipelinesCommand) allTeamsPipelines(target rc.Target) ([]atc.Pipeline, error) {
+	client := target.Client()
+
+	teams, err := client.ListTeams()
+	if err != nil {
+		return nil, err
+	}
+
+	var pipelines []atc.Pipeline
+	for _, team := range teams {
+		teamPipelines, err := client.Team(team.Name).ListPipelines()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the pipelines of team %s: %s", team.Name, err)
+		}
+
+		for _, p := range teamPipelines {
+			p.TeamName = team.Name
+			pipelines = append(pipelines, p)
+		}
+	}
+
+	return pipelines, nil
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"syscall"
+
+	"github.com/concourse/fly/rc"
+	"github.com/jessevdk/go-flags"
+)
+
+// PluginPrefix prefixes the executables on $PATH that extend fly with
+// subcommands of their own, e.g. fly-audit is run as fly audit.
+const PluginPrefix = "fly-"
+
+// RunPlugin runs the plugin named by the subcommand in args if fly has no
+// such subcommand of its own, returning whether it did. The plugin is
+// given the rest of the args, and the target given by -t or $FLY_TARGET in
+// its env.
+func RunPlugin(parser *flags.Parser, args []string) (bool, int, error) {
+	name, pluginArgs, target := findSubcommand(parser, args)
+	if name == "" || isCommand(parser, name) {
+		return false, 0, nil
+	}
+
+	if target == "" {
+		target = os.Getenv(rc.TargetEnv)
+	}
+
+	path, err := exec.LookPath(PluginPrefix + name)
+	if err != nil {
+		return false, 0, nil
+	}
+
+	env := os.Environ()
+	if target != "" {
+		targetEnv, err := pluginTargetEnv(rc.TargetName(target))
+		if err != nil {
+			return true, 1, err
+		}
+
+		env = append(env, targetEnv...)
+	}
+
+	plugin := exec.Command(path, pluginArgs...)
+	plugin.Env = env
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+
+	err = plugin.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return true, status.ExitStatus(), nil
+		}
+
+		return true, 1, nil
+	}
+
+	if err != nil {
+		return true, 1, err
+	}
+
+	return true, 0, nil
+}
+
+// findSubcommand returns the first argument that isn't an option, the
+// arguments after it, and the target given before it, if any.
+func findSubcommand(parser *flags.Parser, args []string) (string, []string, string) {
+	optionsWithValues := optionsWithValues(parser)
+
+	target := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			return "", nil, target
+		case optionsWithValues[arg]:
+			if i+1 < len(args) && (arg == "-t" || arg == "--target") {
+				target = args[i+1]
+			}
+
+			i++
+		case strings.HasPrefix(arg, "--target="):
+			target = strings.TrimPrefix(arg, "--target=")
+		case strings.HasPrefix(arg, "-t") && !strings.HasPrefix(arg, "--"):
+			target = strings.TrimPrefix(arg, "-t")
+		case strings.HasPrefix(arg, "-"):
+		default:
+			return arg, args[i+1:], target
+		}
+	}
+
+	return "", nil, target
+}
+
+// optionsWithValues returns fly's own options that take a value as the next
+// argument, which are skipped over in finding the subcommand.
+func optionsWithValues(parser *flags.Parser) map[string]bool {
+	options := map[string]bool{}
+
+	var add func(group *flags.Group)
+	add = func(group *flags.Group) {
+		for _, option := range group.Options() {
+			if option.OptionalArgument || !takesValue(option.Value()) {
+				continue
+			}
+
+			if option.ShortName != 0 {
+				options["-"+string(option.ShortName)] = true
+			}
+
+			if option.LongName != "" {
+				options["--"+option.LongName] = true
+			}
+		}
+
+		for _, child := range group.Groups() {
+			add(child)
+		}
+	}
+
+	add(parser.Group)
+
+	return options
+}
+
+// takesValue mirrors go-flags: bools, and funcs taking no argument, are
+// switches; everything else is given a value.
+func takesValue(value interface{}) bool {
+	t := reflect.TypeOf(value)
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return false
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Bool
+	case reflect.Func:
+		return t.NumIn() > 0
+	default:
+		return true
+	}
+}
+
+func isCommand(parser *flags.Parser, name string) bool {
+	for _, command := range parser.Commands() {
+		if command.Name == name {
+			return true
+		}
+
+		for _, alias := range command.Aliases {
+			if alias == name {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// pluginTargetEnv describes the target to a plugin, so that it can talk to
+// the ATC as fly would.
+func pluginTargetEnv(targetName rc.TargetName) ([]string, error) {
+	target, err := rc.LoadTarget(targetName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	env := []string{
+		"FLY_TARGET=" + string(targetName),
+		"FLY_TARGET_URL=" + target.URL(),
+		"FLY_TARGET_TEAM=" + target.Team().Name(),
+	}
+
+	if authorization, ok := target.TokenAuthorization(); ok {
+		env = append(env, "FLY_TARGET_AUTHORIZATION="+authorization)
+	}
+
+	if target.CACert() != "" {
+		env = append(env, "FLY_TARGET_CA_CERT="+target.CACert())
+	}
+
+	return env, nil
+}
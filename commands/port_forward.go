@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/tedsuo/rata"
+)
+
+type PortForwardCommand struct {
+	Job            flaghelpers.JobFlag `short:"j" long:"job"     value-name:"PIPELINE/JOB" description:"Name of the job whose container to forward to"`
+	Build          string              `short:"b" long:"build"                             description:"Build number within the job, global build ID, or PIPELINE/JOB#BUILD"`
+	StepName       string              `short:"s" long:"step"                              description:"Name of the step whose container to forward to"`
+	Attempt        string              `short:"a" long:"attempt" value-name:"N[,N,...]"    description:"Attempt number of the step"`
+	PositionalArgs struct {
+		Ports string `positional-arg-name:"[LOCAL:]REMOTE" required:"true" description:"Local port to listen on, and the port in the container to forward it to"`
+	} `positional-args:"yes"`
+}
+
+func (command *PortForwardCommand) Execute([]string) error {
+	localPort, remotePort, err := parsePorts(command.PositionalArgs.Ports)
+	if err != nil {
+		return err
+	}
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	locator := HijackCommand{
+		Job:      command.Job,
+		Build:    command.Build,
+		StepName: command.StepName,
+		Attempt:  command.Attempt,
+	}
+
+	containers, err := locator.getContainerIDs(target.Client())
+	if err != nil {
+		return err
+	}
+
+	chosenContainer, chosen, err := chooseContainer(containers)
+	if err != nil {
+		return err
+	}
+
+	if !chosen {
+		return nil
+	}
+
+	h := hijacker.New(target.TLSConfig(), rata.NewRequestGenerator(target.URL(), atc.Routes), target.Token())
+
+	container := hijacker.Container{
+		Handle: chosenContainer.ID,
+		User:   chosenContainer.User,
+		Dir:    chosenContainer.WorkingDirectory,
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(localPort)))
+	if err != nil {
+		return err
+	}
+
+	defer listener.Close()
+
+	fmt.Printf("forwarding %s to port %d of container %s\n", listener.Addr(), remotePort, chosenContainer.ID)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+
+			err := h.Forward(container, remotePort, conn, os.Stderr)
+			if err != nil {
+				fmt.Fprintf(ui.Stderr, "failed to forward connection from %s: %s\n", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// parsePorts parses LOCAL:REMOTE, or REMOTE to listen on the same port
+// locally.
+func parsePorts(ports string) (int, int, error) {
+	local, remote := ports, ports
+	if i := strings.Index(ports, ":"); i >= 0 {
+		local, remote = ports[:i], ports[i+1:]
+	}
+
+	localPort, err := strconv.Atoi(local)
+	if err != nil || localPort < 0 || localPort > 65535 {
+		return 0, 0, fmt.Errorf("invalid local port in %s", ports)
+	}
+
+	remotePort, err := strconv.Atoi(remote)
+	if err != nil || remotePort < 1 || remotePort > 65535 {
+		return 0, 0, fmt.Errorf("invalid port in the container in %s", ports)
+	}
+
+	return localPort, remotePort, nil
+}
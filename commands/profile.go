@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/concourse/fly/commands/internal/profiling"
+
+func init() {
+	Fly.Profile = profiling.Start
+}
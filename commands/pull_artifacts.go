@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/hijacker"
+	"github.com/concourse/fly/commands/internal/pipelinehelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/tedsuo/rata"
+)
+
+type PullArtifactsCommand struct {
+	Job    flaghelpers.JobFlag `short:"j" long:"job"                    value-name:"PIPELINE/JOB" description:"Name of the job whose build to pull the outputs of"`
+	Build  string              `short:"b" long:"build"                                            description:"Build number to pull from, or PIPELINE/JOB#BUILD in place of --job (default: the latest successful build)"`
+	Output string              `short:"o" long:"output" required:"true" value-name:"DIR"          description:"Directory to pull the outputs into, each into a directory named after it"`
+}
+
+func (command *PullArtifactsCommand) Execute([]string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	job, buildName, err := jobBuild(command.Job, command.Build)
+	if err != nil {
+		return err
+	}
+
+	if job.JobName == "" {
+		return errors.New("the job must be given, with --job or as --build PIPELINE/JOB#BUILD")
+	}
+
+	team := target.Team()
+	pipelineName := job.PipelineName
+	jobName := job.JobName
+
+	var build atc.Build
+	if buildName != "" {
+		build, err = GetBuild(target.Client(), team, jobName, buildName, pipelineName)
+	} else {
+		build, err = latestSucceededBuild(team, pipelineName, jobName)
+	}
+	if err != nil {
+		return err
+	}
+
+	config, _, _, found, err := team.PipelineConfig(pipelineName)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		displayhelpers.Failf("pipeline not found")
+	}
+
+	var job atc.JobConfig
+	for _, candidate := range config.Jobs {
+		if candidate.Name == jobName {
+			job = candidate
+		}
+	}
+
+	if job.Name == "" {
+		displayhelpers.Failf("job not found")
+	}
+
+	err = os.MkdirAll(command.Output, 0755)
+	if err != nil {
+		return err
+	}
+
+	h := hijacker.New(target.TLSConfig(), rata.NewRequestGenerator(target.URL(), atc.Routes), target.Token())
+
+	fmt.Printf("pulling the outputs of %s/%s #%s\n", pipelineName, jobName, build.Name)
+
+	pulled, failed := 0, 0
+	for _, step := range pipelinehelpers.JobSteps(job) {
+		if step.Task == "" {
+			continue
+		}
+
+		n, err := pullTaskOutputs(target.Client(), h, build, step, command.Output)
+		pulled += n
+
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to pull the outputs of task %s: %s\n", step.Task, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to pull the outputs of %d tasks", failed)
+	}
+
+	if pulled == 0 {
+		return errors.New("the build's tasks have no outputs")
+	}
+
+	return nil
+}
+
+// latestSucceededBuild returns the job's most recent build that succeeded.
+func latestSucceededBuild(team concourse.Team, pipelineName string, jobName string) (atc.Build, error) {
+	page := &concourse.Page{Limit: 100}
+	for page != nil {
+		builds, pagination, found, err := team.JobBuilds(pipelineName, jobName, *page)
+		if err != nil {
+			return atc.Build{}, err
+		}
+
+		if !found {
+			return atc.Build{}, rc.NewErrNotFound("job", "")
+		}
+
+		for _, build := range builds {
+			if build.Status == string(atc.StatusSucceeded) {
+				return build, nil
+			}
+		}
+
+		page = pagination.Next
+	}
+
+	return atc.Build{}, errors.New("job has no successful builds")
+}
+
+// pullTaskOutputs copies the outputs of the task step out of its container
+// in the build, under the names the rest of the build knew them by, and
+// returns how many it pulled.
+func pullTaskOutputs(client concourse.Client, h *hijacker.Hijacker, build atc.Build, step atc.PlanConfig, dir string) (int, error) {
+	containers, err := client.ListContainers(map[string]string{
+		"build_id":  fmt.Sprintf("%d", build.ID),
+		"step_name": step.Task,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(containers) == 0 {
+		return 0, errors.New("its container is gone; containers expire soon after their build finishes")
+	}
+
+	// the last attempt of a retried task is the one that counted
+	chosen := containers[len(containers)-1]
+
+	container := hijacker.Container{
+		Handle: chosen.ID,
+		User:   chosen.User,
+		Dir:    chosen.WorkingDirectory,
+	}
+
+	taskConfig := step.TaskConfig
+	if taskConfig == nil {
+		payload, err := h.ReadFile(container, step.TaskConfigPath, os.Stderr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read %s: %s", step.TaskConfigPath, err)
+		}
+
+		loaded, err := atc.NewTaskConfig(payload)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse %s: %s", step.TaskConfigPath, err)
+		}
+
+		taskConfig = &loaded
+	}
+
+	pulled := 0
+	for _, output := range taskConfig.Outputs {
+		path := output.Path
+		if path == "" {
+			path = output.Name
+		}
+
+		name := output.Name
+		if mapped, found := step.OutputMapping[output.Name]; found {
+			name = mapped
+		}
+
+		err := copyOut(h, container, path, filepath.Join(dir, name))
+		if err != nil {
+			return pulled, fmt.Errorf("failed to pull output %s: %s", name, err)
+		}
+
+		fmt.Printf("pulled %s from task %s\n", name, step.Task)
+		pulled++
+	}
+
+	return pulled, nil
+}
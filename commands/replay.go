@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/eventstream"
+)
+
+type ReplayCommand struct {
+	MaxLogSize     flaghelpers.ByteSizeFlag `long:"max-log-size" value-name:"SIZE" description:"Stop printing the build's logs after SIZE bytes (e.g. 100MB)"`
+	JSON           bool                     `long:"json"                           description:"Print the build's events as lines of json rather than rendering them"`
+	Timestamps     bool                     `long:"timestamps"                     description:"Prefix each line of the build's logs with when it was first received and how long after the build started"`
+	PositionalArgs struct {
+		Path string `positional-arg-name:"PATH" required:"true" description:"A file of a build's events written by --log-file"`
+	} `positional-args:"yes"`
+}
+
+func (command *ReplayCommand) Execute([]string) error {
+	file, err := os.Open(command.PositionalArgs.Path)
+	if err != nil {
+		return err
+	}
+
+	exitCode := eventstream.RenderWithOptions(os.Stdout, eventstream.ReadLogFile(file), eventstream.RenderOptions{
+		MaxLogBytes: int64(command.MaxLogSize),
+		StepSummary: true,
+		JSON:        command.JSON,
+		Timestamps:  command.Timestamps,
+	})
+
+	file.Close()
+
+	atexit.Exit(exitCode)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+)
+
+type EnableResourceVersionCommand struct {
+	Resource flaghelpers.ResourceFlag `short:"r" long:"resource" required:"true" value-name:"PIPELINE/RESOURCE" description:"Name of the resource"`
+	Version  atc.Version              `short:"v" long:"version"  required:"true" value-name:"VERSION"           description:"Version to enable, e.g. ref:abcd (can be specified multiple times for versions with several fields)"`
+}
+
+func (command *EnableResourceVersionCommand) Execute([]string) error {
+	return setResourceVersionEnabled(command.Resource, command.Version, true)
+}
+
+type DisableResourceVersionCommand struct {
+	Resource flaghelpers.ResourceFlag `short:"r" long:"resource" required:"true" value-name:"PIPELINE/RESOURCE" description:"Name of the resource"`
+	Version  atc.Version              `short:"v" long:"version"  required:"true" value-name:"VERSION"           description:"Version to disable, e.g. ref:abcd (can be specified multiple times for versions with several fields)"`
+}
+
+func (command *DisableResourceVersionCommand) Execute([]string) error {
+	return setResourceVersionEnabled(command.Resource, command.Version, false)
+}
+
+// setResourceVersionEnabled enables or disables the version of a resource
+// that has every field of the given version. Disabled versions are never
+// used by the pipeline's builds.
+func setResourceVersionEnabled(resource flaghelpers.ResourceFlag, version atc.Version, enabled bool) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	versioned, found, err := findResourceVersion(target, resource, version)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("resource '%s' has no version %s", resource.ResourceName, versionString(version))
+	}
+
+	action, done := "disable", "disabled"
+	if enabled {
+		action, done = "enable", "enabled"
+	}
+
+	if versioned.Enabled == enabled {
+		fmt.Printf("version %s of '%s' is already %s\n", versionString(versioned.Version), resource.ResourceName, done)
+		return nil
+	}
+
+	request, err := http.NewRequest("PUT", pipelineAPIURL(target, resource.PipelineName, "resources", resource.ResourceName, "versions", strconv.Itoa(versioned.ID), action), nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := target.Client().HTTPClient().Do(request)
+	if err != nil {
+		return err
+	}
+
+	response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to %s version: %s", action, response.Status)
+	}
+
+	fmt.Printf("%s version %s of '%s'\n", done, versionString(versioned.Version), resource.ResourceName)
+
+	return nil
+}
+
+var nextPageLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// findResourceVersion pages through a resource's versions, newest first,
+// for the one with every field of the given version.
+func findResourceVersion(target rc.Target, resource flaghelpers.ResourceFlag, version atc.Version) (atc.VersionedResource, bool, error) {
+	client := target.Client()
+	pageURL := pipelineAPIURL(target, resource.PipelineName, "resources", resource.ResourceName, "versions") + "?limit=100"
+
+	for pageURL != "" {
+		response, err := client.HTTPClient().Get(pageURL)
+		if err != nil {
+			return atc.VersionedResource{}, false, err
+		}
+
+		if response.StatusCode == http.StatusNotFound {
+			response.Body.Close()
+			return atc.VersionedResource{}, false, rc.NewErrNotFound("pipeline or resource", resource.PipelineName+"/"+resource.ResourceName)
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return atc.VersionedResource{}, false, fmt.Errorf("failed to list versions: %s", response.Status)
+		}
+
+		var versions []atc.VersionedResource
+		err = json.NewDecoder(response.Body).Decode(&versions)
+		response.Body.Close()
+		if err != nil {
+			return atc.VersionedResource{}, false, err
+		}
+
+		for _, versioned := range versions {
+			if versionMatches(versioned.Version, version) {
+				return versioned, true, nil
+			}
+		}
+
+		pageURL = ""
+		if match := nextPageLink.FindStringSubmatch(response.Header.Get("Link")); match != nil {
+			// the link is to the ATC's external URL, which may not be the
+			// one we were given
+			next, err := url.Parse(match[1])
+			if err != nil {
+				return atc.VersionedResource{}, false, err
+			}
+
+			pageURL = strings.TrimRight(client.URL(), "/") + next.RequestURI()
+		}
+	}
+
+	return atc.VersionedResource{}, false, nil
+}
+
+func versionMatches(version atc.Version, fields atc.Version) bool {
+	for key, value := range fields {
+		if version[key] != value {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/httpcache"
+	"github.com/concourse/fly/rc"
+)
+
+// cachingCommands are the listing commands whose responses are cached, e.g.
+// for shell prompts and completion. Other commands, e.g. wait-for-job, must
+// see changes as soon as they happen.
+var cachingCommands = map[string]bool{
+	"workers":   true,
+	"pipelines": true,
+	"jobs":      true,
+}
+
+var responseCacheRules = []httpcache.Rule{
+	{Path: regexp.MustCompile(`^/api/v1/workers$`), TTL: 10 * time.Second},
+	{Path: regexp.MustCompile(`^/api/v1/(teams/[^/]+/)?pipelines$`), TTL: 10 * time.Second},
+	{Path: regexp.MustCompile(`^/api/v1/teams/[^/]+/pipelines/[^/]+/jobs$`), TTL: 5 * time.Second},
+	{Path: regexp.MustCompile(`^/api/v1/teams/[^/]+/pipelines/[^/]+/config$`), TTL: 5 * time.Second},
+}
+
+// ResponseCacheMiddleware caches the responses of listing commands and shell
+// completion on disk for a few seconds, revalidating them with their ETags
+// afterwards, unless --no-cache is given.
+func ResponseCacheMiddleware(activeCommand func() string) rc.Middleware {
+	return func(base http.RoundTripper) http.RoundTripper {
+		return responseCacheTransport{
+			base:          base,
+			activeCommand: activeCommand,
+			cache: &httpcache.Transport{
+				Base:  base,
+				Dir:   rc.ResponseCacheDir(),
+				Rules: responseCacheRules,
+			},
+		}
+	}
+}
+
+type responseCacheTransport struct {
+	base          http.RoundTripper
+	cache         http.RoundTripper
+	activeCommand func() string
+}
+
+func (transport responseCacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method == "GET" && (Fly.NoCache || !(cachingCommands[transport.activeCommand()] || completing())) {
+		return transport.base.RoundTrip(request)
+	}
+
+	// requests that change anything go through the cache whatever the
+	// command, so that it's emptied of what they may have changed
+	return transport.cache.RoundTrip(request)
+}
+
+// completing says whether fly's been run by the shell to complete an
+// argument rather than to run a command.
+func completing() bool {
+	return os.Getenv("GO_FLAGS_COMPLETION") != ""
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/concourse/fly/commands/internal/displayhelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/mattn/go-isatty"
+	"github.com/vito/go-interact/interact"
+)
+
+// ensureSessionLasts warns if the target's token will expire before an
+// operation expected to take the given duration finishes, rather than
+// letting it fail partway through. When run interactively, it offers to log
+// in again and returns the re-loaded target.
+func ensureSessionLasts(target rc.Target, expected time.Duration) (rc.Target, error) {
+	if expected <= 0 {
+		return target, nil
+	}
+
+	expiry, ok := target.Token().ExpiresAt()
+	if !ok {
+		return target, nil
+	}
+
+	remaining := expiry.Sub(time.Now())
+	if remaining >= expected {
+		return target, nil
+	}
+
+	displayhelpers.PrintWarningHeader()
+
+	if remaining <= 0 {
+		fmt.Fprintf(ui.Stderr, "your token for target '%s' has expired\n\n", Fly.Target)
+	} else {
+		fmt.Fprintf(
+			ui.Stderr,
+			"your token for target '%s' expires in %s, which may be before this finishes (expected duration: %s)\n\n",
+			Fly.Target,
+			remaining-remaining%time.Second,
+			expected,
+		)
+	}
+
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Fprintf(ui.Stderr, "to avoid failing partway through, run the following first:\n\n    fly -t %s login\n\n", Fly.Target)
+		return target, nil
+	}
+
+	relogin := false
+	err := interact.NewInteraction("log in again now?").Resolve(&relogin)
+	if err != nil || !relogin {
+		return target, err
+	}
+
+	login := &LoginCommand{}
+	err = login.Execute(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return rc.LoadTarget(Fly.Target, Fly.Verbose)
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/concourse/fly/rc"
+)
+
+type SetDefaultsCommand struct {
+	Pipeline string `short:"p" long:"pipeline" description:"Pipeline for commands like jobs to use when not given -p"`
+	Job      string `short:"j" long:"job"      description:"Job of the pipeline for commands like builds and trigger-job to use when not given -j"`
+}
+
+func (command *SetDefaultsCommand) Execute([]string) error {
+	if Fly.Target == "" {
+		return rc.ErrNoTargetSpecified
+	}
+
+	if command.Job != "" && command.Pipeline == "" {
+		return errors.New("a default job needs a default pipeline")
+	}
+
+	err := rc.SaveDefaults(Fly.Target, rc.Defaults{
+		Pipeline: command.Pipeline,
+		Job:      command.Job,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case command.Job != "":
+		fmt.Printf("target %s now defaults to %s/%s\n", Fly.Target, command.Pipeline, command.Job)
+	case command.Pipeline != "":
+		fmt.Printf("target %s now defaults to pipeline %s\n", Fly.Target, command.Pipeline)
+	default:
+		fmt.Printf("cleared the defaults of target %s\n", Fly.Target)
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"errors"
+
 	"github.com/concourse/atc"
 	"github.com/concourse/atc/web"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
@@ -13,15 +15,22 @@ type SetPipelineCommand struct {
 	SkipInteractive bool `short:"n"  long:"non-interactive"               description:"Skips interactions, uses default values"`
 
 	Pipeline flaghelpers.PipelineFlag `short:"p"  long:"pipeline"  required:"true"  description:"Pipeline to configure"`
-	Config   atc.PathFlag             `short:"c"  long:"config"    required:"true"  description:"Pipeline configuration file"`
+	Config   atc.PathFlag             `short:"c"  long:"config"    required:"true"  description:"Pipeline configuration file, or - to read it from stdin"`
 
 	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in the pipeline"`
 	YAMLVar []flaghelpers.YAMLVariablePairFlag `short:"y"  long:"yaml-var"  value-name:"[NAME=YAML]"    description:"Specify a YAML value to set for a variable in the pipeline"`
 
 	VarsFrom []atc.PathFlag `short:"l"  long:"load-vars-from"  description:"Variable flag that can be used for filling in template values in configuration from a YAML file"`
+
+	DeferVar  []string `long:"defer-var"   value-name:"NAME"  description:"Variable left for the credential manager to provide; may be a glob, e.g. aws-* (can be specified multiple times)"`
+	CheckVars bool     `long:"check-vars"                     description:"Fail if a variable is neither given nor deferred, or is given but never used"`
 }
 
 func (command *SetPipelineCommand) Validate() error {
+	if command.Config == setpipelinehelpers.StdinPath && !command.SkipInteractive {
+		return errors.New("reading the config from stdin needs --non-interactive, as stdin can't also be used to confirm it")
+	}
+
 	return command.Pipeline.Validate()
 }
 
@@ -51,6 +60,8 @@ func (command *SetPipelineCommand) Execute(args []string) error {
 		PipelineName:        pipelineName,
 		WebRequestGenerator: webRequestGenerator,
 		SkipInteraction:     command.SkipInteractive,
+		DeferredVars:        command.DeferVar,
+		CheckVars:           command.CheckVars,
 	}
 
 	return atcConfig.Set(configPath, command.Var, command.YAMLVar, templateVariablesFiles)
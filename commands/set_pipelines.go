@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/setpipelinehelpers"
+	"github.com/concourse/fly/rc"
+)
+
+type SetPipelinesCommand struct {
+	SkipInteractive bool `short:"n"  long:"non-interactive"               description:"Skips interactions, uses default values"`
+
+	Dir string `short:"d"  long:"dir"  required:"true"  value-name:"DIR"  description:"Directory of pipeline configs; NAME.yml configures the pipeline NAME, with the vars in NAME.vars.yml"`
+
+	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in every pipeline"`
+	YAMLVar []flaghelpers.YAMLVariablePairFlag `short:"y"  long:"yaml-var"  value-name:"[NAME=YAML]"    description:"Specify a YAML value to set for a variable in every pipeline"`
+
+	VarsFrom []atc.PathFlag `short:"l"  long:"load-vars-from"  description:"Variable flag that can be used for filling in template values in every pipeline's configuration from a YAML file"`
+}
+
+func (command *SetPipelinesCommand) Execute(args []string) error {
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	pipelines, err := setpipelinehelpers.DiscoverPipelines(command.Dir, command.VarsFrom)
+	if err != nil {
+		return err
+	}
+
+	if len(pipelines) == 0 {
+		return fmt.Errorf("no pipeline configs found in %s", command.Dir)
+	}
+
+	atcConfig := setpipelinehelpers.ATCConfig{
+		Team:            target.Team(),
+		SkipInteraction: command.SkipInteractive,
+	}
+
+	_, err = atcConfig.SetAll(pipelines, command.Var, command.YAMLVar)
+	return err
+}
@@ -3,10 +3,10 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/atc/auth/provider"
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
@@ -122,7 +122,7 @@ func (command *SetTeamCommand) ValidateFlags() error {
 			fmt.Fprintln(ui.Stderr, "    "+ui.Embolden("fly -t %s set-team -n %s --no-really-i-dont-want-any-auth", Fly.Target, command.TeamName))
 			fmt.Fprintln(ui.Stderr, "")
 			fmt.Fprintln(ui.Stderr, "this will leave the team open to anyone to mess with!")
-			os.Exit(1)
+			atexit.Exit(1)
 		}
 
 		displayhelpers.PrintWarningHeader()
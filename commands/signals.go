@@ -0,0 +1,11 @@
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminationSignals are what fly aborts or detaches from builds on. On
+// Windows, Ctrl-C and Ctrl-Break in cmd.exe or PowerShell arrive as
+// os.Interrupt, and closing the console window as SIGTERM.
+var terminationSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
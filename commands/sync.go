@@ -1,7 +1,12 @@
 package commands
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"runtime"
 	"strconv"
 
@@ -10,11 +15,22 @@ import (
 	"github.com/concourse/fly/version"
 	update "github.com/inconshreveable/go-update"
 
+	"github.com/concourse/atc"
 	"github.com/concourse/fly/commands/internal/displayhelpers"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
 )
 
-type SyncCommand struct{}
+const (
+	// an ATC may publish the hex-encoded SHA256 of the binary it serves, and
+	// a base64-encoded ECDSA signature of it, in these headers
+	cliChecksumHeader  = "X-Fly-SHA256"
+	cliSignatureHeader = "X-Fly-Signature"
+)
+
+type SyncCommand struct {
+	PublicKey atc.PathFlag `long:"public-key" value-name:"PATH" description:"PEM-encoded ECDSA public key the downloaded binary must be signed with"`
+}
 
 func (command *SyncCommand) Execute(args []string) error {
 	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
@@ -44,6 +60,11 @@ func (command *SyncCommand) Execute(args []string) error {
 		return err
 	}
 
+	err = command.verifyOptions(&updateOptions, headers)
+	if err != nil {
+		displayhelpers.FailWithErrorf("update failed", err)
+	}
+
 	fmt.Printf("downloading fly from %s... \n", client.URL())
 
 	filesSize, _ := strconv.ParseInt(headers.Get("Content-Length"), 10, 64)
@@ -60,3 +81,59 @@ func (command *SyncCommand) Execute(args []string) error {
 
 	return nil
 }
+
+// verifyOptions configures the update to check the downloaded binary against
+// the checksum published by the ATC, if any, and against its signature if
+// given the key to verify it with. The binary is only swapped in once these
+// checks pass.
+//
+// The checksum comes with the binary over the same connection, so it only
+// catches a download that was cut short or corrupted; a malicious proxy can
+// replace both. Only the signature, checked with --public-key, shows the
+// binary is the ATC's.
+func (command *SyncCommand) verifyOptions(updateOptions *update.Options, headers http.Header) error {
+	checksum := headers.Get(cliChecksumHeader)
+	if checksum != "" {
+		sum, err := hex.DecodeString(checksum)
+		if err != nil {
+			return fmt.Errorf("invalid checksum published by the ATC: %s", err)
+		}
+
+		updateOptions.Checksum = sum
+	}
+
+	signature := headers.Get(cliSignatureHeader)
+
+	if command.PublicKey == "" {
+		if signature != "" {
+			fmt.Fprintln(ui.Stderr, "the ATC signs fly; pass --public-key to verify its signature")
+		} else if checksum == "" {
+			fmt.Fprintln(ui.Stderr, "warning: the ATC publishes neither a checksum nor a signature for fly, so the download can't be verified")
+		}
+
+		return nil
+	}
+
+	publicKey, err := ioutil.ReadFile(string(command.PublicKey))
+	if err != nil {
+		return err
+	}
+
+	err = updateOptions.SetPublicKeyPEM(publicKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %s", err)
+	}
+
+	if signature == "" {
+		return errors.New("the ATC did not publish a signature for fly")
+	}
+
+	updateOptions.Signature, err = base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature published by the ATC: %s", err)
+	}
+
+	updateOptions.Verifier = update.NewECDSAVerifier()
+
+	return nil
+}
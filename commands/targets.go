@@ -3,12 +3,10 @@ package commands
 import (
 	"os"
 	"sort"
-	"strconv"
 	"time"
 
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/fatih/color"
 )
 
@@ -48,38 +46,10 @@ func (command *TargetsCommand) Execute([]string) error {
 }
 
 func GetExpirationFromString(token *rc.TargetToken) string {
-	if token == nil || token.Type == "" || token.Value == "" {
-		return "n/a"
-	}
-
-	parsedToken, _ := jwt.Parse(token.Value, func(token *jwt.Token) (interface{}, error) {
-		return "", nil
-	})
-
-	claims := parsedToken.Claims.(jwt.MapClaims)
-	expClaim, ok := claims["exp"]
+	expiry, ok := token.ExpiresAt()
 	if !ok {
 		return "n/a"
 	}
 
-	var intSeconds int64
-
-	floatSeconds, ok := expClaim.(float64)
-	if ok {
-		intSeconds = int64(floatSeconds)
-	} else {
-		stringSeconds, ok := expClaim.(string)
-		if !ok {
-			return "n/a"
-		}
-		var err error
-		intSeconds, err = strconv.ParseInt(stringSeconds, 10, 64)
-		if err != nil {
-			return "n/a"
-		}
-	}
-
-	unixSeconds := time.Unix(intSeconds, 0).UTC()
-
-	return unixSeconds.Format(time.RFC1123)
+	return expiry.Format(time.RFC1123)
 }
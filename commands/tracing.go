@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/tracing"
+	"github.com/concourse/fly/ui"
+)
+
+// EnableTracing traces each fly invocation, exporting its spans once fly
+// exits through Exit to the OTLP/HTTP collector configured by the standard
+// OpenTelemetry environment variables, i.e. OTEL_EXPORTER_OTLP_ENDPOINT (or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT), OTEL_EXPORTER_OTLP_HEADERS and
+// OTEL_SERVICE_NAME, if any. A TRACEPARENT in the environment, e.g. from the
+// CI job running fly, becomes the parent of fly's spans. activeCommand must
+// report the name of the command that ran.
+func EnableTracing(activeCommand func() string) error {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint == "" {
+		base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		if base == "" {
+			return nil
+		}
+
+		endpoint = strings.TrimRight(base, "/") + "/v1/traces"
+	}
+
+	exporter := tracing.Exporter{
+		Endpoint:    endpoint,
+		Headers:     tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")),
+		ServiceName: os.Getenv("OTEL_SERVICE_NAME"),
+	}
+
+	tracer := tracing.Enable("fly", os.Getenv("TRACEPARENT"))
+
+	atexit.Register(func(code int) {
+		root := tracer.Root()
+		if command := activeCommand(); command != "" {
+			root.SetName("fly " + command)
+			root.SetAttribute("fly.command", command)
+		}
+
+		root.SetAttribute("fly.target", string(Fly.Target))
+		root.SetAttribute("fly.exit_code", strconv.Itoa(code))
+
+		if code != 0 {
+			root.Fail(fmt.Errorf("exited %d", code))
+		}
+
+		err := exporter.Export(tracer.Finish())
+		if err != nil {
+			fmt.Fprintln(ui.Stderr, "failed to export traces:", err)
+		}
+	})
+
+	return nil
+}
@@ -4,21 +4,29 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"syscall"
 
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
-	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 )
 
 type TriggerJobCommand struct {
-	Job   flaghelpers.JobFlag `short:"j" long:"job" required:"true" value-name:"PIPELINE/JOB" description:"Name of a job to trigger"`
-	Watch bool                `short:"w" long:"watch" description:"Start watching the build output"`
+	Job   flaghelpers.JobFlag `short:"j" long:"job" value-name:"PIPELINE/JOB" description:"Name of a job to trigger, by default the one in .fly.yml or set with set-defaults"`
+	Watch bool                `short:"w" long:"watch" description:"Start watching the build output, exiting with the build's status as execute does"`
 }
 
 func (command *TriggerJobCommand) Execute(args []string) error {
-	pipelineName, jobName := command.Job.PipelineName, command.Job.JobName
+	job, found, err := defaultJob(command.Job)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return fmt.Errorf("no job given; pass -j, or set a default with set-defaults or %s", rc.DefaultsFileName)
+	}
+
+	pipelineName, jobName := job.PipelineName, job.JobName
 
 	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
 	if err != nil {
@@ -44,22 +52,23 @@ func (command *TriggerJobCommand) Execute(args []string) error {
 			fmt.Fprintf(ui.Stderr, "\ndetached, build is still running...\n")
 			fmt.Fprintf(ui.Stderr, "re-attach to it with:\n\n")
 			fmt.Fprintf(ui.Stderr, "    "+ui.Embolden(fmt.Sprintf("fly -t %s watch -j %s/%s -b %s\n\n", Fly.Target, pipelineName, jobName, build.Name)))
-			os.Exit(2)
+			atexit.Exit(2)
 		}(terminate)
 
-		signal.Notify(terminate, syscall.SIGINT, syscall.SIGTERM)
+		signal.Notify(terminate, terminationSignals...)
 
 		fmt.Println("")
-		eventSource, err := target.Client().BuildEvents(fmt.Sprintf("%d", build.ID))
+
+		// rendered as fly watch would, exiting as fly execute does: with the
+		// exit status of a failed task, or 1, 2 or 3 if the build failed,
+		// errored or was aborted
+		watch := &WatchCommand{}
+		exitCode, err := watch.watch(target.Client(), nil, nil, &watchedBuild{id: build.ID}, os.Stdout)
 		if err != nil {
 			return err
 		}
 
-		exitCode := eventstream.Render(os.Stdout, eventSource)
-
-		eventSource.Close()
-
-		os.Exit(exitCode)
+		atexit.Exit(exitCode)
 	}
 
 	return nil
@@ -27,7 +27,7 @@ func (command *UnpauseResourceCommand) Execute(args []string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("pipeline '%s' or resource '%s' not found\n", command.Resource.PipelineName, command.Resource.ResourceName)
+		return rc.NewErrNotFound("pipeline or resource", command.Resource.PipelineName+"/"+command.Resource.ResourceName)
 	}
 
 	fmt.Printf("unpaused '%s'\n", command.Resource.ResourceName)
@@ -7,7 +7,7 @@ import (
 )
 
 type ValidatePipelineCommand struct {
-	Config atc.PathFlag `short:"c" long:"config" required:"true"        description:"Pipeline configuration file"`
+	Config atc.PathFlag `short:"c" long:"config" required:"true"        description:"Pipeline configuration file, or - to read it from stdin"`
 	Strict bool         `short:"s" long:"strict"                        description:"Fail on warnings"`
 
 	Var     []flaghelpers.VariablePairFlag     `short:"v"  long:"var"       value-name:"[NAME=STRING]"  description:"Specify a string value to set for a variable in the pipeline"`
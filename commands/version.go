@@ -2,14 +2,14 @@ package commands
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/concourse/fly/commands/internal/atexit"
 	"github.com/concourse/fly/version"
 )
 
 func init() {
 	Fly.Version = func() {
 		fmt.Println(version.Version)
-		os.Exit(0)
+		atexit.Exit(0)
 	}
 }
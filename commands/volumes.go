@@ -10,13 +10,16 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/fatih/color"
 )
 
 type VolumesCommand struct {
-	Details bool `short:"d" long:"details" description:"Print additional information for each volume"`
+	Details  bool   `short:"d" long:"details" description:"Print additional information for each volume"`
+	Format   string `long:"format" value-name:"TEMPLATE" description:"Print each volume with a Go template, e.g. '{{.ID}} {{.Type}}'"`
+	JSONPath string `long:"jsonpath" value-name:"PATH" description:"Print the value at a JSONPath of each volume, e.g. '{.id}'"`
 }
 
 func (command *VolumesCommand) Execute([]string) error {
@@ -30,11 +33,20 @@ func (command *VolumesCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
 	volumes, err := target.Client().ListVolumes()
 	if err != nil {
 		return err
 	}
 
+	if formatter != nil {
+		return formatter.Print(os.Stdout, volumes)
+	}
+
 	table := ui.Table{
 		Headers: ui.TableRow{
 			{Contents: "handle", Color: color.New(color.Bold)},
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+type WaitForJobCommand struct {
+	Job      flaghelpers.JobFlag `short:"j" long:"job"      required:"true" value-name:"PIPELINE/JOB" description:"Name of a job to wait for"`
+	Timeout  time.Duration       `          long:"timeout"                  value-name:"DURATION"     description:"Give up if the build hasn't finished after this long (default: wait forever)"`
+	Interval time.Duration       `          long:"interval" default:"5s"    value-name:"DURATION"     description:"How often to check for a new build of the job"`
+}
+
+// Execute waits for the job to start a build newer than any it has now,
+// e.g. once a commit that triggers it is pushed, watches the build as fly
+// watch would, and exits with its status as fly execute does.
+func (command *WaitForJobCommand) Execute([]string) error {
+	pipelineName, jobName := command.Job.PipelineName, command.Job.JobName
+
+	target, err := rc.LoadTarget(Fly.Target, Fly.Verbose)
+	if err != nil {
+		return err
+	}
+
+	err = target.Validate()
+	if err != nil {
+		return err
+	}
+
+	var timedOut <-chan time.Time
+	if command.Timeout > 0 {
+		timedOut = time.After(command.Timeout)
+	}
+
+	job, err := command.job(target.Team())
+	if err != nil {
+		return err
+	}
+
+	seen := latestBuildID(job)
+
+	fmt.Printf("waiting for a new build of %s/%s\n", pipelineName, jobName)
+
+	var build *atc.Build
+	for build == nil {
+		select {
+		case <-timedOut:
+			return fmt.Errorf("timed out after %s waiting for a new build of %s/%s", command.Timeout, pipelineName, jobName)
+		case <-time.After(command.Interval):
+		}
+
+		job, err = command.job(target.Team())
+		if err != nil {
+			return err
+		}
+
+		if latestBuildID(job) > seen {
+			build = job.NextBuild
+			if build == nil {
+				build = job.FinishedBuild
+			}
+		}
+	}
+
+	fmt.Printf("started %s/%s #%s\n\n", pipelineName, jobName, build.Name)
+
+	type result struct {
+		exitCode int
+		err      error
+	}
+
+	watched := make(chan result, 1)
+	go func() {
+		watch := &WatchCommand{}
+		exitCode, err := watch.watch(target.Client(), nil, nil, &watchedBuild{id: build.ID}, os.Stdout)
+		watched <- result{exitCode, err}
+	}()
+
+	select {
+	case <-timedOut:
+		return fmt.Errorf("timed out after %s waiting for %s/%s #%s to finish", command.Timeout, pipelineName, jobName, build.Name)
+	case result := <-watched:
+		if result.err != nil {
+			return result.err
+		}
+
+		atexit.Exit(result.exitCode)
+	}
+
+	return nil
+}
+
+func (command *WaitForJobCommand) job(team concourse.Team) (atc.Job, error) {
+	job, found, err := team.Job(command.Job.PipelineName, command.Job.JobName)
+	if err != nil {
+		return atc.Job{}, err
+	}
+
+	if !found {
+		return atc.Job{}, rc.NewErrNotFound("pipeline or job", command.Job.PipelineName+"/"+command.Job.JobName)
+	}
+
+	return job, nil
+}
+
+// latestBuildID is the ID of the newest build of the job, running or not.
+func latestBuildID(job atc.Job) int {
+	id := 0
+	for _, build := range []*atc.Build{job.NextBuild, job.FinishedBuild} {
+		if build != nil && build.ID > id {
+			id = build.ID
+		}
+	}
+
+	return id
+}
@@ -1,18 +1,59 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/concourse/fly/commands/internal/atexit"
+	"github.com/concourse/fly/commands/internal/cioutput"
 	"github.com/concourse/fly/commands/internal/flaghelpers"
+	"github.com/concourse/fly/commands/internal/multibuild"
+	"github.com/concourse/fly/commands/internal/notify"
+	"github.com/concourse/fly/commands/internal/pushmetrics"
+	"github.com/concourse/fly/commands/internal/statshelpers"
+	"github.com/concourse/fly/commands/internal/tracing"
 	"github.com/concourse/fly/eventstream"
 	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
 )
 
 type WatchCommand struct {
-	Job   flaghelpers.JobFlag `short:"j" long:"job"   value-name:"PIPELINE/JOB"   description:"Watches builds of the given job"`
-	Build string              `short:"b" long:"build"                               description:"Watches a specific build"`
+	Job              flaghelpers.JobFlag      `short:"j" long:"job"               value-name:"PIPELINE/JOB"   description:"Watches builds of the given job, or of every job matching a glob, e.g. main/test-* or release-*/deploy"`
+	Builds           []string                 `short:"b" long:"build"                                         description:"Watches a specific build, which may be given as PIPELINE/JOB#BUILD (can be specified multiple times)"`
+	MaxLogSize       flaghelpers.ByteSizeFlag `          long:"max-log-size"      value-name:"SIZE"           description:"Stop printing the build's logs after SIZE bytes (e.g. 100MB)"`
+	Stats            bool                     `          long:"stats"                                         description:"Show the CPU and memory usage of the build's task containers while it runs"`
+	JUnitOutput      string                   `          long:"junit-output"      value-name:"PATH"           description:"Write a JUnit XML report of the build's steps to PATH once it completes"`
+	JUnitTAP         bool                     `          long:"junit-tap"                                     description:"Also report the TAP test results in the build's output in the JUnit report"`
+	ExpectedDuration time.Duration            `          long:"expected-duration" value-name:"DURATION"       description:"Warn if your token expires sooner than this (0 to disable)" default:"1h"`
+	PushMetrics      string                   `          long:"push-metrics"      value-name:"URL"            description:"Push how the builds went to the Prometheus Pushgateway at URL once they finish"`
+	NotifyURL        string                   `          long:"notify-url"        value-name:"URL"            description:"Post to the webhook at URL once each build finishes"`
+	NotifyFormat     string                   `          long:"notify-format"     value-name:"FORMAT"         description:"Payload to post to --notify-url: the build's fields as json, or a Slack message" choice:"json" choice:"slack" default:"json"`
+	NotifyTemplate   string                   `          long:"notify-template"   value-name:"TEMPLATE"       description:"Go template of the payload to post to --notify-url instead, given the build's fields"`
+	JSON             bool                     `          long:"json"                                          description:"Print the build's events as lines of json rather than rendering them"`
+	Timestamps       bool                     `          long:"timestamps"                                    description:"Prefix each line of the build's logs with when it was received and how long after the build started"`
+	LogFile          string                   `          long:"log-file"          value-name:"PATH"           description:"Also write the build's events to PATH as lines of json, with when they were received, to show again with fly replay"`
+	CIOutput         bool                     `          long:"ci-output"                                     description:"Write the builds' IDs, URLs and statuses for later steps of the GitHub Actions or GitLab CI job running fly"`
+}
+
+// watchedBuild is a build being watched, with the label its output is
+// prefixed with when watching several.
+type watchedBuild struct {
+	id    int
+	label string
+
+	exitCode int
+
+	// logFile is where the build's events are written, as asked by
+	// --log-file
+	logFile io.Writer
 }
 
 func (command *WatchCommand) Execute(args []string) error {
@@ -26,32 +67,339 @@ func (command *WatchCommand) Execute(args []string) error {
 		return err
 	}
 
-	var buildId int
+	target, err = ensureSessionLasts(target, command.ExpectedDuration)
+	if err != nil {
+		return err
+	}
+
+	var notifier *notify.Notifier
+	if command.NotifyURL != "" {
+		notifier, err = notify.New(command.NotifyURL, command.NotifyFormat, command.NotifyTemplate)
+		if err != nil {
+			return err
+		}
+	}
+
 	client := target.Client()
-	if command.Job.JobName != "" || command.Build == "" {
-		build, err := GetBuild(client, target.Team(), command.Job.JobName, command.Build, command.Job.PipelineName)
+
+	builds, err := command.builds(client, target.Team())
+	if err != nil {
+		return err
+	}
+
+	if command.JSON && len(builds) > 1 {
+		return errors.New("--json can only be used when watching a single build, as the events of several would be interleaved")
+	}
+
+	if command.LogFile != "" {
+		if len(builds) > 1 {
+			return errors.New("--log-file can only be used when watching a single build, as the events of several would be interleaved")
+		}
+
+		logFile, err := os.Create(command.LogFile)
 		if err != nil {
 			return err
 		}
-		buildId = build.ID
-	} else if command.Build != "" {
-		buildId, err = strconv.Atoi(command.Build)
 
+		defer logFile.Close()
+
+		builds[0].logFile = logFile
+	}
+
+	var junit *eventstream.JUnitReport
+	if command.JUnitOutput != "" {
+		junit = &eventstream.JUnitReport{ParseTAP: command.JUnitTAP}
+	}
+
+	var stats statshelpers.Hijacker
+	if command.Stats {
+		stats = newStatsHijacker(target)
+	}
+
+	if len(builds) == 1 {
+		exitCode, err := command.watch(client, stats, junit, builds[0], os.Stdout)
 		if err != nil {
 			return err
 		}
+
+		builds[0].exitCode = exitCode
+
+		if junit != nil {
+			writeJUnitReport(command.JUnitOutput, junit)
+		}
+
+		command.report(client, builds, notifier)
+
+		atexit.Exit(exitCode)
+
+		return nil
+	}
+
+	// every build's events are streamed at once, each line prefixed with
+	// the build it came from
+	labels := make([]string, len(builds))
+	for i, build := range builds {
+		labels[i] = build.label
+	}
+
+	exitCodes := multibuild.Follow(os.Stdout, labels, func(i int, out io.Writer) (int, error) {
+		return command.watch(client, stats, junit, builds[i], out)
+	})
+
+	for i, build := range builds {
+		build.exitCode = exitCodes[i]
+	}
+
+	if junit != nil {
+		writeJUnitReport(command.JUnitOutput, junit)
 	}
 
-	eventSource, err := client.BuildEvents(fmt.Sprintf("%d", buildId))
+	command.report(client, builds, notifier)
+
+	// fail with the exit status of the first build that failed
+	summary := make([]multibuild.Build, len(builds))
+	for i, build := range builds {
+		summary[i] = multibuild.Build{Label: build.label, ID: build.id, ExitCode: build.exitCode}
+	}
+
+	exitCode, err := multibuild.Summarize(os.Stdout, summary, Fly.PrintTableHeaders)
 	if err != nil {
 		return err
 	}
 
-	exitCode := eventstream.Render(os.Stdout, eventSource)
+	atexit.Exit(exitCode)
 
-	eventSource.Close()
+	return nil
+}
 
-	os.Exit(exitCode)
+// builds returns the builds to watch: those given, the current build of
+// each job matching --job, or else the latest one-off build.
+func (command *WatchCommand) builds(client concourse.Client, team concourse.Team) ([]*watchedBuild, error) {
+	if strings.ContainsAny(command.Job.PipelineName+command.Job.JobName, "*?[") {
+		if len(command.Builds) > 0 {
+			return nil, errors.New("builds cannot be given along with a glob of jobs")
+		}
 
-	return nil
+		return command.matchingJobBuilds(team)
+	}
+
+	buildNamesOrIDs := command.Builds
+	if len(buildNamesOrIDs) == 0 {
+		buildNamesOrIDs = []string{""}
+	}
+
+	var builds []*watchedBuild
+	for _, buildNameOrID := range buildNamesOrIDs {
+		job, buildName, err := jobBuild(command.Job, buildNameOrID)
+		if err != nil {
+			return nil, err
+		}
+
+		if job.JobName == "" && buildName != "" {
+			buildID, err := strconv.Atoi(buildName)
+			if err != nil {
+				return nil, err
+			}
+
+			builds = append(builds, &watchedBuild{id: buildID, label: "build " + buildName})
+			continue
+		}
+
+		build, err := GetBuild(client, team, job.JobName, buildName, job.PipelineName)
+		if err != nil {
+			return nil, err
+		}
+
+		label := "build " + strconv.Itoa(build.ID)
+		if job.JobName != "" {
+			label = fmt.Sprintf("%s/%s #%s", job.PipelineName, job.JobName, build.Name)
+		}
+
+		builds = append(builds, &watchedBuild{id: build.ID, label: label})
+	}
+
+	return builds, nil
+}
+
+func (command *WatchCommand) matchingJobBuilds(team concourse.Team) ([]*watchedBuild, error) {
+	pipelineNames, err := command.matchingPipelines(team)
+	if err != nil {
+		return nil, err
+	}
+
+	var builds []*watchedBuild
+	for _, pipelineName := range pipelineNames {
+		jobs, err := team.ListJobs(pipelineName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, job := range jobs {
+			matched, err := path.Match(command.Job.JobName, job.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid glob of jobs: %s", err)
+			}
+
+			if !matched {
+				continue
+			}
+
+			build := job.NextBuild
+			if build == nil {
+				build = job.FinishedBuild
+			}
+
+			if build == nil {
+				continue
+			}
+
+			builds = append(builds, &watchedBuild{
+				id:    build.ID,
+				label: fmt.Sprintf("%s/%s #%s", pipelineName, job.Name, build.Name),
+			})
+		}
+	}
+
+	if len(builds) == 0 {
+		return nil, fmt.Errorf("no builds of jobs matching %s/%s", command.Job.PipelineName, command.Job.JobName)
+	}
+
+	return builds, nil
+}
+
+// matchingPipelines returns the pipeline given by --job, or if it's a glob,
+// every pipeline of the team matching it.
+func (command *WatchCommand) matchingPipelines(team concourse.Team) ([]string, error) {
+	if !strings.ContainsAny(command.Job.PipelineName, "*?[") {
+		return []string{command.Job.PipelineName}, nil
+	}
+
+	pipelines, err := team.ListPipelines()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, pipeline := range pipelines {
+		matched, err := path.Match(command.Job.PipelineName, pipeline.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob of pipelines: %s", err)
+		}
+
+		if matched {
+			names = append(names, pipeline.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// watch renders the events of a build to out, returning the exit status of
+// the build.
+func (command *WatchCommand) watch(
+	client concourse.Client,
+	stats statshelpers.Hijacker,
+	junit *eventstream.JUnitReport,
+	build *watchedBuild,
+	out io.Writer,
+) (int, error) {
+	span := tracing.Start("connect to event stream", "fly.build_id", strconv.Itoa(build.id))
+	eventSource, err := eventstream.Events(context.Background(), client, strconv.Itoa(build.id))
+	span.Fail(err)
+	span.End()
+	if err != nil {
+		return 0, err
+	}
+
+	defer eventSource.Close()
+
+	if stats != nil {
+		ctx, stopStats := context.WithCancel(context.Background())
+		defer stopStats()
+
+		go statshelpers.Poll(ctx, client, stats, build.id, ui.Stderr)
+	}
+
+	span = tracing.Start("wait for build", "fly.build_id", strconv.Itoa(build.id))
+	defer span.End()
+
+	exitCode := eventstream.RenderWithOptions(out, eventSource, eventstream.RenderOptions{
+		MaxLogBytes: int64(command.MaxLogSize),
+		StepSummary: true,
+		Containers:  stepContainers(client, build.id),
+		JUnit:       junit,
+		JUnitSuite:  fmt.Sprintf("build %d", build.id),
+		JSON:        command.JSON,
+		LogFile:     build.logFile,
+		Timestamps:  command.Timestamps,
+	})
+
+	span.SetAttribute("fly.exit_code", strconv.Itoa(exitCode))
+
+	return exitCode, nil
+}
+
+// report pushes metrics of and notifies about the finished builds, as asked
+// by --push-metrics, --notify-url and --ci-output, as the ATC reports them.
+func (command *WatchCommand) report(client concourse.Client, builds []*watchedBuild, notifier *notify.Notifier) {
+	if command.PushMetrics == "" && notifier == nil && !command.CIOutput {
+		return
+	}
+
+	var metrics []pushmetrics.Build
+	var results []cioutput.Build
+	for _, watched := range builds {
+		build := finishedBuild(client, watched.id, watched.exitCode)
+
+		var duration time.Duration
+		if build.StartTime != 0 && build.EndTime >= build.StartTime {
+			duration = time.Duration(build.EndTime-build.StartTime) * time.Second
+		}
+
+		metric := pushmetrics.Build{
+			ID:         watched.id,
+			Status:     build.Status,
+			ExitStatus: watched.exitCode,
+			Duration:   duration,
+		}
+
+		if len(builds) > 1 {
+			metric.Name = watched.label
+		}
+
+		metrics = append(metrics, metric)
+
+		results = append(results, cioutput.Build{ID: watched.id, URL: buildURL(client, build), Status: build.Status})
+
+		if notifier != nil {
+			err := notifier.Notify(notify.Build{
+				ID:         watched.id,
+				Name:       build.Name,
+				Team:       build.TeamName,
+				Pipeline:   build.PipelineName,
+				Job:        build.JobName,
+				Status:     build.Status,
+				ExitStatus: watched.exitCode,
+				URL:        buildURL(client, build),
+				Duration:   duration.Seconds(),
+			})
+			if err != nil {
+				fmt.Fprintf(ui.Stderr, "failed to notify %s: %s\n", command.NotifyURL, err)
+			}
+		}
+	}
+
+	if command.PushMetrics != "" {
+		err := pushmetrics.Push(command.PushMetrics, command.Job.PipelineName, command.Job.JobName, metrics, 0)
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to push metrics: %s\n", err)
+		}
+	}
+
+	if command.CIOutput {
+		err := cioutput.Write(results)
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "failed to write CI output: %s\n", err)
+		}
+	}
 }
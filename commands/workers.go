@@ -8,13 +8,16 @@ import (
 	"strings"
 
 	"github.com/concourse/atc"
+	"github.com/concourse/fly/commands/internal/formathelpers"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
 	"github.com/fatih/color"
 )
 
 type WorkersCommand struct {
-	Details bool `short:"d" long:"details" description:"Print additional information for each worker"`
+	Details  bool   `short:"d" long:"details" description:"Print additional information for each worker"`
+	Format   string `long:"format" value-name:"TEMPLATE" description:"Print each worker with a Go template, e.g. '{{.Name}} {{.State}}'"`
+	JSONPath string `long:"jsonpath" value-name:"PATH" description:"Print the value at a JSONPath of each worker, e.g. '{.name}'"`
 }
 
 func (command *WorkersCommand) Execute([]string) error {
@@ -28,6 +31,11 @@ func (command *WorkersCommand) Execute([]string) error {
 		return err
 	}
 
+	formatter, err := formathelpers.New(command.Format, command.JSONPath)
+	if err != nil {
+		return err
+	}
+
 	workers, err := target.Client().ListWorkers()
 	if err != nil {
 		return err
@@ -35,6 +43,10 @@ func (command *WorkersCommand) Execute([]string) error {
 
 	sort.Sort(byWorkerName(workers))
 
+	if formatter != nil {
+		return formatter.Print(os.Stdout, workers)
+	}
+
 	var runningWorkers []worker
 	var stalledWorkers []worker
 	var outdatedWorkers []worker
@@ -2,31 +2,104 @@ package config
 
 import (
 	"fmt"
-	"io/ioutil"
 	"syscall"
 
+	"github.com/cloudfoundry/bosh-cli/director/template"
 	"github.com/concourse/atc"
+	"gopkg.in/yaml.v2"
 )
 
-func LoadTaskConfig(configPath string, args []string) (atc.TaskConfig, error) {
-	configFile, err := ioutil.ReadFile(configPath)
+// TaskHooks are steps to run once an executed task finishes, like the hooks
+// of a task step in a pipeline, along with the tags of the workers to run it
+// on, as a task step's tags. They're given alongside the task's own config,
+// in the same file.
+type TaskHooks struct {
+	OnSuccess *atc.PlanConfig `yaml:"on_success,omitempty"`
+	OnFailure *atc.PlanConfig `yaml:"on_failure,omitempty"`
+
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+var hookKeys = []string{"on_success", "on_failure", "tags"}
+
+// LoadTask reads the task config at configPath, along with its hooks, merged
+// over the config it extends, if any. If vars is non-nil, any ((var))
+// references it can resolve are interpolated first; the rest are left for
+// the ATC to resolve, unless requireVars is set, in which case they're an
+// error listing them. If inheritEnv is set, the config's params are
+// overridden by the environment variables of the same name.
+func LoadTask(configPath string, args []string, vars template.Variables, requireVars bool, inheritEnv bool) (atc.TaskConfig, TaskHooks, error) {
+	configFile, err := readTaskConfig(configPath)
+	if err != nil {
+		return atc.TaskConfig{}, TaskHooks{}, fmt.Errorf("failed to read task config: %s", err)
+	}
+
+	if vars != nil {
+		configFile, err = template.NewTemplate(configFile).Evaluate(vars, nil, template.EvaluateOpts{ExpectAllKeys: requireVars})
+		if err != nil {
+			return atc.TaskConfig{}, TaskHooks{}, fmt.Errorf("failed to interpolate task config: %s", err)
+		}
+	}
+
+	var hooks TaskHooks
+	err = yaml.Unmarshal(configFile, &hooks)
 	if err != nil {
-		return atc.TaskConfig{}, fmt.Errorf("failed to read task config: %s", err)
+		return atc.TaskConfig{}, TaskHooks{}, fmt.Errorf("failed to parse task hooks: %s", err)
+	}
+
+	configFile, err = withoutHooks(configFile)
+	if err != nil {
+		return atc.TaskConfig{}, TaskHooks{}, err
 	}
 
 	config, err := atc.NewTaskConfig(configFile)
 	if err != nil {
-		return atc.TaskConfig{}, err
+		return atc.TaskConfig{}, TaskHooks{}, err
 	}
 
 	config.Run.Args = append(config.Run.Args, args...)
 
-	for k := range config.Params {
-		env, found := syscall.Getenv(k)
-		if found {
-			config.Params[k] = env
+	if inheritEnv {
+		for k := range config.Params {
+			env, found := syscall.Getenv(k)
+			if found {
+				config.Params[k] = env
+			}
+		}
+	}
+
+	return config, hooks, nil
+}
+
+// withoutHooks strips the hooks and tags from a task config, as they aren't
+// a part of the config the ATC knows about.
+func withoutHooks(configFile []byte) ([]byte, error) {
+	var fields yaml.MapSlice
+	err := yaml.Unmarshal(configFile, &fields)
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := yaml.MapSlice{}
+	for _, field := range fields {
+		if !isHookKey(field.Key) {
+			stripped = append(stripped, field)
+		}
+	}
+
+	if len(stripped) == len(fields) {
+		return configFile, nil
+	}
+
+	return yaml.Marshal(stripped)
+}
+
+func isHookKey(key interface{}) bool {
+	for _, hookKey := range hookKeys {
+		if key == hookKey {
+			return true
 		}
 	}
 
-	return config, nil
+	return false
 }
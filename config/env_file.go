@@ -0,0 +1,75 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadEnvFile reads the KEY=VALUE pairs of a .env file. Blank lines and
+// lines starting with # are skipped, a leading "export " is allowed, and a
+// value may be quoted, with double quotes taking Go's escapes. A key given
+// twice takes its last value.
+func LoadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	env := map[string]string{}
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		pair := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(pair[0])
+		if len(pair) != 2 || key == "" {
+			return nil, fmt.Errorf("%s:%d: must be KEY=VALUE", path, lineNumber)
+		}
+
+		value, err := unquote(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s", path, lineNumber, err)
+		}
+
+		env[key] = value
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+func unquote(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+
+	switch {
+	case value[0] == '"' && value[len(value)-1] == '"':
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid quoted value %s", value)
+		}
+
+		return unquoted, nil
+
+	case value[0] == '\'' && value[len(value)-1] == '\'':
+		return value[1 : len(value)-1], nil
+	}
+
+	return value, nil
+}
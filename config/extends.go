@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+const extendsKey = "extends"
+
+// readTaskConfig reads the task config at configPath. If it extends another
+// config, given relative to its own directory, it is merged over that one
+// field by field: maps are merged key by key, and anything else, including
+// lists, replaces what the base config has.
+func readTaskConfig(configPath string) ([]byte, error) {
+	configFile, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, base, err := parseExtends(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", configPath, err)
+	}
+
+	if base == "" {
+		return configFile, nil
+	}
+
+	merged, err := extend(configPath, fields, base, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(merged)
+}
+
+func extend(configPath string, fields yaml.MapSlice, base string, extending []string) (yaml.MapSlice, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range extending {
+		if path == absPath {
+			return nil, fmt.Errorf("%s: task configs extend each other in a cycle", configPath)
+		}
+	}
+
+	if !filepath.IsAbs(base) {
+		base = filepath.Join(filepath.Dir(configPath), base)
+	}
+
+	baseFile, err := ioutil.ReadFile(base)
+	if err != nil {
+		return nil, err
+	}
+
+	baseFields, baseBase, err := parseExtends(baseFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", base, err)
+	}
+
+	if baseBase != "" {
+		baseFields, err = extend(base, baseFields, baseBase, append(extending, absPath))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mergeFields(baseFields, fields), nil
+}
+
+// parseExtends returns the fields of a task config other than extends, and
+// the config it extends, if any.
+func parseExtends(configFile []byte) (yaml.MapSlice, string, error) {
+	var fields yaml.MapSlice
+	err := yaml.Unmarshal(configFile, &fields)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var base string
+
+	rest := yaml.MapSlice{}
+	for _, field := range fields {
+		if field.Key != extendsKey {
+			rest = append(rest, field)
+			continue
+		}
+
+		path, ok := field.Value.(string)
+		if !ok || path == "" {
+			return nil, "", fmt.Errorf("%s must be the path to a task config", extendsKey)
+		}
+
+		base = path
+	}
+
+	return rest, base, nil
+}
+
+func mergeFields(base yaml.MapSlice, overrides yaml.MapSlice) yaml.MapSlice {
+	merged := make(yaml.MapSlice, len(base))
+	copy(merged, base)
+
+	for _, override := range overrides {
+		i := fieldIndex(merged, override.Key)
+		if i < 0 {
+			merged = append(merged, override)
+			continue
+		}
+
+		baseMap, baseIsMap := merged[i].Value.(yaml.MapSlice)
+		overrideMap, overrideIsMap := override.Value.(yaml.MapSlice)
+
+		if baseIsMap && overrideIsMap {
+			merged[i].Value = mergeFields(baseMap, overrideMap)
+		} else {
+			merged[i].Value = override.Value
+		}
+	}
+
+	return merged
+}
+
+func fieldIndex(fields yaml.MapSlice, key interface{}) int {
+	for i, field := range fields {
+		if field.Key == key {
+			return i
+		}
+	}
+
+	return -1
+}
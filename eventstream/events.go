@@ -0,0 +1,467 @@
+package eventstream
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+)
+
+const DefaultMaxReconnects = 5
+
+// MaxReconnects is how many times in a row an event stream that dropped is
+// tried to be re-established before giving up.
+var MaxReconnects = DefaultMaxReconnects
+
+// ReconnectInterval is how long to wait before re-establishing an event
+// stream that dropped. It doubles with every failed attempt in a row, up to
+// MaxReconnectInterval, so as not to hammer an ATC that's struggling.
+var ReconnectInterval = time.Second
+
+var MaxReconnectInterval = 30 * time.Second
+
+// SleepCheckInterval is how often an event stream checks whether the machine
+// was asleep, e.g. with a laptop's lid closed, or the stream stalled. Its
+// connection has likely died while asleep without anything noticing, so it's
+// re-established at once.
+var SleepCheckInterval = 5 * time.Second
+
+// StallTimeout is how long an event stream may go without an event before
+// the build's status is polled instead, in case the stream was dropped
+// silently, e.g. by a proxy that kills idle connections during a long and
+// quiet build. 0 turns this off.
+var StallTimeout = 5 * time.Minute
+
+// StatusPollInterval is how often a build's status is polled while its event
+// stream is stalled or once it's given up on, so that how it went is still
+// known.
+var StatusPollInterval = 10 * time.Second
+
+// sleepThreshold is how long the machine must have been asleep for its
+// event streams to be re-established.
+const sleepThreshold = 10 * time.Second
+
+// asleepSince returns how long the machine was asleep since then: how far
+// the wall clock got ahead of the monotonic clock, which stops while asleep.
+var asleepSince = func(then time.Time) time.Duration {
+	now := time.Now()
+	return now.Round(0).Sub(then.Round(0)) - now.Sub(then)
+}
+
+//go:generate counterfeiter . EventSource
+
+type EventSource interface {
+	NextEvent() (atc.Event, error)
+	Close() error
+}
+
+type Handler func(atc.Event) error
+
+//go:generate counterfeiter . Transport
+
+// Transport opens a raw event stream for a build. Each call to Connect must
+// start a fresh stream from the build's first event.
+type Transport interface {
+	Connect(buildID string) (EventSource, error)
+}
+
+// SSETransport streams events from the ATC's server-sent events endpoint.
+type SSETransport struct {
+	Client concourse.Client
+}
+
+func (transport SSETransport) Connect(buildID string) (EventSource, error) {
+	return transport.Client.BuildEvents(buildID)
+}
+
+func (transport SSETransport) Build(buildID string) (atc.Build, bool, error) {
+	return transport.Client.Build(buildID)
+}
+
+// BuildPoller is implemented by transports that can look a build up, so that
+// its status can be polled once its event stream is given up on.
+type BuildPoller interface {
+	Build(buildID string) (atc.Build, bool, error)
+}
+
+// Events opens the event stream of a build over SSE. If the connection drops
+// before the stream ends or the machine was asleep, it is re-established and
+// the events that were already returned are skipped, so each event is seen
+// exactly once. It's given up on after MaxReconnects attempts in a row fail;
+// the build's status is then polled until it finishes, and returned as its
+// last event.
+//
+// If no event comes for StallTimeout, the build's status is polled until
+// events resume or it finishes; only then is the stream re-established, once,
+// for the events it missed.
+//
+// Events of a type or version this fly does not understand are skipped
+// rather than failing the stream, with a warning on stderr to upgrade fly.
+//
+// Cancelling ctx closes the stream; NextEvent then returns ctx.Err().
+func Events(ctx context.Context, client concourse.Client, buildID string) (EventSource, error) {
+	return EventsVia(ctx, SSETransport{Client: client}, buildID)
+}
+
+// EventsVia is like Events, but streams over the given transport.
+func EventsVia(ctx context.Context, transport Transport, buildID string) (EventSource, error) {
+	source := &resumingEventSource{
+		ctx:           ctx,
+		transport:     transport,
+		buildID:       buildID,
+		maxReconnects: MaxReconnects,
+		unparsable:    unparsableEvents{},
+		closed:        make(chan struct{}),
+	}
+
+	err := source.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	go source.closeOnCancel()
+	go source.watch()
+
+	return source, nil
+}
+
+// Each calls handler with every event from src until the stream ends or the
+// handler returns an error. Reaching the end of the stream is not an error.
+func Each(src EventSource, handler Handler) error {
+	for {
+		ev, err := src.NextEvent()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		err = handler(ev)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type resumingEventSource struct {
+	ctx       context.Context
+	transport Transport
+	buildID   string
+
+	maxReconnects int
+
+	streamL   sync.Mutex
+	stream    EventSource
+	seen      int
+	lastEvent time.Time
+
+	unparsable unparsableEvents
+
+	// restarted is set when the stream was closed on purpose, after the
+	// machine was asleep or the build finished while the stream stalled
+	restarted bool
+
+	// polled is set once the build's status was polled rather than streamed
+	polled bool
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (source *resumingEventSource) NextEvent() (atc.Event, error) {
+	if source.polled {
+		return nil, io.EOF
+	}
+
+	reconnects := 0
+
+	for {
+		ev, err := source.currentStream().NextEvent()
+		if source.ctx.Err() != nil {
+			return nil, source.ctx.Err()
+		}
+
+		if err == nil {
+			source.seen++
+			source.heardFrom()
+			return ev, nil
+		}
+
+		if source.unparsable.skip(err) {
+			source.seen++
+			continue
+		}
+
+		// the connection was dropped on waking or stalling, rather than
+		// failing, so it's re-established at once
+		restarted := source.takeRestarted()
+		if restarted {
+			reconnects = 0
+		} else if err == io.EOF {
+			return nil, err
+		}
+
+		source.currentStream().Close()
+
+		// e.g. the network may take a while to come back, so failing to
+		// reconnect is retried too
+		for {
+			if !restarted {
+				if reconnects >= source.maxReconnects {
+					return source.pollStatus(err)
+				}
+
+				reconnects++
+
+				wait := reconnectBackoff(reconnects)
+				if wait > 0 {
+					fmt.Fprintf(ui.Stderr, "lost the build's event stream (%s); reconnecting in %s (attempt %d of %d)\n", err, wait, reconnects, source.maxReconnects)
+				}
+
+				select {
+				case <-time.After(wait):
+				case <-source.ctx.Done():
+					return nil, source.ctx.Err()
+				}
+			}
+
+			restarted = false
+
+			err = source.reconnect()
+			if source.ctx.Err() != nil {
+				return nil, source.ctx.Err()
+			}
+
+			if err == nil {
+				break
+			}
+		}
+	}
+}
+
+// reconnectBackoff is how long to wait before the given attempt in a row to
+// reconnect, counting from 1.
+func reconnectBackoff(attempt int) time.Duration {
+	wait := ReconnectInterval
+	for i := 1; i < attempt && wait < MaxReconnectInterval; i++ {
+		wait *= 2
+	}
+
+	if wait > MaxReconnectInterval {
+		wait = MaxReconnectInterval
+	}
+
+	return wait
+}
+
+func (source *resumingEventSource) Close() error {
+	source.closeOnce.Do(func() {
+		close(source.closed)
+	})
+
+	return source.currentStream().Close()
+}
+
+func (source *resumingEventSource) closeOnCancel() {
+	select {
+	case <-source.ctx.Done():
+		source.currentStream().Close()
+	case <-source.closed:
+	}
+}
+
+// watch closes the stream whenever the machine wakes from sleep, or the
+// stream stalled and the build has since finished, so that NextEvent
+// re-establishes it rather than waiting on a dead connection.
+func (source *resumingEventSource) watch() {
+	ticker := time.NewTicker(SleepCheckInterval)
+	defer ticker.Stop()
+
+	last := time.Now()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-source.ctx.Done():
+			return
+		case <-source.closed:
+			return
+		}
+
+		if asleepSince(last) > sleepThreshold {
+			source.restart()
+		} else if source.stalled() {
+			source.pollWhileStalled()
+		}
+
+		last = time.Now()
+	}
+}
+
+func (source *resumingEventSource) restart() {
+	source.streamL.Lock()
+	source.restarted = true
+	source.lastEvent = time.Now()
+	stream := source.stream
+	source.streamL.Unlock()
+
+	stream.Close()
+}
+
+// pollWhileStalled polls the build's status until events resume or it
+// finishes, rather than replaying the whole stream every StallTimeout in case
+// it was dropped. Builds that can't be looked up fall back to the latter.
+func (source *resumingEventSource) pollWhileStalled() {
+	poller, ok := source.transport.(BuildPoller)
+	if !ok {
+		fmt.Fprintf(ui.Stderr, "no events from the build for %s; re-establishing its event stream in case it was dropped\n", StallTimeout)
+		source.restart()
+		return
+	}
+
+	fmt.Fprintf(ui.Stderr, "no events from the build for %s; polling its status until they resume\n", StallTimeout)
+
+	for source.stalled() {
+		build, found, err := poller.Build(source.buildID)
+		if err == nil && !found {
+			fmt.Fprintln(ui.Stderr, "could not look the build up; re-establishing its event stream in case it was dropped")
+			source.restart()
+			return
+		}
+
+		if err == nil {
+			status := atc.BuildStatus(build.Status)
+			if status != atc.StatusPending && status != atc.StatusStarted {
+				fmt.Fprintf(ui.Stderr, "the build has %s; re-establishing its event stream for the events it missed\n", status)
+				source.restart()
+				return
+			}
+		}
+
+		select {
+		case <-time.After(StatusPollInterval):
+		case <-source.ctx.Done():
+			return
+		case <-source.closed:
+			return
+		}
+	}
+}
+
+func (source *resumingEventSource) stalled() bool {
+	source.streamL.Lock()
+	defer source.streamL.Unlock()
+
+	return StallTimeout > 0 && time.Since(source.lastEvent) > StallTimeout
+}
+
+func (source *resumingEventSource) heardFrom() {
+	source.streamL.Lock()
+	source.lastEvent = time.Now()
+	source.streamL.Unlock()
+}
+
+func (source *resumingEventSource) takeRestarted() bool {
+	source.streamL.Lock()
+	defer source.streamL.Unlock()
+
+	restarted := source.restarted
+	source.restarted = false
+
+	return restarted
+}
+
+// pollStatus waits for the build to finish once its event stream is given up
+// on, returning its status as an event so that how it went is still known.
+// The stream's error is returned if the build can't be looked up.
+func (source *resumingEventSource) pollStatus(streamErr error) (atc.Event, error) {
+	poller, ok := source.transport.(BuildPoller)
+	if !ok {
+		return nil, streamErr
+	}
+
+	failures := 0
+	announced := false
+	for {
+		build, found, err := poller.Build(source.buildID)
+		if err == nil && !found {
+			return nil, streamErr
+		}
+
+		if err != nil {
+			failures++
+			if failures > source.maxReconnects {
+				return nil, streamErr
+			}
+		} else {
+			failures = 0
+
+			if !announced {
+				fmt.Fprintf(ui.Stderr, "gave up on the build's event stream (%s); polling its status instead\n", streamErr)
+				announced = true
+			}
+
+			status := atc.BuildStatus(build.Status)
+			if status != atc.StatusPending && status != atc.StatusStarted {
+				source.polled = true
+				return event.Status{Status: status, Time: build.EndTime}, nil
+			}
+		}
+
+		select {
+		case <-time.After(StatusPollInterval):
+		case <-source.ctx.Done():
+			return nil, source.ctx.Err()
+		}
+	}
+}
+
+func (source *resumingEventSource) currentStream() EventSource {
+	source.streamL.Lock()
+	defer source.streamL.Unlock()
+
+	return source.stream
+}
+
+func (source *resumingEventSource) connect() error {
+	stream, err := source.transport.Connect(source.buildID)
+	if err != nil {
+		return err
+	}
+
+	source.streamL.Lock()
+	source.stream = stream
+	source.lastEvent = time.Now()
+	source.streamL.Unlock()
+
+	return nil
+}
+
+func (source *resumingEventSource) reconnect() error {
+	err := source.connect()
+	if err != nil {
+		return err
+	}
+
+	for skipped := 0; skipped < source.seen; skipped++ {
+		_, err := source.currentStream().NextEvent()
+		if err != nil {
+			if source.unparsable.skip(err) {
+				continue
+			}
+
+			source.currentStream().Close()
+			return err
+		}
+	}
+
+	return nil
+}
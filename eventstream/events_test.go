@@ -0,0 +1,560 @@
+package eventstream_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	flyeventstreamfakes "github.com/concourse/fly/eventstream/eventstreamfakes"
+	"github.com/concourse/fly/ui"
+	"github.com/concourse/go-concourse/concourse"
+	"github.com/concourse/go-concourse/concourse/concoursefakes"
+	"github.com/concourse/go-concourse/concourse/eventstream/eventstreamfakes"
+)
+
+var _ = Describe("Events", func() {
+	var (
+		ctx     context.Context
+		client  *concoursefakes.FakeClient
+		streams []*eventstreamfakes.FakeEventStream
+
+		source eventstream.EventSource
+		err    error
+	)
+
+	streamOf := func(events []atc.Event, finalErr error) *eventstreamfakes.FakeEventStream {
+		stream := new(eventstreamfakes.FakeEventStream)
+
+		remaining := events
+		stream.NextEventStub = func() (atc.Event, error) {
+			if len(remaining) == 0 {
+				return nil, finalErr
+			}
+
+			ev := remaining[0]
+			remaining = remaining[1:]
+			return ev, nil
+		}
+
+		return stream
+	}
+
+	BeforeEach(func() {
+		eventstream.ReconnectInterval = 0
+
+		ctx = context.Background()
+		client = new(concoursefakes.FakeClient)
+		streams = nil
+
+		client.BuildEventsStub = func(string) (concourse.Events, error) {
+			if len(streams) == 0 {
+				return nil, errors.New("no more streams")
+			}
+
+			stream := streams[0]
+			streams = streams[1:]
+
+			// a nil stream stands for the network being down
+			if stream == nil {
+				return nil, errors.New("connection refused")
+			}
+
+			return stream, nil
+		}
+	})
+
+	JustBeforeEach(func() {
+		source, err = eventstream.Events(ctx, client, "42")
+	})
+
+	Context("when the stream ends normally", func() {
+		BeforeEach(func() {
+			streams = append(streams, streamOf([]atc.Event{
+				event.Log{Payload: "one"},
+				event.Log{Payload: "two"},
+			}, io.EOF))
+		})
+
+		It("opens the events of the given build", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.BuildEventsCallCount()).To(Equal(1))
+			Expect(client.BuildEventsArgsForCall(0)).To(Equal("42"))
+		})
+
+		It("yields every event to the handler", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+		})
+	})
+
+	Context("when the stream has events this fly doesn't understand", func() {
+		var stderr *gbytes.Buffer
+
+		BeforeEach(func() {
+			stderr = gbytes.NewBuffer()
+			ui.Stderr = stderr
+
+			eventstream.TargetName = func() string { return "some-target" }
+
+			_, unknownType := event.ParseEvent("1.0", "some-future-event", json.RawMessage(`{}`))
+
+			errs := []error{nil, unknownType, unknownType, nil}
+			stream := streamOf([]atc.Event{
+				event.Log{Payload: "one"},
+				nil,
+				nil,
+				event.Log{Payload: "two"},
+			}, io.EOF)
+
+			next := stream.NextEventStub
+			stream.NextEventStub = func() (atc.Event, error) {
+				ev, err := next()
+				if len(errs) > 0 {
+					err = errs[0]
+					errs = errs[1:]
+				}
+
+				return ev, err
+			}
+
+			streams = append(streams, stream)
+		})
+
+		AfterEach(func() {
+			ui.Stderr = os.Stderr
+			eventstream.TargetName = func() string { return "" }
+		})
+
+		It("skips them, warning once to upgrade fly", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+
+			Expect(stderr).To(gbytes.Say(`skipping events this fly doesn't understand \(.*some-future-event.*\); this fly is older than the ATC, so please run fly -t some-target sync to see them\n`))
+			Expect(stderr).NotTo(gbytes.Say("skipping"))
+		})
+	})
+
+	Context("when the connection drops mid-stream", func() {
+		BeforeEach(func() {
+			streams = append(streams,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+				}, errors.New("connection reset")),
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+					event.Log{Payload: "two"},
+				}, io.EOF),
+			)
+		})
+
+		It("reconnects and resumes after the events already seen", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+			Expect(client.BuildEventsCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when reconnecting fails at first", func() {
+		BeforeEach(func() {
+			streams = append(streams,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+				}, errors.New("connection reset")),
+				nil,
+				nil,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+					event.Log{Payload: "two"},
+				}, io.EOF),
+			)
+		})
+
+		It("keeps trying until it's back", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+			Expect(client.BuildEventsCallCount()).To(Equal(4))
+		})
+	})
+
+	Context("when the connection keeps dropping", func() {
+		BeforeEach(func() {
+			eventstream.MaxReconnects = 2
+
+			for i := 0; i < 4; i++ {
+				streams = append(streams, streamOf(nil, errors.New("connection reset")))
+			}
+		})
+
+		AfterEach(func() {
+			eventstream.MaxReconnects = eventstream.DefaultMaxReconnects
+		})
+
+		It("gives up after MaxReconnects attempts in a row", func() {
+			err := eventstream.Each(source, func(atc.Event) error {
+				return nil
+			})
+			Expect(err).To(MatchError("connection reset"))
+			Expect(client.BuildEventsCallCount()).To(Equal(3))
+		})
+	})
+
+	Context("when the stream can't be re-established", func() {
+		BeforeEach(func() {
+			eventstream.MaxReconnects = 1
+			eventstream.StatusPollInterval = 0
+
+			streams = append(streams,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+				}, errors.New("connection reset")),
+				nil,
+			)
+
+			client.BuildReturnsOnCall(0, atc.Build{Status: string(atc.StatusStarted)}, true, nil)
+			client.BuildReturnsOnCall(1, atc.Build{Status: string(atc.StatusFailed), EndTime: 100}, true, nil)
+		})
+
+		AfterEach(func() {
+			eventstream.MaxReconnects = eventstream.DefaultMaxReconnects
+			eventstream.StatusPollInterval = 10 * time.Second
+		})
+
+		It("polls the build's status until it finishes, and ends with it", func() {
+			var events []atc.Event
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				events = append(events, ev)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(Equal([]atc.Event{
+				event.Log{Payload: "one"},
+				event.Status{Status: atc.StatusFailed, Time: 100},
+			}))
+
+			Expect(client.BuildCallCount()).To(Equal(2))
+			Expect(client.BuildArgsForCall(0)).To(Equal("42"))
+		})
+	})
+
+	Describe("the wait before reconnecting", func() {
+		BeforeEach(func() {
+			eventstream.ReconnectInterval = time.Second
+		})
+
+		AfterEach(func() {
+			eventstream.ReconnectInterval = 0
+		})
+
+		It("doubles with every attempt, up to MaxReconnectInterval", func() {
+			Expect(eventstream.ReconnectBackoff(1)).To(Equal(time.Second))
+			Expect(eventstream.ReconnectBackoff(2)).To(Equal(2 * time.Second))
+			Expect(eventstream.ReconnectBackoff(4)).To(Equal(8 * time.Second))
+			Expect(eventstream.ReconnectBackoff(10)).To(Equal(eventstream.MaxReconnectInterval))
+		})
+	})
+
+	Context("when the machine wakes from sleep", func() {
+		var restore func()
+
+		BeforeEach(func() {
+			eventstream.SleepCheckInterval = 10 * time.Millisecond
+
+			var woken sync.Once
+			restore = eventstream.SetAsleepSince(func(time.Time) time.Duration {
+				asleep := time.Duration(0)
+				woken.Do(func() { asleep = time.Hour })
+				return asleep
+			})
+
+			dead := new(eventstreamfakes.FakeEventStream)
+
+			closed := make(chan struct{})
+			var closeOnce sync.Once
+			dead.CloseStub = func() error {
+				closeOnce.Do(func() { close(closed) })
+				return nil
+			}
+
+			sent := false
+			dead.NextEventStub = func() (atc.Event, error) {
+				if !sent {
+					sent = true
+					return event.Log{Payload: "one"}, nil
+				}
+
+				// the connection died while asleep, so this would hang
+				// until closed
+				<-closed
+				return nil, io.EOF
+			}
+
+			streams = append(streams,
+				dead,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+					event.Log{Payload: "two"},
+				}, io.EOF),
+			)
+		})
+
+		AfterEach(func() {
+			restore()
+			eventstream.SleepCheckInterval = 5 * time.Second
+		})
+
+		It("re-establishes the stream and resumes after the events already seen", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+			Expect(client.BuildEventsCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the stream stalls", func() {
+		var stderr *gbytes.Buffer
+
+		BeforeEach(func() {
+			eventstream.SleepCheckInterval = 10 * time.Millisecond
+			eventstream.StallTimeout = 50 * time.Millisecond
+			eventstream.StatusPollInterval = 10 * time.Millisecond
+
+			stderr = gbytes.NewBuffer()
+			ui.Stderr = stderr
+
+			silent := new(eventstreamfakes.FakeEventStream)
+
+			closed := make(chan struct{})
+			var closeOnce sync.Once
+			silent.CloseStub = func() error {
+				closeOnce.Do(func() { close(closed) })
+				return nil
+			}
+
+			sent := false
+			silent.NextEventStub = func() (atc.Event, error) {
+				if !sent {
+					sent = true
+					return event.Log{Payload: "one"}, nil
+				}
+
+				// a connection dropped by a proxy without telling either end
+				<-closed
+				return nil, errors.New("use of closed network connection")
+			}
+
+			streams = append(streams,
+				silent,
+				streamOf([]atc.Event{
+					event.Log{Payload: "one"},
+					event.Log{Payload: "two"},
+				}, io.EOF),
+			)
+		})
+
+		AfterEach(func() {
+			eventstream.SleepCheckInterval = 5 * time.Second
+			eventstream.StallTimeout = 5 * time.Minute
+			eventstream.StatusPollInterval = 10 * time.Second
+			ui.Stderr = os.Stderr
+		})
+
+		Context("while the build is still running", func() {
+			BeforeEach(func() {
+				client.BuildReturnsOnCall(0, atc.Build{Status: string(atc.StatusStarted)}, true, nil)
+				client.BuildReturnsOnCall(1, atc.Build{Status: string(atc.StatusStarted)}, true, nil)
+				client.BuildReturns(atc.Build{Status: string(atc.StatusSucceeded)}, true, nil)
+			})
+
+			It("polls its status, and re-establishes the stream only once it's finished", func() {
+				var payloads []string
+				err := eventstream.Each(source, func(ev atc.Event) error {
+					payloads = append(payloads, ev.(event.Log).Payload)
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payloads).To(Equal([]string{"one", "two"}))
+				Expect(client.BuildCallCount()).To(Equal(3))
+				Expect(client.BuildEventsCallCount()).To(Equal(2))
+				Expect(stderr).To(gbytes.Say("no events from the build for 50ms; polling its status until they resume"))
+				Expect(stderr).To(gbytes.Say("the build has succeeded; re-establishing its event stream for the events it missed"))
+			})
+		})
+
+		Context("when the build can't be looked up", func() {
+			It("re-establishes the stream and resumes after the events already seen", func() {
+				var payloads []string
+				err := eventstream.Each(source, func(ev atc.Event) error {
+					payloads = append(payloads, ev.(event.Log).Payload)
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(payloads).To(Equal([]string{"one", "two"}))
+				Expect(client.BuildEventsCallCount()).To(Equal(2))
+			})
+		})
+	})
+
+	Context("when events resume after stalling", func() {
+		BeforeEach(func() {
+			eventstream.SleepCheckInterval = 10 * time.Millisecond
+			eventstream.StallTimeout = 50 * time.Millisecond
+			eventstream.StatusPollInterval = 10 * time.Millisecond
+
+			client.BuildReturns(atc.Build{Status: string(atc.StatusStarted)}, true, nil)
+
+			quiet := streamOf([]atc.Event{
+				event.Log{Payload: "one"},
+				event.Log{Payload: "two"},
+			}, io.EOF)
+
+			next := quiet.NextEventStub
+			calls := 0
+			quiet.NextEventStub = func() (atc.Event, error) {
+				calls++
+				if calls == 2 {
+					// a long and quiet step
+					time.Sleep(200 * time.Millisecond)
+				}
+
+				return next()
+			}
+
+			streams = append(streams, quiet)
+		})
+
+		AfterEach(func() {
+			eventstream.SleepCheckInterval = 5 * time.Second
+			eventstream.StallTimeout = 5 * time.Minute
+			eventstream.StatusPollInterval = 10 * time.Second
+		})
+
+		It("stops polling and keeps the stream it had", func() {
+			var payloads []string
+			err := eventstream.Each(source, func(ev atc.Event) error {
+				payloads = append(payloads, ev.(event.Log).Payload)
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(payloads).To(Equal([]string{"one", "two"}))
+			Expect(client.BuildCallCount()).To(BeNumerically(">", 0))
+			Expect(client.BuildEventsCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the handler returns an error", func() {
+		BeforeEach(func() {
+			streams = append(streams, streamOf([]atc.Event{
+				event.Log{Payload: "one"},
+				event.Log{Payload: "two"},
+			}, io.EOF))
+		})
+
+		It("stops and returns the error", func() {
+			disaster := errors.New("nope")
+
+			calls := 0
+			err := eventstream.Each(source, func(atc.Event) error {
+				calls++
+				return disaster
+			})
+			Expect(err).To(Equal(disaster))
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	Context("when the context is cancelled", func() {
+		var cancel context.CancelFunc
+
+		BeforeEach(func() {
+			stream := new(eventstreamfakes.FakeEventStream)
+
+			closed := make(chan struct{})
+			stream.CloseStub = func() error {
+				close(closed)
+				return nil
+			}
+
+			stream.NextEventStub = func() (atc.Event, error) {
+				<-closed
+				return nil, errors.New("use of closed network connection")
+			}
+
+			streams = append(streams, stream)
+
+			ctx, cancel = context.WithCancel(context.Background())
+		})
+
+		It("unblocks and returns the context's error", func() {
+			Expect(err).NotTo(HaveOccurred())
+
+			cancel()
+
+			_, err := source.NextEvent()
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Context("when opening the stream fails", func() {
+		disaster := errors.New("oh no")
+
+		BeforeEach(func() {
+			client.BuildEventsStub = nil
+			client.BuildEventsReturns(nil, disaster)
+		})
+
+		It("returns the error", func() {
+			Expect(err).To(Equal(disaster))
+		})
+	})
+
+	Describe("EventsVia", func() {
+		It("connects over the given transport", func() {
+			transport := new(flyeventstreamfakes.FakeTransport)
+			transport.ConnectReturns(streamOf([]atc.Event{event.Log{Payload: "via"}}, io.EOF), nil)
+
+			source, err := eventstream.EventsVia(context.Background(), transport, "7")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transport.ConnectCallCount()).To(Equal(1))
+			Expect(transport.ConnectArgsForCall(0)).To(Equal("7"))
+
+			ev, err := source.NextEvent()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ev).To(Equal(event.Log{Payload: "via"}))
+		})
+	})
+})
@@ -0,0 +1,16 @@
+package eventstream
+
+import "time"
+
+// SetAsleepSince replaces how long the machine is taken to have been asleep,
+// returning a func to restore it.
+func SetAsleepSince(fake func(time.Time) time.Duration) func() {
+	original := asleepSince
+	asleepSince = fake
+
+	return func() {
+		asleepSince = original
+	}
+}
+
+var ReconnectBackoff = reconnectBackoff
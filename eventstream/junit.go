@@ -0,0 +1,211 @@
+package eventstream
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JUnitReport collects the results of the steps of builds rendered with it,
+// to be written as a JUnit XML report for tools that understand nothing else.
+// Each build is a test suite, with a test case for each of its steps.
+type JUnitReport struct {
+	// ParseTAP also reports the TAP results ("ok 1 - ...", "not ok 2 - ...")
+	// a step prints, each as a test case in a suite of the step's own.
+	ParseTAP bool
+
+	suitesL sync.Mutex
+	suites  []junitTestSuite
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr,omitempty"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteReport writes the report of every build rendered with it so far.
+func (report *JUnitReport) WriteReport(dst io.Writer) error {
+	report.suitesL.Lock()
+	defer report.suitesL.Unlock()
+
+	payload, err := xml.MarshalIndent(junitTestSuites{Suites: report.suites}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(dst, "%s%s\n", xml.Header, payload)
+	return err
+}
+
+func (report *JUnitReport) add(name string, timings *stepTimings, tap *tapParser) {
+	suite := junitTestSuite{Name: name}
+
+	var total time.Duration
+	for _, step := range timings.steps {
+		testCase := junitTestCase{Name: step.name, ClassName: name}
+
+		if step.start != 0 && step.finish >= step.start {
+			duration := time.Duration(step.finish-step.start) * time.Second
+			testCase.Time = junitSeconds(duration)
+			total += duration
+		}
+
+		switch {
+		case step.status == "":
+			testCase.Skipped = &junitSkipped{Message: "did not finish"}
+		case step.status == "errored":
+			testCase.Error = &junitFailure{Message: step.status}
+		case step.status != "succeeded":
+			testCase.Failure = &junitFailure{Message: step.status}
+		}
+
+		suite.add(testCase)
+	}
+
+	suite.Time = junitSeconds(total)
+
+	suites := []junitTestSuite{suite}
+
+	if tap != nil {
+		for _, step := range timings.steps {
+			cases := tap.results(step.origin)
+			if len(cases) == 0 {
+				continue
+			}
+
+			tapSuite := junitTestSuite{Name: name + ": " + step.name}
+			for _, testCase := range cases {
+				testCase.ClassName = tapSuite.Name
+				tapSuite.add(testCase)
+			}
+
+			suites = append(suites, tapSuite)
+		}
+	}
+
+	report.suitesL.Lock()
+	report.suites = append(report.suites, suites...)
+	report.suitesL.Unlock()
+}
+
+func (suite *junitTestSuite) add(testCase junitTestCase) {
+	suite.Cases = append(suite.Cases, testCase)
+	suite.Tests++
+
+	switch {
+	case testCase.Failure != nil:
+		suite.Failures++
+	case testCase.Error != nil:
+		suite.Errors++
+	case testCase.Skipped != nil:
+		suite.Skipped++
+	}
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+var tapResult = regexp.MustCompile(`^(not )?ok\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(?i:(skip|todo))\b\s*(.*))?$`)
+
+// tapParser picks the TAP results out of the output of each step. Only the
+// first maxPartialLineSize bytes of a line are kept, which is plenty for a
+// result.
+type tapParser struct {
+	partial map[string]string
+	cases   map[string][]junitTestCase
+}
+
+func newTAPParser() *tapParser {
+	return &tapParser{
+		partial: map[string]string{},
+		cases:   map[string][]junitTestCase{},
+	}
+}
+
+func (parser *tapParser) write(origin string, payload string) {
+	lines := strings.Split(parser.partial[origin]+payload, "\n")
+
+	partial := lines[len(lines)-1]
+	if len(partial) > maxPartialLineSize {
+		partial = partial[:maxPartialLineSize]
+	}
+
+	parser.partial[origin] = partial
+
+	for _, line := range lines[:len(lines)-1] {
+		parser.parseLine(origin, line)
+	}
+}
+
+func (parser *tapParser) results(origin string) []junitTestCase {
+	if partial := parser.partial[origin]; partial != "" {
+		parser.parseLine(origin, partial)
+		parser.partial[origin] = ""
+	}
+
+	return parser.cases[origin]
+}
+
+func (parser *tapParser) parseLine(origin string, line string) {
+	match := tapResult.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return
+	}
+
+	notOK, number, description, directive, reason := match[1] != "", match[2], match[3], match[4], match[5]
+
+	if number == "" {
+		number = strconv.Itoa(len(parser.cases[origin]) + 1)
+	}
+
+	name := description
+	if name == "" {
+		name = "test " + number
+	}
+
+	testCase := junitTestCase{Name: name}
+
+	switch {
+	case directive != "":
+		// TODO tests are expected to fail, so are no more a failure than
+		// skipped ones
+		testCase.Skipped = &junitSkipped{Message: strings.TrimSpace(strings.ToLower(directive) + " " + reason)}
+	case notOK:
+		testCase.Failure = &junitFailure{Message: "not ok"}
+	}
+
+	parser.cases[origin] = append(parser.cases[origin], testCase)
+}
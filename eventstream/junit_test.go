@@ -0,0 +1,114 @@
+package eventstream_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/go-concourse/concourse/eventstream/eventstreamfakes"
+)
+
+var _ = Describe("JUnitReport", func() {
+	var (
+		events []atc.Event
+		report *eventstream.JUnitReport
+	)
+
+	render := func() {
+		stream := new(eventstreamfakes.FakeEventStream)
+		stream.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			ev := events[0]
+			events = events[1:]
+			return ev, nil
+		}
+
+		eventstream.RenderWithOptions(gbytes.NewBuffer(), stream, eventstream.RenderOptions{
+			JUnit:      report,
+			JUnitSuite: "build 128",
+		})
+	}
+
+	written := func() string {
+		buf := new(bytes.Buffer)
+		Expect(report.WriteReport(buf)).To(Succeed())
+		return buf.String()
+	}
+
+	BeforeEach(func() {
+		report = &eventstream.JUnitReport{}
+
+		events = []atc.Event{
+			event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "some-input"}, ExitStatus: 0},
+			event.StartTask{Origin: event.Origin{ID: "2"}, Time: 100, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "run-tests"}}},
+			event.Log{Origin: event.Origin{ID: "2"}, Payload: "1..3\nok 1 - adds\nnot ok 2 - sub"},
+			event.Log{Origin: event.Origin{ID: "2"}, Payload: "tracts\nok 3 # SKIP no network\n"},
+			event.FinishTask{Origin: event.Origin{ID: "2"}, Time: 190, ExitStatus: 1},
+			event.StartTask{Origin: event.Origin{ID: "3"}, Time: 200},
+			event.Status{Status: atc.StatusFailed},
+		}
+	})
+
+	It("reports each step of the build as a test case", func() {
+		render()
+
+		Expect(written()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="build 128" tests="3" failures="1" errors="0" skipped="1" time="90.000">
+    <testcase name="get: some-input" classname="build 128"></testcase>
+    <testcase name="task running run-tests" classname="build 128" time="90.000">
+      <failure message="failed (exit 1)"></failure>
+    </testcase>
+    <testcase name="task running " classname="build 128">
+      <skipped message="did not finish"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+	})
+
+	Context("when TAP results are to be parsed", func() {
+		BeforeEach(func() {
+			report.ParseTAP = true
+		})
+
+		It("reports them as a suite of the step that printed them", func() {
+			render()
+
+			Expect(written()).To(ContainSubstring(`  <testsuite name="build 128: task running run-tests" tests="3" failures="1" errors="0" skipped="1">
+    <testcase name="adds" classname="build 128: task running run-tests"></testcase>
+    <testcase name="subtracts" classname="build 128: task running run-tests">
+      <failure message="not ok"></failure>
+    </testcase>
+    <testcase name="test 3" classname="build 128: task running run-tests">
+      <skipped message="skip no network"></skipped>
+    </testcase>
+  </testsuite>
+`))
+		})
+	})
+
+	It("reports every build rendered with it", func() {
+		render()
+
+		events = []atc.Event{
+			event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "other-input"}, ExitStatus: 0},
+			event.Status{Status: atc.StatusSucceeded},
+		}
+
+		render()
+
+		Expect(written()).To(ContainSubstring(`<testcase name="get: some-input"`))
+		Expect(written()).To(ContainSubstring(`<testcase name="get: other-input"`))
+	})
+})
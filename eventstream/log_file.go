@@ -0,0 +1,77 @@
+package eventstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+// loggedEvent is an event as written to RenderOptions.LogFile: as written by
+// RenderOptions.JSON, with the time it was received.
+type loggedEvent struct {
+	Time    time.Time        `json:"time"`
+	Event   atc.EventType    `json:"event"`
+	Version atc.EventVersion `json:"version"`
+	Data    json.RawMessage  `json:"data"`
+}
+
+// ReadLogFile returns the events written to a RenderOptions.LogFile, e.g. to
+// render them again, with the times they were first received. Events of a
+// type or version this fly does not understand are skipped, as they are when
+// streamed.
+func ReadLogFile(src io.Reader) EventSource {
+	return &logFileEventSource{
+		decoder:    json.NewDecoder(src),
+		unparsable: unparsableEvents{},
+	}
+}
+
+type logFileEventSource struct {
+	decoder    *json.Decoder
+	line       int
+	unparsable unparsableEvents
+
+	// lastReceived is when the event last returned was first received
+	lastReceived time.Time
+}
+
+func (source *logFileEventSource) NextEvent() (atc.Event, error) {
+	for {
+		var logged loggedEvent
+		err := source.decoder.Decode(&logged)
+		if err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("malformed event %d of the log file: %s", source.line+1, err)
+		}
+
+		source.line++
+
+		ev, err := event.ParseEvent(logged.Version, logged.Event, logged.Data)
+		if err != nil {
+			if source.unparsable.skip(err) {
+				continue
+			}
+
+			return nil, fmt.Errorf("malformed event %d of the log file: %s", source.line, err)
+		}
+
+		source.lastReceived = logged.Time
+
+		return ev, nil
+	}
+}
+
+func (source *logFileEventSource) received() time.Time {
+	return source.lastReceived
+}
+
+func (source *logFileEventSource) Close() error {
+	return nil
+}
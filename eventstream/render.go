@@ -1,87 +1,888 @@
 package eventstream
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/concourse/atc"
 	"github.com/concourse/atc/event"
 	"github.com/concourse/fly/ui"
 	"github.com/concourse/go-concourse/concourse/eventstream"
 	"github.com/fatih/color"
 )
 
+const (
+	// events are read ahead of rendering into a queue of up to this many
+	// events and bytes of them, so a slow terminal doesn't stall the
+	// connection to the ATC without a flood of logs piling up in memory
+	renderQueueSize  = 4096
+	renderQueueBytes = 4 * 1024 * 1024
+
+	// roughly what an event other than its payload takes up in the queue
+	queuedEventOverhead = 256
+
+	renderBufferSize = 64 * 1024
+
+	// incomplete lines are held until the rest of them arrives, up to this
+	// long, so a step that never prints a newline can't run fly out of
+	// memory
+	maxPartialLineSize = 64 * 1024
+)
+
+// RenderOptions configures how a build's events are rendered.
+type RenderOptions struct {
+	// MaxLogBytes caps the amount of log output written. Once reached, a
+	// truncation marker is printed and further logs are discarded, though
+	// the build's status is still reported. Zero means no limit.
+	MaxLogBytes int64
+
+	// Redact lists values, e.g. secrets interpolated into the build's
+	// config, to be replaced with ((redacted)) wherever they appear in
+	// the build's logs or errors.
+	Redact []string
+
+	// StepSummary prints a table of the build's steps once it completes,
+	// with how long each took and how it finished.
+	StepSummary bool
+
+	// StepNames names the steps in the summary by their plan IDs, e.g. as
+	// returned by StepNames. Steps not named are described by their events.
+	StepNames map[string]string
+
+	// Containers, if set, is used to note the worker and container each
+	// task runs in as it starts.
+	Containers ContainerLookup
+
+	// JUnit, if set, is given the results of the build's steps once it
+	// completes, as a test suite named JUnitSuite.
+	JUnit      *JUnitReport
+	JUnitSuite string
+
+	// TaskInitializing and TaskStarted, if set, are called as each of the
+	// build's tasks initializes and starts running, and may write to the
+	// build's output as they are.
+	TaskInitializing func(dst io.Writer)
+	TaskStarted      func(dst io.Writer)
+
+	// JSON writes each event as a line of json, as sent by the ATC, rather
+	// than rendering it; logs and errors are still redacted. The step
+	// summary is left out, and TaskInitializing and TaskStarted are given
+	// stderr to write to.
+	JSON bool
+
+	// LogFile, if set, is written each event as a line of json, as by JSON,
+	// with the time it was received, e.g. to archive the build's output and
+	// render it again with ReadLogFile.
+	LogFile io.Writer
+
+	// Timestamps prefixes each line of the build's logs with when it was
+	// received, and how long after the build started that was.
+	Timestamps bool
+}
+
+// jsonEvent is an event as written by RenderOptions.JSON.
+type jsonEvent struct {
+	Event   atc.EventType    `json:"event"`
+	Version atc.EventVersion `json:"version"`
+	Data    atc.Event        `json:"data"`
+}
+
+// ContainerLookup returns the container of the step with the given plan ID,
+// if it is known.
+type ContainerLookup func(planID string) (atc.Container, bool)
+
+// Render writes a build's events to dst until the build finishes, returning
+// the exit status fly should exit with.
+//
+// Events are read ahead of rendering into a queue bounded by renderQueueBytes
+// and log payloads are written straight through to dst, so memory use stays
+// flat regardless of how much a build logs; an event larger than the queue
+// is only let in once the queue is empty.
 func Render(dst io.Writer, src eventstream.EventStream) int {
-	exitStatus := 0
+	return RenderWithOptions(dst, src, RenderOptions{})
+}
+
+func RenderWithOptions(dst io.Writer, src eventstream.EventStream, options RenderOptions) int {
+	queue := make(chan queuedEvent, renderQueueSize)
+	budget := newQueueBudget(renderQueueBytes)
+	done := make(chan struct{})
+	defer close(done)
+	defer budget.close()
+
+	go readEvents(src, queue, budget, done)
+
+	// writes are batched and only flushed once the queue has drained, so
+	// that a flood of small log lines becomes a few large writes
+	out := bufio.NewWriterSize(dst, renderBufferSize)
+	defer out.Flush()
+
+	renderer := &renderer{
+		dst:      out,
+		logs:     &logWriter{dst: out, limit: options.MaxLogBytes},
+		redactor: newRedactor(options.Redact),
+
+		containers:       options.Containers,
+		taskInitializing: options.TaskInitializing,
+		taskStarted:      options.TaskStarted,
+	}
+
+	if ui.StructuredOutput {
+		renderer.logs.records = &logRecords{
+			names:   options.StepNames,
+			partial: map[event.Origin]string{},
+		}
+
+		defer renderer.logs.flush()
+	} else if options.Timestamps {
+		renderer.logs.stamp = renderer.timestamp
+		renderer.logs.lineStart = true
+	}
+
+	if options.StepSummary || options.JUnit != nil {
+		renderer.timings = newStepTimings(options.StepNames)
+	}
+
+	renderer.stepSummary = options.StepSummary
+
+	if options.JSON {
+		renderer.json = json.NewEncoder(out)
+	}
+
+	if options.LogFile != nil {
+		renderer.logFile = json.NewEncoder(options.LogFile)
+	}
+
+	if options.JUnit != nil {
+		renderer.junit = options.JUnit
+		renderer.junitSuite = options.JUnitSuite
+
+		if options.JUnit.ParseTAP {
+			renderer.tap = newTAPParser()
+		}
+	}
+
+	for queued := range queue {
+		if queued.err != nil {
+			if queued.err == io.EOF {
+				for _, ev := range renderer.redactor.flush() {
+					renderer.write(ev, queued.received)
+				}
+
+				return renderer.exitStatus
+			} else if queued.err == context.Canceled {
+				return 2
+			} else {
+				fmt.Fprintf(out, "failed to parse next event: %s\n", queued.err)
+				return 255
+			}
+		}
+
+		renderer.received = queued.received
+		if renderer.firstReceived.IsZero() {
+			renderer.firstReceived = queued.received
+		}
+
+		for _, ev := range renderer.redactor.logs(queued.event) {
+			if renderer.write(ev, queued.received) {
+				return renderer.exitStatus
+			}
+		}
+
+		budget.release(queued.size)
+
+		if len(queue) == 0 {
+			out.Flush()
+		}
+	}
+
+	return 255
+}
+
+// write records and renders an event, returning whether the build finished.
+func (renderer *renderer) write(ev atc.Event, received time.Time) bool {
+	if renderer.logFile != nil {
+		renderer.record(ev, received)
+	}
+
+	if renderer.json != nil {
+		return renderer.renderJSON(ev)
+	}
+
+	return renderer.render(ev)
+}
+
+type queuedEvent struct {
+	event    atc.Event
+	err      error
+	received time.Time
+	size     int
+}
+
+// queuedSize is roughly how much memory an event takes up while queued.
+func queuedSize(ev atc.Event) int {
+	switch e := ev.(type) {
+	case event.Log:
+		return queuedEventOverhead + len(e.Payload)
+	case event.Error:
+		return queuedEventOverhead + len(e.Message)
+	default:
+		return queuedEventOverhead
+	}
+}
+
+// queueBudget holds back reading events while those queued take up more
+// than its limit. One event is always let in, however large, so that an
+// event larger than the limit doesn't block forever.
+type queueBudget struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	used   int
+	limit  int
+	closed bool
+}
+
+func newQueueBudget(limit int) *queueBudget {
+	budget := &queueBudget{limit: limit}
+	budget.cond = sync.NewCond(&budget.lock)
+	return budget
+}
+
+// acquire waits until there's room for size bytes, returning false if the
+// budget was closed in the meantime.
+func (budget *queueBudget) acquire(size int) bool {
+	budget.lock.Lock()
+	defer budget.lock.Unlock()
+
+	for !budget.closed && budget.used > 0 && budget.used+size > budget.limit {
+		budget.cond.Wait()
+	}
+
+	if budget.closed {
+		return false
+	}
+
+	budget.used += size
+
+	return true
+}
+
+func (budget *queueBudget) release(size int) {
+	budget.lock.Lock()
+	budget.used -= size
+	budget.lock.Unlock()
+
+	budget.cond.Broadcast()
+}
+
+func (budget *queueBudget) close() {
+	budget.lock.Lock()
+	budget.closed = true
+	budget.lock.Unlock()
+
+	budget.cond.Broadcast()
+}
+
+// replayedEventSource is implemented by event sources that know when their
+// events were first received, e.g. a log file.
+type replayedEventSource interface {
+	received() time.Time
+}
+
+func readEvents(src eventstream.EventStream, queue chan<- queuedEvent, budget *queueBudget, done <-chan struct{}) {
+	defer close(queue)
+
+	replayed, isReplayed := src.(replayedEventSource)
 
 	for {
 		ev, err := src.NextEvent()
+
+		received := time.Now()
+		if isReplayed {
+			received = replayed.received()
+		}
+
+		size := queuedSize(ev)
+		if !budget.acquire(size) {
+			return
+		}
+
+		select {
+		case queue <- queuedEvent{event: ev, err: err, received: received, size: size}:
+		case <-done:
+			return
+		}
+
 		if err != nil {
-			if err == io.EOF {
-				return exitStatus
-			} else {
-				fmt.Fprintf(dst, "failed to parse next event: %s\n", err)
-				return 255
+			return
+		}
+	}
+}
+
+type renderer struct {
+	dst      io.Writer
+	logs     *logWriter
+	redactor *redactor
+	timings  *stepTimings
+
+	stepSummary bool
+
+	containers       ContainerLookup
+	taskInitializing func(io.Writer)
+	taskStarted      func(io.Writer)
+
+	junit      *JUnitReport
+	junitSuite string
+	tap        *tapParser
+
+	json    *json.Encoder
+	logFile *json.Encoder
+
+	// started is when the build started, as a unix timestamp
+	started int64
+
+	// received is when the event being rendered was received, and
+	// firstReceived when the first one was
+	received      time.Time
+	firstReceived time.Time
+
+	exitStatus int
+}
+
+func (renderer *renderer) render(ev atc.Event) bool {
+	dst := renderer.dst
+
+	if renderer.timings != nil {
+		renderer.timings.record(ev)
+	}
+
+	switch ev.(type) {
+	case event.InitializeTask, event.StartTask, event.Error:
+		// what's printed for them ends the line the logs were on
+		renderer.logs.lineStart = true
+	}
+
+	switch e := ev.(type) {
+	case event.Log:
+		// already redacted, across the chunks it was sent in
+		payload := e.Payload
+
+		renderer.logs.write(e.Origin, payload)
+
+		if renderer.tap != nil {
+			renderer.tap.write(string(e.Origin.ID), payload)
+		}
+
+	case event.InitializeTask:
+		if renderer.taskInitializing != nil {
+			renderer.taskInitializing(dst)
+		}
+
+		fmt.Fprintln(dst, color.New(color.Bold).Sprint("initializing"))
+
+	case event.StartTask:
+		if renderer.taskStarted != nil {
+			renderer.taskStarted(dst)
+		}
+
+		buildConfig := e.TaskConfig
+
+		argv := strings.Join(append([]string{buildConfig.Run.Path}, buildConfig.Run.Args...), " ")
+		fmt.Fprintln(dst, color.New(color.Bold).Sprintf("running %s", renderer.redactor.Replace(argv)))
+
+		if renderer.containers != nil {
+			container, found := renderer.containers(string(e.Origin.ID))
+			if found {
+				faint := color.New(color.Faint).SprintfFunc()
+				fmt.Fprintln(dst, faint("in container %s on worker %s", container.ID, container.WorkerName))
 			}
 		}
 
-		switch e := ev.(type) {
-		case event.Log:
-			fmt.Fprintf(dst, "%s", e.Payload)
+	case event.FinishTask:
+		renderer.exitStatus = e.ExitStatus
 
-		case event.InitializeTask:
-			fmt.Fprintf(dst, "\x1b[1minitializing\x1b[0m\n")
+	case event.Error:
+		errCol := ui.ErroredColor.SprintFunc()
+		fmt.Fprintf(dst, "%s\n", errCol(renderer.redactor.Replace(e.Message)))
 
-		case event.StartTask:
-			buildConfig := e.TaskConfig
+	case event.Status:
+		var printColor *color.Color
 
-			argv := strings.Join(append([]string{buildConfig.Run.Path}, buildConfig.Run.Args...), " ")
-			fmt.Fprintf(dst, "\x1b[1mrunning %s\x1b[0m\n", argv)
+		switch e.Status {
+		case "started":
+			renderer.started = e.Time
+			return false
+		case "succeeded":
+			printColor = ui.SucceededColor
+		case "failed":
+			printColor = ui.FailedColor
 
-		case event.FinishTask:
-			exitStatus = e.ExitStatus
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 1
+			}
+		case "errored":
+			printColor = ui.ErroredColor
 
-		case event.Error:
-			errCol := ui.ErroredColor.SprintFunc()
-			fmt.Fprintf(dst, "%s\n", errCol(e.Message))
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 2
+			}
+		case "aborted":
+			printColor = ui.AbortedColor
 
-		case event.Status:
-			var printColor *color.Color
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 3
+			}
+		default:
+			fmt.Fprintf(dst, "unknown status: %s", e.Status)
+			renderer.exitStatus = 255
+			return true
+		}
 
-			switch e.Status {
-			case "started":
-				continue
-			case "succeeded":
-				printColor = ui.SucceededColor
-			case "failed":
-				printColor = ui.FailedColor
+		renderer.logs.flush()
 
-				if exitStatus == 0 {
-					exitStatus = 1
-				}
-			case "errored":
-				printColor = ui.ErroredColor
+		printColorFunc := printColor.SprintFunc()
+		fmt.Fprint(dst, printColorFunc(e.Status))
 
-				if exitStatus == 0 {
-					exitStatus = 2
-				}
-			case "aborted":
-				printColor = ui.AbortedColor
+		if renderer.started != 0 && e.Time >= renderer.started {
+			fmt.Fprintf(dst, " after %s", time.Duration(e.Time-renderer.started)*time.Second)
+		}
 
-				if exitStatus == 0 {
-					exitStatus = 3
-				}
-			default:
-				fmt.Fprintf(dst, "unknown status: %s", e.Status)
-				return 255
+		fmt.Fprintln(dst)
+
+		if renderer.stepSummary {
+			renderer.timings.render(dst)
+		}
+
+		if renderer.junit != nil {
+			renderer.junit.add(renderer.junitSuite, renderer.timings, renderer.tap)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// renderJSON writes an event as a line of json, keeping track of the build's
+// exit status as render does.
+func (renderer *renderer) renderJSON(ev atc.Event) bool {
+	if renderer.timings != nil {
+		renderer.timings.record(ev)
+	}
+
+	ev = renderer.redact(ev)
+
+	switch e := ev.(type) {
+	case event.Log:
+		if renderer.tap != nil {
+			renderer.tap.write(string(e.Origin.ID), e.Payload)
+		}
+
+	case event.InitializeTask:
+		if renderer.taskInitializing != nil {
+			renderer.taskInitializing(ui.Stderr)
+		}
+
+	case event.StartTask:
+		if renderer.taskStarted != nil {
+			renderer.taskStarted(ui.Stderr)
+		}
+	}
+
+	err := renderer.json.Encode(jsonEvent{
+		Event:   ev.EventType(),
+		Version: ev.Version(),
+		Data:    ev,
+	})
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to write event as json: %s\n", err)
+	}
+
+	switch e := ev.(type) {
+	case event.FinishTask:
+		renderer.exitStatus = e.ExitStatus
+
+	case event.Status:
+		switch e.Status {
+		case "started":
+			return false
+		case "succeeded":
+		case "failed":
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 1
+			}
+		case "errored":
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 2
 			}
+		case "aborted":
+			if renderer.exitStatus == 0 {
+				renderer.exitStatus = 3
+			}
+		default:
+			renderer.exitStatus = 255
+			return true
+		}
+
+		if renderer.junit != nil {
+			renderer.junit.add(renderer.junitSuite, renderer.timings, renderer.tap)
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// timestamp is what each line of the logs is prefixed with: the wall-clock
+// time the line was received, and how long after the build started, or
+// failing that the first event was received, that was.
+func (renderer *renderer) timestamp() string {
+	received := renderer.received
+
+	start := renderer.firstReceived
+	if renderer.started != 0 {
+		start = time.Unix(renderer.started, 0)
+	}
+
+	return color.New(color.Faint).Sprintf("%s +%s", received.Format("15:04:05"), formatElapsed(received.Sub(start))) + " "
+}
+
+// formatElapsed formats a duration as a clock would, e.g. 1:02:03, or 02:03
+// when it's under an hour.
+func formatElapsed(elapsed time.Duration) string {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	seconds := int64(elapsed / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// record writes an event to the log file, redacted as renderJSON does.
+func (renderer *renderer) record(ev atc.Event, received time.Time) {
+	ev = renderer.redact(ev)
+
+	data, err := json.Marshal(ev)
+	if err == nil {
+		err = renderer.logFile.Encode(loggedEvent{
+			Time:    received,
+			Event:   ev.EventType(),
+			Version: ev.Version(),
+			Data:    data,
+		})
+	}
 
-			printColorFunc := printColor.SprintFunc()
-			fmt.Fprintf(dst, "%s\n", printColorFunc(e.Status))
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "failed to write event to the log file: %s\n", err)
+	}
+}
+
+// redact replaces the values to redact in the errors and task configs of
+// events written out whole. Logs are redacted as they're read, by
+// redactor.logs.
+func (renderer *renderer) redact(ev atc.Event) atc.Event {
+	switch e := ev.(type) {
+	case event.Error:
+		e.Message = renderer.redactor.Replace(e.Message)
+		return e
+
+	case event.InitializeTask:
+		e.TaskConfig = renderer.redactTaskConfig(e.TaskConfig)
+		return e
+
+	case event.StartTask:
+		e.TaskConfig = renderer.redactTaskConfig(e.TaskConfig)
+		return e
+	}
+
+	return ev
+}
 
-			return exitStatus
+// redactTaskConfig redacts what a task's config may have had interpolated
+// into it. Its params aren't sent with its events.
+func (renderer *renderer) redactTaskConfig(config event.TaskConfig) event.TaskConfig {
+	config.Image = renderer.redactor.Replace(config.Image)
+	config.Run.Path = renderer.redactor.Replace(config.Run.Path)
+	config.Run.Dir = renderer.redactor.Replace(config.Run.Dir)
+
+	if config.Run.Args != nil {
+		args := make([]string, len(config.Run.Args))
+		for i, arg := range config.Run.Args {
+			args[i] = renderer.redactor.Replace(arg)
 		}
+
+		config.Run.Args = args
 	}
 
-	return 255
+	return config
+}
+
+const redacted = "((redacted))"
+
+// redactor replaces secrets with ((redacted)). Logs arrive in chunks that
+// may split a secret between them, so the end of a step's logs that could be
+// the start of a secret is held back until its next chunk, or until another
+// kind of event, to be redacted along with it.
+type redactor struct {
+	*strings.Replacer
+
+	secrets []string
+
+	// held is the end of the last chunk of each step's logs, to prepend to
+	// its next one; origins are the steps with some held, in order
+	held    map[event.Origin]event.Log
+	origins []event.Origin
+}
+
+func newRedactor(secrets []string) *redactor {
+	sorted := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		if secret != "" {
+			sorted = append(sorted, secret)
+		}
+	}
+
+	// prefer the longest match, so that a secret containing another is
+	// redacted as a whole
+	sort.Sort(longestFirst(sorted))
+
+	oldnew := make([]string, 0, len(sorted)*2)
+	for _, secret := range sorted {
+		oldnew = append(oldnew, secret, redacted)
+	}
+
+	return &redactor{
+		Replacer: strings.NewReplacer(oldnew...),
+		secrets:  sorted,
+		held:     map[event.Origin]event.Log{},
+	}
+}
+
+// logs returns the events to write in place of ev: a log redacted up to
+// what's held back of it, or, for any other event, the logs held back
+// before it.
+func (redactor *redactor) logs(ev atc.Event) []atc.Event {
+	if len(redactor.secrets) == 0 {
+		return []atc.Event{ev}
+	}
+
+	log, isLog := ev.(event.Log)
+	if !isLog {
+		return append(redactor.flush(), ev)
+	}
+
+	text := log.Payload
+	if held, found := redactor.held[log.Origin]; found {
+		text = held.Payload + text
+		redactor.forget(log.Origin)
+	}
+
+	var payload strings.Builder
+
+	i := 0
+scan:
+	for i < len(text) {
+		rest := text[i:]
+
+		for _, secret := range redactor.secrets {
+			if strings.HasPrefix(rest, secret) {
+				payload.WriteString(redacted)
+				i += len(secret)
+				continue scan
+			}
+
+			if len(rest) < len(secret) && strings.HasPrefix(secret, rest) {
+				break scan
+			}
+		}
+
+		payload.WriteByte(text[i])
+		i++
+	}
+
+	if i < len(text) {
+		held := log
+		held.Payload = text[i:]
+
+		redactor.held[log.Origin] = held
+		redactor.origins = append(redactor.origins, log.Origin)
+	}
+
+	if payload.Len() == 0 {
+		return nil
+	}
+
+	log.Payload = payload.String()
+
+	return []atc.Event{log}
+}
+
+// flush returns the logs held back, which didn't turn out to be secrets.
+func (redactor *redactor) flush() []atc.Event {
+	var logs []atc.Event
+	for _, origin := range redactor.origins {
+		logs = append(logs, redactor.held[origin])
+	}
+
+	redactor.held = map[event.Origin]event.Log{}
+	redactor.origins = nil
+
+	return logs
+}
+
+func (redactor *redactor) forget(origin event.Origin) {
+	delete(redactor.held, origin)
+
+	for i, held := range redactor.origins {
+		if held == origin {
+			redactor.origins = append(redactor.origins[:i], redactor.origins[i+1:]...)
+			break
+		}
+	}
+}
+
+type longestFirst []string
+
+func (s longestFirst) Len() int           { return len(s) }
+func (s longestFirst) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s longestFirst) Less(i, j int) bool { return len(s[i]) > len(s[j]) }
+
+type logWriter struct {
+	dst   io.Writer
+	limit int64
+
+	// records, if set, writes each line of the logs as a record saying
+	// where it came from
+	records *logRecords
+
+	// stamp, if set, returns what to prefix each line of the logs with
+	stamp     func() string
+	lineStart bool
+
+	written   int64
+	truncated bool
+}
+
+func (writer *logWriter) write(origin event.Origin, payload string) {
+	if writer.truncated {
+		return
+	}
+
+	if writer.limit > 0 && writer.written+int64(len(payload)) > writer.limit {
+		writer.writeLogs(origin, ui.TruncateBytes(payload, int(writer.limit-writer.written)))
+		writer.flush()
+		fmt.Fprintf(writer.dst, "\n\x1b[1m[log output truncated after %d bytes]\x1b[0m\n", writer.limit)
+
+		writer.written = writer.limit
+		writer.truncated = true
+		return
+	}
+
+	writer.writeLogs(origin, payload)
+	writer.written += int64(len(payload))
+}
+
+func (writer *logWriter) writeLogs(origin event.Origin, payload string) {
+	if writer.records == nil {
+		paint := fmt.Sprint
+		if origin.Source == "stderr" {
+			paint = ui.StderrColor.Sprint
+		}
+
+		if writer.stamp != nil {
+			writer.writeStamped(payload, paint)
+			return
+		}
+
+		io.WriteString(writer.dst, paint(payload))
+		return
+	}
+
+	writer.records.write(writer.dst, origin, payload)
+}
+
+// writeStamped writes the payload with the stamp before every line it
+// starts, leaving the stamps uncolored.
+func (writer *logWriter) writeStamped(payload string, paint func(...interface{}) string) {
+	for payload != "" {
+		if writer.lineStart {
+			io.WriteString(writer.dst, writer.stamp())
+		}
+
+		line := payload
+		writer.lineStart = false
+
+		if i := strings.IndexByte(payload, '\n'); i != -1 {
+			line = payload[:i+1]
+			writer.lineStart = true
+		}
+
+		io.WriteString(writer.dst, paint(line))
+		payload = payload[len(line):]
+	}
+}
+
+func (writer *logWriter) flush() {
+	if writer.records != nil {
+		writer.records.flush(writer.dst)
+	}
+}
+
+// logRecords encodes the lines of a build's logs, which may arrive in parts,
+// with the step they came from and whether it was from stdout or stderr.
+// Lines longer than maxPartialLineSize are split into several records.
+type logRecords struct {
+	names   map[string]string
+	partial map[event.Origin]string
+}
+
+func (records *logRecords) write(dst io.Writer, origin event.Origin, payload string) {
+	lines := strings.Split(records.partial[origin]+payload, "\n")
+
+	partial := lines[len(lines)-1]
+	for len(partial) > maxPartialLineSize {
+		lines = append(lines[:len(lines)-1], partial[:maxPartialLineSize], partial[maxPartialLineSize:])
+		partial = lines[len(lines)-1]
+	}
+
+	records.partial[origin] = partial
+
+	for _, line := range lines[:len(lines)-1] {
+		io.WriteString(dst, ui.RecordLine(line, records.fields(origin)...))
+	}
+}
+
+// flush writes out the incomplete last lines of the logs.
+func (records *logRecords) flush(dst io.Writer) {
+	for origin, line := range records.partial {
+		if line != "" {
+			io.WriteString(dst, ui.RecordLine(line, records.fields(origin)...))
+		}
+
+		delete(records.partial, origin)
+	}
+}
+
+func (records *logRecords) fields(origin event.Origin) []ui.Field {
+	fields := []ui.Field{
+		{Key: "origin", Value: string(origin.ID)},
+		{Key: "source", Value: string(origin.Source)},
+	}
+
+	if name, found := records.names[string(origin.ID)]; found {
+		fields = append(fields, ui.Field{Key: "step", Value: name})
+	}
+
+	return fields
 }
@@ -1,7 +1,11 @@
 package eventstream_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -61,6 +65,19 @@ var _ = Describe("V1.0 Renderer", func() {
 		})
 	})
 
+	Context("when a Log event from stderr is received", func() {
+		BeforeEach(func() {
+			receivedEvents <- event.Log{
+				Origin:  event.Origin{ID: "2", Source: "stderr"},
+				Payload: "oops\n",
+			}
+		})
+
+		It("prints its payload in red", func() {
+			Expect(out.Contents()).To(ContainSubstring(ui.StderrColor.SprintFunc()("oops\n")))
+		})
+	})
+
 	Context("when an Error event is received", func() {
 		BeforeEach(func() {
 			receivedEvents <- event.Error{
@@ -147,6 +164,17 @@ var _ = Describe("V1.0 Renderer", func() {
 			})
 		})
 
+		Context("after the build started", func() {
+			BeforeEach(func() {
+				receivedEvents <- event.Status{Status: atc.StatusStarted, Time: 1000}
+				receivedEvents <- event.Status{Status: atc.StatusSucceeded, Time: 1092}
+			})
+
+			It("prints how long it took", func() {
+				Expect(out.Contents()).To(ContainSubstring(ui.SucceededColor.SprintFunc()("succeeded") + " after 1m32s\n"))
+			})
+		})
+
 		Context("with status 'failed'", func() {
 			BeforeEach(func() {
 				receivedEvents <- event.Status{
@@ -196,3 +224,498 @@ var _ = Describe("V1.0 Renderer", func() {
 		})
 	})
 })
+
+var _ = Describe("RenderWithOptions", func() {
+	var (
+		out    *gbytes.Buffer
+		stream *eventstreamfakes.FakeEventStream
+		events []atc.Event
+	)
+
+	BeforeEach(func() {
+		out = gbytes.NewBuffer()
+		stream = new(eventstreamfakes.FakeEventStream)
+
+		events = []atc.Event{
+			event.Log{Payload: "0123456789"},
+			event.Log{Payload: "abcdefghij"},
+			event.Log{Payload: "never shown"},
+			event.FinishTask{ExitStatus: 0},
+			event.Status{Status: atc.StatusSucceeded},
+		}
+
+		stream.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			ev := events[0]
+			events = events[1:]
+			return ev, nil
+		}
+	})
+
+	Context("when the logs exceed MaxLogBytes", func() {
+		It("truncates them with a marker but still reports the status", func() {
+			exitStatus := eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{MaxLogBytes: 15})
+			Expect(exitStatus).To(Equal(0))
+
+			Expect(out.Contents()).To(ContainSubstring("0123456789abcde\n\x1b[1m[log output truncated after 15 bytes]\x1b[0m\n"))
+			Expect(out.Contents()).NotTo(ContainSubstring("never shown"))
+			Expect(out).To(gbytes.Say("succeeded"))
+		})
+	})
+
+	Context("when values are to be redacted", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.Log{Payload: "logging in with hunter2 and hunter22\n"},
+				event.Error{Message: "hunter2 was rejected"},
+				event.Status{Status: atc.StatusSucceeded},
+			}
+		})
+
+		It("replaces them in logs and errors", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				Redact: []string{"hunter2", "hunter22", ""},
+			})
+
+			Expect(out).To(gbytes.Say(`logging in with \(\(redacted\)\) and \(\(redacted\)\)\n`))
+			Expect(out).To(gbytes.Say(`\(\(redacted\)\) was rejected`))
+			Expect(out.Contents()).NotTo(ContainSubstring("hunter2"))
+		})
+
+		Context("when they're split between logs", func() {
+			BeforeEach(func() {
+				origin := event.Origin{ID: "some-step"}
+				other := event.Origin{ID: "other-step"}
+
+				events = []atc.Event{
+					event.Log{Origin: origin, Payload: "logging in with hun"},
+					event.Log{Origin: other, Payload: "hun"},
+					event.Log{Origin: origin, Payload: "ter2 and hunter"},
+					event.Log{Origin: origin, Payload: "2\n"},
+					event.Log{Origin: other, Payload: "gry\n"},
+					event.Log{Origin: origin, Payload: "bye hun"},
+					event.Status{Status: atc.StatusSucceeded},
+				}
+			})
+
+			It("replaces them across the logs they're split between", func() {
+				logFile := new(bytes.Buffer)
+
+				eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+					Redact:  []string{"hunter2"},
+					LogFile: logFile,
+				})
+
+				Expect(out.Contents()).To(ContainSubstring("logging in with ((redacted)) and ((redacted))\nhungry\nbye hun"))
+				Expect(out.Contents()).NotTo(ContainSubstring("hunter2"))
+				Expect(out).To(gbytes.Say("succeeded"))
+
+				Expect(logFile.String()).To(ContainSubstring(`((redacted))`))
+				Expect(logFile.String()).NotTo(ContainSubstring("ter2"))
+			})
+		})
+
+		Context("when they were interpolated into the task's command", func() {
+			BeforeEach(func() {
+				config := event.TaskConfig{Run: event.TaskRunConfig{Path: "login", Args: []string{"--password", "hunter2"}}}
+
+				events = []atc.Event{
+					event.InitializeTask{TaskConfig: config},
+					event.StartTask{TaskConfig: config},
+					event.Status{Status: atc.StatusSucceeded},
+				}
+			})
+
+			It("replaces them in the command shown", func() {
+				eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+					Redact: []string{"hunter2"},
+				})
+
+				Expect(out).To(gbytes.Say(`running login --password \(\(redacted\)\)`))
+				Expect(out.Contents()).NotTo(ContainSubstring("hunter2"))
+			})
+
+			It("replaces them in the json and log file written", func() {
+				logFile := new(bytes.Buffer)
+
+				eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+					Redact:  []string{"hunter2"},
+					JSON:    true,
+					LogFile: logFile,
+				})
+
+				Expect(out).To(gbytes.Say(`"args":\["--password","\(\(redacted\)\)"\]`))
+				Expect(out.Contents()).NotTo(ContainSubstring("hunter2"))
+				Expect(logFile.String()).To(ContainSubstring(`((redacted))`))
+				Expect(logFile.String()).NotTo(ContainSubstring("hunter2"))
+			})
+		})
+	})
+
+	Context("when MaxLogBytes falls in the middle of a character", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.Log{Payload: "0123456789日本語"},
+				event.Status{Status: atc.StatusSucceeded},
+			}
+		})
+
+		It("truncates before the character", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{MaxLogBytes: 14})
+			Expect(out.Contents()).To(ContainSubstring("0123456789日\n\x1b[1m[log output truncated after 14 bytes]\x1b[0m\n"))
+		})
+	})
+
+	Context("when MaxLogBytes is zero", func() {
+		It("writes every log", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{})
+			Expect(out).To(gbytes.Say("0123456789abcdefghijnever shown"))
+		})
+	})
+
+	Context("when writing json", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.Log{Payload: "logging in with hunter2\n"},
+				event.FinishTask{ExitStatus: 1},
+				event.Status{Status: atc.StatusFailed},
+			}
+		})
+
+		It("writes each event as a line of json, redacted, and still reports the exit status", func() {
+			exitStatus := eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				JSON:        true,
+				Redact:      []string{"hunter2"},
+				StepSummary: true,
+			})
+			Expect(exitStatus).To(Equal(1))
+
+			lines := strings.Split(strings.TrimSuffix(string(out.Contents()), "\n"), "\n")
+			Expect(lines).To(HaveLen(3))
+
+			var log struct {
+				Event   string `json:"event"`
+				Version string `json:"version"`
+				Data    struct {
+					Payload string `json:"payload"`
+				} `json:"data"`
+			}
+			err := json.Unmarshal([]byte(lines[0]), &log)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(log.Event).To(Equal("log"))
+			Expect(log.Version).NotTo(BeEmpty())
+			Expect(log.Data.Payload).To(Equal("logging in with ((redacted))\n"))
+
+			Expect(lines[2]).To(ContainSubstring(`"event":"status"`))
+			Expect(lines[2]).To(ContainSubstring(`"status":"failed"`))
+		})
+	})
+
+	Context("when writing a log file", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.Log{Payload: "logging in with hunter2\n"},
+				event.FinishTask{ExitStatus: 1},
+				event.Status{Status: atc.StatusFailed},
+			}
+		})
+
+		It("writes every event to it, redacted, with when it was received", func() {
+			logFile := new(bytes.Buffer)
+
+			before := time.Now()
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				Redact:  []string{"hunter2"},
+				LogFile: logFile,
+			})
+
+			Expect(out).To(gbytes.Say(`logging in with \(\(redacted\)\)`))
+
+			lines := strings.Split(strings.TrimSuffix(logFile.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(3))
+
+			var logged struct {
+				Time  time.Time `json:"time"`
+				Event string    `json:"event"`
+			}
+			err := json.Unmarshal([]byte(lines[0]), &logged)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logged.Event).To(Equal("log"))
+			Expect(logged.Time).To(BeTemporally(">=", before.Truncate(time.Second)))
+
+			Expect(logFile.String()).NotTo(ContainSubstring("hunter2"))
+		})
+
+		It("can be read back and rendered again the same way", func() {
+			logFile := new(bytes.Buffer)
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{LogFile: logFile})
+
+			replayed := gbytes.NewBuffer()
+			exitStatus := eventstream.RenderWithOptions(replayed, eventstream.ReadLogFile(logFile), eventstream.RenderOptions{})
+			Expect(exitStatus).To(Equal(1))
+			Expect(replayed.Contents()).To(Equal(out.Contents()))
+		})
+	})
+
+	Context("when stamping logs with when they were received", func() {
+		var noColor bool
+
+		BeforeEach(func() {
+			noColor = color.NoColor
+			color.NoColor = true
+		})
+
+		AfterEach(func() {
+			color.NoColor = noColor
+		})
+
+		It("prefixes every line with the time and how long after the build started", func() {
+			started := time.Date(2018, 3, 1, 9, 30, 0, 0, time.Local)
+
+			logFile := new(bytes.Buffer)
+			encoder := json.NewEncoder(logFile)
+			for _, logged := range []struct {
+				at time.Duration
+				ev atc.Event
+			}{
+				{0, event.Status{Status: atc.StatusStarted, Time: started.Unix()}},
+				{65 * time.Second, event.Log{Payload: "one\ntw"}},
+				{66 * time.Second, event.Log{Payload: "o\nthree\n"}},
+				{time.Hour + 2*time.Minute + 3*time.Second, event.Log{Payload: "four\n"}},
+				{time.Hour + 2*time.Minute + 3*time.Second, event.Status{Status: atc.StatusSucceeded}},
+			} {
+				data, err := json.Marshal(logged.ev)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = encoder.Encode(map[string]interface{}{
+					"time":    started.Add(logged.at),
+					"event":   logged.ev.EventType(),
+					"version": logged.ev.Version(),
+					"data":    json.RawMessage(data),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			eventstream.RenderWithOptions(out, eventstream.ReadLogFile(logFile), eventstream.RenderOptions{
+				Timestamps: true,
+			})
+
+			Expect(string(out.Contents())).To(HavePrefix(
+				"09:31:05 +01:05 one\n" +
+					"09:31:05 +01:05 two\n" +
+					"09:31:06 +01:06 three\n" +
+					"10:32:03 +1:02:03 four\n",
+			))
+		})
+	})
+
+	Context("when told about tasks initializing and starting", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.InitializeTask{Origin: event.Origin{ID: "2"}},
+				event.StartTask{Origin: event.Origin{ID: "2"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "ls"}}},
+				event.Status{Status: atc.StatusSucceeded},
+			}
+		})
+
+		It("calls them in order, letting them write to the output", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				TaskInitializing: func(dst io.Writer) {
+					io.WriteString(dst, "task initializing\n")
+				},
+				TaskStarted: func(dst io.Writer) {
+					io.WriteString(dst, "task started\n")
+				},
+			})
+
+			Expect(out).To(gbytes.Say("task initializing\n"))
+			Expect(out).To(gbytes.Say("initializing"))
+			Expect(out).To(gbytes.Say("task started\n"))
+			Expect(out).To(gbytes.Say("running ls"))
+		})
+	})
+
+	Context("when output is structured", func() {
+		BeforeEach(func() {
+			ui.StructuredOutput = true
+
+			events = []atc.Event{
+				event.Log{Origin: event.Origin{ID: "2", Source: "stdout"}, Payload: "running "},
+				event.Log{Origin: event.Origin{ID: "3", Source: "stderr"}, Payload: "oh no\n"},
+				event.Log{Origin: event.Origin{ID: "2", Source: "stdout"}, Payload: "tests\nno newline"},
+				event.Status{Status: atc.StatusSucceeded},
+			}
+		})
+
+		AfterEach(func() {
+			ui.StructuredOutput = false
+		})
+
+		It("writes each line of the logs as a record of where it came from", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				StepNames: map[string]string{"2": "task: unit"},
+			})
+
+			records := new(bytes.Buffer)
+			writer := ui.NewRecordWriter(records, "logfmt", "info", new(sync.Mutex))
+			writer.Write(out.Contents())
+
+			lines := strings.Split(strings.TrimSpace(records.String()), "\n")
+			Expect(lines).To(HaveLen(4))
+			Expect(lines[0]).To(HaveSuffix(`msg="oh no" origin=3 source=stderr`))
+			Expect(lines[1]).To(HaveSuffix(`msg="running tests" origin=2 source=stdout step="task: unit"`))
+			Expect(lines[2]).To(HaveSuffix(`msg="no newline" origin=2 source=stdout step="task: unit"`))
+			Expect(lines[3]).To(HaveSuffix(`msg=succeeded`))
+		})
+
+		Context("when a step never ends its line", func() {
+			BeforeEach(func() {
+				long := strings.Repeat("x", 100*1024)
+
+				events = []atc.Event{
+					event.Log{Origin: event.Origin{ID: "2", Source: "stdout"}, Payload: long},
+					event.Log{Origin: event.Origin{ID: "2", Source: "stdout"}, Payload: long},
+					event.Status{Status: atc.StatusSucceeded},
+				}
+			})
+
+			It("splits it into records rather than holding it all", func() {
+				eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{})
+
+				records := new(bytes.Buffer)
+				writer := ui.NewRecordWriter(records, "logfmt", "info", new(sync.Mutex))
+				writer.Write(out.Contents())
+
+				lines := strings.Split(strings.TrimSpace(records.String()), "\n")
+				Expect(lines).To(HaveLen(5))
+				Expect(lines[0]).To(ContainSubstring(`msg=` + strings.Repeat("x", 64*1024) + ` origin=2`))
+				Expect(lines[4]).To(HaveSuffix(`msg=succeeded`))
+			})
+		})
+	})
+
+	Context("when a step summary is requested", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "some-input"}, ExitStatus: 0},
+				event.InitializeTask{Origin: event.Origin{ID: "2"}, Time: 100},
+				event.StartTask{Origin: event.Origin{ID: "2"}, Time: 110},
+				event.FinishTask{Origin: event.Origin{ID: "2"}, Time: 190, ExitStatus: 1},
+				event.StartTask{Origin: event.Origin{ID: "3"}, Time: 200},
+				event.Status{Status: atc.StatusFailed},
+			}
+		})
+
+		It("prints each step with its duration and status once the build completes", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				StepSummary: true,
+				StepNames:   map[string]string{"2": "task: one-off"},
+			})
+
+			Expect(out).To(gbytes.Say("failed\n"))
+			Expect(out).To(gbytes.Say(`step\s+duration\s+status`))
+			Expect(out).To(gbytes.Say(`get: some-input\s+n/a\s+succeeded`))
+			Expect(out).To(gbytes.Say(`task: one-off\s+1m30s\s+failed \(exit 1\)`))
+			Expect(out).To(gbytes.Say(`task running \s+n/a\s+did not finish`))
+		})
+
+		It("names steps from the plan", func() {
+			plan := atc.Plan{
+				ID: "0",
+				Do: &atc.DoPlan{
+					{ID: "1", Get: &atc.GetPlan{Name: "some-input"}},
+					{ID: "2", Task: &atc.TaskPlan{Name: "one-off"}},
+				},
+			}
+
+			Expect(eventstream.StepNames(plan)).To(Equal(map[string]string{
+				"1": "get: some-input",
+				"2": "task: one-off",
+			}))
+		})
+	})
+
+	Context("when no step summary is requested", func() {
+		It("doesn't print one", func() {
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{})
+			Expect(out.Contents()).NotTo(ContainSubstring("duration"))
+		})
+	})
+
+	Context("when containers can be looked up", func() {
+		BeforeEach(func() {
+			events = []atc.Event{
+				event.StartTask{Origin: event.Origin{ID: "1"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "known"}}},
+				event.StartTask{Origin: event.Origin{ID: "2"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "unknown"}}},
+				event.Status{Status: atc.StatusSucceeded},
+			}
+		})
+
+		It("notes the container each task starts in", func() {
+			var looked []string
+
+			eventstream.RenderWithOptions(out, stream, eventstream.RenderOptions{
+				Containers: func(planID string) (atc.Container, bool) {
+					looked = append(looked, planID)
+
+					if planID != "1" {
+						return atc.Container{}, false
+					}
+
+					return atc.Container{ID: "some-handle", WorkerName: "some-worker"}, true
+				},
+			})
+
+			Expect(looked).To(Equal([]string{"1", "2"}))
+
+			Expect(out).To(gbytes.Say(`running known`))
+			Expect(out).To(gbytes.Say(`in container some-handle on worker some-worker`))
+			Expect(out).To(gbytes.Say(`running unknown\x1b\[0m\n`))
+		})
+	})
+})
+
+type gatedWriter struct {
+	gate   <-chan struct{}
+	writes int
+	out    *gbytes.Buffer
+}
+
+func (writer *gatedWriter) Write(p []byte) (int, error) {
+	<-writer.gate
+	writer.writes++
+	return writer.out.Write(p)
+}
+
+var _ = Describe("Rendering a flood of logs", func() {
+	It("keeps reading events while the terminal is busy and batches its writes", func() {
+		drained := make(chan struct{})
+
+		writer := &gatedWriter{gate: drained, out: gbytes.NewBuffer()}
+
+		remaining := 1000
+
+		stream := new(eventstreamfakes.FakeEventStream)
+		stream.NextEventStub = func() (atc.Event, error) {
+			if remaining == 0 {
+				close(drained)
+				remaining = -1
+				return event.Status{Status: atc.StatusSucceeded}, nil
+			} else if remaining < 0 {
+				return nil, io.EOF
+			}
+
+			remaining--
+			return event.Log{Payload: "x\n"}, nil
+		}
+
+		exitStatus := eventstream.Render(writer, stream)
+		Expect(exitStatus).To(Equal(0))
+
+		Expect(writer.out.Contents()).To(HavePrefix(strings.Repeat("x\n", 1000)))
+		Expect(writer.writes).To(BeNumerically("<=", 3))
+	})
+})
@@ -0,0 +1,86 @@
+package eventstream
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var errBuildFinished = errors.New("build finished")
+
+// StepLog is the output of one step of a build.
+type StepLog struct {
+	Name string
+	Log  string
+}
+
+// StepLogs reads a build's events until it finishes, returning the output of
+// each of its steps in the order they started. Errors are included in the
+// output of the step they came from; those of the build itself are given as
+// a final "build" step.
+//
+// Steps are named as in the step summary, by the given names if any, e.g.
+// as returned by StepNames.
+func StepLogs(src EventSource, names map[string]string) ([]StepLog, error) {
+	timings := newStepTimings(names)
+	logs := map[string]*bytes.Buffer{}
+
+	stepLog := func(origin event.Origin) *bytes.Buffer {
+		id := timings.step(origin, "step").origin
+
+		buf, found := logs[id]
+		if !found {
+			buf = new(bytes.Buffer)
+			logs[id] = buf
+		}
+
+		return buf
+	}
+
+	buildLog := new(bytes.Buffer)
+
+	err := Each(src, func(ev atc.Event) error {
+		timings.record(ev)
+
+		switch e := ev.(type) {
+		case event.Log:
+			stepLog(e.Origin).WriteString(e.Payload)
+
+		case event.Error:
+			log := buildLog
+			if e.Origin.ID != "" {
+				log = stepLog(e.Origin)
+			}
+
+			log.WriteString(e.Message + "\n")
+
+		case event.Status:
+			if e.Status != atc.StatusStarted && e.Status != atc.StatusPending {
+				return errBuildFinished
+			}
+		}
+
+		return nil
+	})
+	if err != nil && err != errBuildFinished {
+		return nil, err
+	}
+
+	stepLogs := []StepLog{}
+	for _, step := range timings.steps {
+		log := ""
+		if buf, found := logs[step.origin]; found {
+			log = buf.String()
+		}
+
+		stepLogs = append(stepLogs, StepLog{Name: step.name, Log: log})
+	}
+
+	if buildLog.Len() > 0 {
+		stepLogs = append(stepLogs, StepLog{Name: "build", Log: buildLog.String()})
+	}
+
+	return stepLogs, nil
+}
@@ -0,0 +1,63 @@
+package eventstream_test
+
+import (
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/eventstream"
+	"github.com/concourse/fly/eventstream/eventstreamfakes"
+)
+
+var _ = Describe("StepLogs", func() {
+	var (
+		events []atc.Event
+		source *eventstreamfakes.FakeEventSource
+	)
+
+	BeforeEach(func() {
+		events = []atc.Event{
+			event.Log{Origin: event.Origin{ID: "1"}, Payload: "fetching\n"},
+			event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "some-input"}},
+			event.StartTask{Origin: event.Origin{ID: "2"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "/some/script"}}},
+			event.Log{Origin: event.Origin{ID: "2"}, Payload: "hello "},
+			event.Log{Origin: event.Origin{ID: "2"}, Payload: "world\n"},
+			event.Error{Origin: event.Origin{ID: "2"}, Message: "oh no"},
+			event.Error{Message: "build blew up"},
+			event.Status{Status: atc.StatusErrored},
+			event.Log{Origin: event.Origin{ID: "3"}, Payload: "never seen\n"},
+		}
+
+		source = new(eventstreamfakes.FakeEventSource)
+		source.NextEventStub = func() (atc.Event, error) {
+			if len(events) == 0 {
+				return nil, io.EOF
+			}
+
+			ev := events[0]
+			events = events[1:]
+			return ev, nil
+		}
+	})
+
+	It("returns the output of each step, in order, until the build finishes", func() {
+		logs, err := eventstream.StepLogs(source, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logs).To(Equal([]eventstream.StepLog{
+			{Name: "get: some-input", Log: "fetching\n"},
+			{Name: "task running /some/script", Log: "hello world\noh no\n"},
+			{Name: "build", Log: "build blew up\n"},
+		}))
+	})
+
+	It("names steps by the given names", func() {
+		logs, err := eventstream.StepLogs(source, map[string]string{"2": "task: unit"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(logs[1].Name).To(Equal("task: unit"))
+	})
+})
@@ -0,0 +1,222 @@
+package eventstream
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+)
+
+// StepNames names every step of a plan by its ID, which events give as their
+// origin, e.g. for RenderOptions.StepNames.
+func StepNames(plan atc.Plan) map[string]string {
+	names := map[string]string{}
+	for _, step := range PlanSteps(plan) {
+		names[step.ID] = step.Type + ": " + step.Name
+	}
+
+	return names
+}
+
+// PlanStep is a step of a plan that runs in a container of its own.
+type PlanStep struct {
+	ID   string
+	Type string
+	Name string
+}
+
+// PlanSteps returns the gets, puts and tasks of a plan, in the order they
+// appear in it.
+func PlanSteps(plan atc.Plan) []PlanStep {
+	var steps []PlanStep
+	collectSteps(plan, &steps)
+	return steps
+}
+
+func collectSteps(plan atc.Plan, steps *[]PlanStep) {
+	switch {
+	case plan.Get != nil:
+		*steps = append(*steps, PlanStep{ID: string(plan.ID), Type: "get", Name: plan.Get.Name})
+	case plan.Put != nil:
+		*steps = append(*steps, PlanStep{ID: string(plan.ID), Type: "put", Name: plan.Put.Name})
+	case plan.DependentGet != nil:
+		*steps = append(*steps, PlanStep{ID: string(plan.ID), Type: "get", Name: plan.DependentGet.Name})
+	case plan.Task != nil:
+		*steps = append(*steps, PlanStep{ID: string(plan.ID), Type: "task", Name: plan.Task.Name})
+	case plan.Aggregate != nil:
+		for _, step := range *plan.Aggregate {
+			collectSteps(step, steps)
+		}
+	case plan.Do != nil:
+		for _, step := range *plan.Do {
+			collectSteps(step, steps)
+		}
+	case plan.Retry != nil:
+		for _, step := range *plan.Retry {
+			collectSteps(step, steps)
+		}
+	case plan.OnSuccess != nil:
+		collectSteps(plan.OnSuccess.Step, steps)
+		collectSteps(plan.OnSuccess.Next, steps)
+	case plan.OnFailure != nil:
+		collectSteps(plan.OnFailure.Step, steps)
+		collectSteps(plan.OnFailure.Next, steps)
+	case plan.Ensure != nil:
+		collectSteps(plan.Ensure.Step, steps)
+		collectSteps(plan.Ensure.Next, steps)
+	case plan.Try != nil:
+		collectSteps(plan.Try.Step, steps)
+	case plan.Timeout != nil:
+		collectSteps(plan.Timeout.Step, steps)
+	}
+}
+
+type stepTiming struct {
+	origin string
+	name   string
+
+	// start and finish are only known for steps whose events carry times,
+	// i.e. tasks
+	start  int64
+	finish int64
+
+	status string
+}
+
+// stepTimings tracks each step of a build from its events, in the order
+// they started.
+type stepTimings struct {
+	names map[string]string
+
+	steps  []*stepTiming
+	byStep map[string]*stepTiming
+}
+
+func newStepTimings(names map[string]string) *stepTimings {
+	return &stepTimings{
+		names:  names,
+		byStep: map[string]*stepTiming{},
+	}
+}
+
+func (timings *stepTimings) step(origin event.Origin, fallbackName string) *stepTiming {
+	id := string(origin.ID)
+
+	step, found := timings.byStep[id]
+	if !found {
+		name, named := timings.names[id]
+		if !named {
+			name = fallbackName
+		}
+
+		step = &stepTiming{origin: id, name: name}
+		timings.byStep[id] = step
+		timings.steps = append(timings.steps, step)
+	}
+
+	return step
+}
+
+func (timings *stepTimings) record(ev atc.Event) {
+	switch e := ev.(type) {
+	case event.InitializeTask:
+		step := timings.step(e.Origin, "task")
+		if step.start == 0 {
+			step.start = e.Time
+		}
+
+	case event.StartTask:
+		step := timings.step(e.Origin, "task")
+		if step.start == 0 {
+			step.start = e.Time
+		}
+
+		// without the plan, tasks can only be told apart by what they run
+		if _, named := timings.names[step.origin]; !named {
+			step.name = "task running " + e.TaskConfig.Run.Path
+		}
+
+	case event.FinishTask:
+		step := timings.step(e.Origin, "task")
+		step.finish = e.Time
+		step.status = exitStatusOf(e.ExitStatus)
+
+	case event.FinishGet:
+		step := timings.step(e.Origin, "get: "+e.Plan.Name)
+		step.status = exitStatusOf(e.ExitStatus)
+
+		// the step may have been seen first by its logs, before its name was
+		// known
+		if _, named := timings.names[step.origin]; !named {
+			step.name = "get: " + e.Plan.Name
+		}
+
+	case event.FinishPut:
+		step := timings.step(e.Origin, "put: "+e.Plan.Name)
+		step.status = exitStatusOf(e.ExitStatus)
+
+		if _, named := timings.names[step.origin]; !named {
+			step.name = "put: " + e.Plan.Name
+		}
+
+	case event.Error:
+		if e.Origin.ID != "" {
+			timings.step(e.Origin, "step").status = "errored"
+		}
+	}
+}
+
+func exitStatusOf(exitStatus int) string {
+	if exitStatus == 0 {
+		return "succeeded"
+	}
+
+	return fmt.Sprintf("failed (exit %d)", exitStatus)
+}
+
+func (timings *stepTimings) render(dst io.Writer) error {
+	if len(timings.steps) == 0 {
+		return nil
+	}
+
+	table := ui.Table{
+		Headers: ui.TableRow{
+			{Contents: "step", Color: color.New(color.Bold)},
+			{Contents: "duration", Color: color.New(color.Bold)},
+			{Contents: "status", Color: color.New(color.Bold)},
+		},
+	}
+
+	for _, step := range timings.steps {
+		duration := ui.TableCell{Contents: "n/a", Color: color.New(color.Faint)}
+		if step.start != 0 && step.finish >= step.start {
+			duration = ui.TableCell{Contents: (time.Duration(step.finish-step.start) * time.Second).String()}
+		}
+
+		status := ui.TableCell{Contents: step.status}
+		switch {
+		case step.status == "":
+			status = ui.TableCell{Contents: "did not finish", Color: ui.PendingColor}
+		case step.status == "succeeded":
+			status.Color = ui.SucceededColor
+		case step.status == "errored":
+			status.Color = ui.ErroredColor
+		default:
+			status.Color = ui.FailedColor
+		}
+
+		table.Data = append(table.Data, ui.TableRow{
+			{Contents: step.name},
+			duration,
+			status,
+		})
+	}
+
+	fmt.Fprintln(dst)
+
+	return table.Render(dst, true)
+}
@@ -0,0 +1,39 @@
+package eventstream
+
+import (
+	"fmt"
+
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+)
+
+// TargetName returns the target fly was run against, to say how to upgrade
+// fly from it when it's too old for the ATC's events.
+var TargetName = func() string { return "" }
+
+// unparsableEvents tracks the kinds of events skipped for being of a type or
+// version this fly doesn't understand, e.g. from an ATC newer than it, so
+// that each is warned about once rather than hidden.
+type unparsableEvents map[string]bool
+
+// skip says whether err is from parsing such an event, warning of it the
+// first time.
+func (skipped unparsableEvents) skip(err error) bool {
+	switch err.(type) {
+	case event.UnknownEventTypeError, event.UnknownEventVersionError:
+	default:
+		return false
+	}
+
+	if !skipped[err.Error()] {
+		skipped[err.Error()] = true
+		target := TargetName()
+		if target == "" {
+			target = "<target>"
+		}
+
+		fmt.Fprintf(ui.Stderr, "skipping events this fly doesn't understand (%s); this fly is older than the ATC, so please run fly -t %s sync to see them\n", err, target)
+	}
+
+	return true
+}
@@ -60,6 +60,31 @@ var _ = Describe("AbortBuild", func() {
 			})
 		})
 
+		Context("and the build has already finished", func() {
+			BeforeEach(func() {
+				finishedBuild := expectedBuild
+				finishedBuild.Status = "succeeded"
+
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/23"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, finishedBuild),
+					),
+				)
+			})
+
+			It("does not abort it and exits nonzero", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "abort", "-b", "23")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+
+				Expect(sess.Err).To(gbytes.Say("error: build 23 has already finished: succeeded"))
+			})
+		})
+
 		Context("and the build id does not exist", func() {
 			BeforeEach(func() {
 				expectedURL := "/api/v1/builds/42"
@@ -170,6 +195,86 @@ var _ = Describe("AbortBuild", func() {
 		})
 	})
 
+	Context("when the build is given as pipeline/job#build", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/my-pipeline/jobs/my-job/builds/42"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, expectedBuild),
+				),
+
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", expectedAbortURL),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+		})
+
+		It("aborts the build of that job", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "abort-build", "-b", "my-pipeline/my-job#42")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say("build successfully aborted"))
+		})
+
+		Context("and the job is given as well", func() {
+			It("errors", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "abort-build", "-j", "my-pipeline/my-job", "-b", "my-pipeline/my-job#42")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+
+				Expect(sess.Err).To(gbytes.Say("--job cannot be given as well"))
+			})
+		})
+	})
+
+	Context("when the build is given as pipeline/job", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/my-pipeline/jobs/my-job"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Job{Name: "my-job", NextBuild: &expectedBuild}),
+				),
+
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", expectedAbortURL),
+					ghttp.RespondWith(http.StatusNoContent, ""),
+				),
+			)
+		})
+
+		It("aborts the job's latest build", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "abort-build", "-b", "my-pipeline/my-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say("build successfully aborted"))
+		})
+	})
+
+	Context("when the build is given as pipeline/job# without a number", func() {
+		It("errors", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "abort-build", "-b", "my-pipeline/my-job#")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			Expect(sess.Err).To(gbytes.Say("has no number after the #"))
+		})
+	})
+
 	Context("when the build or pipeline does not exist", func() {
 		BeforeEach(func() {
 			expectedJobBuildURL := "/api/v1/teams/main/pipelines/my-pipeline/jobs/my-job/builds/42"
@@ -0,0 +1,106 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("audit log", func() {
+		var (
+			tmpDir  string
+			logPath string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "fly-audit")
+			Expect(err).NotTo(HaveOccurred())
+
+			logPath = filepath.Join(tmpDir, "audit.log")
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		readEntries := func() []map[string]interface{} {
+			contents, err := ioutil.ReadFile(logPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			var entries []map[string]interface{}
+			decoder := json.NewDecoder(bytes.NewReader(contents))
+			for decoder.More() {
+				var entry map[string]interface{}
+				Expect(decoder.Decode(&entry)).To(Succeed())
+				entries = append(entries, entry)
+			}
+
+			return entries
+		}
+
+		Context("when FLY_AUDIT_LOG is set to a file", func() {
+			It("records the command, target, and outcome", func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/containers"),
+						ghttp.RespondWith(http.StatusOK, "[]"),
+					),
+				)
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "containers")
+				flyCmd.Env = append(os.Environ(), "FLY_AUDIT_LOG="+logPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sess).Should(gexec.Exit(0))
+
+				entries := readEntries()
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0]["command"]).To(Equal("containers"))
+				Expect(entries[0]["target"]).To(Equal(targetName))
+				Expect(entries[0]["exit_code"]).To(Equal(float64(0)))
+				Expect(entries[0]["time"]).NotTo(BeEmpty())
+			})
+
+			It("records failures", func() {
+				flyCmd := exec.Command(flyPath, "-t", "bogus-target", "containers")
+				flyCmd.Env = append(os.Environ(), "FLY_AUDIT_LOG="+logPath)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sess).Should(gexec.Exit(1))
+
+				entries := readEntries()
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0]["command"]).To(Equal("containers"))
+				Expect(entries[0]["target"]).To(Equal("bogus-target"))
+				Expect(entries[0]["exit_code"]).To(Equal(float64(1)))
+			})
+		})
+
+		Context("when FLY_AUDIT_LOG is not set", func() {
+			It("does not record anything", func() {
+				flyCmd := exec.Command(flyPath, "-t", "bogus-target", "containers")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sess).Should(gexec.Exit(1))
+
+				_, err = os.Stat(logPath)
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+	})
+})
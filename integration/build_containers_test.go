@@ -0,0 +1,121 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/ui"
+	"github.com/fatih/color"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	var containers []atc.Container
+
+	BeforeEach(func() {
+		containers = []atc.Container{
+			{ID: "get-handle", WorkerName: "worker-1", Type: "get", StepName: "some-input", BuildID: 128},
+			{ID: "task-handle", WorkerName: "worker-2", Type: "task", StepName: "unit", Attempt: "1", BuildID: 128},
+		}
+	})
+
+	Describe("build-containers", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/128"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers", "build_id=128"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, containers),
+				),
+			)
+		})
+
+		It("lists the containers of the build's steps", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "build-containers", "-b", "128")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(PrintTable(ui.Table{
+				Headers: ui.TableRow{
+					{Contents: "step", Color: color.New(color.Bold)},
+					{Contents: "type", Color: color.New(color.Bold)},
+					{Contents: "attempt", Color: color.New(color.Bold)},
+					{Contents: "worker", Color: color.New(color.Bold)},
+					{Contents: "handle", Color: color.New(color.Bold)},
+				},
+				Data: []ui.TableRow{
+					{{Contents: "some-input"}, {Contents: "get"}, {Contents: "n/a"}, {Contents: "worker-1"}, {Contents: "get-handle"}},
+					{{Contents: "unit"}, {Contents: "task"}, {Contents: "1"}, {Contents: "worker-2"}, {Contents: "task-handle"}},
+				},
+			}))
+		})
+	})
+
+	Describe("watching a build", func() {
+		BeforeEach(func() {
+			plan := json.RawMessage(`{"id": "1", "do": [
+				{"id": "2", "get": {"type": "git", "name": "some-input", "resource": "some-input"}},
+				{"id": "3", "task": {"name": "unit"}}
+			]}`)
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/plan",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.PublicBuildPlan{Schema: "exec.v2", Plan: &plan}),
+			)
+
+			atcServer.RouteToHandler("GET", "/api/v1/containers", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, containers)(w, r)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				events := []atc.Event{
+					event.StartTask{Origin: event.Origin{ID: "3"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "run-tests"}}},
+					event.Log{Origin: event.Origin{ID: "3"}, Payload: "ok\n"},
+					event.FinishTask{Origin: event.Origin{ID: "3"}, ExitStatus: 0},
+					event.Status{Status: atc.StatusSucceeded},
+				}
+
+				for _, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		It("notes the worker and container each task runs in", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "128")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say("running run-tests"))
+			Expect(sess.Out).To(gbytes.Say("in container task-handle on worker worker-2"))
+			Expect(sess.Out).To(gbytes.Say("ok"))
+		})
+	})
+})
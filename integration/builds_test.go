@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"os/exec"
 	"time"
@@ -348,5 +349,40 @@ var _ = Describe("Fly CLI", func() {
 				})
 			})
 		})
+
+		Context("when printing json", func() {
+			BeforeEach(func() {
+				cmdArgs = append(cmdArgs, "-j", "some-pipeline/some-job", "--json")
+
+				expectedURL = "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/builds"
+				queryParams = "limit=50"
+				returnedStatusCode = http.StatusOK
+				returnedBuilds = []atc.Build{
+					{
+						ID:           3,
+						PipelineName: "some-pipeline",
+						JobName:      "some-job",
+						Name:         "63",
+						Status:       "succeeded",
+						StartTime:    succeededBuildStartTime.Unix(),
+						EndTime:      succeededBuildEndTime.Unix(),
+					},
+				}
+			})
+
+			It("prints each build with its duration", func() {
+				Eventually(session).Should(gexec.Exit(0))
+
+				var builds []map[string]interface{}
+				err := json.Unmarshal(session.Out.Contents(), &builds)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(builds).To(HaveLen(1))
+				Expect(builds[0]["id"]).To(Equal(float64(3)))
+				Expect(builds[0]["job_name"]).To(Equal("some-job"))
+				Expect(builds[0]["status"]).To(Equal("succeeded"))
+				Expect(builds[0]["duration"]).To(Equal("1h15m0s"))
+			})
+		})
 	})
 })
@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+	"sync"
+
+	"github.com/concourse/atc"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("builds --watch", func() {
+		var (
+			lock     sync.Mutex
+			requests int
+		)
+
+		BeforeEach(func() {
+			requests = 0
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				requests++
+				status := "started"
+				if requests > 1 {
+					status = "succeeded"
+				}
+				lock.Unlock()
+
+				ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Build{
+					{
+						ID:           2,
+						PipelineName: "some-pipeline",
+						JobName:      "some-job",
+						Name:         "62",
+						Status:       status,
+					},
+				})(w, r)
+			})
+		})
+
+		It("keeps refreshing the builds until interrupted", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "builds", "--watch", "--interval", "50ms")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gbytes.Say("every 50ms: fly builds"))
+			Eventually(sess).Should(gbytes.Say(`some-pipeline/some-job\s+62\s+started`))
+			Eventually(sess).Should(gbytes.Say(`some-pipeline/some-job\s+62\s+succeeded`))
+
+			sess.Interrupt()
+			Eventually(sess).Should(gexec.Exit())
+		})
+
+		It("rejects a non-positive interval", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "builds", "--watch", "--interval", "0s")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say("invalid interval 0s"))
+		})
+	})
+})
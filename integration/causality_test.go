@@ -0,0 +1,78 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/ui"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("causality", func() {
+		versionsPath := "/api/v1/teams/main/pipelines/some-pipeline/resources/some-resource/versions"
+
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", versionsPath),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.VersionedResource{
+						{ID: 7, Version: atc.Version{"ref": "abc123"}, Enabled: true},
+					}),
+				),
+			)
+		})
+
+		It("lists the builds that produced and used the version, and the jobs that used it", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", versionsPath+"/7/output_of"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Build{
+						{ID: 10, JobName: "bump", Name: "3", Status: "succeeded"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", versionsPath+"/7/input_to"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Build{
+						{ID: 12, JobName: "unit", Name: "8", Status: "failed"},
+						{ID: 11, JobName: "unit", Name: "7", Status: "succeeded"},
+						{ID: 13, JobName: "deploy", Name: "2", Status: "started"},
+					}),
+				),
+			)
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "causality", "-r", "some-pipeline/some-resource", "--version", "ref:abc123")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(PrintTable(ui.Table{
+				Data: []ui.TableRow{
+					{{Contents: "10"}, {Contents: "bump"}, {Contents: "3"}, {Contents: "succeeded"}, {Contents: "output"}},
+					{{Contents: "12"}, {Contents: "unit"}, {Contents: "8"}, {Contents: "failed"}, {Contents: "input"}},
+					{{Contents: "11"}, {Contents: "unit"}, {Contents: "7"}, {Contents: "succeeded"}, {Contents: "input"}},
+					{{Contents: "13"}, {Contents: "deploy"}, {Contents: "2"}, {Contents: "started"}, {Contents: "input"}},
+				},
+			}))
+
+			Expect(sess.Out).To(gbytes.Say("jobs that used this version:\n  some-pipeline/deploy\n  some-pipeline/unit\n"))
+		})
+
+		It("fails for a version the resource does not have", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "causality", "-r", "some-pipeline/some-resource", "--version", "ref:bogus")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say(`resource 'some-resource' has no version {"ref":"bogus"}`))
+		})
+	})
+})
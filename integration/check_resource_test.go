@@ -2,8 +2,11 @@ package integration_test
 
 import (
 	"net/http"
+	"os"
 	"os/exec"
 
+	"github.com/concourse/atc"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -122,4 +125,56 @@ var _ = Describe("CheckResource", func() {
 			Expect(sess.Err).To(gbytes.Say("pipeline 'mypipeline' or resource 'myresource' not found"))
 		})
 	})
+
+	Context("completion", func() {
+		BeforeEach(func() {
+			config := atc.Config{
+				Resources: atc.ResourceConfigs{
+					{Name: "some-repo"},
+					{Name: "some-image"},
+					{Name: "another-repo"},
+				},
+			}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+		})
+
+		complete := func() *gexec.Session {
+			flyCmd = exec.Command(flyPath, "-t", targetName, "check-resource", "-r", "some-pipeline/some-")
+			flyCmd.Env = append(os.Environ(), "GO_FLAGS_COMPLETION=1")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			return sess
+		}
+
+		It("returns the matching resources of the pipeline", func() {
+			sess := complete()
+
+			Expect(sess.Out).To(gbytes.Say("some-pipeline/some-repo"))
+			Expect(sess.Out).To(gbytes.Say("some-pipeline/some-image"))
+			Expect(sess.Out).NotTo(gbytes.Say("another-repo"))
+		})
+
+		It("reuses the pipeline's config when completing again shortly after", func() {
+			complete()
+
+			atcServer.AppendHandlers(infoHandler())
+			requests := len(atcServer.ReceivedRequests())
+
+			sess := complete()
+			Expect(sess.Out).To(gbytes.Say("some-pipeline/some-repo"))
+
+			// only the target's version is checked again
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(requests + 1))
+		})
+	})
 })
@@ -0,0 +1,69 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("CheckResourceType", func() {
+	Context("when ATC request succeeds", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/teams/main/pipelines/mypipeline/resource-types/mytype/check"),
+					ghttp.VerifyJSON(`{"from":null}`),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/mypipeline/resource-types"),
+					ghttp.RespondWith(http.StatusOK, `[
+						{"name": "othertype", "type": "docker-image", "version": {"digest": "sha256:other"}},
+						{"name": "mytype", "type": "docker-image", "version": {"digest": "sha256:abc"}}
+					]`),
+				),
+			)
+		})
+
+		It("checks the resource type and reports the version of its image", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "check-resource-type", "-r", "mypipeline/mytype")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say("checked 'mytype'"))
+			Expect(sess.Out).To(gbytes.Say(`using version {"digest":"sha256:abc"}`))
+			Expect(sess.Out).NotTo(gbytes.Say("sha256:other"))
+		})
+	})
+
+	Context("when the resource type does not exist", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/teams/main/pipelines/mypipeline/resource-types/mytype/check"),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				),
+			)
+		})
+
+		It("fails", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "check-resource-type", "-r", "mypipeline/mytype")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			Expect(sess.Err).To(gbytes.Say("pipeline or resource type 'mypipeline/mytype' not found"))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package integration_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("clear-task-cache", func() {
+		cachePath := "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/tasks/some-task/cache"
+
+		var (
+			stdin io.Writer
+			args  []string
+			sess  *gexec.Session
+		)
+
+		BeforeEach(func() {
+			args = []string{"-j", "some-pipeline/some-job", "-s", "some-task"}
+		})
+
+		JustBeforeEach(func() {
+			var err error
+
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "clear-task-cache"}, args...)...)
+			stdin, err = flyCmd.StdinPipe()
+			Expect(err).NotTo(HaveOccurred())
+
+			sess, err = gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("when the user confirms", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", cachePath, ""),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]int{"caches_removed": 2}),
+					),
+				)
+			})
+
+			It("clears every cache of the step", func() {
+				Eventually(sess).Should(gbytes.Say("every cache of task 'some-task' in job 'some-job'"))
+				Eventually(sess).Should(gbytes.Say(`are you sure\? \[yN\]: `))
+				fmt.Fprintf(stdin, "y\n")
+
+				Eventually(sess).Should(gbytes.Say("2 caches removed"))
+				Eventually(sess).Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when given a cache path", func() {
+			BeforeEach(func() {
+				args = append(args, "--cache-path", "node_modules", "-n")
+
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", cachePath, "cachePath=node_modules"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]int{"caches_removed": 1}),
+					),
+				)
+			})
+
+			It("clears only that cache", func() {
+				Eventually(sess).Should(gbytes.Say("the cache at 'node_modules'"))
+				Eventually(sess).Should(gbytes.Say("1 caches removed"))
+				Eventually(sess).Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when the user does not confirm", func() {
+			It("bails out", func() {
+				Eventually(sess).Should(gbytes.Say(`are you sure\? \[yN\]: `))
+				fmt.Fprintf(stdin, "n\n")
+
+				Eventually(sess).Should(gbytes.Say("bailing out"))
+				Eventually(sess).Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when the step is not found", func() {
+			BeforeEach(func() {
+				args = append(args, "-n")
+
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", cachePath),
+						ghttp.RespondWith(http.StatusNotFound, nil),
+					),
+				)
+			})
+
+			It("fails", func() {
+				Eventually(sess.Err).Should(gbytes.Say("pipeline, job or step 'some-pipeline/some-job/some-task' not found"))
+				Eventually(sess).Should(gexec.Exit(1))
+			})
+		})
+	})
+})
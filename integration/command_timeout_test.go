@@ -0,0 +1,60 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("--command-timeout", func() {
+		var release chan struct{}
+
+		BeforeEach(func() {
+			release = make(chan struct{})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+					func(w http.ResponseWriter, r *http.Request) {
+						<-release
+					},
+				),
+			)
+		})
+
+		AfterEach(func() {
+			close(release)
+		})
+
+		It("exits 124 when the command hasn't finished in time", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "--command-timeout", "500ms", "pipelines")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Exited, 5*time.Second).Should(BeClosed())
+			Expect(sess.ExitCode()).To(Equal(124))
+
+			Expect(sess.Err).To(gbytes.Say("error: fly did not finish within --command-timeout of 500ms"))
+		})
+
+		It("rejects a timeout that isn't a duration", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "--command-timeout", "soon", "pipelines")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("invalid --command-timeout 'soon'"))
+		})
+	})
+})
@@ -0,0 +1,194 @@
+package integration_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/archive"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/onsi/gomega/types"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("cp", func() {
+		var localDir string
+
+		upgrader := websocket.Upgrader{}
+
+		// copyHandler plays the container's side of a copy, checking the
+		// process fly runs, reading its stdin and answering with stdout
+		copyHandler := func(path string, args types.GomegaMatcher, stdout []byte, stdin *bytes.Buffer) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/containers/container-id-1/hijack"),
+				func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+
+					conn, err := upgrader.Upgrade(w, r, nil)
+					Expect(err).NotTo(HaveOccurred())
+
+					defer conn.Close()
+
+					var processSpec atc.HijackProcessSpec
+					err = conn.ReadJSON(&processSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processSpec.Path).To(Equal(path))
+					Expect(processSpec.Args).To(args)
+					Expect(processSpec.Dir).To(Equal("/tmp/build/some-guid"))
+					Expect(processSpec.User).To(Equal("root"))
+
+					for {
+						var input atc.HijackInput
+						err = conn.ReadJSON(&input)
+						Expect(err).NotTo(HaveOccurred())
+
+						if input.Closed {
+							break
+						}
+
+						stdin.Write(input.Stdin)
+					}
+
+					if len(stdout) > 0 {
+						err = conn.WriteJSON(atc.HijackOutput{Stdout: stdout})
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					exitStatus := 0
+					err = conn.WriteJSON(atc.HijackOutput{ExitStatus: &exitStatus})
+					Expect(err).NotTo(HaveOccurred())
+				},
+			)
+		}
+
+		BeforeEach(func() {
+			var err error
+			localDir, err = ioutil.TempDir("", "fly-cp")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers", "build_id=128&step_name=unit"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Container{
+						{ID: "container-id-1", BuildID: 128, Type: "task", StepName: "unit", WorkingDirectory: "/tmp/build/some-guid", User: "root"},
+					}),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(localDir)
+		})
+
+		Context("when copying out of a container", func() {
+			BeforeEach(func() {
+				tarball := new(bytes.Buffer)
+				tarWriter := tar.NewWriter(tarball)
+
+				contents := []byte("<html>all green</html>")
+				err := tarWriter.WriteHeader(&tar.Header{Name: "report.html", Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = tarWriter.Write(contents)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = tarWriter.Close()
+				Expect(err).NotTo(HaveOccurred())
+
+				atcServer.AppendHandlers(
+					copyHandler("tar", Equal([]string{"-cf", "-", "-C", "out", "report.html"}), tarball.Bytes(), new(bytes.Buffer)),
+				)
+			})
+
+			It("extracts what the container sends into a local directory", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "cp", "-s", "unit", "128:out/report.html", localDir)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				contents, err := ioutil.ReadFile(filepath.Join(localDir, "report.html"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("<html>all green</html>"))
+			})
+
+			It("extracts it as a new local file", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "cp", "-b", "128", "-s", "unit", ":out/report.html", filepath.Join(localDir, "renamed.html"))
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				contents, err := ioutil.ReadFile(filepath.Join(localDir, "renamed.html"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("<html>all green</html>"))
+			})
+		})
+
+		Context("when copying into a container", func() {
+			var stdin *bytes.Buffer
+
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(localDir, "fix.sh"), []byte("echo fixed"), 0755)
+				Expect(err).NotTo(HaveOccurred())
+
+				stdin = new(bytes.Buffer)
+
+				atcServer.AppendHandlers(
+					copyHandler("sh", SatisfyAll(HaveLen(5), ContainElement("/tmp/fix.sh"), ContainElement("fix.sh")), nil, stdin),
+				)
+			})
+
+			It("streams a tarball of the local file to the container", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "cp", "-s", "unit", filepath.Join(localDir, "fix.sh"), "128:/tmp/fix.sh")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				extracted, err := ioutil.TempDir("", "fly-cp-extracted")
+				Expect(err).NotTo(HaveOccurred())
+
+				defer os.RemoveAll(extracted)
+
+				err = archive.Extract(stdin, extracted)
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := ioutil.ReadFile(filepath.Join(extracted, "fix.sh"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(Equal("echo fixed"))
+			})
+		})
+
+		Context("when neither path is in a container", func() {
+			It("fails without reaching the ATC", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "cp", "a", "b")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("one of the source and destination must be in a container"))
+			})
+		})
+	})
+})
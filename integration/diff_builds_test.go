@@ -0,0 +1,148 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("diff-builds", func() {
+		finishedEventsHandler := func(buildID int, events []atc.Event) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID)),
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+
+					for id, e := range events {
+						payload, err := json.Marshal(event.Message{Event: e})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{
+							ID:   fmt.Sprintf("%d", id),
+							Name: "event",
+							Data: payload,
+						}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					err := sse.Event{Name: "end"}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				},
+			)
+		}
+
+		taskEvents := func(output string, status atc.BuildStatus) []atc.Event {
+			return []atc.Event{
+				event.Log{Origin: event.Origin{ID: "1"}, Payload: "fetched\n"},
+				event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "some-input"}},
+				event.StartTask{Origin: event.Origin{ID: "2"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "run-tests"}}},
+				event.Log{Origin: event.Origin{ID: "2"}, Payload: output},
+				event.Status{Status: status},
+			}
+		}
+
+		Context("when the builds' logs differ", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/127"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 127}),
+					),
+					finishedEventsHandler(127, taskEvents("ok 1\nok 2\n", atc.StatusSucceeded)),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/128"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128}),
+					),
+					finishedEventsHandler(128, taskEvents("ok 1\nnot ok 2\n", atc.StatusFailed)),
+				)
+			})
+
+			It("prints a unified diff of each step that changed", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "diff-builds", "-b", "127", "-b", "128")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(0))
+
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("get: some-input"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("--- build 127: task running run-tests"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("+++ build 128: task running run-tests"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("@@ -1,2 +1,2 @@"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("-ok 2"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("+not ok 2"))
+			})
+		})
+
+		Context("when the builds' logs are the same", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/127"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 127}),
+					),
+					finishedEventsHandler(127, taskEvents("ok\n", atc.StatusSucceeded)),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/128"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128}),
+					),
+					finishedEventsHandler(128, taskEvents("ok\n", atc.StatusSucceeded)),
+				)
+			})
+
+			It("says so", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "diff-builds", "-b", "127", "-b", "128")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(0))
+				Expect(sess.Out).To(gbytes.Say("the builds' logs are the same"))
+			})
+		})
+
+		Context("when a build does not exist", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/builds/127"),
+						ghttp.RespondWith(http.StatusNotFound, nil),
+					),
+				)
+			})
+
+			It("fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "diff-builds", "-b", "127", "-b", "128")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say("build 127 does not exist"))
+			})
+		})
+
+		It("requires exactly two builds", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "diff-builds", "-b", "127")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say("exactly two builds must be given"))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("diff-pipeline", func() {
+		var config atc.Config
+
+		BeforeEach(func() {
+			config = atc.Config{
+				Groups: atc.GroupConfigs{},
+				Resources: atc.ResourceConfigs{
+					{
+						Name: "some-resource",
+						Type: "some-type",
+						Source: atc.Source{
+							"source-config": "some-value",
+						},
+					},
+				},
+				Jobs: atc.JobConfigs{
+					{
+						Name: "job",
+						Plan: atc.PlanSequence{
+							{Get: "some-resource"},
+						},
+					},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+		})
+
+		Context("when the pipeline matches the local config", func() {
+			It("says so and succeeds", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "diff-pipeline", "-p", "some-pipeline", "-c", "fixtures/testConfigValid.yml")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("pipeline 'some-pipeline' matches fixtures/testConfigValid.yml"))
+			})
+		})
+
+		Context("when the pipeline has been changed", func() {
+			BeforeEach(func() {
+				config.Resources[0].Source["source-config"] = "some-other-value"
+			})
+
+			It("prints the differences and fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "diff-pipeline", "-p", "some-pipeline", "-c", "fixtures/testConfigValid.yml")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say("resource some-resource has changed:"))
+				Expect(sess.Out).To(gbytes.Say("some-other-value"))
+				Expect(sess.Out).To(gbytes.Say("some-value"))
+				Expect(sess.Err).To(gbytes.Say("pipeline 'some-pipeline' differs from fixtures/testConfigValid.yml"))
+			})
+		})
+	})
+})
@@ -0,0 +1,74 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("doctor", func() {
+		var pipelinesStatus int
+
+		BeforeEach(func() {
+			pipelinesStatus = http.StatusOK
+		})
+
+		JustBeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+					ghttp.RespondWithJSONEncodedPtr(&pipelinesStatus, []atc.Pipeline{}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers/fly-doctor/hijack"),
+					ghttp.VerifyHeaderKV("Authorization", tokenString()),
+					ghttp.RespondWith(http.StatusNotFound, ""),
+				),
+			)
+		})
+
+		It("reports each check and exits 0 when none fail", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "doctor")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say(`target testserver at ` + atcServer.URL()))
+			Expect(sess.Out).To(gbytes.Say(`pass  target URL resolves: 127.0.0.1 is 127.0.0.1`))
+			Expect(sess.Out).To(gbytes.Say(`skip  TLS verifies: target is not https`))
+			Expect(sess.Out).To(gbytes.Say(`pass  auth is valid: logged in to team main`))
+			Expect(sess.Out).To(gbytes.Say(`pass  websockets connect: reached the ATC directly`))
+			Expect(sess.Out).To(gbytes.Say(`pass  clock is in sync`))
+			Expect(sess.Out).To(gbytes.Say(`warn  versions match: fly is a dev build; the ATC is 1.2.3`))
+		})
+
+		Context("when the ATC rejects the token", func() {
+			BeforeEach(func() {
+				pipelinesStatus = http.StatusUnauthorized
+			})
+
+			It("reports the failure and exits 1", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "doctor")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say(`fail  auth is valid: the ATC rejected the token; run fly -t testserver login`))
+				Expect(sess.Out).To(gbytes.Say(`1 checks failed`))
+			})
+		})
+	})
+})
@@ -1,8 +1,11 @@
 package integration_test
 
 import (
+	"io/ioutil"
 	"os/exec"
+	"path/filepath"
 
+	"github.com/dgrijalva/jwt-go"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
@@ -43,6 +46,52 @@ var _ = Describe("Fly CLI", func() {
 		})
 	})
 
+	Describe("authorization failures", func() {
+		var (
+			flyCmd *exec.Cmd
+		)
+
+		BeforeEach(func() {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"teamName": "other-team",
+				"isAdmin":  false,
+			}).SignedString([]byte("some-key"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(
+				filepath.Join(homeDir, ".flyrc-tokens"),
+				[]byte(targetName+":\n  type: Bearer\n  value: "+token+"\n"),
+				0600,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				infoHandler(),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/pipeline/jobs"),
+					ghttp.RespondWith(403, "not a member of team main"),
+				),
+			)
+
+			flyCmd = exec.Command(flyPath, "-t", targetName, "jobs", "-p", "pipeline")
+		})
+
+		Context("when a 403 response is received", func() {
+			It("explains what was required and who the user is logged in as", func() {
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("you are not authorized to GET /api/v1/teams/main/pipelines/pipeline/jobs: it requires being logged in to team 'main', but you are logged in to team 'other-team'"))
+				Expect(sess.Err).To(gbytes.Say("the server said: not a member of team main"))
+				Expect(sess.Err).To(gbytes.Say("to log in to team 'main', run:"))
+				Expect(sess.Err).To(gbytes.Say(`fly -t ` + targetName + ` login -n main`))
+			})
+		})
+	})
+
 	Describe("missing target", func() {
 		var (
 			flyCmd *exec.Cmd
@@ -0,0 +1,61 @@
+package integration_test
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("eval", func() {
+		It("prints the config with its vars interpolated and its anchors resolved", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"eval",
+				"-c", "fixtures/anchors-pipeline.yml",
+				"-v", "repo-uri=https://example.com/repo.git",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			var config atc.Config
+			err = yaml.Unmarshal(sess.Out.Contents(), &config)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(config.Resources).To(Equal(atc.ResourceConfigs{
+				{
+					Name: "repo",
+					Type: "git",
+					Source: atc.Source{
+						"uri":    "https://example.com/repo.git",
+						"branch": "master",
+					},
+				},
+				{
+					Name: "repo-docs",
+					Type: "git",
+					Source: atc.Source{
+						"uri":    "https://example.com/repo.git",
+						"branch": "master",
+						"paths":  []interface{}{"docs"},
+					},
+				},
+			}))
+
+			Expect(config.Jobs[0].Plan[2].Put).To(Equal("((notify-resource))"))
+
+			Expect(sess.Err).To(gbytes.Say("  - notify-resource"))
+		})
+	})
+})
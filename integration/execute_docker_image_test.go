@@ -0,0 +1,242 @@
+package integration_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute --image-from-docker", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			binDir         string
+			taskConfigPath string
+
+			plan atc.Plan
+
+			uploadsLock sync.Mutex
+			uploads     []map[string]string
+		)
+
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("the fake docker CLI is a shell script")
+			}
+
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+inputs:
+- name: fixture
+
+run:
+  path: ls
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			rootfsPath := filepath.Join(tmpdir, "rootfs.tar")
+			rootfs, err := os.Create(rootfsPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			tarWriter := tar.NewWriter(rootfs)
+			Expect(tarWriter.WriteHeader(&tar.Header{Name: "bin/ls", Typeflag: tar.TypeReg, Mode: 0755, Size: 2})).To(Succeed())
+			_, err = tarWriter.Write([]byte("ls"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tarWriter.Close()).To(Succeed())
+			Expect(rootfs.Close()).To(Succeed())
+
+			// a fake docker CLI, standing in for a local image
+			binDir = filepath.Join(tmpdir, "bin")
+			Expect(os.Mkdir(binDir, 0755)).To(Succeed())
+
+			err = ioutil.WriteFile(filepath.Join(binDir, "docker"), []byte(`#!/bin/sh
+case "$1" in
+  image) echo '{"Env":["PATH=/bin"],"User":"nobody"}' ;;
+  create) echo some-container ;;
+  export) cat `+rootfsPath+` ;;
+  rm) ;;
+  *) exit 1 ;;
+esac
+`), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			uploads = nil
+
+			atcServer.RouteToHandler("POST", "/api/v1/pipes",
+				ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+					ID:       "some-pipe-id",
+					ReadURL:  atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+					WriteURL: atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+				}),
+			)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, r *http.Request) {
+				gzReader, err := gzip.NewReader(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				files := map[string]string{}
+
+				tarReader := tar.NewReader(gzReader)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := ioutil.ReadAll(tarReader)
+					Expect(err).NotTo(HaveOccurred())
+
+					files[header.Name] = string(contents)
+				}
+
+				uploadsLock.Lock()
+				uploads = append(uploads, files)
+				uploadsLock.Unlock()
+			})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		It("uploads the image's filesystem and runs the task in it", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--image-from-docker", "my-image:dev")
+			flyCmd.Dir = buildDir
+			flyCmd.Env = append(os.Environ(), "PATH="+binDir+":"+os.Getenv("PATH"))
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect((*plan.Do)[1].Task.ImageArtifactName).To(Equal("fly-docker-image"))
+
+			var gets []string
+			for _, get := range *(*plan.Do)[0].Aggregate {
+				gets = append(gets, get.Get.Name)
+				Expect(get.Get.Type).To(Equal("archive"))
+			}
+			Expect(gets).To(ConsistOf("fixture", "fly-docker-image"))
+
+			var image map[string]string
+			for _, upload := range uploads {
+				if _, found := upload["metadata.json"]; found {
+					image = upload
+				}
+			}
+
+			Expect(image).NotTo(BeNil())
+			Expect(image["rootfs/bin/ls"]).To(Equal("ls"))
+			Expect(image["metadata.json"]).To(MatchJSON(`{"env":["PATH=/bin"],"user":"nobody"}`))
+		})
+
+		Context("when the image can't be exported", func() {
+			var aborts chan struct{}
+
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(binDir, "docker"), []byte(`#!/bin/sh
+case "$1" in
+  image) echo '{"Env":["PATH=/bin"],"User":"nobody"}' ;;
+  create) echo some-container ;;
+  export) echo 'no space left on device' >&2; exit 1 ;;
+  rm) ;;
+  *) exit 1 ;;
+esac
+`), 0755)
+				Expect(err).NotTo(HaveOccurred())
+
+				aborts = make(chan struct{}, 10)
+
+				atcServer.RouteToHandler("PUT", "/api/v1/builds/128/abort", func(w http.ResponseWriter, r *http.Request) {
+					aborts <- struct{}{}
+				})
+
+				// the build waits for its image until it's aborted
+				atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+					w.(http.Flusher).Flush()
+
+					<-aborts
+
+					payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusAborted}})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{Name: "end"}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			It("aborts the build rather than leaving it waiting, and fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--image-from-docker", "my-image:dev")
+				flyCmd.Dir = buildDir
+				flyCmd.Env = append(os.Environ(), "PATH="+binDir+":"+os.Getenv("PATH"))
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess, 10).Should(gexec.Exit(1))
+
+				Expect(sess.Err).To(gbytes.Say("no space left on device"))
+				Expect(sess.Err).To(gbytes.Say("could not upload input 'fly-docker-image': could not export docker image: exit status 1"))
+				Expect(sess.Out).NotTo(gbytes.Say("starting a new build"))
+			})
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute with a task config that extends another", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			plan atc.Plan
+		)
+
+		writeConfig := func(path string, contents string) {
+			err := os.MkdirAll(filepath.Dir(path), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(path, []byte(contents), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			plan = atc.Plan{}
+
+			writeConfig(filepath.Join(buildDir, "ci", "base-task.yml"), `---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+    tag: latest
+
+params:
+  SOME_PARAM: base
+  OTHER_PARAM: base
+
+run:
+  path: make
+  args: [all]
+`)
+
+			taskConfigPath = filepath.Join(buildDir, "build.yml")
+			writeConfig(taskConfigPath, `---
+extends: ci/base-task.yml
+
+image_resource:
+  source:
+    tag: edge
+
+params:
+  OTHER_PARAM: override
+
+run:
+  args: [test]
+`)
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func() *gexec.Session {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			return sess
+		}
+
+		It("merges the config over its base, field by field", func() {
+			sess := execute()
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			config := (*plan.Do)[1].Task.Config
+			Expect(config.Platform).To(Equal("linux"))
+			Expect(config.ImageResource.Source).To(Equal(atc.Source{
+				"repository": "busybox",
+				"tag":        "edge",
+			}))
+			Expect(config.Params).To(Equal(map[string]string{
+				"SOME_PARAM":  "base",
+				"OTHER_PARAM": "override",
+			}))
+			Expect(config.Run.Path).To(Equal("make"))
+			Expect(config.Run.Args).To(Equal([]string{"test"}))
+		})
+
+		Context("when the configs extend each other", func() {
+			BeforeEach(func() {
+				writeConfig(filepath.Join(buildDir, "ci", "base-task.yml"), `---
+extends: ../build.yml
+`)
+			})
+
+			It("fails", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("task configs extend each other in a cycle"))
+			})
+		})
+	})
+})
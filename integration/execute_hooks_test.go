@@ -0,0 +1,168 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute with hooks in the task config", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			hooks string
+
+			plan atc.Plan
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+
+			plan = atc.Plan{}
+			hooks = `
+on_success:
+  task: notify
+  config:
+    platform: linux
+    image_resource:
+      type: docker-image
+      source: {repository: busybox}
+    run: {path: echo, args: [yay]}
+
+on_failure:
+  do:
+  - task: cleanup
+    file: fixture/ci/cleanup.yml
+    tags: [cleaner]
+`
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		JustBeforeEach(func() {
+			err := ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+
+run:
+  path: ls
+`+hooks), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func(args ...string) *gexec.Session {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			return sess
+		}
+
+		It("runs them after the task, as a pipeline would", func() {
+			sess := execute("--tag", "some-tag")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			onFailure := (*plan.Do)[1].OnFailure
+			Expect(onFailure).NotTo(BeNil())
+
+			onSuccess := onFailure.Step.OnSuccess
+			Expect(onSuccess).NotTo(BeNil())
+
+			Expect(onSuccess.Step.Task.Name).To(Equal("one-off"))
+			Expect(onSuccess.Step.Task.Config.Run.Path).To(Equal("ls"))
+
+			Expect(onSuccess.Next.Task.Name).To(Equal("notify"))
+			Expect(onSuccess.Next.Task.Config.Run.Path).To(Equal("echo"))
+			Expect(onSuccess.Next.Task.Tags).To(ConsistOf("some-tag"))
+
+			cleanup := (*onFailure.Next.Do)[0].Task
+			Expect(cleanup.Name).To(Equal("cleanup"))
+			Expect(cleanup.ConfigPath).To(Equal("fixture/ci/cleanup.yml"))
+			Expect(cleanup.Tags).To(ConsistOf("cleaner"))
+		})
+
+		Context("when a hook isn't a task", func() {
+			BeforeEach(func() {
+				hooks = `
+on_success:
+  put: some-resource
+`
+			})
+
+			It("fails without creating a build", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("invalid on_success hook: only task steps"))
+				Expect(plan.Do).To(BeNil())
+			})
+		})
+
+		Context("without hooks", func() {
+			BeforeEach(func() {
+				hooks = ""
+			})
+
+			It("runs the task alone", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect((*plan.Do)[1].Task.Name).To(Equal("one-off"))
+			})
+		})
+	})
+})
@@ -0,0 +1,124 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute with container limits", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			plan atc.Plan
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			plan = atc.Plan{}
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+
+container_limits:
+  cpu: 512
+  memory: 1024
+
+run:
+  path: ls
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func(args ...string) *gexec.Session {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			return sess
+		}
+
+		It("sets the limits on the task, overriding the task config's", func() {
+			sess := execute("--cpu-limit", "256", "--memory-limit", "2GB")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			limits := (*plan.Do)[1].Task.Config.Limits
+			Expect(*limits.CPU).To(Equal(uint64(256)))
+			Expect(*limits.Memory).To(Equal(uint64(2 * 1024 * 1024 * 1024)))
+		})
+
+		It("leaves the task config's limits alone when not given", func() {
+			sess := execute("--memory-limit", "2GB")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			limits := (*plan.Do)[1].Task.Config.Limits
+			Expect(*limits.CPU).To(Equal(uint64(512)))
+		})
+
+		Context("when the memory limit is invalid", func() {
+			It("fails", func() {
+				sess := execute("--memory-limit", "lots")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("invalid size 'lots'"))
+			})
+		})
+	})
+})
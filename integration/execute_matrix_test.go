@@ -0,0 +1,230 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute --matrix", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			paramsLock sync.Mutex
+			params     map[int]map[string]string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+
+			err = os.Mkdir(buildDir, 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+
+			err = ioutil.WriteFile(
+				taskConfigPath,
+				[]byte(`---
+platform: some-platform
+
+image_resource:
+  type: docker-image
+  source:
+    repository: golang
+    tag: ((GOVERSION))
+
+inputs:
+- name: fixture
+
+run:
+  path: go
+  args: [test]
+`),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			params = map[int]map[string]string{}
+
+			atcServer.RouteToHandler("POST", "/api/v1/pipes",
+				ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+					ID:       "some-pipe-id",
+					ReadURL:  atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+					WriteURL: atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+				}),
+			)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", ghttp.RespondWith(200, ""))
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				var plan atc.Plan
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				config := (*plan.Do)[1].Task.Config
+				Expect(config.ImageResource.Source["tag"]).To(Equal(config.Params["GOVERSION"]))
+
+				paramsLock.Lock()
+				id := 100 + len(params)
+				params[id] = config.Params
+				paramsLock.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"id":%d,"url":"builds/%d"}`, id, id)
+			})
+
+			atcServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/builds/\d+/events$`), func(w http.ResponseWriter, r *http.Request) {
+				var id int
+				fmt.Sscanf(r.URL.Path, "/api/v1/builds/%d/events", &id)
+
+				paramsLock.Lock()
+				goVersion := params[id]["GOVERSION"]
+				paramsLock.Unlock()
+
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				events := []atc.Event{event.Log{Payload: "testing with go " + goVersion + "\n"}}
+				if goVersion == "1.22" {
+					events = append(events, event.FinishTask{ExitStatus: 1}, event.Status{Status: atc.StatusFailed})
+				} else {
+					events = append(events, event.FinishTask{ExitStatus: 0}, event.Status{Status: atc.StatusSucceeded})
+				}
+
+				for i, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{ID: fmt.Sprintf("%d", i), Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		It("runs a build for every combination, prefixing their output and failing if any of them fail", func() {
+			flyCmd := exec.Command(
+				flyPath, "-t", targetName, "e", "-c", taskConfigPath,
+				"--matrix", "GOVERSION=1.21,1.22",
+				"--matrix", "OS=linux",
+			)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(params).To(HaveLen(2))
+			Expect(params).To(ContainElement(map[string]string{"GOVERSION": "1.21", "OS": "linux"}))
+			Expect(params).To(ContainElement(map[string]string{"GOVERSION": "1.22", "OS": "linux"}))
+
+			out := string(sess.Out.Contents())
+			Expect(out).To(ContainSubstring("[GOVERSION=1.21,OS=linux] testing with go 1.21\n"))
+			Expect(out).To(ContainSubstring("[GOVERSION=1.22,OS=linux] testing with go 1.22\n"))
+			Expect(out).To(MatchRegexp(`(?m)^GOVERSION=1\.21,OS=linux\s+100\s+succeeded\s+0\s*$`))
+			Expect(out).To(MatchRegexp(`(?m)^GOVERSION=1\.22,OS=linux\s+101\s+failed\s+1\s*$`))
+		})
+
+		Context("when several task configs are given", func() {
+			var otherConfigPath string
+
+			BeforeEach(func() {
+				otherConfigPath = filepath.Join(buildDir, "other.yml")
+
+				err := ioutil.WriteFile(
+					otherConfigPath,
+					[]byte(`---
+platform: some-platform
+
+image_resource:
+  type: docker-image
+  source:
+    repository: golang
+    tag: ((GOVERSION))
+
+inputs:
+- name: fixture
+
+run:
+  path: go
+  args: [vet]
+`),
+					0644,
+				)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("runs a build for each of them at once, labelled with their config", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e",
+					"-c", taskConfigPath,
+					"-c", otherConfigPath,
+					"--matrix", "GOVERSION=1.21",
+				)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(params).To(HaveLen(2))
+
+				out := string(sess.Out.Contents())
+				Expect(out).To(ContainSubstring("[" + taskConfigPath + " GOVERSION=1.21] testing with go 1.21\n"))
+				Expect(out).To(ContainSubstring("[" + otherConfigPath + " GOVERSION=1.21] testing with go 1.21\n"))
+				Expect(out).To(MatchRegexp(`(?m)^` + regexp.QuoteMeta(taskConfigPath+" GOVERSION=1.21") + `\s+100\s+succeeded\s+0\s*$`))
+				Expect(out).To(MatchRegexp(`(?m)^` + regexp.QuoteMeta(otherConfigPath+" GOVERSION=1.21") + `\s+101\s+succeeded\s+0\s*$`))
+			})
+		})
+
+		Context("when outputs are requested", func() {
+			It("errors, as every build would write to the same paths", func() {
+				flyCmd := exec.Command(
+					flyPath, "-t", targetName, "e", "-c", taskConfigPath,
+					"--matrix", "GOVERSION=1.21,1.22",
+					"-o", "some-output=./out",
+				)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(string(sess.Err.Contents())).To(ContainSubstring("outputs cannot be fetched when executing several builds"))
+			})
+		})
+	})
+})
@@ -0,0 +1,172 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute --worker", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			isAdmin bool
+			workers []atc.Worker
+
+			plan atc.Plan
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+
+run:
+  path: ls
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			plan = atc.Plan{}
+			isAdmin = true
+			workers = []atc.Worker{
+				{Name: "worker-1", Tags: []string{"gpu", "big"}},
+				{Name: "worker-2", Tags: []string{"gpu"}},
+				{Name: "worker-3"},
+			}
+
+			atcServer.RouteToHandler("GET", "/api/v1/workers", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, workers)(w, r)
+			})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		JustBeforeEach(func() {
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"teamName": "main",
+				"isAdmin":  isAdmin,
+			}).SignedString([]byte("some-key"))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(
+				filepath.Join(homeDir, ".flyrc-tokens"),
+				[]byte(targetName+":\n  type: Bearer\n  value: "+token+"\n"),
+				0600,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func(args ...string) *gexec.Session {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			return sess
+		}
+
+		It("places the build using the worker's tags", func() {
+			sess := execute("--worker", "worker-1", "--tag", "extra")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect((*plan.Do)[1].Task.Tags).To(ConsistOf("extra", "gpu", "big"))
+		})
+
+		Context("when the worker's tags are shared with another worker", func() {
+			It("fails without creating a build", func() {
+				sess := execute("--worker", "worker-2")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("worker 'worker-2' can't be targeted alone: its tags \\(gpu\\) are shared with worker-1"))
+				Expect(plan.Do).To(BeNil())
+			})
+		})
+
+		Context("when the worker has no tags", func() {
+			It("fails without creating a build", func() {
+				sess := execute("--worker", "worker-3")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("worker 'worker-3' has no tags"))
+			})
+		})
+
+		Context("when the worker does not exist", func() {
+			It("fails", func() {
+				sess := execute("--worker", "bogus")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("worker 'bogus' not found"))
+			})
+		})
+
+		Context("when not logged in as an admin", func() {
+			BeforeEach(func() {
+				isAdmin = false
+			})
+
+			It("fails", func() {
+				sess := execute("--worker", "worker-1")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("only admins can run builds on a specific worker"))
+			})
+		})
+	})
+})
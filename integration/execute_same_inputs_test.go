@@ -0,0 +1,198 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/concourse/fly/archive"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute --same-inputs-as", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			uploadsLock sync.Mutex
+			uploads     []string
+
+			nextBuildID int
+
+			inputsDir string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+
+inputs:
+- name: fixture
+
+run:
+  path: ls
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(buildDir, "version"), []byte("yesterday"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			uploads = nil
+			nextBuildID = 128
+
+			inputsDir = filepath.Join(homeDir, ".fly", "inputs", targetName)
+
+			atcServer.RouteToHandler("POST", "/api/v1/pipes",
+				ghttp.RespondWithJSONEncoded(http.StatusCreated, atc.Pipe{
+					ID:       "some-pipe-id",
+					ReadURL:  atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+					WriteURL: atcServer.URL() + "/api/v1/pipes/some-pipe-id",
+				}),
+			)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, r *http.Request) {
+				dst, err := ioutil.TempDir("", "fly-upload")
+				Expect(err).NotTo(HaveOccurred())
+				defer os.RemoveAll(dst)
+
+				Expect(archive.Extract(r.Body, dst)).To(Succeed())
+
+				version, err := ioutil.ReadFile(filepath.Join(dst, "version"))
+				Expect(err).NotTo(HaveOccurred())
+
+				uploadsLock.Lock()
+				uploads = append(uploads, string(version))
+				uploadsLock.Unlock()
+			})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				var plan atc.Plan
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"id":%d,"url":"builds/%d"}`, nextBuildID, nextBuildID)
+				nextBuildID++
+			})
+
+			atcServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/builds/\d+/events$`), func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: atc.StatusSucceeded}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+			os.RemoveAll(inputsDir)
+		})
+
+		execute := func(args ...string) *gexec.Session {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			return sess
+		}
+
+		It("uploads the bits the earlier build was given", func() {
+			Expect(execute("--record-inputs").ExitCode()).To(Equal(0))
+
+			err := ioutil.WriteFile(filepath.Join(buildDir, "version"), []byte("today"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			sess := execute("--same-inputs-as", "128")
+			Expect(sess.ExitCode()).To(Equal(0))
+			Expect(sess.Out).To(gbytes.Say("executing build 129"))
+
+			Expect(uploads).To(Equal([]string{"yesterday", "yesterday"}))
+		})
+
+		Context("when the earlier build is the oldest recorded", func() {
+			It("only removes its record once it's been uploaded again", func() {
+				Expect(execute("--record-inputs").ExitCode()).To(Equal(0))
+
+				// as many newer builds as are kept
+				for id := 200; id < 210; id++ {
+					Expect(os.Mkdir(filepath.Join(inputsDir, fmt.Sprint(id)), 0700)).To(Succeed())
+				}
+
+				sess := execute("--same-inputs-as", "128", "--record-inputs")
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(uploads).To(Equal([]string{"yesterday", "yesterday"}))
+				Expect(filepath.Join(inputsDir, "128")).NotTo(BeADirectory())
+				Expect(filepath.Join(inputsDir, "200")).To(BeADirectory())
+			})
+		})
+
+		Context("when the earlier build's inputs weren't recorded", func() {
+			It("fails", func() {
+				Expect(execute().ExitCode()).To(Equal(0))
+
+				sess := execute("--same-inputs-as", "128")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("the inputs of build 128 are not cached"))
+			})
+		})
+
+		Context("when the earlier build's inputs are not cached", func() {
+			It("fails", func() {
+				sess := execute("--same-inputs-as", "42")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("the inputs of build 42 are not cached"))
+			})
+		})
+
+		Context("when combined with --input", func() {
+			It("fails", func() {
+				sess := execute("--same-inputs-as", "128", "-i", "fixture=.")
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("--same-inputs-as cannot be combined with --input or --inputs-from"))
+			})
+		})
+	})
+})
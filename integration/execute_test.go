@@ -5,6 +5,7 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -13,6 +14,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -326,6 +328,38 @@ run: {}
 		})
 	})
 
+	Context("when the task file has tags", func() {
+		BeforeEach(func() {
+			taskConfig, err := ioutil.ReadFile(taskConfigPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(taskConfigPath, append(taskConfig, []byte("\ntags: [tag-2]\n")...), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			(*expectedPlan.Do)[1].Task.Tags = []string{"tag-1", "tag-2"}
+		})
+
+		It("adds them to those given by --tag, leaving them out of the config", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--tag", "tag-1")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			// sync with after create
+			Eventually(streaming).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+	})
+
 	Context("when invalid inputs are passed", func() {
 		It("prints an error", func() {
 			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-i", "fixture=.", "-i", "evan=.")
@@ -460,6 +494,323 @@ run:
 		})
 	})
 
+	Context("when running with an out of range --compression-level", func() {
+		It("exits 1", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--compression-level", "12")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say(`invalid compression level 12 \(expected 1..9\)`))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+		})
+	})
+
+	Context("when an input contains a FIFO", func() {
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("windows has no FIFOs")
+			}
+
+			err := exec.Command("mkfifo", filepath.Join(buildDir, "some-fifo")).Run()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("leaves it out with a warning", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess.Err).Should(gbytes.Say("skipping some-fifo in input fixture: special file"))
+
+			Eventually(streaming).Should(BeClosed())
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+
+		Context("with --special-files fail", func() {
+			It("does not upload the input", func() {
+				atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, req *http.Request) {
+					ioutil.ReadAll(req.Body)
+				})
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--special-files", "fail")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("some-fifo is a special file"))
+
+				Eventually(streaming).Should(BeClosed())
+				close(events)
+
+				<-sess.Exited
+			})
+		})
+	})
+
+	Context("when the repo excludes paths from uploads", func() {
+		var uploaded chan []string
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(filepath.Join(buildDir, ".fly.yml"), []byte("exclude: [node_modules/]\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = os.MkdirAll(filepath.Join(buildDir, "node_modules", "some-module"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(buildDir, "some-log.txt"), []byte("log"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		JustBeforeEach(func() {
+			uploaded = make(chan []string, 1)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, req *http.Request) {
+				gr, err := gzip.NewReader(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				var names []string
+
+				tr := tar.NewReader(gr)
+				for {
+					hdr, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+
+					Expect(err).NotTo(HaveOccurred())
+					names = append(names, strings.TrimPrefix(hdr.Name, "./"))
+				}
+
+				uploaded <- names
+			})
+		})
+
+		run := func(args ...string) []string {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			var names []string
+			Eventually(uploaded).Should(Receive(&names))
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			return names
+		}
+
+		It("leaves them out, along with any given by --exclude", func() {
+			names := run("--exclude", "*.txt")
+			Expect(names).To(ContainElement("task.yml"))
+			Expect(names).NotTo(ContainElement(HavePrefix("node_modules")))
+			Expect(names).NotTo(ContainElement("some-log.txt"))
+		})
+
+		Context("with --no-default-excludes", func() {
+			It("uploads them", func() {
+				names := run("--no-default-excludes")
+				Expect(names).To(ContainElement("node_modules/some-module/"))
+				Expect(names).To(ContainElement("some-log.txt"))
+			})
+		})
+
+		Context("when the input has a .flyignore", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(buildDir, ".flyignore"), []byte("# logs\n*.txt\n"), 0644)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("leaves out its patterns too, even with --no-default-excludes", func() {
+				names := run("--no-default-excludes")
+				Expect(names).To(ContainElement("task.yml"))
+				Expect(names).To(ContainElement("node_modules/some-module/"))
+				Expect(names).NotTo(ContainElement("some-log.txt"))
+			})
+		})
+	})
+
+	Context("when an input is read from stdin", func() {
+		var uploaded chan map[string]string
+
+		JustBeforeEach(func() {
+			uploaded = make(chan map[string]string, 1)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, req *http.Request) {
+				gr, err := gzip.NewReader(req.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				files := map[string]string{}
+
+				tr := tar.NewReader(gr)
+				for {
+					hdr, err := tr.Next()
+					if err == io.EOF {
+						break
+					}
+
+					Expect(err).NotTo(HaveOccurred())
+
+					if hdr.Typeflag == tar.TypeReg {
+						contents, err := ioutil.ReadAll(tr)
+						Expect(err).NotTo(HaveOccurred())
+						files[strings.TrimPrefix(hdr.Name, "./")] = string(contents)
+					}
+				}
+
+				uploaded <- files
+			})
+		})
+
+		run := func(args ...string) map[string]string {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "e", "-c", taskConfigPath}, args...)...)
+			flyCmd.Dir = buildDir
+			flyCmd.Stdin = strings.NewReader(`{"some":"data"}`)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			var files map[string]string
+			Eventually(uploaded).Should(Receive(&files))
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			return files
+		}
+
+		It("uploads what's piped to fly as its only file", func() {
+			files := run("--input-from-stdin", "fixture")
+			Expect(files).To(Equal(map[string]string{"stdin": `{"some":"data"}`}))
+		})
+
+		It("names the file as given", func() {
+			files := run("--input-from-stdin", "fixture=data.json")
+			Expect(files).To(Equal(map[string]string{"data.json": `{"some":"data"}`}))
+		})
+
+		Context("when the input is also given by --input", func() {
+			It("errors", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-i", "fixture=.", "--input-from-stdin", "fixture")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.Err).To(gbytes.Say("input 'fixture' is given by both --input and --input-from-stdin"))
+			})
+		})
+	})
+
+	Context("when the task config has ((variables))", func() {
+		BeforeEach(func() {
+			err := ioutil.WriteFile(
+				taskConfigPath,
+				[]byte(`---
+platform: some-platform
+
+image_resource:
+  type: docker-image
+  source:
+    repository: ubuntu
+
+inputs:
+- name: fixture
+
+params:
+  FOO: ((secret))
+  BAZ: buzz
+  X: 1
+
+run:
+  path: find
+  args: [.]
+`),
+				0644,
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("interpolates them and redacts their values from the output", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-v", "secret=bar")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			events <- event.Log{Payload: "FOO is bar\n"}
+
+			Eventually(sess.Out).Should(gbytes.Say(`FOO is \(\(redacted\)\)`))
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		Context("when running with --require-vars", func() {
+			It("fails, listing those that aren't given", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--require-vars")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("failed to interpolate task config"))
+				Expect(sess.Err).To(gbytes.Say("secret"))
+			})
+		})
+
+		Context("when running with --no-redact", func() {
+			It("shows their values", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-v", "secret=bar", "--no-redact")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				events <- event.Log{Payload: "FOO is bar\n"}
+
+				Eventually(sess.Out).Should(gbytes.Say("FOO is bar"))
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+			})
+		})
+	})
+
 	Context("when parameters are specified in the environment", func() {
 		BeforeEach(func() {
 			(*expectedPlan.Do)[1].Task.Config.Params = map[string]string{
@@ -491,6 +842,127 @@ run:
 		})
 	})
 
+	Context("when running with --no-inherit-env", func() {
+		It("leaves the build's parameter values alone", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--no-inherit-env")
+			flyCmd.Dir = buildDir
+			flyCmd.Env = append(os.Environ(), "FOO=newbar", "X=")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+	})
+
+	Context("when parameters are given with --env-file and --env", func() {
+		var envFile string
+
+		BeforeEach(func() {
+			envFile = filepath.Join(tmpdir, "build.env")
+			err := ioutil.WriteFile(envFile, []byte("# from the file\nFOO=fromfile\nexport BAZ=\"from file\"\n"), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			(*expectedPlan.Do)[1].Task.Config.Params = map[string]string{
+				"FOO": "fromfile",
+				"BAZ": "fromflag",
+				"X":   "1",
+				"NEW": "",
+			}
+		})
+
+		It("sets them over the task config and the environment, flags last", func() {
+			atcServer.AllowUnhandledRequests = true
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--env-file", envFile, "-e", "BAZ=fromflag", "-e", "NEW=")
+			flyCmd.Dir = buildDir
+			flyCmd.Env = append(os.Environ(), "FOO=newbar")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(uploadingBits).To(BeClosed())
+		})
+	})
+
+	Context("when the build takes longer than --timeout", func() {
+		var aborted chan struct{}
+
+		JustBeforeEach(func() {
+			aborted = make(chan struct{})
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/builds/128/abort"),
+					func(w http.ResponseWriter, r *http.Request) {
+						close(aborted)
+					},
+				),
+			)
+		})
+
+		It("aborts the build and exits 124", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--timeout", "1s")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			Eventually(aborted, 5*time.Second).Should(BeClosed())
+			Expect(sess.Err).To(gbytes.Say("timed out after 1s; aborting"))
+
+			events <- event.Status{Status: atc.StatusAborted}
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(124))
+		})
+
+		Context("but only what comes after the build does", func() {
+			It("neither aborts the build nor exits 124", func() {
+				atcServer.RouteToHandler("PUT", "/api/v1/pipes/some-pipe-id", func(w http.ResponseWriter, req *http.Request) {
+					ioutil.ReadAll(req.Body)
+					time.Sleep(2 * time.Second)
+				})
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--timeout", "1s")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				events <- event.Status{Status: atc.StatusSucceeded}
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).NotTo(gbytes.Say("timed out"))
+				Expect(aborted).NotTo(BeClosed())
+			})
+		})
+	})
+
 	Context("when the build is interrupted", func() {
 		var aborted chan struct{}
 
@@ -532,6 +1004,31 @@ run:
 				})
 			})
 
+			Describe("with --no-abort-on-interrupt", func() {
+				It("leaves the build running and prints its URL", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--no-abort-on-interrupt")
+					flyCmd.Dir = buildDir
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).ToNot(HaveOccurred())
+
+					Eventually(streaming).Should(BeClosed())
+
+					Eventually(uploadingBits).Should(BeClosed())
+
+					sess.Signal(os.Interrupt)
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(2))
+
+					Expect(sess.Err).To(gbytes.Say("detached, leaving the builds running"))
+					Expect(sess.Err).To(gbytes.Say(atcServer.URL() + "/builds/128"))
+					Expect(aborted).NotTo(BeClosed())
+
+					close(events)
+				})
+			})
+
 			Describe("with SIGTERM", func() {
 				It("aborts the build and exits nonzero", func() {
 					flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
@@ -558,6 +1055,127 @@ run:
 		}
 	})
 
+	Context("when the build can't be aborted", func() {
+		JustBeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/builds/128/abort"),
+					ghttp.RespondWith(http.StatusInternalServerError, ""),
+				),
+			)
+		})
+
+		if runtime.GOOS != "windows" {
+			It("says so, and still exits immediately when interrupted again", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				Eventually(uploadingBits).Should(BeClosed())
+
+				sess.Signal(os.Interrupt)
+
+				Eventually(sess.Err).Should(gbytes.Say("failed to abort: build 128"))
+
+				sess.Signal(os.Interrupt)
+
+				Eventually(sess.Err).Should(gbytes.Say("exiting immediately"))
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).NotTo(Equal(0))
+			})
+		}
+	})
+
+	Context("when aborting the build hangs", func() {
+		var hanging chan struct{}
+
+		JustBeforeEach(func() {
+			hanging = make(chan struct{})
+
+			atcServer.RouteToHandler("PUT", "/api/v1/builds/128/abort", func(w http.ResponseWriter, r *http.Request) {
+				<-hanging
+			})
+		})
+
+		AfterEach(func() {
+			close(hanging)
+		})
+
+		if runtime.GOOS != "windows" {
+			It("gives up on it when interrupted again", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				Eventually(uploadingBits).Should(BeClosed())
+
+				sess.Signal(os.Interrupt)
+
+				Eventually(sess.Err).Should(gbytes.Say("aborting..."))
+
+				sess.Signal(os.Interrupt)
+
+				Eventually(sess.Err).Should(gbytes.Say("exiting immediately"))
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).NotTo(Equal(0))
+				Expect(sess.Err).NotTo(gbytes.Say("failed to abort"))
+			})
+		}
+	})
+
+	Context("when creating the build hangs", func() {
+		var (
+			creating chan struct{}
+			hanging  chan struct{}
+		)
+
+		JustBeforeEach(func() {
+			creating = make(chan struct{})
+			hanging = make(chan struct{})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				close(creating)
+				<-hanging
+			})
+		})
+
+		AfterEach(func() {
+			close(hanging)
+		})
+
+		if runtime.GOOS != "windows" {
+			It("gives up on it when interrupted", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).ToNot(HaveOccurred())
+
+				Eventually(creating).Should(BeClosed())
+
+				sess.Signal(os.Interrupt)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(2))
+				Expect(sess.Err).To(gbytes.Say("aborting..."))
+			})
+		}
+	})
+
 	Context("when the target has an auth token", func() {
 		var tmpDir string
 		var targetName string
@@ -635,6 +1253,33 @@ run:
 		})
 	})
 
+	Context("when the build's task starts", func() {
+		It("announces each phase and reports how long they took", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(sess.Out).Should(gbytes.Say("uploading inputs..."))
+
+			Eventually(streaming).Should(BeClosed())
+			Eventually(uploadingBits).Should(BeClosed())
+
+			events <- event.InitializeTask{}
+			Eventually(sess.Out).Should(gbytes.Say("waiting for worker..."))
+
+			events <- event.StartTask{TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "find"}}}
+			events <- event.Status{Status: atc.StatusSucceeded}
+			close(events)
+
+			Eventually(sess.Out).Should(gbytes.Say(`phases: uploading \S+, waiting for worker \S+, running \S+\n`))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+	})
+
 	Context("when the build fails", func() {
 		It("exits 1", func() {
 			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
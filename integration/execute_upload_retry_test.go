@@ -0,0 +1,212 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute when an upload fails", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+
+			lock          sync.Mutex
+			failedUploads int
+			uploadsToFail int
+			nextPipeID    int
+			nextBuildID   int
+			aborted       map[string]chan struct{}
+			uploaded      chan struct{}
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "task.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: busybox
+
+inputs:
+- name: fixture
+
+run:
+  path: ls
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			failedUploads = 0
+			uploadsToFail = 1
+			nextPipeID = 1
+			nextBuildID = 128
+			aborted = map[string]chan struct{}{}
+			uploaded = make(chan struct{})
+
+			atcServer.RouteToHandler("POST", "/api/v1/pipes", func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				id := fmt.Sprintf("pipe-%d", nextPipeID)
+				nextPipeID++
+				lock.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(atc.Pipe{
+					ID:       id,
+					ReadURL:  atcServer.URL() + "/api/v1/pipes/" + id,
+					WriteURL: atcServer.URL() + "/api/v1/pipes/" + id,
+				})
+			})
+
+			atcServer.RouteToHandler("PUT", regexp.MustCompile(`^/api/v1/pipes/pipe-\d+$`), func(w http.ResponseWriter, r *http.Request) {
+				ioutil.ReadAll(r.Body)
+
+				lock.Lock()
+				defer lock.Unlock()
+
+				if failedUploads < uploadsToFail {
+					failedUploads++
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+
+				close(uploaded)
+			})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				id := nextBuildID
+				nextBuildID++
+				aborted[fmt.Sprint(id)] = make(chan struct{})
+				lock.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"id":%d,"url":"builds/%d"}`, id, id)
+			})
+
+			atcServer.RouteToHandler("PUT", regexp.MustCompile(`^/api/v1/builds/\d+/abort$`), func(w http.ResponseWriter, r *http.Request) {
+				id := regexp.MustCompile(`\d+`).FindString(r.URL.Path)
+
+				lock.Lock()
+				close(aborted[id])
+				lock.Unlock()
+			})
+
+			atcServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/builds/\d+/events$`), func(w http.ResponseWriter, r *http.Request) {
+				id := regexp.MustCompile(`\d+`).FindString(r.URL.Path)
+
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				w.(http.Flusher).Flush()
+
+				lock.Lock()
+				abortedBuild := aborted[id]
+				lock.Unlock()
+
+				// builds wait for their inputs until they're uploaded, or
+				// the build's aborted
+				status := atc.StatusSucceeded
+				select {
+				case <-uploaded:
+				case <-abortedBuild:
+					status = atc.StatusAborted
+				}
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: status}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func() *gexec.Session {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath)
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess, 10).Should(gexec.Exit())
+			return sess
+		}
+
+		It("aborts the build and runs a new one with a fresh pipe", func() {
+			sess := execute()
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("executing build 128"))
+			Expect(sess.Out).To(gbytes.Say("the inputs of build 128 could not be uploaded; starting a new build with fresh pipes"))
+			Expect(sess.Out).To(gbytes.Say("executing build 129"))
+			Expect(sess.Out).To(gbytes.Say("succeeded"))
+
+			Expect(aborted["128"]).To(BeClosed())
+		})
+
+		Context("when an input can't be read", func() {
+			BeforeEach(func() {
+				Expect(os.Mkdir(filepath.Join(buildDir, ".flyignore"), 0755)).To(Succeed())
+			})
+
+			It("aborts the build and fails rather than running a new one", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("could not upload input 'fixture': could not read its .flyignore"))
+				Expect(sess.Out).NotTo(gbytes.Say("starting a new build"))
+
+				Expect(aborted["128"]).To(BeClosed())
+				Expect(nextBuildID).To(Equal(129))
+			})
+		})
+
+		Context("when the uploads keep failing", func() {
+			BeforeEach(func() {
+				uploadsToFail = 100
+			})
+
+			It("gives up after a few builds", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say("executing build 130"))
+				Expect(sess.Err).To(gbytes.Say("the inputs of the build could not be uploaded after 3 attempts"))
+
+				Expect(nextBuildID).To(Equal(131))
+			})
+		})
+	})
+})
@@ -0,0 +1,247 @@
+package integration_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/vito/go-sse/sse"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("execute --cache", func() {
+		var (
+			tmpdir         string
+			buildDir       string
+			taskConfigPath string
+			cacheDir       string
+
+			status atc.BuildStatus
+			plan   atc.Plan
+
+			pipesLock sync.Mutex
+			pipes     int
+			uploads   map[string]map[string]string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-build-dir")
+			Expect(err).NotTo(HaveOccurred())
+
+			buildDir = filepath.Join(tmpdir, "fixture")
+			Expect(os.Mkdir(buildDir, 0755)).To(Succeed())
+
+			taskConfigPath = filepath.Join(buildDir, "build.yml")
+			err = ioutil.WriteFile(taskConfigPath, []byte(`---
+platform: linux
+
+image_resource:
+  type: docker-image
+  source:
+    repository: node
+
+inputs:
+- name: fixture
+
+run:
+  path: npm
+  args: [install]
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			cacheDir = filepath.Join(homeDir, ".fly", "cache", targetName, "main", "build", "deps")
+			Expect(os.MkdirAll(cacheDir, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(cacheDir, "old-dep"), []byte("old"), 0644)).To(Succeed())
+
+			status = atc.StatusSucceeded
+			pipes = 0
+			uploads = map[string]map[string]string{}
+
+			atcServer.RouteToHandler("POST", "/api/v1/pipes", func(w http.ResponseWriter, r *http.Request) {
+				pipesLock.Lock()
+				pipes++
+				id := fmt.Sprintf("pipe-%d", pipes)
+				pipesLock.Unlock()
+
+				w.WriteHeader(http.StatusCreated)
+				json.NewEncoder(w).Encode(atc.Pipe{
+					ID:       id,
+					ReadURL:  atcServer.URL() + "/api/v1/pipes/" + id,
+					WriteURL: atcServer.URL() + "/api/v1/pipes/" + id,
+				})
+			})
+
+			atcServer.RouteToHandler("PUT", regexp.MustCompile(`^/api/v1/pipes/.*$`), func(w http.ResponseWriter, r *http.Request) {
+				gzReader, err := gzip.NewReader(r.Body)
+				Expect(err).NotTo(HaveOccurred())
+
+				files := map[string]string{}
+
+				tarReader := tar.NewReader(gzReader)
+				for {
+					header, err := tarReader.Next()
+					if err == io.EOF {
+						break
+					}
+					Expect(err).NotTo(HaveOccurred())
+
+					contents, err := ioutil.ReadAll(tarReader)
+					Expect(err).NotTo(HaveOccurred())
+
+					files[path.Clean(header.Name)] = string(contents)
+				}
+
+				pipesLock.Lock()
+				uploads[path.Base(r.URL.Path)] = files
+				pipesLock.Unlock()
+			})
+
+			atcServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/pipes/.*$`), func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+
+				gzWriter := gzip.NewWriter(w)
+				tarWriter := tar.NewWriter(gzWriter)
+
+				err := tarWriter.WriteHeader(&tar.Header{Name: "new-dep", Mode: 0644, Size: 3})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = tarWriter.Write([]byte("new"))
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(tarWriter.Close()).To(Succeed())
+				Expect(gzWriter.Close()).To(Succeed())
+			})
+
+			atcServer.RouteToHandler("POST", "/api/v1/builds", func(w http.ResponseWriter, r *http.Request) {
+				err := json.NewDecoder(r.Body).Decode(&plan)
+				Expect(err).NotTo(HaveOccurred())
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":128,"url":"builds/128"}`)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				payload, err := json.Marshal(event.Message{Event: event.Status{Status: status}})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{ID: "0", Name: "event", Data: payload}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		execute := func() *gexec.Session {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--cache", "deps=node_modules")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+
+			return sess
+		}
+
+		It("adds the cache to the task as an input and an output at its path", func() {
+			Expect(execute().ExitCode()).To(Equal(0))
+
+			config := (*plan.Ensure.Step.Do)[1].Task.Config
+			Expect(config.Inputs).To(ContainElement(atc.TaskInputConfig{Name: "deps", Path: "node_modules"}))
+			Expect(config.Outputs).To(ContainElement(atc.TaskOutputConfig{Name: "deps", Path: "node_modules"}))
+		})
+
+		It("uploads the cache's saved contents", func() {
+			Expect(execute().ExitCode()).To(Equal(0))
+
+			var cacheInput string
+			for _, get := range *(*plan.Ensure.Step.Do)[0].Aggregate {
+				if get.Get.Name == "deps" {
+					cacheInput = path.Base(get.Get.Source["uri"].(string))
+				}
+			}
+
+			Expect(uploads).To(HaveKey(cacheInput))
+			Expect(uploads[cacheInput]["old-dep"]).To(Equal("old"))
+		})
+
+		It("replaces the cache's contents with the task's once the build succeeds", func() {
+			Expect(execute().ExitCode()).To(Equal(0))
+
+			contents, err := ioutil.ReadFile(filepath.Join(cacheDir, "new-dep"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("new"))
+
+			Expect(filepath.Join(cacheDir, "old-dep")).NotTo(BeAnExistingFile())
+		})
+
+		Context("when the cache can't be downloaded", func() {
+			BeforeEach(func() {
+				// the connection's dropped partway through the archive
+				atcServer.RouteToHandler("GET", regexp.MustCompile(`^/api/v1/pipes/.*$`), func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+
+					gzWriter := gzip.NewWriter(w)
+					tarWriter := tar.NewWriter(gzWriter)
+
+					err := tarWriter.WriteHeader(&tar.Header{Name: "new-dep", Mode: 0644, Size: 100})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = tarWriter.Write([]byte("new"))
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(gzWriter.Close()).To(Succeed())
+				})
+			})
+
+			It("leaves the cache as it was", func() {
+				sess := execute()
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Err).To(gbytes.Say("could not download output 'deps'.*; keeping cache 'deps' as it was"))
+
+				Expect(filepath.Join(cacheDir, "old-dep")).To(BeAnExistingFile())
+				Expect(filepath.Join(cacheDir, "new-dep")).NotTo(BeAnExistingFile())
+			})
+		})
+
+		Context("when the build fails", func() {
+			BeforeEach(func() {
+				status = atc.StatusFailed
+			})
+
+			It("leaves the cache as it was", func() {
+				Expect(execute().ExitCode()).To(Equal(1))
+
+				Expect(filepath.Join(cacheDir, "old-dep")).To(BeAnExistingFile())
+				Expect(filepath.Join(cacheDir, "new-dep")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+})
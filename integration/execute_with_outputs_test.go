@@ -308,6 +308,58 @@ run:
 			})
 		})
 
+		Context("when limiting download parallelism", func() {
+			It("still downloads the tasks output", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--download-parallelism", "1")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				data, err := ioutil.ReadFile(filepath.Join(outputDir, "some-file"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(Equal([]byte("tar-contents")))
+			})
+		})
+
+		Context("when collecting tests from an output", func() {
+			It("exits 65 if the build succeeded but its reports can't be parsed", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--collect-tests", "some-dir/some-*")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(streaming).Should(BeClosed())
+
+				close(events)
+
+				<-sess.Exited
+				Expect(sess.Err).To(gbytes.Say("failed to collect test reports: failed to parse test report '" + filepath.Join(outputDir, "some-file") + "'"))
+				Expect(sess.ExitCode()).To(Equal(65))
+			})
+
+			It("fails up front if the reports aren't in a fetched output", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output", "some-dir="+outputDir, "--collect-tests", "other-dir/*.xml")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say("error: test reports 'other-dir/\\*.xml' must be in an output fetched with --output"))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+
 		Context("when the task does not specify those outputs", func() {
 			It("exits 1", func() {
 				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "-o", "wrong-output=wrong-path")
@@ -323,4 +375,40 @@ run:
 			})
 		})
 	})
+
+	Context("when running with --output-filter", func() {
+		It("downloads the matching outputs into directories named after them", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output-filter", "some-*")
+			flyCmd.Dir = buildDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(streaming).Should(BeClosed())
+
+			close(events)
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			data, err := ioutil.ReadFile(filepath.Join(buildDir, "some-dir", "some-file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(Equal([]byte("tar-contents")))
+		})
+
+		Context("when none of the outputs match", func() {
+			It("exits 1", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "e", "-c", taskConfigPath, "--output-filter", "reports*")
+				flyCmd.Dir = buildDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess.Err).Should(gbytes.Say(`error: none of the task's outputs match the output filters \[reports\*\]`))
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+			})
+		})
+	})
 })
@@ -0,0 +1,84 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("export-pipelines", func() {
+		var (
+			outputDir string
+			config    atc.Config
+		)
+
+		BeforeEach(func() {
+			outputDir = filepath.Join(homeDir, "backup")
+
+			config = atc.Config{
+				Resources: atc.ResourceConfigs{
+					{Name: "repo", Type: "git", Source: atc.Source{"branch": "master"}},
+				},
+				Jobs: atc.JobConfigs{
+					{Name: "unit", Plan: atc.PlanSequence{{Get: "repo"}}},
+				},
+			}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Pipeline{
+						{Name: "some-pipeline", TeamName: "main", Paused: true},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+		})
+
+		It("writes each pipeline's config and a manifest of them", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "export-pipelines", "-o", outputDir)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("exported main/some-pipeline.yml"))
+
+			payload, err := ioutil.ReadFile(filepath.Join(outputDir, "main", "some-pipeline.yml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var exported atc.Config
+			Expect(yaml.Unmarshal(payload, &exported)).To(Succeed())
+			Expect(exported).To(Equal(config))
+
+			manifest, err := ioutil.ReadFile(filepath.Join(outputDir, "manifest.yml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(manifest)).To(ContainSubstring("target: " + atcServer.URL()))
+			Expect(string(manifest)).To(ContainSubstring(`- team: main
+  name: some-pipeline
+  file: main/some-pipeline.yml
+  config_version: "42"
+  paused: true
+  public: false
+`))
+		})
+	})
+})
@@ -0,0 +1,138 @@
+package integration_test
+
+import (
+	"os/exec"
+
+	"github.com/concourse/atc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("--targets", func() {
+		var otherServer *ghttp.Server
+
+		BeforeEach(func() {
+			otherServer = ghttp.NewServer()
+
+			otherServer.AppendHandlers(
+				infoHandler(),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/auth/methods"),
+					ghttp.RespondWithJSONEncoded(200, []atc.AuthMethod{}),
+				),
+				tokenHandler("main"),
+				infoHandler(),
+			)
+
+			loginCmd := exec.Command(flyPath, "-t", "other", "login", "-c", otherServer.URL())
+
+			sess, err := gexec.Start(loginCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{{Name: "staging-pipeline"}}),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			otherServer.Close()
+		})
+
+		Context("when the command succeeds against every target", func() {
+			BeforeEach(func() {
+				otherServer.AppendHandlers(
+					infoHandler(),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{{Name: "prod-pipeline"}}),
+					),
+				)
+			})
+
+			It("runs it against each target in turn, prefixing its output", func() {
+				flyCmd := exec.Command(flyPath, "--targets", targetName+",other", "pipelines", "--format", "{{.Name}}")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(string(sess.Out.Contents())).To(Equal("[testserver] staging-pipeline\n[other] prod-pipeline\n"))
+			})
+
+			It("runs it against every saved target with --all-targets", func() {
+				flyCmd := exec.Command(flyPath, "--all-targets", "--parallel", "pipelines", "--format", "{{.Name}}")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out.Contents()).To(ContainSubstring("[testserver] staging-pipeline\n"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("[other] prod-pipeline\n"))
+			})
+		})
+
+		Context("when the command fails against a target", func() {
+			BeforeEach(func() {
+				otherServer.AppendHandlers(
+					infoHandler(),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+						ghttp.RespondWith(500, ""),
+					),
+				)
+			})
+
+			It("still runs it against the others, and fails naming the target", func() {
+				flyCmd := exec.Command(flyPath, "--targets", targetName+",other", "pipelines", "--format", "{{.Name}}")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Out).To(gbytes.Say(`\[testserver\] staging-pipeline`))
+				Expect(sess.Err).To(gbytes.Say(`\[other\] `))
+				Expect(sess.Err).To(gbytes.Say("error: pipelines failed against 1 of 2 targets: other"))
+			})
+		})
+
+		It("refuses to set pipelines in parallel without -n, as they can't be confirmed", func() {
+			flyCmd := exec.Command(flyPath, "--targets", targetName+",other", "--parallel", "set-pipeline", "-p", "some-pipeline", "-c", "pipeline.yml")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("--parallel needs -n for set-pipeline"))
+		})
+
+		It("refuses commands that don't support it", func() {
+			flyCmd := exec.Command(flyPath, "--targets", targetName+",other", "hijack")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("--targets and --all-targets only work with set-pipeline, trigger-job, pipelines"))
+		})
+	})
+})
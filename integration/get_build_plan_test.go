@@ -0,0 +1,111 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("get-build-plan", func() {
+		var plan json.RawMessage
+
+		BeforeEach(func() {
+			plan = json.RawMessage(`{
+				"id": "1",
+				"do": [
+					{"id": "2", "aggregate": [
+						{"id": "3", "get": {"type": "git", "name": "repo", "resource": "repo", "version": {"ref": "abc"}}}
+					]},
+					{"id": "4", "on_failure": {
+						"step": {"id": "5", "task": {"name": "unit", "privileged": true}},
+						"on_failure": {"id": "6", "put": {"type": "slack", "name": "notify", "resource": "slack-alert"}}
+					}}
+				]
+			}`)
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/128"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/128/plan"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.PublicBuildPlan{
+						Schema: "exec.v2",
+						Plan:   &plan,
+					}),
+				),
+			)
+		})
+
+		It("prints the plan as a tree of steps", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "get-build-plan", "-b", "128")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(string(sess.Out.Contents())).To(Equal(`do:
+  aggregate:
+    get: repo (git)
+      version: {"ref":"abc"}
+  task: unit (privileged)
+  on failure:
+    put: notify (slack resource slack-alert)
+`))
+		})
+
+		Context("with --json", func() {
+			It("prints the plan as given by the ATC", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "get-build-plan", "-b", "128", "--json")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(0))
+
+				var printed atc.PublicBuildPlan
+				err = json.Unmarshal(sess.Out.Contents(), &printed)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(printed.Schema).To(Equal("exec.v2"))
+				Expect(*printed.Plan).To(MatchJSON(plan))
+			})
+		})
+	})
+
+	Describe("get-build-plan of a build without a plan", func() {
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/128"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/builds/128/plan"),
+					ghttp.RespondWith(http.StatusNotFound, nil),
+				),
+			)
+		})
+
+		It("fails", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "get-build-plan", "-b", "128")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say("build plan not found"))
+		})
+	})
+})
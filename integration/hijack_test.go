@@ -389,6 +389,7 @@ var _ = Describe("Hijacking", func() {
 			jobName            string
 			buildName          string
 			attempt            string
+			containerUser      string
 		)
 
 		BeforeEach(func() {
@@ -403,6 +404,7 @@ var _ = Describe("Hijacking", func() {
 			containerArguments = ""
 			hijackHandlerError = nil
 			attempt = ""
+			containerUser = user
 		})
 
 		JustBeforeEach(func() {
@@ -413,7 +415,7 @@ var _ = Describe("Hijacking", func() {
 				ghttp.CombineHandlers(
 					ghttp.VerifyRequest("GET", "/api/v1/containers", containerArguments),
 					ghttp.RespondWithJSONEncoded(200, []atc.Container{
-						{ID: "container-id-1", WorkerName: "some-worker", PipelineName: pipelineName, JobName: jobName, BuildName: buildName, BuildID: buildID, Type: stepType, StepName: stepName, ResourceName: resourceName, Attempt: attempt, User: user},
+						{ID: "container-id-1", WorkerName: "some-worker", PipelineName: pipelineName, JobName: jobName, BuildName: buildName, BuildID: buildID, Type: stepType, StepName: stepName, ResourceName: resourceName, Attempt: attempt, User: containerUser},
 					}),
 				),
 				hijackHandler("container-id-1", didHijack, hijackHandlerError),
@@ -506,6 +508,37 @@ var _ = Describe("Hijacking", func() {
 			})
 		})
 
+		Context("when called with a command with flags of its own after --", func() {
+			BeforeEach(func() {
+				path = "/bin/sh"
+				args = []string{"-c", "echo hello"}
+
+				containerArguments = "build_id=2&step_name=some-step"
+				stepType = "task"
+				stepName = "some-step"
+				buildID = 2
+			})
+
+			It("runs it in the container and exits with its exit status", func() {
+				hijack("-b", "2", "-s", "some-step", "--", "/bin/sh", "-c", "echo hello")
+			})
+		})
+
+		Context("when called with a user", func() {
+			BeforeEach(func() {
+				user = "vcap"
+
+				containerArguments = "build_id=2&step_name=some-step"
+				stepType = "task"
+				stepName = "some-step"
+				buildID = 2
+			})
+
+			It("runs the command as that user rather than the container's", func() {
+				hijack("-b", "2", "-s", "some-step", "--user", "vcap")
+			})
+		})
+
 		Context("when hijacking yields an error", func() {
 			BeforeEach(func() {
 				resourceName = "some-resource-name"
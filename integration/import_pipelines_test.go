@@ -0,0 +1,116 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/tedsuo/rata"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("import-pipelines", func() {
+		var (
+			backupDir string
+			requests  []string
+		)
+
+		BeforeEach(func() {
+			backupDir = filepath.Join(homeDir, "backup")
+
+			err := os.MkdirAll(filepath.Join(backupDir, "main"), 0755)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(backupDir, "manifest.yml"), []byte(`
+target: https://ci.example.com
+pipelines:
+- team: main
+  name: some-pipeline
+  file: main/some-pipeline.yml
+  config_version: "42"
+  paused: false
+  public: true
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(backupDir, "main", "some-pipeline.yml"), []byte(`
+resources:
+- name: repo
+  type: git
+  source: {branch: master}
+jobs:
+- name: unit
+  plan:
+  - get: repo
+`), 0644)
+			Expect(err).NotTo(HaveOccurred())
+
+			requests = nil
+
+			record := func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.Path)
+			}
+
+			atcServer.RouteToHandler("GET", "/api/v1/teams/main/pipelines/some-pipeline/config",
+				ghttp.RespondWith(http.StatusNotFound, nil),
+			)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/teams/main/pipelines/some-pipeline/config",
+				ghttp.CombineHandlers(record, ghttp.RespondWith(http.StatusCreated, `{}`)),
+			)
+
+			for _, route := range []string{atc.UnpausePipeline, atc.ExposePipeline} {
+				path, err := atc.Routes.CreatePathForRoute(route, rata.Params{"pipeline_name": "some-pipeline", "team_name": "main"})
+				Expect(err).NotTo(HaveOccurred())
+
+				atcServer.RouteToHandler("PUT", path, ghttp.CombineHandlers(record, ghttp.RespondWith(http.StatusOK, nil)))
+			}
+		})
+
+		It("re-creates the pipelines, restoring whether they were paused or public", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "import-pipelines", "-n", backupDir)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("pipeline some-pipeline is new:"))
+			Expect(sess.Out).To(gbytes.Say("pipeline some-pipeline created"))
+			Expect(sess.Out).To(gbytes.Say("pipeline some-pipeline is unpaused and exposed"))
+
+			Expect(requests).To(Equal([]string{
+				"PUT /api/v1/teams/main/pipelines/some-pipeline/config",
+				"PUT /api/v1/teams/main/pipelines/some-pipeline/unpause",
+				"PUT /api/v1/teams/main/pipelines/some-pipeline/expose",
+			}))
+		})
+
+		Context("with --dry-run", func() {
+			It("only shows the changes", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "import-pipelines", "--dry-run", backupDir)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(sess.Out).To(gbytes.Say("pipeline some-pipeline is new:"))
+				Expect(sess.Out).To(gbytes.Say("1 pipelines to apply, 0 unchanged"))
+				Expect(requests).To(BeEmpty())
+			})
+		})
+	})
+})
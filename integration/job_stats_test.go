@@ -0,0 +1,113 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("job-stats", func() {
+		var (
+			builds     []atc.Build
+			statusCode int
+		)
+
+		hoursAgo := func(hours int) int64 {
+			return time.Now().Add(-time.Duration(hours) * time.Hour).Unix()
+		}
+
+		BeforeEach(func() {
+			statusCode = http.StatusOK
+
+			// newest first, as the ATC returns them
+			builds = []atc.Build{
+				{ID: 7, Name: "7", Status: "started", StartTime: hoursAgo(1)},
+				{ID: 6, Name: "6", Status: "failed", StartTime: hoursAgo(3), EndTime: hoursAgo(3) + 300},
+				{ID: 5, Name: "5", Status: "errored", StartTime: hoursAgo(4), EndTime: hoursAgo(4) + 100},
+				{ID: 4, Name: "4", Status: "aborted", StartTime: hoursAgo(5), EndTime: hoursAgo(5) + 10},
+				{ID: 3, Name: "3", Status: "succeeded", StartTime: hoursAgo(6), EndTime: hoursAgo(6) + 200},
+				{ID: 2, Name: "2", Status: "failed", StartTime: hoursAgo(7), EndTime: hoursAgo(7) + 100},
+				{ID: 1, Name: "1", Status: "succeeded", StartTime: hoursAgo(24 * 40), EndTime: hoursAgo(24*40) + 100},
+			}
+		})
+
+		JustBeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/builds", "limit=100"),
+					ghttp.RespondWithJSONEncodedPtr(&statusCode, &builds),
+				),
+			)
+		})
+
+		It("summarizes the builds started since the cutoff", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "job-stats", "-j", "some-pipeline/some-job")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say(`builds of some-pipeline/some-job started in the last 720h0m0s`))
+			Expect(sess.Out).To(gbytes.Say(`builds\s+5`))
+			Expect(sess.Out).To(gbytes.Say(`succeeded\s+1`))
+			Expect(sess.Out).To(gbytes.Say(`failed\s+3`))
+			Expect(sess.Out).To(gbytes.Say(`aborted\s+1`))
+			Expect(sess.Out).To(gbytes.Say(`success rate\s+25\.0%`))
+			Expect(sess.Out).To(gbytes.Say(`mean duration\s+2m55s`))
+			Expect(sess.Out).To(gbytes.Say(`95p duration\s+5m0s`))
+			Expect(sess.Out).To(gbytes.Say(`longest failure streak\s+2`))
+			Expect(sess.Out).To(gbytes.Say(`current failure streak\s+2`))
+		})
+
+		Context("when --since is given", func() {
+			It("only considers builds started within it", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "job-stats", "-j", "some-pipeline/some-job", "--since", "2h")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(0))
+
+				Expect(sess.Out).To(gbytes.Say(`builds\s+0`))
+				Expect(sess.Out).To(gbytes.Say(`success rate\s+n/a`))
+				Expect(sess.Out).To(gbytes.Say(`mean duration\s+n/a`))
+			})
+
+			It("rejects a malformed duration", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "job-stats", "-j", "some-pipeline/some-job", "--since", "a while")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say(`invalid duration 'a while'`))
+			})
+		})
+
+		Context("when the job does not exist", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusNotFound
+			})
+
+			It("fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "job-stats", "-j", "some-pipeline/some-job")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say("pipeline/job not found"))
+			})
+		})
+	})
+})
@@ -0,0 +1,89 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+	"strconv"
+
+	"github.com/concourse/atc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("job-status", func() {
+		jobPath := "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job"
+
+		var (
+			job        atc.Job
+			statusCode int
+		)
+
+		BeforeEach(func() {
+			job = atc.Job{Name: "some-job"}
+			statusCode = http.StatusOK
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", jobPath),
+					ghttp.RespondWithJSONEncodedPtr(&statusCode, &job),
+				),
+			)
+		})
+
+		statusOf := func() *gexec.Session {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "job-status", "-j", "some-pipeline/some-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit())
+
+			return sess
+		}
+
+		for _, example := range []struct {
+			status   string
+			exitCode int
+		}{
+			{"succeeded", 0},
+			{"failed", 1},
+			{"errored", 2},
+			{"aborted", 2},
+		} {
+			status, exitCode := example.status, example.exitCode
+
+			It("exits "+strconv.Itoa(exitCode)+" when the latest finished build "+status, func() {
+				job.FinishedBuild = &atc.Build{ID: 42, Name: "7", Status: status}
+				job.NextBuild = &atc.Build{ID: 43, Name: "8", Status: "started"}
+
+				sess := statusOf()
+				Expect(sess.ExitCode()).To(Equal(exitCode))
+				Expect(sess.Out).To(gbytes.Say(status + ` \(some-pipeline/some-job #7\)`))
+			})
+		}
+
+		Context("when the job has never finished a build", func() {
+			It("exits 2", func() {
+				sess := statusOf()
+				Expect(sess.ExitCode()).To(Equal(2))
+				Expect(sess.Out).To(gbytes.Say("n/a"))
+			})
+		})
+
+		Context("when the job does not exist", func() {
+			BeforeEach(func() {
+				statusCode = http.StatusNotFound
+			})
+
+			It("fails", func() {
+				sess := statusOf()
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.Err).To(gbytes.Say("pipeline or job 'some-pipeline/some-job' not found"))
+			})
+		})
+	})
+})
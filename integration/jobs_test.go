@@ -1,8 +1,12 @@
 package integration_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/ui"
@@ -30,7 +34,79 @@ var _ = Describe("Fly CLI", func() {
 				<-sess.Exited
 				Expect(sess.ExitCode()).To(Equal(1))
 
-				Expect(sess.Err).To(gbytes.Say("error: the required flag `" + osFlag("p", "pipeline") + "' was not specified"))
+				Expect(sess.Err).To(gbytes.Say("error: no pipeline given; pass -p, or set a default with set-defaults or .fly.yml"))
+			})
+
+			Context("when a .fly.yml sets a default pipeline", func() {
+				var repoDir string
+
+				BeforeEach(func() {
+					var err error
+					repoDir, err = ioutil.TempDir("", "fly-repo")
+					Expect(err).NotTo(HaveOccurred())
+
+					err = ioutil.WriteFile(filepath.Join(repoDir, ".fly.yml"), []byte("pipeline: repo-pipeline\n"), 0644)
+					Expect(err).NotTo(HaveOccurred())
+
+					err = os.Mkdir(filepath.Join(repoDir, "ci"), 0755)
+					Expect(err).NotTo(HaveOccurred())
+
+					atcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/repo-pipeline/jobs"),
+							ghttp.RespondWithJSONEncoded(200, []atc.Job{{Name: "some-job"}}),
+						),
+					)
+				})
+
+				AfterEach(func() {
+					os.RemoveAll(repoDir)
+				})
+
+				It("gets the jobs of that pipeline from anywhere in the repo", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "jobs")
+					flyCmd.Dir = filepath.Join(repoDir, "ci")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					Expect(sess.Out).To(gbytes.Say("some-job"))
+				})
+			})
+
+			Context("when the target has a default pipeline", func() {
+				BeforeEach(func() {
+					setDefaults := exec.Command(flyPath, "-t", targetName, "set-defaults", "-p", "target-pipeline")
+
+					sess, err := gexec.Start(setDefaults, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+					Expect(sess.Out).To(gbytes.Say("target testserver now defaults to pipeline target-pipeline"))
+
+					atcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/target-pipeline/jobs"),
+							ghttp.RespondWithJSONEncoded(200, []atc.Job{{Name: "some-job"}}),
+						),
+					)
+				})
+
+				It("gets the jobs of that pipeline", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "jobs")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					Expect(sess.Out).To(gbytes.Say("some-job"))
+				})
 			})
 		})
 
@@ -78,6 +154,53 @@ var _ = Describe("Fly CLI", func() {
 			})
 		})
 
+		Context("when printing json", func() {
+			BeforeEach(func() {
+				flyCmd = exec.Command(flyPath, "-t", targetName, "jobs", "-p", "pipeline", "--json", "--recent-builds", "3")
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/pipeline/jobs"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Job{
+							{
+								Name:          "job-1",
+								FinishedBuild: &atc.Build{ID: 2, Name: "2", Status: "failed"},
+								NextBuild:     &atc.Build{ID: 3, Name: "3", Status: "started"},
+							},
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/pipeline/jobs/job-1/builds", "limit=3"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Build{
+							{ID: 3, Name: "3", Status: "started"},
+							{ID: 2, Name: "2", Status: "failed"},
+							{ID: 1, Name: "1", Status: "succeeded"},
+						}),
+					),
+				)
+			})
+
+			It("prints each job with its builds and how its recent builds went", func() {
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sess).Should(gexec.Exit(0))
+
+				var jobs []map[string]interface{}
+				err = json.Unmarshal(sess.Out.Contents(), &jobs)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(jobs).To(HaveLen(1))
+				Expect(jobs[0]["name"]).To(Equal("job-1"))
+				Expect(jobs[0]["finished_build"]).To(HaveKeyWithValue("status", "failed"))
+				Expect(jobs[0]["next_build"]).To(HaveKeyWithValue("status", "started"))
+				Expect(jobs[0]["recent_builds"]).To(Equal(map[string]interface{}{
+					"finished":  float64(2),
+					"succeeded": float64(1),
+					"failed":    float64(1),
+					"aborted":   float64(0),
+				}))
+			})
+		})
+
 		Context("when the api returns an internal server error", func() {
 			BeforeEach(func() {
 				flyCmd = exec.Command(flyPath, "-t", targetName, "jobs", "-p", "pipeline")
@@ -0,0 +1,66 @@
+package integration_test
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("lint-pipeline", func() {
+		It("notes things worth knowing without failing", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"lint-pipeline",
+				"-c", "fixtures/vars-pipeline.yml",
+				"-l", "fixtures/vars-pipeline-params-types.yml",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("info: job some-job has no trigger: true inputs"))
+		})
+
+		It("prints warnings without failing", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"lint-pipeline",
+				"-c", "fixtures/testConfigLint.yml",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("warning: resource some-unused-resource is not used by any job"))
+		})
+
+		It("fails on warnings with strict", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"lint-pipeline",
+				"-c", "fixtures/testConfigLint.yml",
+				"--strict",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Out).To(gbytes.Say("warning: resource some-unused-resource is not used by any job"))
+			Expect(sess.Err).To(gbytes.Say("0 errors, 1 warnings"))
+		})
+	})
+})
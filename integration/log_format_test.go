@@ -0,0 +1,72 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("--log-format", func() {
+		It("writes each line as a logfmt record", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/pause"),
+					ghttp.RespondWith(http.StatusOK, nil),
+				),
+			)
+
+			flyCmd := exec.Command(flyPath, "--log-format", "logfmt", "-t", targetName, "pause-job", "-j", "some-pipeline/some-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(sess.Out).To(gbytes.Say(`time=\S+ level=info msg="paused 'some-job'"\n`))
+		})
+
+		It("writes each line as a json record, with errors at the error level", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", "/api/v1/teams/main/pipelines/some-pipeline/jobs/missing-job/pause"),
+					ghttp.RespondWith(http.StatusInternalServerError, nil),
+				),
+			)
+
+			flyCmd := exec.Command(flyPath, "--log-format", "json", "-t", targetName, "pause-job", "-j", "some-pipeline/missing-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			lines := strings.Split(strings.TrimSpace(string(sess.Err.Contents())), "\n")
+			Expect(lines).NotTo(BeEmpty())
+
+			for _, line := range lines {
+				var record map[string]string
+				Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+				Expect(record).To(HaveKeyWithValue("level", "error"))
+				Expect(record).To(HaveKey("time"))
+				Expect(record).To(HaveKey("msg"))
+			}
+		})
+
+		It("rejects unknown formats", func() {
+			flyCmd := exec.Command(flyPath, "--log-format", "xml", "-t", targetName, "pause-job", "-j", "some-pipeline/some-job")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say("unknown log format 'xml'"))
+		})
+	})
+})
@@ -554,6 +554,19 @@ var _ = Describe("login Command", func() {
 					Expect(sess.ExitCode()).To(Equal(0))
 				})
 
+				It("takes username and password from the environment", func() {
+					flyCmd = exec.Command(flyPath, "-t", "some-target", "login", "-c", loginATCServer.URL())
+					flyCmd.Env = append(os.Environ(), "FLY_USERNAME=some_username", "FLY_PASSWORD=some_password")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(sess.Out).Should(gbytes.Say("target saved"))
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+				})
+
 				Context("after logging in succeeds", func() {
 					BeforeEach(func() {
 						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
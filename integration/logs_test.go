@@ -0,0 +1,133 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("logs", func() {
+		var status string
+
+		BeforeEach(func() {
+			status = "failed"
+
+			plan := json.RawMessage(`{"id": "1", "do": [
+				{"id": "2", "get": {"type": "git", "name": "repo", "resource": "repo"}},
+				{"id": "3", "task": {"name": "unit"}}
+			]}`)
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128", func(w http.ResponseWriter, r *http.Request) {
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Build{ID: 128, Status: status})(w, r)
+			})
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/plan",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.PublicBuildPlan{Schema: "exec.v2", Plan: &plan}),
+			)
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/128/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				events := []atc.Event{
+					event.Log{Origin: event.Origin{ID: "2"}, Payload: "Cloning into repo\n"},
+					event.FinishGet{Origin: event.Origin{ID: "2"}, Plan: event.GetPlan{Name: "repo"}},
+					event.StartTask{Origin: event.Origin{ID: "3"}, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "run-tests"}}},
+					event.Log{Origin: event.Origin{ID: "3"}, Payload: "\x1b[31mnot ok 1 - adds\x1b[0m\n"},
+					event.FinishTask{Origin: event.Origin{ID: "3"}, ExitStatus: 1},
+					event.Status{Status: atc.StatusFailed},
+				}
+
+				for _, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		It("prints the output of every step", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "logs", "-b", "128")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(sess.Out).To(gbytes.Say(`==> get: repo`))
+			Expect(sess.Out).To(gbytes.Say(`Cloning into repo`))
+			Expect(sess.Out).To(gbytes.Say(`==> task: unit`))
+			Expect(sess.Out).To(gbytes.Say("\x1b\\[31mnot ok 1 - adds"))
+		})
+
+		It("prints the output of only the given step, without escape codes", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "logs", "-b", "128", "--step", "unit", "--no-ansi")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			Expect(string(sess.Out.Contents())).To(Equal("not ok 1 - adds\n"))
+		})
+
+		It("writes the log to a file", func() {
+			logPath := filepath.Join(homeDir, "build.log")
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "logs", "-b", "128", "--step", "get: repo", "-o", logPath)
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+
+			contents, err := ioutil.ReadFile(logPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("Cloning into repo\n"))
+		})
+
+		It("fails for a step the build does not have", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "logs", "-b", "128", "--step", "integration")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say("build 128 has no step named 'integration'"))
+		})
+
+		Context("when the build has not finished", func() {
+			BeforeEach(func() {
+				status = "started"
+			})
+
+			It("fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "logs", "-b", "128")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say("build 128 has not finished; use fly watch to follow it"))
+			})
+		})
+	})
+})
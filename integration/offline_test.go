@@ -0,0 +1,53 @@
+package integration_test
+
+import (
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("--offline", func() {
+		for _, args := range [][]string{
+			{"validate-pipeline", "-c", "fixtures/testConfigValid.yml"},
+			{"lint-pipeline", "-c", "fixtures/testConfigValid.yml"},
+			{"eval", "-c", "fixtures/vars-pipeline.yml", "-l", "fixtures/vars-pipeline-params-types.yml"},
+			{"format-pipeline", "-c", "fixtures/testConfigValid.yml"},
+		} {
+			args := args
+
+			It("runs "+args[0]+" without reaching the network", func() {
+				requests := len(atcServer.ReceivedRequests())
+
+				flyCmd := exec.Command(flyPath, append([]string{"-t", targetName, "--offline"}, args...)...)
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				Expect(atcServer.ReceivedRequests()).To(HaveLen(requests))
+			})
+		}
+
+		It("fails commands that would reach the network at their first request", func() {
+			requests := len(atcServer.ReceivedRequests())
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "--offline", "pipelines")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("error: refusing to reach the network with --offline, which this command needs"))
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(requests))
+		})
+	})
+})
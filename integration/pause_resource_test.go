@@ -79,6 +79,27 @@ var _ = Describe("Fly CLI", func() {
 			})
 		})
 
+		Context("when the resource does not exist", func() {
+			BeforeEach(func() {
+				flyCmd = exec.Command(flyPath, "-t", targetName, "pause-resource", "-r", "pipeline/missing-resource")
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/api/v1/teams/main/pipelines/pipeline/resources/missing-resource/pause"),
+						ghttp.RespondWith(http.StatusNotFound, nil),
+					),
+				)
+			})
+
+			It("exits 1 and says the resource was not found", func() {
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say(`pipeline or resource 'pipeline/missing-resource' not found\n`))
+				Expect(sess.Err).NotTo(gbytes.Say(`\n\n`))
+			})
+		})
+
 		Context("when the resource flag is not provided", func() {
 			BeforeEach(func() {
 				flyCmd = exec.Command(flyPath, "-t", targetName, "pause-resource")
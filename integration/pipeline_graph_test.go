@@ -0,0 +1,62 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("pipeline-graph", func() {
+		BeforeEach(func() {
+			config := atc.Config{
+				Resources: atc.ResourceConfigs{
+					{Name: "repo", Type: "git"},
+				},
+
+				Jobs: atc.JobConfigs{
+					{
+						Name: "unit",
+						Plan: atc.PlanSequence{
+							{Get: "repo", Trigger: true},
+						},
+					},
+					{
+						Name: "deploy",
+						Plan: atc.PlanSequence{
+							{Get: "repo", Passed: []string{"unit"}},
+						},
+					},
+				},
+			}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+			)
+		})
+
+		It("prints the pipeline's graph in the DOT language", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "pipeline-graph", "-p", "some-pipeline")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say(`digraph "some-pipeline" {`))
+			Expect(sess.Out).To(gbytes.Say(`"resource:repo" -> "job:unit";`))
+			Expect(sess.Out).To(gbytes.Say(`"job:unit" -> "job:deploy" \[label="repo", style=dashed\];`))
+		})
+	})
+})
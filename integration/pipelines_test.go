@@ -1,11 +1,14 @@
 package integration_test
 
 import (
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/ui"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/fatih/color"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -57,6 +60,40 @@ var _ = Describe("Fly CLI", func() {
 				})
 			})
 
+			Context("when formatting the pipelines", func() {
+				BeforeEach(func() {
+					atcServer.AppendHandlers(
+						ghttp.CombineHandlers(
+							ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+							ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{
+								{Name: "pipeline-1", URL: "/pipelines/pipeline-1", Paused: false, Public: false},
+								{Name: "pipeline-2", URL: "/pipelines/pipeline-2", Paused: true, Public: false},
+							}),
+						),
+					)
+				})
+
+				It("prints each pipeline with the --format template", func() {
+					flyCmd = exec.Command(flyPath, "-t", targetName, "pipelines", "--format", "{{.Name}} paused={{.Paused}}")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(sess).Should(gexec.Exit(0))
+
+					Expect(string(sess.Out.Contents())).To(Equal("pipeline-1 paused=false\npipeline-2 paused=true\n"))
+				})
+
+				It("prints the value at the --jsonpath of each pipeline", func() {
+					flyCmd = exec.Command(flyPath, "-t", targetName, "pipelines", "--jsonpath", "{.url}")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+					Eventually(sess).Should(gexec.Exit(0))
+
+					Expect(string(sess.Out.Contents())).To(Equal("/pipelines/pipeline-1\n/pipelines/pipeline-2\n"))
+				})
+			})
+
 			Context("when --all is specified", func() {
 				BeforeEach(func() {
 					flyCmd = exec.Command(flyPath, "-t", targetName, "pipelines", "--all")
@@ -97,6 +134,86 @@ var _ = Describe("Fly CLI", func() {
 				})
 			})
 
+			Context("when --all-teams is specified", func() {
+				var isAdmin bool
+
+				BeforeEach(func() {
+					isAdmin = true
+					flyCmd = exec.Command(flyPath, "-t", targetName, "pipelines", "--all-teams")
+				})
+
+				JustBeforeEach(func() {
+					token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+						"teamName": "main",
+						"isAdmin":  isAdmin,
+					}).SignedString([]byte("some-key"))
+					Expect(err).NotTo(HaveOccurred())
+
+					err = ioutil.WriteFile(
+						filepath.Join(homeDir, ".flyrc-tokens"),
+						[]byte(targetName+":\n  type: Bearer\n  value: "+token+"\n"),
+						0600,
+					)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				Context("when logged in as an admin", func() {
+					BeforeEach(func() {
+						atcServer.AppendHandlers(
+							ghttp.CombineHandlers(
+								ghttp.VerifyRequest("GET", "/api/v1/teams"),
+								ghttp.RespondWithJSONEncoded(200, []atc.Team{{ID: 1, Name: "main"}, {ID: 2, Name: "other"}}),
+							),
+							ghttp.CombineHandlers(
+								ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+								ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{
+									{Name: "pipeline-1", Paused: false, Public: false},
+								}),
+							),
+							ghttp.CombineHandlers(
+								ghttp.VerifyRequest("GET", "/api/v1/teams/other/pipelines"),
+								ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{
+									{Name: "private-pipeline", Paused: true, Public: false},
+								}),
+							),
+						)
+					})
+
+					It("lists every team's pipelines with a team name column", func() {
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(sess).Should(gexec.Exit(0))
+
+						Expect(sess.Out).To(PrintTable(ui.Table{
+							Headers: ui.TableRow{
+								{Contents: "name", Color: color.New(color.Bold)},
+								{Contents: "team", Color: color.New(color.Bold)},
+								{Contents: "paused", Color: color.New(color.Bold)},
+								{Contents: "public", Color: color.New(color.Bold)},
+							},
+							Data: []ui.TableRow{
+								{{Contents: "pipeline-1"}, {Contents: "main"}, {Contents: "no"}, {Contents: "no"}},
+								{{Contents: "private-pipeline"}, {Contents: "other"}, {Contents: "yes", Color: color.New(color.FgCyan)}, {Contents: "no"}},
+							},
+						}))
+					})
+				})
+
+				Context("when not logged in as an admin", func() {
+					BeforeEach(func() {
+						isAdmin = false
+					})
+
+					It("fails without listing anything", func() {
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(sess).Should(gexec.Exit(1))
+
+						Expect(sess.Err).To(gbytes.Say("only admins can list the pipelines of every team"))
+					})
+				})
+			})
+
 			Context("completion", func() {
 				BeforeEach(func() {
 					atcServer.AppendHandlers(
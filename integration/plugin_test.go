@@ -0,0 +1,114 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("plugins", func() {
+		var pluginDir string
+
+		writePlugin := func(name string, script string) {
+			path := filepath.Join(pluginDir, "fly-"+name)
+			Expect(ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755)).To(Succeed())
+		}
+
+		flyWithPlugins := func(args ...string) *gexec.Session {
+			flyCmd := exec.Command(flyPath, args...)
+			flyCmd.Env = append(os.Environ(), "PATH="+pluginDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+
+			return sess
+		}
+
+		BeforeEach(func() {
+			if runtime.GOOS == "windows" {
+				Skip("plugins here are shell scripts")
+			}
+
+			var err error
+			pluginDir, err = ioutil.TempDir("", "fly-plugins")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(pluginDir)
+		})
+
+		It("runs fly-<name> on $PATH as fly <name>, with the target in its env", func() {
+			writePlugin("hello", `
+echo "args: $@"
+echo "target: $FLY_TARGET $FLY_TARGET_URL $FLY_TARGET_TEAM"
+echo "authorization: $FLY_TARGET_AUTHORIZATION"
+exit 3
+`)
+
+			sess := flyWithPlugins("-t", targetName, "hello", "some-arg", "-x")
+			Expect(sess.ExitCode()).To(Equal(3))
+
+			Expect(sess.Out).To(gbytes.Say("args: some-arg -x"))
+			Expect(sess.Out).To(gbytes.Say("target: " + targetName + " " + atcServer.URL() + " main"))
+			Expect(sess.Out).To(gbytes.Say("authorization: " + tokenString()))
+		})
+
+		It("gives plugins the target from $FLY_TARGET when -t isn't given", func() {
+			writePlugin("hello", `echo "target: $FLY_TARGET $FLY_TARGET_URL"`)
+
+			os.Setenv("FLY_TARGET", string(targetName))
+			defer os.Unsetenv("FLY_TARGET")
+
+			sess := flyWithPlugins("hello")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("target: " + targetName + " " + atcServer.URL()))
+		})
+
+		It("skips over the values of fly's own options", func() {
+			writePlugin("hello", `echo "args: $@"`)
+
+			sess := flyWithPlugins("--api-retries", "2", "--color", "never", "-t", targetName, "hello", "some-arg")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("args: some-arg"))
+		})
+
+		It("runs plugins without a target", func() {
+			writePlugin("hello", `echo "target: [$FLY_TARGET]"`)
+
+			sess := flyWithPlugins("hello")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say(`target: \[\]`))
+		})
+
+		It("prefers fly's own commands", func() {
+			writePlugin("targets", `echo "from the plugin"`)
+
+			sess := flyWithPlugins("-t", targetName, "targets")
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).NotTo(gbytes.Say("from the plugin"))
+		})
+
+		It("fails on unknown commands with no plugin", func() {
+			sess := flyWithPlugins("-t", targetName, "no-such-command")
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("Unknown command"))
+		})
+	})
+})
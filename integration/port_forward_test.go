@@ -0,0 +1,113 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("port-forward", func() {
+		var localPort int
+
+		upgrader := websocket.Upgrader{}
+
+		BeforeEach(func() {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+
+			localPort = listener.Addr().(*net.TCPAddr).Port
+			listener.Close()
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers", "build_id=128&step_name=web"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Container{
+						{ID: "container-id-1", BuildID: 128, Type: "task", StepName: "web", WorkingDirectory: "/tmp/build/some-guid", User: "root"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers/container-id-1/hijack"),
+					func(w http.ResponseWriter, r *http.Request) {
+						defer GinkgoRecover()
+
+						conn, err := upgrader.Upgrade(w, r, nil)
+						Expect(err).NotTo(HaveOccurred())
+
+						defer conn.Close()
+
+						var processSpec atc.HijackProcessSpec
+						err = conn.ReadJSON(&processSpec)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(processSpec.Path).To(Equal("sh"))
+						Expect(processSpec.Args).To(HaveLen(4))
+						Expect(processSpec.Args[3]).To(Equal("80"))
+
+						var input atc.HijackInput
+						err = conn.ReadJSON(&input)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(string(input.Stdin)).To(Equal("ping"))
+
+						err = conn.WriteJSON(atc.HijackOutput{Stdout: []byte("pong")})
+						Expect(err).NotTo(HaveOccurred())
+
+						exitStatus := 0
+						err = conn.WriteJSON(atc.HijackOutput{ExitStatus: &exitStatus})
+						Expect(err).NotTo(HaveOccurred())
+					},
+				),
+			)
+		})
+
+		It("forwards connections to the local port to the port in the container", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "port-forward", "-b", "128", "-s", "web", fmt.Sprintf("%d:80", localPort))
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			defer func() {
+				sess.Interrupt()
+				Eventually(sess).Should(gexec.Exit())
+			}()
+
+			Eventually(sess.Out).Should(gbytes.Say(fmt.Sprintf("forwarding 127.0.0.1:%d to port 80 of container container-id-1", localPort)))
+
+			conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", localPort))
+			Expect(err).NotTo(HaveOccurred())
+
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("ping"))
+			Expect(err).NotTo(HaveOccurred())
+
+			response, err := ioutil.ReadAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(response)).To(Equal("pong"))
+		})
+
+		Context("when the ports are invalid", func() {
+			It("fails without reaching the ATC", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "port-forward", "-b", "128", "http")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("invalid local port in http"))
+			})
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("--profile", func() {
+		var workDir string
+
+		BeforeEach(func() {
+			var err error
+			workDir, err = ioutil.TempDir("", "fly-profile")
+			Expect(err).NotTo(HaveOccurred())
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers"),
+					ghttp.RespondWith(http.StatusOK, "[]"),
+				),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(workDir)
+		})
+
+		It("writes a cpu profile to the working directory", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "--profile", "cpu", "containers")
+			flyCmd.Dir = workDir
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(sess.Err).To(gbytes.Say("wrote cpu profile to fly.cpu.pprof"))
+
+			info, err := os.Stat(filepath.Join(workDir, "fly.cpu.pprof"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Size()).To(BeNumerically(">", 0))
+		})
+
+		Context("when the kind of profile is unknown", func() {
+			It("fails", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "--profile", "bogus", "containers")
+				flyCmd.Dir = workDir
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say("unknown profile 'bogus' \\(expected cpu, mem, or trace\\)"))
+			})
+		})
+	})
+})
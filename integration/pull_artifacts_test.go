@@ -0,0 +1,173 @@
+package integration_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("pull-artifacts", func() {
+		var outputDir string
+
+		upgrader := websocket.Upgrader{}
+
+		// processHandler plays a process in a container, checking what fly
+		// runs and answering with stdout
+		processHandler := func(container string, path string, args []string, stdout []byte) http.HandlerFunc {
+			return ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/containers/"+container+"/hijack"),
+				func(w http.ResponseWriter, r *http.Request) {
+					defer GinkgoRecover()
+
+					conn, err := upgrader.Upgrade(w, r, nil)
+					Expect(err).NotTo(HaveOccurred())
+
+					defer conn.Close()
+
+					var processSpec atc.HijackProcessSpec
+					err = conn.ReadJSON(&processSpec)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(processSpec.Path).To(Equal(path))
+					Expect(processSpec.Args).To(Equal(args))
+
+					for {
+						var input atc.HijackInput
+						err = conn.ReadJSON(&input)
+						Expect(err).NotTo(HaveOccurred())
+
+						if input.Closed {
+							break
+						}
+					}
+
+					err = conn.WriteJSON(atc.HijackOutput{Stdout: stdout})
+					Expect(err).NotTo(HaveOccurred())
+
+					exitStatus := 0
+					err = conn.WriteJSON(atc.HijackOutput{ExitStatus: &exitStatus})
+					Expect(err).NotTo(HaveOccurred())
+				},
+			)
+		}
+
+		tarball := func(name string, contents string) []byte {
+			buf := new(bytes.Buffer)
+			tarWriter := tar.NewWriter(buf)
+
+			err := tarWriter.WriteHeader(&tar.Header{Name: name + "/", Mode: 0755, Typeflag: tar.TypeDir})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = tarWriter.WriteHeader(&tar.Header{Name: name + "/file", Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = tarWriter.Write([]byte(contents))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tarWriter.Close()).To(Succeed())
+
+			return buf.Bytes()
+		}
+
+		BeforeEach(func() {
+			var err error
+			outputDir, err = ioutil.TempDir("", "fly-pull-artifacts")
+			Expect(err).NotTo(HaveOccurred())
+
+			config := atc.Config{
+				Jobs: atc.JobConfigs{
+					{
+						Name: "some-job",
+						Plan: atc.PlanSequence{
+							{Get: "repo"},
+							{
+								Task: "build",
+								TaskConfig: &atc.TaskConfig{
+									Platform: "linux",
+									Run:      atc.TaskRunConfig{Path: "make"},
+									Outputs:  []atc.TaskOutputConfig{{Name: "binaries"}},
+								},
+								OutputMapping: map[string]string{"binaries": "release"},
+							},
+							{Task: "package", TaskConfigPath: "repo/ci/package.yml"},
+						},
+					},
+				},
+			}
+
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job/builds"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Build{
+						{ID: 9, Name: "3", Status: "failed"},
+						{ID: 8, Name: "2", Status: "succeeded"},
+					}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/config"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &config}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers", "build_id=8&step_name=build"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Container{
+						{ID: "build-container", BuildID: 8, Type: "task", StepName: "build", WorkingDirectory: "/tmp/build/1", User: "root"},
+					}),
+				),
+				processHandler("build-container", "tar", []string{"-cf", "-", "-C", ".", "binaries"}, tarball("binaries", "some binary")),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/containers", "build_id=8&step_name=package"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Container{
+						{ID: "package-container", BuildID: 8, Type: "task", StepName: "package", WorkingDirectory: "/tmp/build/2", User: "root"},
+					}),
+				),
+				processHandler("package-container", "cat", []string{"repo/ci/package.yml"}, []byte(`---
+platform: linux
+run: {path: package}
+outputs:
+- name: pkg
+  path: out/pkg
+`)),
+				processHandler("package-container", "tar", []string{"-cf", "-", "-C", "out", "pkg"}, tarball("pkg", "some package")),
+			)
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(outputDir)
+		})
+
+		It("pulls every task's outputs from the latest successful build", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "pull-artifacts", "-j", "some-pipeline/some-job", "-o", outputDir)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("pulling the outputs of some-pipeline/some-job #2"))
+			Expect(sess.Out).To(gbytes.Say("pulled release from task build"))
+			Expect(sess.Out).To(gbytes.Say("pulled pkg from task package"))
+
+			contents, err := ioutil.ReadFile(filepath.Join(outputDir, "release", "file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some binary"))
+
+			contents, err = ioutil.ReadFile(filepath.Join(outputDir, "pkg", "file"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("some package"))
+		})
+	})
+})
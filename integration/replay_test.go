@@ -0,0 +1,88 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("replay", func() {
+		var (
+			tmpdir  string
+			logFile string
+		)
+
+		BeforeEach(func() {
+			var err error
+			tmpdir, err = ioutil.TempDir("", "fly-replay")
+			Expect(err).NotTo(HaveOccurred())
+
+			logFile = filepath.Join(tmpdir, "build.log")
+
+			file, err := os.Create(logFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			encoder := json.NewEncoder(file)
+			for _, ev := range []atc.Event{
+				event.Log{Payload: "compiling\n"},
+				event.FinishTask{ExitStatus: 1},
+				event.Status{Status: atc.StatusFailed},
+			} {
+				data, err := json.Marshal(ev)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = encoder.Encode(map[string]interface{}{
+					"time":    time.Now(),
+					"event":   ev.EventType(),
+					"version": ev.Version(),
+					"data":    json.RawMessage(data),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(file.Close()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpdir)
+		})
+
+		It("renders the build's events again, exiting as the build did", func() {
+			flyCmd := exec.Command(flyPath, "replay", logFile)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Out).To(gbytes.Say("compiling"))
+			Expect(sess.Out).To(gbytes.Say("failed"))
+		})
+
+		Context("when the file doesn't exist", func() {
+			It("errors", func() {
+				flyCmd := exec.Command(flyPath, "replay", filepath.Join(tmpdir, "missing.log"))
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.Err).To(gbytes.Say("no such file or directory"))
+			})
+		})
+	})
+})
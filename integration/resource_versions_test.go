@@ -0,0 +1,87 @@
+package integration_test
+
+import (
+	"net/http"
+	"os/exec"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("disable-resource-version", func() {
+		versionsPath := "/api/v1/teams/main/pipelines/some-pipeline/resources/some-resource/versions"
+
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", versionsPath, "limit=100"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.VersionedResource{
+						{ID: 3, Version: atc.Version{"ref": "newest"}, Enabled: true},
+						{ID: 2, Version: atc.Version{"ref": "newer"}, Enabled: true},
+					}, http.Header{"Link": {`<https://elsewhere.example.com` + versionsPath + `?until=2&limit=100>; rel="next"`}}),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", versionsPath, "until=2&limit=100"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.VersionedResource{
+						{ID: 1, Version: atc.Version{"ref": "abc123"}, Enabled: true},
+					}),
+				),
+			)
+		})
+
+		It("finds the version and disables it", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("PUT", versionsPath+"/1/disable"),
+					ghttp.RespondWith(http.StatusOK, nil),
+				),
+			)
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "disable-resource-version", "-r", "some-pipeline/some-resource", "--version", "ref:abc123")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(sess.Out).To(gbytes.Say(`disabled version {"ref":"abc123"} of 'some-resource'`))
+		})
+
+		It("fails for a version the resource does not have", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "disable-resource-version", "-r", "some-pipeline/some-resource", "--version", "ref:bogus")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+			Expect(sess.Err).To(gbytes.Say(`resource 'some-resource' has no version {"ref":"bogus"}`))
+		})
+	})
+
+	Describe("enable-resource-version", func() {
+		It("leaves a version that is already enabled alone", func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/some-pipeline/resources/some-resource/versions"),
+					ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.VersionedResource{
+						{ID: 1, Version: atc.Version{"ref": "abc123"}, Enabled: true},
+					}),
+				),
+			)
+
+			flyCmd := exec.Command(flyPath, "-t", targetName, "enable-resource-version", "-r", "some-pipeline/some-resource", "-v", "ref:abc123")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(0))
+			Expect(sess.Out).To(gbytes.Say(`version {"ref":"abc123"} of 'some-resource' is already enabled`))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package integration_test
+
+import (
+	"os/exec"
+
+	"github.com/concourse/atc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("response caching", func() {
+		pipelines := func(args ...string) string {
+			flyCmd := exec.Command(flyPath, append([]string{"-t", targetName}, append(args, "pipelines", "--format", "{{.Name}}")...)...)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			return string(sess.Out.Contents())
+		}
+
+		BeforeEach(func() {
+			atcServer.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+					ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{{Name: "some-pipeline"}}),
+				),
+				infoHandler(),
+			)
+		})
+
+		It("answers a listing again from the cache", func() {
+			Expect(pipelines()).To(Equal("some-pipeline\n"))
+
+			requests := len(atcServer.ReceivedRequests())
+
+			Expect(pipelines()).To(Equal("some-pipeline\n"))
+
+			// only the info request to validate the target
+			Expect(atcServer.ReceivedRequests()).To(HaveLen(requests + 1))
+		})
+
+		Context("with --no-cache", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{{Name: "new-pipeline"}}),
+					),
+				)
+			})
+
+			It("asks the ATC", func() {
+				Expect(pipelines()).To(Equal("some-pipeline\n"))
+				Expect(pipelines("--no-cache")).To(Equal("new-pipeline\n"))
+			})
+		})
+
+		Context("when a command changes something in between", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("PUT", "/api/v1/teams/main/pipelines/some-pipeline/pause"),
+						ghttp.RespondWith(200, ""),
+					),
+					infoHandler(),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+						ghttp.RespondWithJSONEncoded(200, []atc.Pipeline{{Name: "some-pipeline", Paused: true}}),
+					),
+				)
+			})
+
+			It("asks the ATC afresh", func() {
+				Expect(pipelines()).To(Equal("some-pipeline\n"))
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "pause-pipeline", "-p", "some-pipeline")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+
+				requests := len(atcServer.ReceivedRequests())
+
+				Expect(pipelines()).To(Equal("some-pipeline\n"))
+				Expect(atcServer.ReceivedRequests()).To(HaveLen(requests + 2))
+			})
+		})
+	})
+})
@@ -0,0 +1,76 @@
+package integration_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+)
+
+var _ = Describe("Session expiry", func() {
+	BeforeEach(func() {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp": time.Now().Add(5 * time.Minute).Unix(),
+		}).SignedString([]byte("some-key"))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(
+			filepath.Join(homeDir, ".flyrc-tokens"),
+			[]byte(targetName+":\n  type: Bearer\n  value: "+token+"\n"),
+			0600,
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		atcServer.AppendHandlers(
+			infoHandler(),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v1/builds/3/events"),
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+
+					sse.Event{Name: "end"}.Write(w)
+				},
+			),
+		)
+	})
+
+	Context("when the token expires before the expected duration", func() {
+		It("warns and explains how to log in again", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--expected-duration", "1h")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Err).To(gbytes.Say("WARNING:"))
+			Expect(sess.Err).To(gbytes.Say("your token for target '" + targetName + "' expires in"))
+			Expect(sess.Err).To(gbytes.Say("fly -t " + targetName + " login"))
+		})
+	})
+
+	Context("when the token outlasts the expected duration", func() {
+		It("does not warn", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--expected-duration", "1m")
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Err).NotTo(gbytes.Say("WARNING:"))
+		})
+	})
+})
@@ -582,6 +582,89 @@ this is super secure
 					}).By(3))
 				})
 			})
+
+			Context("when the given vars do not match the config's vars", func() {
+				It("warns about vars that are missing or never used", func() {
+					flyCmd := exec.Command(
+						flyPath, "-t", targetName,
+						"set-pipeline",
+						"--pipeline", "awesome-pipeline",
+						"-c", "fixtures/vars-pipeline.yml",
+						"-l", "fixtures/vars-pipeline-params-a.yml",
+						"-l", "fixtures/vars-pipeline-params-types.yml",
+						"-v", "param_b=some-param-b-via-v",
+					)
+
+					stdin, err := flyCmd.StdinPipe()
+					Expect(err).NotTo(HaveOccurred())
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					Eventually(sess.Err).Should(gbytes.Say("WARNING:"))
+					Eventually(sess.Err).Should(gbytes.Say(`vars not given with -v, -y or -l \(the credential manager must provide these\):`))
+					Eventually(sess.Err).Should(gbytes.Say("  - param-b"))
+					Eventually(sess.Err).Should(gbytes.Say("vars given but never used by the pipeline:"))
+					Eventually(sess.Err).Should(gbytes.Say("  - param_b"))
+
+					Eventually(sess).Should(gbytes.Say(`apply configuration\? \[yN\]: `))
+					no(stdin)
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+				})
+
+				Context("with --check-vars", func() {
+					It("fails before applying the config", func() {
+						flyCmd := exec.Command(
+							flyPath, "-t", targetName,
+							"set-pipeline",
+							"-n",
+							"--pipeline", "awesome-pipeline",
+							"-c", "fixtures/vars-pipeline.yml",
+							"-l", "fixtures/vars-pipeline-params-a.yml",
+							"-l", "fixtures/vars-pipeline-params-types.yml",
+							"-v", "param_b=some-param-b-via-v",
+							"--check-vars",
+						)
+
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						Eventually(sess.Err).Should(gbytes.Say("vars do not match the pipeline config"))
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(1))
+						Expect(sess.Out).NotTo(gbytes.Say("apply configuration"))
+					})
+
+					It("allows vars deferred to the credential manager", func() {
+						flyCmd := exec.Command(
+							flyPath, "-t", targetName,
+							"set-pipeline",
+							"--pipeline", "awesome-pipeline",
+							"-c", "fixtures/vars-pipeline.yml",
+							"-l", "fixtures/vars-pipeline-params-a.yml",
+							"-l", "fixtures/vars-pipeline-params-types.yml",
+							"--defer-var", "param-*",
+							"--check-vars",
+						)
+
+						stdin, err := flyCmd.StdinPipe()
+						Expect(err).NotTo(HaveOccurred())
+
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						Eventually(sess).Should(gbytes.Say(`apply configuration\? \[yN\]: `))
+						no(stdin)
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(0))
+						Expect(sess.Err).NotTo(gbytes.Say("WARNING:"))
+					})
+				})
+			})
 		})
 
 		Describe("setting", func() {
@@ -668,6 +751,58 @@ this is super secure
 				})
 			})
 
+			Context("when reading the config from stdin", func() {
+				It("fails unless it is non-interactive", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "set-pipeline", "-p", "awesome-pipeline", "-c", "-")
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+
+					Expect(sess.Err).To(gbytes.Say("error: reading the config from stdin needs --non-interactive"))
+				})
+
+				It("sets the piped config", func() {
+					path, err := atc.Routes.CreatePathForRoute(atc.SaveConfig, rata.Params{"pipeline_name": "awesome-pipeline", "team_name": "main"})
+					Expect(err).NotTo(HaveOccurred())
+
+					atcServer.RouteToHandler("PUT", path,
+						ghttp.CombineHandlers(
+							ghttp.VerifyHeaderKV(atc.ConfigVersionHeader, "42"),
+							func(w http.ResponseWriter, r *http.Request) {
+								receivedConfig := atc.Config{}
+								err := yaml.Unmarshal(getConfig(r), &receivedConfig)
+								Expect(err).NotTo(HaveOccurred())
+
+								Expect(receivedConfig).To(Equal(changedConfig))
+
+								w.WriteHeader(http.StatusOK)
+								w.Write([]byte(`{}`))
+							},
+						),
+					)
+
+					flyCmd := exec.Command(flyPath, "-t", targetName, "set-pipeline", "-n", "-p", "awesome-pipeline", "-c", "-")
+
+					stdin, err := flyCmd.StdinPipe()
+					Expect(err).NotTo(HaveOccurred())
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = stdin.Write(payload)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stdin.Close()).To(Succeed())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					Expect(sess.Out).To(gbytes.Say("configuration updated"))
+				})
+			})
+
 			Context("when configuring succeeds", func() {
 				BeforeEach(func() {
 					newGroup := changedConfig.Groups[1]
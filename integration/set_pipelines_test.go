@@ -0,0 +1,147 @@
+package integration_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/concourse/atc"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("set-pipelines", func() {
+		var (
+			dir string
+
+			existingConfig atc.Config
+			newBStatus     int
+			aPuts          [][]byte
+		)
+
+		writeConfig := func(name string, config interface{}) {
+			payload, err := yaml.Marshal(config)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = ioutil.WriteFile(filepath.Join(dir, name), payload, 0644)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = ioutil.TempDir("", "fly-pipelines")
+			Expect(err).NotTo(HaveOccurred())
+
+			existingConfig = atc.Config{
+				Resources: atc.ResourceConfigs{
+					{Name: "repo", Type: "git", Source: atc.Source{"branch": "master"}},
+				},
+				Jobs: atc.JobConfigs{
+					{Name: "unit", Plan: atc.PlanSequence{{Get: "repo"}}},
+				},
+			}
+
+			writeConfig("a.yml", map[string]interface{}{
+				"resources": []map[string]interface{}{
+					{"name": "repo", "type": "git", "source": map[string]string{"branch": "((branch))"}},
+				},
+				"jobs": []map[string]interface{}{
+					{"name": "unit", "plan": []map[string]string{{"get": "repo"}}},
+				},
+			})
+			writeConfig("a.vars.yml", map[string]string{"branch": "develop"})
+			writeConfig("b.yml", existingConfig)
+
+			newBStatus = http.StatusCreated
+			aPuts = nil
+
+			atcServer.RouteToHandler("GET", "/api/v1/teams/main/pipelines/a/config",
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.ConfigResponse{Config: &existingConfig}, http.Header{atc.ConfigVersionHeader: {"42"}}),
+			)
+
+			atcServer.RouteToHandler("GET", "/api/v1/teams/main/pipelines/b/config",
+				ghttp.RespondWith(http.StatusNotFound, nil),
+			)
+
+			atcServer.RouteToHandler("PUT", "/api/v1/teams/main/pipelines/a/config", func(w http.ResponseWriter, r *http.Request) {
+				aPuts = append(aPuts, getConfig(r))
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{}`))
+			})
+
+			atcServer.RouteToHandler("PUT", "/api/v1/teams/main/pipelines/b/config", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(newBStatus)
+				w.Write([]byte(`{}`))
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		It("shows every change and applies them all with one confirmation", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "set-pipelines", "-d", dir)
+
+			stdin, err := flyCmd.StdinPipe()
+			Expect(err).NotTo(HaveOccurred())
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gbytes.Say("pipeline a has changed:"))
+			Eventually(sess).Should(gbytes.Say("resource repo has changed:"))
+			Eventually(sess).Should(gbytes.Say("pipeline b is new:"))
+			Eventually(sess).Should(gbytes.Say("2 pipelines to apply, 0 unchanged"))
+			Eventually(sess).Should(gbytes.Say(`apply configuration\? \[yN\]: `))
+			fmt.Fprintf(stdin, "y\n")
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+
+			Expect(sess.Out).To(gbytes.Say("pipeline a updated"))
+			Expect(sess.Out).To(gbytes.Say(`pipeline b created \(it is currently paused\)`))
+
+			Expect(aPuts).To(HaveLen(1))
+
+			var applied atc.Config
+			Expect(yaml.Unmarshal(aPuts[0], &applied)).To(Succeed())
+			Expect(applied.Resources[0].Source).To(Equal(atc.Source{"branch": "develop"}))
+		})
+
+		Context("when applying a pipeline fails", func() {
+			BeforeEach(func() {
+				newBStatus = http.StatusInternalServerError
+			})
+
+			It("puts back the pipelines already applied", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "set-pipelines", "-d", dir, "-n")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+
+				Expect(sess.Err).To(gbytes.Say("failed to apply pipeline 'b'"))
+				Expect(sess.Err).To(gbytes.Say("rolled back pipeline 'a'"))
+
+				Expect(aPuts).To(HaveLen(2))
+
+				var restored atc.Config
+				Expect(yaml.Unmarshal(aPuts[1], &restored)).To(Succeed())
+				Expect(restored.Resources[0].Source).To(Equal(atc.Source{"branch": "master"}))
+			})
+		})
+	})
+})
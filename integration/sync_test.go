@@ -1,8 +1,18 @@
 package integration_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"os"
 	"os/exec"
@@ -23,8 +33,19 @@ var _ = Describe("Syncing", func() {
 	var (
 		flyVersion string
 		flyPath    string
+
+		cliHeaders http.Header
 	)
 
+	const cliBinary = "this will totally execute"
+
+	BeforeEach(func() {
+		sum := sha256.Sum256([]byte(cliBinary))
+
+		cliHeaders = http.Header{}
+		cliHeaders.Set("X-Fly-SHA256", hex.EncodeToString(sum[:]))
+	})
+
 	cliHandler := func() http.HandlerFunc {
 		return ghttp.CombineHandlers(
 			ghttp.VerifyRequest("GET", "/api/v1/cli"),
@@ -37,8 +58,12 @@ var _ = Describe("Syncing", func() {
 					return
 				}
 
+				for header, values := range cliHeaders {
+					w.Header()[header] = values
+				}
+
 				w.WriteHeader(http.StatusOK)
-				fmt.Fprint(w, "this will totally execute")
+				fmt.Fprint(w, cliBinary)
 			},
 		)
 	}
@@ -70,10 +95,169 @@ var _ = Describe("Syncing", func() {
 			<-sess.Exited
 			Expect(sess.ExitCode()).To(Equal(0))
 
-			expected := []byte("this will totally execute")
+			expected := []byte(cliBinary)
 			expectBinaryToMatch(flyPath, expected[:8])
 		})
 
+		Context("when the downloaded binary does not match the published checksum", func() {
+			BeforeEach(func() {
+				sum := sha256.Sum256([]byte("something else entirely"))
+				cliHeaders.Set("X-Fly-SHA256", hex.EncodeToString(sum[:]))
+			})
+
+			It("returns an error, and doesn't replace the executable", func() {
+				expectedBinary := readBinary(flyPath)
+
+				flyCmd := exec.Command(flyPath, "-t", targetName, "sync")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(1))
+				Expect(sess.Err).To(gbytes.Say("update failed.*checksum"))
+
+				expectBinaryToMatch(flyPath, expectedBinary)
+			})
+		})
+
+		Context("when the ATC does not publish a checksum", func() {
+			BeforeEach(func() {
+				cliHeaders.Del("X-Fly-SHA256")
+			})
+
+			It("warns that the download is unverified, and replaces the executable", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "sync")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				<-sess.Exited
+				Expect(sess.ExitCode()).To(Equal(0))
+				Expect(sess.Err).To(gbytes.Say("the download can't be verified"))
+
+				expected := []byte(cliBinary)
+				expectBinaryToMatch(flyPath, expected[:8])
+			})
+		})
+
+		Context("when a public key is given", func() {
+			var (
+				privateKey    *ecdsa.PrivateKey
+				publicKeyPath string
+			)
+
+			BeforeEach(func() {
+				var err error
+				privateKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+				Expect(err).NotTo(HaveOccurred())
+
+				der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+				Expect(err).NotTo(HaveOccurred())
+
+				keyFile, err := ioutil.TempFile("", "fly-public-key")
+				Expect(err).NotTo(HaveOccurred())
+
+				err = pem.Encode(keyFile, &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(keyFile.Close()).To(Succeed())
+				publicKeyPath = keyFile.Name()
+			})
+
+			AfterEach(func() {
+				os.Remove(publicKeyPath)
+			})
+
+			sign := func(key *ecdsa.PrivateKey) string {
+				sum := sha256.Sum256([]byte(cliBinary))
+
+				r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+				Expect(err).NotTo(HaveOccurred())
+
+				signature, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+				Expect(err).NotTo(HaveOccurred())
+
+				return base64.StdEncoding.EncodeToString(signature)
+			}
+
+			Context("when the binary is signed with the matching key", func() {
+				BeforeEach(func() {
+					cliHeaders.Set("X-Fly-Signature", sign(privateKey))
+				})
+
+				It("replaces the executable", func() {
+					flyCmd := exec.Command(flyPath, "-t", targetName, "sync", "--public-key", publicKeyPath)
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(0))
+
+					expected := []byte(cliBinary)
+					expectBinaryToMatch(flyPath, expected[:8])
+				})
+
+				Context("when the key isn't given", func() {
+					It("suggests verifying the signature, and replaces the executable", func() {
+						flyCmd := exec.Command(flyPath, "-t", targetName, "sync")
+
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(0))
+						Expect(sess.Err).To(gbytes.Say("pass --public-key to verify its signature"))
+
+						expected := []byte(cliBinary)
+						expectBinaryToMatch(flyPath, expected[:8])
+					})
+				})
+			})
+
+			Context("when the binary is signed with another key", func() {
+				BeforeEach(func() {
+					otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+					Expect(err).NotTo(HaveOccurred())
+
+					cliHeaders.Set("X-Fly-Signature", sign(otherKey))
+				})
+
+				It("returns an error, and doesn't replace the executable", func() {
+					expectedBinary := readBinary(flyPath)
+
+					flyCmd := exec.Command(flyPath, "-t", targetName, "sync", "--public-key", publicKeyPath)
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.Err).To(gbytes.Say("update failed"))
+
+					expectBinaryToMatch(flyPath, expectedBinary)
+				})
+			})
+
+			Context("when the ATC does not publish a signature", func() {
+				It("returns an error, and doesn't replace the executable", func() {
+					expectedBinary := readBinary(flyPath)
+
+					flyCmd := exec.Command(flyPath, "-t", targetName, "sync", "--public-key", publicKeyPath)
+
+					sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+					Expect(err).NotTo(HaveOccurred())
+
+					<-sess.Exited
+					Expect(sess.ExitCode()).To(Equal(1))
+					Expect(sess.Err).To(gbytes.Say("update failed: the ATC did not publish a signature for fly"))
+
+					expectBinaryToMatch(flyPath, expectedBinary)
+				})
+			})
+		})
+
 		Context("When the user running sync doesn't have write permissions for the target directory", func() {
 			It("returns an error, and doesn't download/replace the executable", func() {
 				me, err := user.Current()
@@ -57,6 +57,30 @@ var _ = Describe("Fly CLI", func() {
 					}).By(2))
 				})
 
+				Context("when the job is left to the target's default", func() {
+					BeforeEach(func() {
+						setDefaults := exec.Command(flyPath, "-t", targetName, "set-defaults", "-p", "awesome-pipeline", "-j", "awesome-job")
+
+						sess, err := gexec.Start(setDefaults, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(0))
+					})
+
+					It("starts a build of the default job", func() {
+						flyCmd := exec.Command(flyPath, "-t", targetName, "trigger-job")
+
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						Eventually(sess).Should(gbytes.Say(`started awesome-pipeline/awesome-job #42`))
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(0))
+					})
+				})
+
 				Context("when -w option is provided", func() {
 					var streaming chan struct{}
 					var events chan atc.Event
@@ -127,6 +151,46 @@ var _ = Describe("Fly CLI", func() {
 						<-sess.Exited
 						Expect(sess.ExitCode()).To(Equal(0))
 					})
+
+					for status, exitCode := range map[atc.BuildStatus]int{
+						atc.StatusSucceeded: 0,
+						atc.StatusFailed:    1,
+						atc.StatusErrored:   2,
+						atc.StatusAborted:   3,
+					} {
+						status, exitCode := status, exitCode
+
+						It(fmt.Sprintf("exits %d when the build has %s", exitCode, status), func() {
+							flyCmd := exec.Command(flyPath, "-t", targetName, "trigger-job", "-j", "awesome-pipeline/awesome-job", "-w")
+
+							sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+							Expect(err).NotTo(HaveOccurred())
+
+							Eventually(streaming).Should(BeClosed())
+
+							events <- event.Status{Status: status}
+							close(events)
+
+							<-sess.Exited
+							Expect(sess.ExitCode()).To(Equal(exitCode))
+						})
+					}
+
+					It("exits with the exit status of a failed task", func() {
+						flyCmd := exec.Command(flyPath, "-t", targetName, "trigger-job", "-j", "awesome-pipeline/awesome-job", "-w")
+
+						sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+						Expect(err).NotTo(HaveOccurred())
+
+						Eventually(streaming).Should(BeClosed())
+
+						events <- event.FinishTask{ExitStatus: 7}
+						events <- event.Status{Status: atc.StatusFailed}
+						close(events)
+
+						<-sess.Exited
+						Expect(sess.ExitCode()).To(Equal(7))
+					})
 				})
 			})
 
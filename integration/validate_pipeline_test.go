@@ -1,6 +1,7 @@
 package integration_test
 
 import (
+	"os"
 	"os/exec"
 
 	. "github.com/onsi/ginkgo"
@@ -83,5 +84,61 @@ var _ = Describe("Fly CLI", func() {
 
 			Expect(sess.Err).To(gbytes.Say("configuration invalid"))
 		})
+
+		It("resolves YAML anchors and merge keys", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"validate-pipeline",
+				"-c", "fixtures/anchors-pipeline.yml",
+				"-v", "repo-uri=https://example.com/repo.git",
+				"-v", "notify-resource=repo",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gbytes.Say("looks good"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
+
+		It("returns invalid when a YAML anchor refers to itself", func() {
+			flyCmd := exec.Command(
+				flyPath,
+				"validate-pipeline",
+				"-c", "fixtures/testConfigAnchorCycle.yml",
+			)
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(1))
+
+			Expect(sess.Err).To(gbytes.Say("could not resolve template vars"))
+			Expect(sess.Err).To(gbytes.Say("YAML anchor 'some-resource' refers to itself, so it can never be resolved"))
+		})
+
+		It("reads the configuration from stdin given -", func() {
+			config, err := os.Open("fixtures/testConfigValid.yml")
+			Expect(err).NotTo(HaveOccurred())
+			defer config.Close()
+
+			flyCmd := exec.Command(
+				flyPath,
+				"validate-pipeline",
+				"-c", "-",
+			)
+			flyCmd.Stdin = config
+
+			sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gbytes.Say("looks good"))
+
+			<-sess.Exited
+			Expect(sess.ExitCode()).To(Equal(0))
+		})
 	})
 })
@@ -0,0 +1,94 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"sync/atomic"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("wait-for-job", func() {
+		jobPath := "/api/v1/teams/main/pipelines/some-pipeline/jobs/some-job"
+
+		var polls int32
+
+		BeforeEach(func() {
+			atomic.StoreInt32(&polls, 0)
+
+			// the job's new build starts on the third time it's looked at
+			atcServer.RouteToHandler("GET", jobPath, func(w http.ResponseWriter, r *http.Request) {
+				job := atc.Job{
+					Name:          "some-job",
+					FinishedBuild: &atc.Build{ID: 41, Name: "6", Status: "succeeded"},
+				}
+
+				if atomic.AddInt32(&polls, 1) >= 3 {
+					job.NextBuild = &atc.Build{ID: 42, Name: "7", Status: "started"}
+				}
+
+				ghttp.RespondWithJSONEncoded(http.StatusOK, job)(w, r)
+			})
+		})
+
+		Context("when a new build starts", func() {
+			BeforeEach(func() {
+				atcServer.RouteToHandler("GET", "/api/v1/builds/42/events", func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+					w.WriteHeader(http.StatusOK)
+
+					events := []atc.Event{
+						event.Log{Payload: "hello from 42\n"},
+						event.Status{Status: atc.StatusFailed},
+					}
+
+					for _, e := range events {
+						payload, err := json.Marshal(event.Message{Event: e})
+						Expect(err).NotTo(HaveOccurred())
+
+						err = sse.Event{Name: "event", Data: payload}.Write(w)
+						Expect(err).NotTo(HaveOccurred())
+					}
+
+					err := sse.Event{Name: "end"}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			It("watches it and exits with its status", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "wait-for-job", "-j", "some-pipeline/some-job", "--interval", "10ms")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+
+				Expect(sess.Out).To(gbytes.Say("waiting for a new build of some-pipeline/some-job"))
+				Expect(sess.Out).To(gbytes.Say("started some-pipeline/some-job #7"))
+				Expect(sess.Out).To(gbytes.Say("hello from 42"))
+			})
+		})
+
+		Context("when no new build starts in time", func() {
+			It("gives up", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "wait-for-job", "-j", "some-pipeline/some-job", "--interval", "1s", "--timeout", "100ms")
+
+				sess, err := gexec.Start(flyCmd, GinkgoWriter, GinkgoWriter)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+				Expect(sess.Err).To(gbytes.Say("timed out after 100ms waiting for a new build of some-pipeline/some-job"))
+			})
+		})
+	})
+})
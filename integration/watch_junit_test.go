@@ -0,0 +1,88 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("watch --junit-output", func() {
+		var reportPath string
+
+		BeforeEach(func() {
+			reportPath = filepath.Join(homeDir, "report.xml")
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3/plan", ghttp.RespondWith(http.StatusNotFound, nil))
+
+			atcServer.RouteToHandler("GET", "/api/v1/builds/3/events", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				events := []atc.Event{
+					event.FinishGet{Origin: event.Origin{ID: "1"}, Plan: event.GetPlan{Name: "some-input"}},
+					event.StartTask{Origin: event.Origin{ID: "2"}, Time: 100, TaskConfig: event.TaskConfig{Run: event.TaskRunConfig{Path: "run-tests"}}},
+					event.Log{Origin: event.Origin{ID: "2"}, Payload: "ok 1 - adds\nnot ok 2 - subtracts\n"},
+					event.FinishTask{Origin: event.Origin{ID: "2"}, Time: 130, ExitStatus: 1},
+					event.Status{Status: atc.StatusFailed},
+				}
+
+				for _, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		It("writes a JUnit report of the build's steps", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--junit-output", reportPath)
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(1))
+
+			report, err := ioutil.ReadFile(reportPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(report)).To(ContainSubstring(`<testsuite name="build 3" tests="2" failures="1" errors="0" skipped="0" time="30.000">`))
+			Expect(string(report)).To(ContainSubstring(`<testcase name="get: some-input" classname="build 3"></testcase>`))
+			Expect(string(report)).To(ContainSubstring(`<failure message="failed (exit 1)"></failure>`))
+			Expect(string(report)).NotTo(ContainSubstring("subtracts"))
+		})
+
+		Context("with --junit-tap", func() {
+			It("also reports the TAP results the task printed", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "3", "--junit-output", reportPath, "--junit-tap")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(1))
+
+				report, err := ioutil.ReadFile(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(string(report)).To(ContainSubstring(`<testsuite name="build 3: task running run-tests" tests="2" failures="1" errors="0" skipped="0">`))
+				Expect(string(report)).To(ContainSubstring(`<testcase name="subtracts" classname="build 3: task running run-tests">`))
+			})
+		})
+	})
+})
@@ -0,0 +1,141 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/atc/event"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/vito/go-sse/sse"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fly CLI", func() {
+	Describe("watching several builds", func() {
+		finishedBuild := func(buildID int, output string, exitStatus int) {
+			status := atc.StatusSucceeded
+			if exitStatus != 0 {
+				status = atc.StatusFailed
+			}
+
+			atcServer.RouteToHandler("GET", fmt.Sprintf("/api/v1/builds/%d/events", buildID), func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "text/event-stream; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+
+				events := []atc.Event{
+					event.Log{Payload: output},
+					event.FinishTask{ExitStatus: exitStatus},
+					event.Status{Status: status},
+				}
+
+				for _, e := range events {
+					payload, err := json.Marshal(event.Message{Event: e})
+					Expect(err).NotTo(HaveOccurred())
+
+					err = sse.Event{Name: "event", Data: payload}.Write(w)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := sse.Event{Name: "end"}.Write(w)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		}
+
+		BeforeEach(func() {
+			finishedBuild(128, "hello from 128\n", 0)
+			finishedBuild(129, "hello from 129\n", 3)
+		})
+
+		It("streams every build with a prefix and exits with the first failure", func() {
+			flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-b", "128", "-b", "129")
+
+			sess, err := gexec.Start(flyCmd, nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(sess).Should(gexec.Exit(3))
+
+			Expect(sess.Out.Contents()).To(ContainSubstring("[build 128] hello from 128"))
+			Expect(sess.Out.Contents()).To(ContainSubstring("[build 129] hello from 129"))
+			Expect(sess.Out).To(gbytes.Say(`build 128\s+128\s+succeeded\s+0`))
+			Expect(sess.Out).To(gbytes.Say(`build 129\s+129\s+failed\s+3`))
+		})
+
+		Context("when given a glob of jobs", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/main/jobs"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Job{
+							{Name: "test-unit", NextBuild: &atc.Build{ID: 128, Name: "7"}},
+							{Name: "test-integration", FinishedBuild: &atc.Build{ID: 129, Name: "3"}},
+							{Name: "test-never-run"},
+							{Name: "deploy", NextBuild: &atc.Build{ID: 130, Name: "1"}},
+						}),
+					),
+				)
+			})
+
+			It("watches the current build of every matching job", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-j", "main/test-*")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(3))
+
+				Expect(sess.Out.Contents()).To(ContainSubstring("[main/test-unit #7] hello from 128"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("[main/test-integration #3] hello from 129"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("deploy"))
+			})
+		})
+
+		Context("when given a glob of pipelines", func() {
+			BeforeEach(func() {
+				atcServer.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Pipeline{
+							{Name: "release-api"},
+							{Name: "release-web"},
+							{Name: "nightly"},
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/release-api/jobs"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Job{
+							{Name: "test", FinishedBuild: &atc.Build{ID: 130, Name: "2"}},
+							{Name: "deploy", NextBuild: &atc.Build{ID: 128, Name: "7"}},
+						}),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/api/v1/teams/main/pipelines/release-web/jobs"),
+						ghttp.RespondWithJSONEncoded(http.StatusOK, []atc.Job{
+							{Name: "deploy", FinishedBuild: &atc.Build{ID: 129, Name: "3"}},
+						}),
+					),
+				)
+			})
+
+			It("watches the current build of the matching job of every matching pipeline", func() {
+				flyCmd := exec.Command(flyPath, "-t", targetName, "watch", "-j", "release-*/deploy")
+
+				sess, err := gexec.Start(flyCmd, nil, nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(sess).Should(gexec.Exit(3))
+
+				Expect(sess.Out.Contents()).To(ContainSubstring("[release-api/deploy #7] hello from 128"))
+				Expect(sess.Out.Contents()).To(ContainSubstring("[release-web/deploy #3] hello from 129"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("nightly"))
+				Expect(sess.Out.Contents()).NotTo(ContainSubstring("release-api/test"))
+			})
+		})
+	})
+})
@@ -3,13 +3,13 @@ package main
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 
 	"github.com/concourse/atc/auth/provider"
 	"github.com/concourse/fly/commands"
 	"github.com/concourse/fly/rc"
 	"github.com/concourse/fly/ui"
-	"github.com/concourse/go-concourse/concourse"
 	"github.com/jessevdk/go-flags"
 
 	_ "github.com/concourse/atc/auth/genericoauth"
@@ -43,9 +43,53 @@ func main() {
 	helpParser := flags.NewParser(&commands.Fly, flags.HelpFlag)
 	helpParser.NamespaceDelimiter = "-"
 
-	_, err := parser.Parse()
+	// requests other than to targets, e.g. to Vault or a Pushgateway, go
+	// through the default transport
+	http.DefaultTransport = commands.OfflineMiddleware(http.DefaultTransport)
+
+	activeCommand := func() string {
+		if parser.Active == nil {
+			return ""
+		}
+
+		return parser.Active.Name
+	}
+
+	rc.Use(commands.OfflineMiddleware, commands.ResponseCacheMiddleware(activeCommand), rc.ExplainForbidden)
+
+	err := commands.EnableAudit(activeCommand)
+	if err != nil {
+		fmt.Fprintf(ui.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = commands.EnableTracing(activeCommand)
 	if err != nil {
-		if err == concourse.ErrUnauthorized {
+		fmt.Fprintf(ui.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	ran, code, err := commands.RunPlugin(parser, os.Args[1:])
+	if ran {
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "error: %s\n", err)
+		}
+
+		commands.Exit(code)
+	}
+
+	ran, code, err = commands.RunFanOut(parser, os.Args[1:])
+	if ran {
+		if err != nil {
+			fmt.Fprintf(ui.Stderr, "error: %s\n", err)
+		}
+
+		commands.Exit(code)
+	}
+
+	_, err = parser.Parse()
+	if err != nil {
+		if err == rc.ErrUnauthorized {
 			fmt.Fprintln(ui.Stderr, "not authorized. run the following to log in:")
 			fmt.Fprintln(ui.Stderr, "")
 			fmt.Fprintln(ui.Stderr, "    "+ui.Embolden("fly -t %s login", commands.Fly.Target))
@@ -58,6 +102,18 @@ func main() {
 		} else if versionErr, ok := err.(rc.ErrVersionMismatch); ok {
 			fmt.Fprintln(ui.Stderr, versionErr.Error())
 			fmt.Fprintln(ui.Stderr, ui.WarningColor("cowardly refusing to run due to significant version discrepancy"))
+		} else if commands.IsOffline(err) {
+			fmt.Fprintf(ui.Stderr, "error: %s, which this command needs\n", commands.ErrOffline)
+		} else if forbidden, ok := rc.AsForbidden(err); ok {
+			fmt.Fprintln(ui.Stderr, forbidden.Error())
+
+			if forbidden.RequiredTeam != "" && forbidden.RequiredTeam != forbidden.Team {
+				fmt.Fprintln(ui.Stderr, "")
+				fmt.Fprintf(ui.Stderr, "to log in to team '%s', run:\n", forbidden.RequiredTeam)
+				fmt.Fprintln(ui.Stderr, "")
+				fmt.Fprintln(ui.Stderr, "    "+ui.Embolden("fly -t %s login -n %s", commands.Fly.Target, forbidden.RequiredTeam))
+				fmt.Fprintln(ui.Stderr, "")
+			}
 		} else if netErr, ok := err.(net.Error); ok {
 			fmt.Fprintf(ui.Stderr, "could not reach the Concourse server called %s:\n", ui.Embolden("%s", commands.Fly.Target))
 
@@ -68,15 +124,17 @@ func main() {
 		} else if err == commands.ErrShowHelpMessage {
 			helpParser.ParseArgs([]string{"-h"})
 			helpParser.WriteHelp(os.Stdout)
-			os.Exit(0)
+			commands.Exit(0)
 		} else if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrCommandRequired {
 			helpParser.ParseArgs([]string{"-h"})
 			helpParser.WriteHelp(os.Stdout)
-			os.Exit(0)
+			commands.Exit(0)
 		} else {
 			fmt.Fprintf(ui.Stderr, "error: %s\n", err)
 		}
 
-		os.Exit(1)
+		commands.Exit(1)
 	}
+
+	commands.Exit(0)
 }
@@ -0,0 +1,95 @@
+package rc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultsFileName is the file that sets the pipeline and job commands use
+// when run in its directory, or any directory under it, without -p or -j.
+const DefaultsFileName = ".fly.yml"
+
+// Defaults are the pipeline and job to use when a command isn't given one.
+type Defaults struct {
+	Pipeline string `yaml:"pipeline,omitempty"`
+	Job      string `yaml:"job,omitempty"`
+
+	// Exclude is only read from .fly.yml; see LoadExcludes.
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// LoadDefaults returns the defaults in the nearest .fly.yml above the working
+// directory, or if there's none, those saved for the target.
+func LoadDefaults(targetName TargetName) (Defaults, error) {
+	defaults, found, err := loadDefaultsFile()
+	if err != nil || found {
+		return defaults, err
+	}
+
+	if targetName == "" {
+		return Defaults{}, nil
+	}
+
+	flyTargets, err := LoadTargets()
+	if err != nil {
+		return Defaults{}, err
+	}
+
+	targetProps := flyTargets.Targets[targetName]
+
+	return Defaults{Pipeline: targetProps.Pipeline, Job: targetProps.Job}, nil
+}
+
+// SaveDefaults saves the pipeline and job for the target, replacing any it
+// had; empty ones are cleared.
+func SaveDefaults(targetName TargetName, defaults Defaults) error {
+	return updateTargets(func(flyTargets *targetDetailsYAML) error {
+		targetProps, ok := flyTargets.Targets[targetName]
+		if !ok {
+			return UnknownTargetError{targetName}
+		}
+
+		targetProps.Pipeline = defaults.Pipeline
+		targetProps.Job = defaults.Job
+
+		flyTargets.Targets[targetName] = targetProps
+		return nil
+	})
+}
+
+func loadDefaultsFile() (Defaults, bool, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return Defaults{}, false, err
+	}
+
+	for {
+		path := filepath.Join(dir, DefaultsFileName)
+
+		defaultsBytes, err := ioutil.ReadFile(path)
+		if err == nil {
+			var defaults Defaults
+			err = yaml.Unmarshal(defaultsBytes, &defaults)
+			if err != nil {
+				return Defaults{}, false, fmt.Errorf("failed to parse %s: %s", path, err)
+			}
+
+			return defaults, true, nil
+		}
+
+		if !os.IsNotExist(err) {
+			return Defaults{}, false, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Defaults{}, false, nil
+		}
+
+		dir = parent
+	}
+}
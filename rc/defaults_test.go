@@ -0,0 +1,103 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Defaults", func() {
+	var tmpDir string
+	var workingDir string
+	var originalDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("HOME", tmpDir)
+
+		flyrcContents := `targets:
+  some-target:
+    api: http://concourse.com
+    pipeline: target-pipeline
+    job: target-job`
+		err = ioutil.WriteFile(filepath.Join(tmpDir, ".flyrc"), []byte(flyrcContents), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		workingDir = filepath.Join(tmpDir, "repo", "some", "dir")
+		err = os.MkdirAll(workingDir, 0755)
+		Expect(err).ToNot(HaveOccurred())
+
+		originalDir, err = os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = os.Chdir(workingDir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Chdir(originalDir)
+		os.RemoveAll(tmpDir)
+	})
+
+	Describe("LoadDefaults", func() {
+		It("loads the defaults saved for the target", func() {
+			defaults, err := rc.LoadDefaults("some-target")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defaults).To(Equal(rc.Defaults{Pipeline: "target-pipeline", Job: "target-job"}))
+		})
+
+		It("loads no defaults for an unknown target", func() {
+			defaults, err := rc.LoadDefaults("other-target")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defaults).To(Equal(rc.Defaults{}))
+		})
+
+		Context("when a directory above the working directory has a .fly.yml", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(tmpDir, "repo", ".fly.yml"), []byte("pipeline: repo-pipeline\n"), 0644)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("loads its defaults instead of the target's", func() {
+				defaults, err := rc.LoadDefaults("some-target")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(defaults).To(Equal(rc.Defaults{Pipeline: "repo-pipeline"}))
+			})
+		})
+
+		Context("when the .fly.yml is invalid", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(workingDir, ".fly.yml"), []byte("pipeline: [nope\n"), 0644)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns an error naming the file", func() {
+				_, err := rc.LoadDefaults("some-target")
+				Expect(err).To(MatchError(ContainSubstring("failed to parse " + filepath.Join(workingDir, ".fly.yml"))))
+			})
+		})
+	})
+
+	Describe("SaveDefaults", func() {
+		It("replaces the target's defaults", func() {
+			err := rc.SaveDefaults("some-target", rc.Defaults{Pipeline: "other-pipeline"})
+			Expect(err).ToNot(HaveOccurred())
+
+			defaults, err := rc.LoadDefaults("some-target")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(defaults).To(Equal(rc.Defaults{Pipeline: "other-pipeline"}))
+		})
+
+		It("returns UnknownTargetError for an unknown target", func() {
+			err := rc.SaveDefaults("other-target", rc.Defaults{Pipeline: "other-pipeline"})
+			Expect(err).To(Equal(rc.UnknownTargetError{TargetName: "other-target"}))
+		})
+	})
+})
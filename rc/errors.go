@@ -0,0 +1,30 @@
+package rc
+
+import (
+	"fmt"
+
+	"github.com/concourse/go-concourse/concourse"
+)
+
+// ErrUnauthorized is returned when the target rejects the saved token.
+var ErrUnauthorized = concourse.ErrUnauthorized
+
+type ErrNotFound struct {
+	Kind string
+	Name string
+}
+
+func NewErrNotFound(kind string, name string) ErrNotFound {
+	return ErrNotFound{
+		Kind: kind,
+		Name: name,
+	}
+}
+
+func (e ErrNotFound) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s not found", e.Kind)
+	}
+
+	return fmt.Sprintf("%s '%s' not found", e.Kind, e.Name)
+}
@@ -0,0 +1,72 @@
+package rc
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExcludesFileName is the file in the home directory listing the patterns
+// of paths fly execute always leaves out of inputs, one per line.
+const ExcludesFileName = ".flyexclude"
+
+// LoadExcludes returns the patterns of paths to leave out of every upload:
+// the user's, from ~/.flyexclude, followed by the repo's, from the exclude
+// list of the nearest .fly.yml above the working directory. A trailing slash,
+// as in target/, is dropped, as patterns match directories and files alike.
+func LoadExcludes() ([]string, error) {
+	patterns, err := ReadExcludesFile(filepath.Join(userHomeDir(), ExcludesFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, _, err := loadDefaultsFile()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range defaults.Exclude {
+		patterns = appendPattern(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// ReadExcludesFile reads patterns one per line, skipping blank lines and
+// lines starting with #. A file that doesn't exist has none.
+func ReadExcludesFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	var patterns []string
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		patterns = appendPattern(patterns, line)
+	}
+
+	return patterns, scanner.Err()
+}
+
+func appendPattern(patterns []string, pattern string) []string {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" {
+		return patterns
+	}
+
+	return append(patterns, pattern)
+}
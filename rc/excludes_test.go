@@ -0,0 +1,64 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Excludes", func() {
+	var tmpDir string
+	var workingDir string
+	var originalDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("HOME", tmpDir)
+
+		workingDir = filepath.Join(tmpDir, "repo", "some", "dir")
+		err = os.MkdirAll(workingDir, 0755)
+		Expect(err).ToNot(HaveOccurred())
+
+		originalDir, err = os.Getwd()
+		Expect(err).ToNot(HaveOccurred())
+
+		err = os.Chdir(workingDir)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.Chdir(originalDir)
+		os.RemoveAll(tmpDir)
+	})
+
+	Describe("LoadExcludes", func() {
+		It("loads nothing when neither the user nor the repo exclude anything", func() {
+			patterns, err := rc.LoadExcludes()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(patterns).To(BeEmpty())
+		})
+
+		Context("when the user and the repo exclude patterns", func() {
+			BeforeEach(func() {
+				err := ioutil.WriteFile(filepath.Join(tmpDir, ".flyexclude"), []byte("# dependencies\nnode_modules\n\n.git\n"), 0644)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = ioutil.WriteFile(filepath.Join(tmpDir, "repo", ".fly.yml"), []byte("exclude: [vendor, target/]\n"), 0644)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("loads the user's, then the repo's, without trailing slashes", func() {
+				patterns, err := rc.LoadExcludes()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(patterns).To(Equal([]string{"node_modules", ".git", "vendor", "target"}))
+			})
+		})
+	})
+})
@@ -0,0 +1,9 @@
+package rc
+
+func ResetMiddleware() {
+	middlewareLock.Lock()
+	middleware = nil
+	middlewareLock.Unlock()
+}
+
+var APIRetryBackoff = apiRetryBackoff
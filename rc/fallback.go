@@ -0,0 +1,148 @@
+package rc
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/concourse/fly/ui"
+)
+
+// endpoints are the URLs a target's ATC can be reached at, e.g. replicas in
+// several regions. They're tried in order until one can be connected to,
+// which is then stuck with for the rest of the invocation.
+type endpoints struct {
+	lock    sync.Mutex
+	urls    []*url.URL
+	current int
+}
+
+func newEndpoints(api string, fallbacks []string) (*endpoints, error) {
+	var urls []*url.URL
+	for _, rawURL := range append([]string{api}, fallbacks...) {
+		parsed, err := url.Parse(strings.TrimRight(rawURL, "/"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback API %s: %s", rawURL, err)
+		}
+
+		urls = append(urls, parsed)
+	}
+
+	return &endpoints{urls: urls}, nil
+}
+
+// URL returns the URL of the endpoint in use.
+func (e *endpoints) URL() string {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.urls[e.current].String()
+}
+
+func (e *endpoints) get() (int, *url.URL) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.current, e.urls[e.current]
+}
+
+// failed moves on from the endpoint that couldn't be connected to, unless
+// a concurrent request already has. It returns false if there's no endpoint
+// left to try.
+func (e *endpoints) failed(i int) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.current == i {
+		if i == len(e.urls)-1 {
+			return false
+		}
+
+		e.current++
+		fmt.Fprintf(ui.Stderr, "could not connect to %s; trying %s\n", e.urls[i], e.urls[e.current])
+	}
+
+	return true
+}
+
+// withFallbacks sends the client's requests for the target's API to the
+// first of its endpoints that can be connected to.
+func withFallbacks(client *http.Client, api string, fallbacks []string) (*http.Client, *endpoints, error) {
+	if len(fallbacks) == 0 {
+		return client, nil, nil
+	}
+
+	endpoints, err := newEndpoints(api, fallbacks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client.Transport = fallbackTransport{
+		endpoints: endpoints,
+		base:      client.Transport,
+	}
+
+	return client, endpoints, nil
+}
+
+type fallbackTransport struct {
+	endpoints *endpoints
+
+	base http.RoundTripper
+}
+
+func (t fallbackTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	primary := t.endpoints.urls[0]
+
+	// e.g. pipes are at whatever URL the ATC gives, which is left alone
+	if r.URL.Scheme != primary.Scheme || r.URL.Host != primary.Host || !strings.HasPrefix(r.URL.Path, primary.Path) {
+		return t.base.RoundTrip(r)
+	}
+
+	body := r.Body
+	for {
+		i, endpoint := t.endpoints.get()
+
+		request := new(http.Request)
+		*request = *r
+
+		rebased := *r.URL
+		rebased.Scheme = endpoint.Scheme
+		rebased.Host = endpoint.Host
+		rebased.Path = endpoint.Path + strings.TrimPrefix(r.URL.Path, primary.Path)
+		rebased.RawPath = ""
+
+		request.URL = &rebased
+		request.Host = ""
+		request.Body = body
+
+		response, err := t.base.RoundTrip(request)
+		if err == nil || !connectFailed(err) {
+			return response, err
+		}
+
+		// a body that's been read from can't be sent again
+		if r.Body != nil && r.GetBody == nil {
+			return response, err
+		}
+
+		if !t.endpoints.failed(i) {
+			return response, err
+		}
+
+		if r.Body != nil {
+			body, err = r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func connectFailed(err error) bool {
+	opErr, ok := err.(*net.OpError)
+	return ok && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
@@ -0,0 +1,86 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/rc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Fallback APIs", func() {
+	var (
+		tmpDir      string
+		server      *ghttp.Server
+		unreachable string
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("HOME", tmpDir)
+
+		// a port nothing listens on any more
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		unreachable = "http://" + listener.Addr().String()
+		listener.Close()
+
+		server = ghttp.NewServer()
+		server.RouteToHandler("GET", "/api/v1/info", ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Info{Version: "1.2.3"}))
+
+		flyrcContents := `targets:
+  some-target:
+    api: ` + unreachable + `
+    team: main
+    fallback_apis:
+    - ` + server.URL()
+		err = ioutil.WriteFile(filepath.Join(tmpDir, ".flyrc"), []byte(flyrcContents), 0600)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	It("uses the first API that can be connected to, and sticks with it", func() {
+		target, err := rc.LoadTarget("some-target", false)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(target.URL()).To(Equal(unreachable))
+
+		info, err := target.Client().GetInfo()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Version).To(Equal("1.2.3"))
+
+		Expect(target.URL()).To(Equal(server.URL()))
+
+		_, err = target.Client().GetInfo()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	Context("when none of the APIs can be connected to", func() {
+		BeforeEach(func() {
+			server.Close()
+		})
+
+		It("returns the last one's error", func() {
+			target, err := rc.LoadTarget("some-target", false)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = target.Client().GetInfo()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
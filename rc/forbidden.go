@@ -0,0 +1,136 @@
+package rc
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const maxForbiddenReasonLength = 512
+
+// ErrForbidden describes a request the ATC refused with 403 Forbidden: what
+// it would have required, and who fly was authenticated as.
+type ErrForbidden struct {
+	Method string
+	Path   string
+
+	// RequiredTeam is the team the request was scoped to, if any.
+	RequiredTeam string
+
+	// RequiresAdmin is set for requests that only admins may make, e.g.
+	// configuring teams.
+	RequiresAdmin bool
+
+	// Team and IsAdmin identify who the request was authenticated as,
+	// according to the token it was sent with. Team is empty if unknown.
+	Team    string
+	IsAdmin bool
+
+	Reason string
+}
+
+func NewErrForbidden(request *http.Request, reason string) ErrForbidden {
+	e := ErrForbidden{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		Reason: reason,
+	}
+
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	if len(segments) >= 3 && segments[0] == "api" && segments[1] == "v1" && segments[2] == "teams" {
+		if len(segments) > 4 {
+			e.RequiredTeam = segments[3]
+		} else {
+			e.RequiresAdmin = true
+		}
+	}
+
+	e.Team, e.IsAdmin = tokenIdentity(request.Header.Get("Authorization"))
+
+	return e
+}
+
+func (e ErrForbidden) Error() string {
+	var message string
+
+	switch {
+	case e.RequiresAdmin:
+		message = fmt.Sprintf("you are not authorized to %s %s: it requires being logged in as an admin", e.Method, e.Path)
+	case e.RequiredTeam != "":
+		message = fmt.Sprintf("you are not authorized to %s %s: it requires being logged in to team '%s'", e.Method, e.Path, e.RequiredTeam)
+	default:
+		message = fmt.Sprintf("you are not authorized to %s %s", e.Method, e.Path)
+	}
+
+	if e.Team != "" {
+		identity := fmt.Sprintf("team '%s'", e.Team)
+		if e.IsAdmin {
+			identity += " (as an admin)"
+		}
+
+		message += fmt.Sprintf(", but you are logged in to %s", identity)
+	}
+
+	if e.Reason != "" {
+		message += fmt.Sprintf("\n\nthe server said: %s", e.Reason)
+	}
+
+	return message
+}
+
+// ExplainForbidden is middleware that fails requests refused with 403
+// Forbidden with an ErrForbidden, rather than the generic error the client
+// would return for them. See AsForbidden.
+func ExplainForbidden(base http.RoundTripper) http.RoundTripper {
+	return forbiddenExplainer{base: base}
+}
+
+// AsForbidden returns the ErrForbidden err is from, if any.
+func AsForbidden(err error) (ErrForbidden, bool) {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	forbidden, ok := err.(ErrForbidden)
+	return forbidden, ok
+}
+
+type forbiddenExplainer struct {
+	base http.RoundTripper
+}
+
+func (explainer forbiddenExplainer) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := explainer.base.RoundTrip(request)
+	if err != nil || response.StatusCode != http.StatusForbidden {
+		return response, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxForbiddenReasonLength+1))
+	if err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(string(body))
+	if len(reason) > maxForbiddenReasonLength {
+		reason = reason[:maxForbiddenReasonLength] + "..."
+	}
+
+	return nil, NewErrForbidden(request, reason)
+}
+
+// tokenIdentity reads the team and admin claims from a bearer token.
+func tokenIdentity(authorization string) (string, bool) {
+	parts := strings.SplitN(authorization, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return "", false
+	}
+
+	token := &TargetToken{Type: parts[0], Value: parts[1]}
+
+	return token.TeamName(), token.IsAdmin()
+}
@@ -0,0 +1,96 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/concourse/fly/rc"
+	"github.com/dgrijalva/jwt-go"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type respondingTransport struct {
+	status int
+	body   string
+}
+
+func (t respondingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: t.status,
+		Body:       ioutil.NopCloser(strings.NewReader(t.body)),
+		Request:    r,
+	}, nil
+}
+
+var _ = Describe("ExplainForbidden", func() {
+	var request *http.Request
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("GET", "http://example.com/api/v1/teams/other/pipelines", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"teamName": "main",
+			"isAdmin":  false,
+		}).SignedString([]byte("some-key"))
+		Expect(err).NotTo(HaveOccurred())
+
+		request.Header.Set("Authorization", "Bearer "+token)
+	})
+
+	Context("when the request is forbidden", func() {
+		It("fails with the required team and the authenticated identity", func() {
+			_, err := rc.ExplainForbidden(respondingTransport{status: 403, body: "not a member\n"}).RoundTrip(request)
+			Expect(err).To(Equal(rc.ErrForbidden{
+				Method:       "GET",
+				Path:         "/api/v1/teams/other/pipelines",
+				RequiredTeam: "other",
+				Team:         "main",
+				Reason:       "not a member",
+			}))
+
+			Expect(err.Error()).To(Equal(
+				"you are not authorized to GET /api/v1/teams/other/pipelines: it requires being logged in to team 'other', but you are logged in to team 'main'\n\nthe server said: not a member",
+			))
+		})
+
+		It("can be told apart once returned by the client", func() {
+			_, err := (&http.Client{Transport: rc.ExplainForbidden(respondingTransport{status: 403})}).Do(request)
+
+			forbidden, ok := rc.AsForbidden(err)
+			Expect(ok).To(BeTrue())
+			Expect(forbidden.RequiredTeam).To(Equal("other"))
+		})
+	})
+
+	Context("when configuring a team", func() {
+		BeforeEach(func() {
+			request.Method = "PUT"
+			request.URL.Path = "/api/v1/teams/other"
+		})
+
+		It("fails saying that an admin is required", func() {
+			_, err := rc.ExplainForbidden(respondingTransport{status: 403}).RoundTrip(request)
+
+			forbidden, ok := rc.AsForbidden(err)
+			Expect(ok).To(BeTrue())
+			Expect(forbidden.RequiresAdmin).To(BeTrue())
+			Expect(forbidden.Error()).To(ContainSubstring("it requires being logged in as an admin, but you are logged in to team 'main'"))
+		})
+	})
+
+	Context("when the request is allowed", func() {
+		It("passes the response through", func() {
+			response, err := rc.ExplainForbidden(respondingTransport{status: 200, body: "ok"}).RoundTrip(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			body, err := ioutil.ReadAll(response.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("ok"))
+		})
+	})
+})
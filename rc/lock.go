@@ -0,0 +1,91 @@
+package rc
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func flyrcLockPath() string {
+	return filepath.Join(userHomeDir(), ".flyrc.lock")
+}
+
+// updateTargets loads the targets, lets update change them, and saves them,
+// holding a lock on .flyrc throughout so that concurrent fly invocations,
+// e.g. in parallel CI steps, don't undo each other's changes.
+func updateTargets(update func(*targetDetailsYAML) error) error {
+	unlock, err := lockFile(flyrcLockPath())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	flyTargets, migrated, err := loadTargets()
+	if err != nil {
+		return err
+	}
+
+	if flyTargets.Version > FlyrcVersion {
+		return ErrFlyrcTooNew{Path: flyrcPath(), Version: flyTargets.Version}
+	}
+
+	if migrated {
+		original, err := ioutil.ReadFile(flyrcPath())
+		if err != nil {
+			return err
+		}
+
+		err = backUpFlyrc(flyrcPath(), original)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = update(flyTargets)
+	if err != nil {
+		return err
+	}
+
+	return writeTargets(flyrcPath(), flyTargets)
+}
+
+// writeFileAtomically replaces the file at path with data by renaming a
+// file written beside it over it, so that readers see either the old file
+// or the new one and never half of either. A symlinked file, e.g. from a
+// dotfiles repository, has its target replaced.
+func writeFileAtomically(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// +build windows
+
+package rc
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 50 * time.Millisecond
+	lockTimeout       = 30 * time.Second
+
+	// a lock held longer than any save takes was left by a process that
+	// died holding it
+	staleLockAge = time.Minute
+)
+
+// lockFile takes an exclusive lock by creating the file at path, waiting
+// for any other process holding it to remove it.
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+
+			return func() { os.Remove(path) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for another fly to let go of %s", path)
+		}
+
+		time.Sleep(lockRetryInterval)
+	}
+}
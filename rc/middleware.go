@@ -0,0 +1,72 @@
+package rc
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Middleware wraps the transport used for requests to a target, e.g. to log
+// requests, record metrics, or inject headers.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+var (
+	middlewareLock sync.RWMutex
+	middleware     []Middleware
+)
+
+// Use registers middleware for every target loaded afterwards. Middleware
+// registered first sees requests first.
+//
+// Websocket handshakes (e.g. for hijacking) are passed through the same
+// middleware, but never reach the network through it; see
+// PrepareWebsocketRequest.
+func Use(m ...Middleware) {
+	middlewareLock.Lock()
+	middleware = append(middleware, m...)
+	middlewareLock.Unlock()
+}
+
+// PrepareWebsocketRequest runs a websocket handshake request through the
+// registered middleware and returns the request as it would have been sent,
+// so that it can be dialed with its final URL and headers.
+func PrepareWebsocketRequest(r *http.Request) (*http.Request, error) {
+	capture := &capturingTransport{}
+
+	response, err := withMiddleware(capture).RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body.Close()
+
+	return capture.request, nil
+}
+
+func withMiddleware(base http.RoundTripper) http.RoundTripper {
+	middlewareLock.RLock()
+	defer middlewareLock.RUnlock()
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		base = middleware[i](base)
+	}
+
+	return base
+}
+
+type capturingTransport struct {
+	request *http.Request
+}
+
+func (t *capturingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.request = r
+
+	return &http.Response{
+		Status:     "101 Switching Protocols",
+		StatusCode: http.StatusSwitchingProtocols,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+		Request:    r,
+	}, nil
+}
@@ -0,0 +1,44 @@
+package rc_test
+
+import (
+	"net/http"
+
+	"github.com/concourse/fly/rc"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type headerInjector struct {
+	base http.RoundTripper
+}
+
+func (t headerInjector) RoundTrip(r *http.Request) (*http.Response, error) {
+	r.Header.Set("X-Injected", "yes")
+	return t.base.RoundTrip(r)
+}
+
+var _ = Describe("Middleware", func() {
+	BeforeEach(func() {
+		rc.Use(func(base http.RoundTripper) http.RoundTripper {
+			return headerInjector{base: base}
+		})
+	})
+
+	AfterEach(func() {
+		rc.ResetMiddleware()
+	})
+
+	Describe("PrepareWebsocketRequest", func() {
+		It("applies the registered middleware without sending the request", func() {
+			request, err := http.NewRequest("GET", "http://127.0.0.1:1/api/v1/containers/some-handle/hijack", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			prepared, err := rc.PrepareWebsocketRequest(request)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(prepared.URL.String()).To(Equal("http://127.0.0.1:1/api/v1/containers/some-handle/hijack"))
+			Expect(prepared.Header.Get("X-Injected")).To(Equal("yes"))
+		})
+	})
+})
@@ -0,0 +1,117 @@
+package rc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/concourse/atc"
+	"gopkg.in/yaml.v2"
+)
+
+// FlyrcVersion is the version of the .flyrc format this fly reads and
+// writes. A .flyrc without a version is version 0.
+//
+// Bump it with a migration whenever a change to the format would be
+// misread by an older fly, or an older .flyrc misread by this one.
+const FlyrcVersion = 1
+
+// flyrcMigrations[i] upgrades a parsed .flyrc from version i to i+1.
+var flyrcMigrations = []func(flyrc map[interface{}]interface{}) error{
+	// 0 to 1: each target's team is saved rather than defaulted, and tokens
+	// saved in .flyrc itself are moved to .flyrc-tokens by the save
+	// following the migration
+	func(flyrc map[interface{}]interface{}) error {
+		targets, _ := flyrc["targets"].(map[interface{}]interface{})
+		for name, props := range targets {
+			target, ok := props.(map[interface{}]interface{})
+			if !ok {
+				target = map[interface{}]interface{}{}
+			}
+
+			if team, _ := target["team"].(string); team == "" {
+				target["team"] = atc.DefaultTeamName
+			}
+
+			targets[name] = target
+		}
+
+		return nil
+	},
+}
+
+// ErrFlyrcTooNew is returned for changes to a .flyrc written by a newer fly,
+// which would lose whatever this fly doesn't know about.
+type ErrFlyrcTooNew struct {
+	Path    string
+	Version int
+}
+
+func (err ErrFlyrcTooNew) Error() string {
+	return fmt.Sprintf("refusing to change %s: it's version %d, and this fly only understands up to version %d; upgrade fly with fly sync", err.Path, err.Version, FlyrcVersion)
+}
+
+// migrateFlyrc upgrades the contents of a .flyrc to FlyrcVersion, returning
+// them unchanged if they're already up to date or newer, and whether it
+// changed them.
+func migrateFlyrc(contents []byte) ([]byte, bool, error) {
+	flyrc := map[interface{}]interface{}{}
+	err := yaml.Unmarshal(contents, &flyrc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	version, _ := flyrc["version"].(int)
+	if version >= FlyrcVersion {
+		return contents, false, nil
+	}
+
+	for ; version < FlyrcVersion; version++ {
+		err := flyrcMigrations[version](flyrc)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to migrate .flyrc from version %d: %s", version, err)
+		}
+	}
+
+	flyrc["version"] = FlyrcVersion
+
+	migrated, err := yaml.Marshal(flyrc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return migrated, true, nil
+}
+
+// backUpFlyrc keeps a copy of the contents of an older .flyrc beside it
+// before it's migrated, unless there already is one. Its tokens are left out,
+// as they're moved to .flyrc-tokens, and only the user can read it.
+func backUpFlyrc(path string, contents []byte) error {
+	flyrc := map[interface{}]interface{}{}
+	err := yaml.Unmarshal(contents, &flyrc)
+	if err != nil {
+		return err
+	}
+
+	version, _ := flyrc["version"].(int)
+	backup := fmt.Sprintf("%s.v%d.backup", path, version)
+
+	_, err = os.Stat(backup)
+	if err == nil {
+		return nil
+	}
+
+	targets, _ := flyrc["targets"].(map[interface{}]interface{})
+	for _, props := range targets {
+		if target, ok := props.(map[interface{}]interface{}); ok {
+			delete(target, "token")
+		}
+	}
+
+	withoutTokens, err := yaml.Marshal(flyrc)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(backup, withoutTokens, 0600)
+}
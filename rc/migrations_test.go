@@ -0,0 +1,124 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/concourse/fly/rc"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(".flyrc migrations", func() {
+	var tmpDir string
+	var flyrc string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("HOME", tmpDir)
+
+		flyrc = filepath.Join(tmpDir, ".flyrc")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("when the .flyrc has no version", func() {
+		const original = `targets:
+  some-target:
+    api: http://concourse.com
+    token:
+      type: Bearer
+      value: some-token
+`
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(flyrc, []byte(original), 0644)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("loads it as it was", func() {
+			targets, err := rc.LoadTargets()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(targets.Targets["some-target"].API).To(Equal("http://concourse.com"))
+			Expect(targets.Targets["some-target"].TeamName).To(Equal("main"))
+			Expect(targets.Targets["some-target"].Token).To(Equal(&rc.TargetToken{Type: "Bearer", Value: "some-token"}))
+		})
+
+		It("upgrades it in place, keeping a backup without its tokens", func() {
+			_, err := rc.LoadTargets()
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(flyrc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("version: 1"))
+			Expect(string(contents)).To(ContainSubstring("team: main"))
+			Expect(string(contents)).ToNot(ContainSubstring("some-token"))
+
+			backup, err := ioutil.ReadFile(flyrc + ".v0.backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(backup)).To(ContainSubstring("api: http://concourse.com"))
+			Expect(string(backup)).ToNot(ContainSubstring("some-token"))
+
+			info, err := os.Stat(flyrc + ".v0.backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			targets, err := rc.LoadTargets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets.Targets["some-target"].Token).To(Equal(&rc.TargetToken{Type: "Bearer", Value: "some-token"}))
+		})
+	})
+
+	Context("when the .flyrc is the current version", func() {
+		BeforeEach(func() {
+			err := rc.SaveTarget("some-target", "http://concourse.com", false, "main", nil, "")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("saves it without a backup", func() {
+			err := rc.SaveTarget("other-target", "http://concourse.com", false, "main", nil, "")
+			Expect(err).ToNot(HaveOccurred())
+
+			backups, err := filepath.Glob(flyrc + ".*.backup")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(backups).To(BeEmpty())
+		})
+	})
+
+	Context("when the .flyrc was written by a newer fly", func() {
+		const newer = `version: 99
+targets:
+  some-target:
+    api: http://concourse.com
+    team: main
+    some-new-field: some-value
+`
+
+		BeforeEach(func() {
+			err := ioutil.WriteFile(flyrc, []byte(newer), 0644)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("still loads the targets", func() {
+			targets, err := rc.LoadTargets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets.Targets["some-target"].API).To(Equal("http://concourse.com"))
+		})
+
+		It("refuses to save over it, so that nothing is lost", func() {
+			err := rc.SaveTarget("other-target", "http://concourse.com", false, "main", nil, "")
+			Expect(err).To(Equal(rc.ErrFlyrcTooNew{Path: flyrc, Version: 99}))
+
+			contents, err := ioutil.ReadFile(flyrc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(newer))
+		})
+	})
+})
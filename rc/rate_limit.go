@@ -0,0 +1,114 @@
+package rc
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//go:generate counterfeiter . RateLimiter
+
+// RateLimiter blocks until the caller may send another request, or ctx is
+// done, returning ctx.Err().
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+type tokenBucket struct {
+	lock sync.Mutex
+
+	rate  float64
+	burst float64
+
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket returns a RateLimiter allowing rate requests per second on
+// average, with bursts of up to burst requests.
+func NewTokenBucket(rate float64, burst int) RateLimiter {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (bucket *tokenBucket) Wait(ctx context.Context) error {
+	bucket.lock.Lock()
+
+	now := time.Now()
+	bucket.tokens = math.Min(bucket.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate)
+	bucket.last = now
+
+	// take the token now, even if it's not there yet, so that concurrent
+	// callers queue up behind each other rather than all waking at once
+	bucket.tokens--
+
+	var wait time.Duration
+	if bucket.tokens < 0 {
+		wait = time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+	}
+
+	bucket.lock.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give the token back, so a cancelled request doesn't hold up those
+		// queued behind it
+		bucket.lock.Lock()
+		bucket.tokens++
+		bucket.lock.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+type rateLimitedTransport struct {
+	limiter RateLimiter
+
+	base http.RoundTripper
+}
+
+func NewRateLimitedTransport(base http.RoundTripper, limiter RateLimiter) http.RoundTripper {
+	return rateLimitedTransport{
+		limiter: limiter,
+		base:    base,
+	}
+}
+
+func (t rateLimitedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	err := t.limiter.Wait(r.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	return t.base.RoundTrip(r)
+}
+
+func rateLimited(client *http.Client, requestsPerSecond float64) *http.Client {
+	if requestsPerSecond <= 0 {
+		return client
+	}
+
+	burst := int(math.Ceil(requestsPerSecond))
+
+	client.Transport = NewRateLimitedTransport(
+		client.Transport,
+		NewTokenBucket(requestsPerSecond, burst),
+	)
+
+	return client
+}
@@ -0,0 +1,82 @@
+package rc_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/rc/rcfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Rate limiting", func() {
+	Describe("NewTokenBucket", func() {
+		It("allows a burst, then spaces out requests", func() {
+			bucket := rc.NewTokenBucket(20, 2)
+
+			start := time.Now()
+			Expect(bucket.Wait(context.Background())).To(Succeed())
+			Expect(bucket.Wait(context.Background())).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically("<", 25*time.Millisecond))
+
+			Expect(bucket.Wait(context.Background())).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", 40*time.Millisecond))
+		})
+
+		It("stops waiting once the context is done", func() {
+			bucket := rc.NewTokenBucket(1, 1)
+			Expect(bucket.Wait(context.Background())).To(Succeed())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			Expect(bucket.Wait(ctx)).To(Equal(context.DeadlineExceeded))
+			Expect(time.Since(start)).To(BeNumerically("<", 500*time.Millisecond))
+		})
+	})
+
+	Describe("NewRateLimitedTransport", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.AppendHandlers(ghttp.RespondWith(http.StatusOK, ""))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("waits on the limiter before every request", func() {
+			limiter := new(rcfakes.FakeRateLimiter)
+
+			client := &http.Client{
+				Transport: rc.NewRateLimitedTransport(http.DefaultTransport, limiter),
+			}
+
+			response, err := client.Get(server.URL())
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			Expect(limiter.WaitCallCount()).To(Equal(1))
+		})
+
+		It("doesn't send the request if the limiter gives up waiting", func() {
+			limiter := new(rcfakes.FakeRateLimiter)
+			limiter.WaitReturns(context.Canceled)
+
+			client := &http.Client{
+				Transport: rc.NewRateLimitedTransport(http.DefaultTransport, limiter),
+			}
+
+			_, err := client.Get(server.URL())
+			Expect(err).To(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,180 @@
+package rc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/concourse/fly/ui"
+)
+
+const DefaultAPIRetries = 3
+
+// APIRetries is how many times a request to a target's API that failed
+// transiently, e.g. with a 502 from a load balancer in front of the ATC, is
+// retried before giving up.
+var APIRetries = DefaultAPIRetries
+
+// APIRetryInterval is how long to wait before retrying a request. It doubles
+// with every retry, up to MaxAPIRetryInterval.
+var APIRetryInterval = time.Second
+
+var MaxAPIRetryInterval = 30 * time.Second
+
+// how much of the body of a response that's given up on goes in the error
+const maxErrorBodySize = 1024
+
+// APIError is returned once a request has failed transiently every time it
+// was tried, or at once if it may have changed something and so can't be.
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Body       string
+	Attempts   int
+}
+
+func (e APIError) Error() string {
+	message := fmt.Sprintf("%s %s failed with %s", e.Method, e.Path, e.Status)
+	if e.Attempts > 1 {
+		message += fmt.Sprintf(" after %d attempts", e.Attempts)
+	}
+
+	if e.Body != "" {
+		message += ": " + e.Body
+	}
+
+	return message
+}
+
+func retrying(client *http.Client) *http.Client {
+	client.Transport = retryingTransport{
+		base: client.Transport,
+	}
+
+	return client
+}
+
+type retryingTransport struct {
+	base http.RoundTripper
+}
+
+func (t retryingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body := r.Body
+	for attempt := 1; ; attempt++ {
+		request := new(http.Request)
+		*request = *r
+		request.Body = body
+
+		response, err := t.base.RoundTrip(request)
+
+		// a body that's been read from can't be sent again, e.g. an input
+		// being streamed to its pipe
+		if attempt > APIRetries || !retryable(r, response, err) || (r.Body != nil && r.GetBody == nil) {
+			if err == nil && transientStatus(response.StatusCode) && (attempt > 1 || !idempotent(r)) {
+				return nil, giveUp(r, response, attempt)
+			}
+
+			return response, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = response.Status
+			response.Body.Close()
+		}
+
+		wait := apiRetryBackoff(attempt)
+		fmt.Fprintf(ui.Stderr, "%s %s: %s; retrying in %s (attempt %d of %d)\n", r.Method, r.URL.Path, reason, wait, attempt, APIRetries+1)
+
+		select {
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		}
+
+		if r.Body != nil {
+			body, err = r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// retryable says whether a failed request is worth sending again. Requests
+// that may change something, e.g. creating a build, are only retried when
+// they can't have reached the ATC: when the connection was refused, or the
+// ATC said it's unavailable. A 502 or 504 from a load balancer may come after
+// the ATC got the request, e.g. creating a build, so those aren't retried.
+func retryable(r *http.Request, response *http.Response, err error) bool {
+	if err != nil {
+		if _, ok := err.(ErrForbidden); ok || r.Context().Err() != nil {
+			return false
+		}
+
+		return idempotent(r) || connectFailed(err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent(r)
+	default:
+		return false
+	}
+}
+
+func idempotent(r *http.Request) bool {
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	default:
+		return false
+	}
+}
+
+func transientStatus(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
+
+func giveUp(r *http.Request, response *http.Response, attempts int) error {
+	defer response.Body.Close()
+
+	body, _ := ioutil.ReadAll(response.Body)
+
+	message := strings.TrimSpace(string(body))
+	if len(message) > maxErrorBodySize {
+		message = message[:maxErrorBodySize] + "..."
+	}
+
+	return APIError{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Body:       message,
+		Attempts:   attempts,
+	}
+}
+
+func apiRetryBackoff(attempt int) time.Duration {
+	wait := APIRetryInterval
+	for i := 1; i < attempt && wait < MaxAPIRetryInterval; i++ {
+		wait *= 2
+	}
+
+	if wait > MaxAPIRetryInterval {
+		wait = MaxAPIRetryInterval
+	}
+
+	return wait
+}
@@ -0,0 +1,173 @@
+package rc_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/concourse/atc"
+	"github.com/concourse/fly/rc"
+	"github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/ghttp"
+)
+
+var _ = Describe("Retrying API requests", func() {
+	var (
+		tmpDir string
+		server *ghttp.Server
+		stderr *gbytes.Buffer
+
+		target rc.Target
+	)
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "fly-test")
+		Expect(err).ToNot(HaveOccurred())
+
+		os.Setenv("HOME", tmpDir)
+
+		server = ghttp.NewServer()
+
+		flyrcContents := `targets:
+  some-target:
+    api: ` + server.URL() + `
+    team: main`
+		err = ioutil.WriteFile(filepath.Join(tmpDir, ".flyrc"), []byte(flyrcContents), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		rc.APIRetryInterval = 0
+
+		stderr = gbytes.NewBuffer()
+		ui.Stderr = stderr
+
+		target, err = rc.LoadTarget("some-target", false)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		rc.APIRetries = rc.DefaultAPIRetries
+		rc.APIRetryInterval = time.Second
+		ui.Stderr = os.Stderr
+
+		server.Close()
+		os.RemoveAll(tmpDir)
+	})
+
+	Context("when a request fails transiently", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, "upstream down"),
+				ghttp.RespondWith(http.StatusServiceUnavailable, "upstream down"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, atc.Info{Version: "1.2.3"}),
+			)
+		})
+
+		It("retries it", func() {
+			info, err := target.Client().GetInfo()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Version).To(Equal("1.2.3"))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(3))
+			Expect(stderr).To(gbytes.Say(`GET /api/v1/info: 502 Bad Gateway; retrying in 0s \(attempt 1 of 4\)`))
+			Expect(stderr).To(gbytes.Say(`GET /api/v1/info: 503 Service Unavailable; retrying in 0s \(attempt 2 of 4\)`))
+		})
+
+		Context("when retrying is turned off", func() {
+			BeforeEach(func() {
+				rc.APIRetries = 0
+			})
+
+			It("doesn't retry it", func() {
+				_, err := target.Client().GetInfo()
+				Expect(err).To(HaveOccurred())
+				Expect(server.ReceivedRequests()).To(HaveLen(1))
+			})
+		})
+	})
+
+	Context("when creating a build fails transiently", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/builds"),
+					ghttp.VerifyJSONRepresenting(atc.Plan{}),
+					ghttp.RespondWith(http.StatusServiceUnavailable, "starting up"),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v1/builds"),
+					ghttp.VerifyJSONRepresenting(atc.Plan{}),
+					ghttp.RespondWith(http.StatusCreated, `{"id":128}`),
+				),
+			)
+		})
+
+		It("sends it again", func() {
+			build, err := target.Client().CreateBuild(atc.Plan{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(build.ID).To(Equal(128))
+		})
+	})
+
+	Context("when the request times out at the load balancer", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusGatewayTimeout, "too slow"),
+			)
+		})
+
+		It("doesn't send a request that may have gone through again", func() {
+			_, err := target.Client().CreateBuild(atc.Plan{})
+			Expect(err).To(HaveOccurred())
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when the load balancer fails to pass creating a build on", func() {
+		BeforeEach(func() {
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusBadGateway, "upstream reset"),
+			)
+		})
+
+		It("doesn't send a request that may have gone through again, saying what failed and how", func() {
+			_, err := target.Client().CreateBuild(atc.Plan{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("POST /api/v1/builds failed with 502 Bad Gateway: upstream reset"))
+			Expect(server.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
+	Context("when a request keeps failing", func() {
+		BeforeEach(func() {
+			server.RouteToHandler("POST", "/api/v1/builds", ghttp.RespondWith(http.StatusServiceUnavailable, "upstream down"))
+		})
+
+		It("gives up, saying what failed and how", func() {
+			_, err := target.Client().CreateBuild(atc.Plan{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("POST /api/v1/builds failed with 503 Service Unavailable after 4 attempts: upstream down"))
+
+			Expect(server.ReceivedRequests()).To(HaveLen(4))
+		})
+	})
+
+	Describe("the backoff", func() {
+		BeforeEach(func() {
+			rc.APIRetryInterval = time.Second
+		})
+
+		It("doubles with every retry, up to a limit", func() {
+			Expect(rc.APIRetryBackoff(1)).To(Equal(time.Second))
+			Expect(rc.APIRetryBackoff(2)).To(Equal(2 * time.Second))
+			Expect(rc.APIRetryBackoff(3)).To(Equal(4 * time.Second))
+			Expect(rc.APIRetryBackoff(10)).To(Equal(rc.MaxAPIRetryInterval))
+		})
+	})
+})
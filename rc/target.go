@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/concourse/atc"
@@ -15,6 +16,7 @@ import (
 	"github.com/concourse/fly/version"
 	"github.com/concourse/go-concourse/concourse"
 	semisemanticversion "github.com/cppforlife/go-semi-semantic/version"
+	"golang.org/x/net/http2"
 	"golang.org/x/oauth2"
 )
 
@@ -36,6 +38,8 @@ func (e ErrVersionMismatch) Error() string {
 	return fmt.Sprintf("fly version (%s) is out of sync with the target (%s). to sync up, run the following:\n\n    fly -t %s sync\n", ui.Embolden(e.flyVersion), ui.Embolden(e.atcVersion), e.targetName)
 }
 
+//go:generate counterfeiter . Target
+
 type Target interface {
 	Client() concourse.Client
 	Team() concourse.Team
@@ -59,6 +63,9 @@ type target struct {
 	url       string
 	token     *TargetToken
 	info      atc.Info
+
+	// endpoints, if the target has fallback APIs, is where it's reached
+	endpoints *endpoints
 }
 
 func newTarget(
@@ -69,12 +76,10 @@ func newTarget(
 	caCert string,
 	caCertPool *x509.CertPool,
 	insecure bool,
+	tlsPolicy TLSPolicy,
 	client concourse.Client,
 ) *target {
-	tlsConfig := &tls.Config{
-		InsecureSkipVerify: insecure,
-		RootCAs:            caCertPool,
-	}
+	tlsConfig := newTLSConfig(insecure, caCertPool, tlsPolicy)
 
 	return &target{
 		name:      name,
@@ -98,10 +103,24 @@ func LoadTarget(selectedTarget TargetName, tracing bool) (Target, error) {
 		return nil, err
 	}
 
-	httpClient := defaultHttpClient(targetProps.Token, targetProps.Insecure, caCertPool)
+	err = targetProps.TLS.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := defaultHttpClient(targetProps.Token, targetProps.Insecure, caCertPool, targetProps.TLS)
+	httpClient = rateLimited(httpClient, targetProps.RateLimit)
+
+	httpClient, endpoints, err := withFallbacks(httpClient, targetProps.API, targetProps.FallbackAPIs)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient = retrying(httpClient)
+
 	client := concourse.NewClient(targetProps.API, httpClient, tracing)
 
-	return newTarget(
+	t := newTarget(
 		selectedTarget,
 		targetProps.TeamName,
 		targetProps.API,
@@ -109,8 +128,12 @@ func LoadTarget(selectedTarget TargetName, tracing bool) (Target, error) {
 		targetProps.CACert,
 		caCertPool,
 		targetProps.Insecure,
+		targetProps.TLS,
 		client,
-	), nil
+	)
+	t.endpoints = endpoints
+
+	return t, nil
 }
 
 func LoadTargetWithInsecure(
@@ -142,9 +165,22 @@ func LoadTargetWithInsecure(
 		return nil, err
 	}
 
-	httpClient := defaultHttpClient(targetProps.Token, commandInsecure, caCertPool)
+	err = targetProps.TLS.Validate()
+	if err != nil {
+		return nil, err
+	}
 
-	return newTarget(
+	httpClient := defaultHttpClient(targetProps.Token, commandInsecure, caCertPool, targetProps.TLS)
+	httpClient = rateLimited(httpClient, targetProps.RateLimit)
+
+	httpClient, endpoints, err := withFallbacks(httpClient, targetProps.API, targetProps.FallbackAPIs)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient = retrying(httpClient)
+
+	t := newTarget(
 		selectedTarget,
 		teamName,
 		targetProps.API,
@@ -152,8 +188,12 @@ func LoadTargetWithInsecure(
 		caCert,
 		caCertPool,
 		targetProps.Insecure,
+		targetProps.TLS,
 		concourse.NewClient(targetProps.API, httpClient, tracing),
-	), nil
+	)
+	t.endpoints = endpoints
+
+	return t, nil
 }
 
 func NewUnauthenticatedTarget(
@@ -162,6 +202,7 @@ func NewUnauthenticatedTarget(
 	teamName string,
 	insecure bool,
 	caCert string,
+	tlsPolicy TLSPolicy,
 	tracing bool,
 ) (Target, error) {
 	caCertPool, err := loadCACertPool(caCert)
@@ -169,7 +210,12 @@ func NewUnauthenticatedTarget(
 		return nil, err
 	}
 
-	httpClient := unauthenticatedHttpClient(insecure, caCertPool)
+	err = tlsPolicy.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := unauthenticatedHttpClient(insecure, caCertPool, tlsPolicy)
 	client := concourse.NewClient(url, httpClient, tracing)
 	return newTarget(
 		name,
@@ -179,6 +225,7 @@ func NewUnauthenticatedTarget(
 		caCert,
 		caCertPool,
 		insecure,
+		tlsPolicy,
 		client,
 	), nil
 }
@@ -191,13 +238,19 @@ func NewBasicAuthTarget(
 	username string,
 	password string,
 	caCert string,
+	tlsPolicy TLSPolicy,
 	tracing bool,
 ) (Target, error) {
 	caCertPool, err := loadCACertPool(caCert)
 	if err != nil {
 		return nil, err
 	}
-	httpClient := basicAuthHttpClient(username, password, insecure, caCertPool)
+
+	err = tlsPolicy.Validate()
+	if err != nil {
+		return nil, err
+	}
+	httpClient := basicAuthHttpClient(username, password, insecure, caCertPool, tlsPolicy)
 	client := concourse.NewClient(url, httpClient, tracing)
 
 	return newTarget(
@@ -208,6 +261,7 @@ func NewBasicAuthTarget(
 		caCert,
 		caCertPool,
 		insecure,
+		tlsPolicy,
 		client,
 	), nil
 }
@@ -218,6 +272,7 @@ func NewNoAuthTarget(
 	teamName string,
 	insecure bool,
 	caCert string,
+	tlsPolicy TLSPolicy,
 	tracing bool,
 ) (Target, error) {
 	caCertPool, err := loadCACertPool(caCert)
@@ -225,7 +280,12 @@ func NewNoAuthTarget(
 		return nil, err
 	}
 
-	httpClient := &http.Client{Transport: transport(insecure, caCertPool)}
+	err = tlsPolicy.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Transport: transport(insecure, caCertPool, tlsPolicy)}
 	client := concourse.NewClient(url, httpClient, tracing)
 
 	return newTarget(
@@ -236,6 +296,7 @@ func NewNoAuthTarget(
 		caCert,
 		caCertPool,
 		insecure,
+		tlsPolicy,
 		client,
 	), nil
 }
@@ -257,6 +318,10 @@ func (t *target) TLSConfig() *tls.Config {
 }
 
 func (t *target) URL() string {
+	if t.endpoints != nil {
+		return t.endpoints.URL()
+	}
+
 	return t.url
 }
 
@@ -366,13 +431,13 @@ func (t *target) getInfo() (atc.Info, error) {
 	return t.info, err
 }
 
-func unauthenticatedHttpClient(insecure bool, caCertPool *x509.CertPool) *http.Client {
+func unauthenticatedHttpClient(insecure bool, caCertPool *x509.CertPool, tlsPolicy TLSPolicy) *http.Client {
 	return &http.Client{
-		Transport: transport(insecure, caCertPool),
+		Transport: transport(insecure, caCertPool, tlsPolicy),
 	}
 }
 
-func defaultHttpClient(token *TargetToken, insecure bool, caCertPool *x509.CertPool) *http.Client {
+func defaultHttpClient(token *TargetToken, insecure bool, caCertPool *x509.CertPool, tlsPolicy TLSPolicy) *http.Client {
 	var oAuthToken *oauth2.Token
 	if token != nil {
 		oAuthToken = &oauth2.Token{
@@ -381,7 +446,7 @@ func defaultHttpClient(token *TargetToken, insecure bool, caCertPool *x509.CertP
 		}
 	}
 
-	transport := transport(insecure, caCertPool)
+	transport := transport(insecure, caCertPool, tlsPolicy)
 
 	if token != nil {
 		transport = &oauth2.Transport{
@@ -393,11 +458,23 @@ func defaultHttpClient(token *TargetToken, insecure bool, caCertPool *x509.CertP
 	return &http.Client{Transport: transport}
 }
 
+var (
+	caCertPoolsLock sync.Mutex
+	caCertPools     = map[string]*x509.CertPool{}
+)
+
 func loadCACertPool(caCert string) (cert *x509.CertPool, err error) {
 	if caCert == "" {
 		return nil, nil
 	}
 
+	caCertPoolsLock.Lock()
+	defer caCertPoolsLock.Unlock()
+
+	if pool, found := caCertPools[caCert]; found {
+		return pool, nil
+	}
+
 	// TODO: remove else block once we switch to go 1.8
 	// x509.SystemCertPool is not supported in go 1.7 on Windows
 	// see: https://github.com/golang/go/issues/16736
@@ -416,6 +493,9 @@ func loadCACertPool(caCert string) (cert *x509.CertPool, err error) {
 	if !ok {
 		return nil, errors.New("CA Cert not valid")
 	}
+
+	caCertPools[caCert] = pool
+
 	return pool, nil
 }
 
@@ -424,30 +504,67 @@ func basicAuthHttpClient(
 	password string,
 	insecure bool,
 	caCertPool *x509.CertPool,
+	tlsPolicy TLSPolicy,
 ) *http.Client {
 	return &http.Client{
 		Transport: basicAuthTransport{
 			username: username,
 			password: password,
-			base:     transport(insecure, caCertPool),
+			base:     transport(insecure, caCertPool, tlsPolicy),
 		},
 	}
 }
 
-func transport(insecure bool, caCertPool *x509.CertPool) http.RoundTripper {
-	var transport http.RoundTripper
+type transportKey struct {
+	insecure   bool
+	caCertPool *x509.CertPool
+	tlsPolicy  string
+}
 
-	transport = &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: insecure,
-			RootCAs:            caCertPool,
-		},
+var (
+	transportsLock sync.Mutex
+	transports     = map[transportKey]*http.Transport{}
+)
+
+// transport returns a round tripper for the given TLS settings. The
+// underlying connection pool is shared by every client with the same
+// settings, so that e.g. uploads, polling, and build requests reuse
+// keep-alive connections (over HTTP/2 where the ATC supports it) rather
+// than each dialing a fresh TLS connection.
+func transport(insecure bool, caCertPool *x509.CertPool, tlsPolicy TLSPolicy) http.RoundTripper {
+	return withMiddleware(sharedTransport(insecure, caCertPool, tlsPolicy))
+}
+
+func sharedTransport(insecure bool, caCertPool *x509.CertPool, tlsPolicy TLSPolicy) *http.Transport {
+	transportsLock.Lock()
+	defer transportsLock.Unlock()
+
+	key := transportKey{insecure: insecure, caCertPool: caCertPool, tlsPolicy: tlsPolicy.key()}
+	if transport, found := transports[key]; found {
+		return transport
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: newTLSConfig(insecure, caCertPool, tlsPolicy),
 		Dial: (&net.Dialer{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
 		}).Dial,
-		Proxy: http.ProxyFromEnvironment,
+		Proxy:               http.ProxyFromEnvironment,
+		TLSHandshakeTimeout: 10 * time.Second,
+		IdleConnTimeout:     90 * time.Second,
+		MaxIdleConnsPerHost: 10,
 	}
 
+	// a custom TLS config disables the standard library's automatic HTTP/2
+	// support, so it has to be enabled explicitly
+	err := http2.ConfigureTransport(transport)
+	if err != nil {
+		fmt.Fprintln(ui.Stderr, "failed to enable HTTP/2:", err)
+	}
+
+	transports[key] = transport
+
 	return transport
 }
 
@@ -103,8 +103,21 @@ AA9WjQKZ7aKQRUzkuxCkPfAyAw7xzvjoyVGM5mKf5p/AfbdynMk2OmufTqj/ZA1k
 				Expect((*base).TLSClientConfig).To(Equal(&tls.Config{
 					InsecureSkipVerify: true,
 					RootCAs:            nil,
+					NextProtos:         []string{"h2", "http/1.1"},
 				}))
 			})
+
+			It("shares the underlying transport between loaded targets", func() {
+				first, err := rc.LoadTarget("some-target", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				second, err := rc.LoadTarget("some-target", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				firstBase := first.Client().HTTPClient().Transport.(*oauth2.Transport).Base
+				secondBase := second.Client().HTTPClient().Transport.(*oauth2.Transport).Base
+				Expect(firstBase).To(BeIdenticalTo(secondBase))
+			})
 		})
 
 		Context("when there is ca-cert", func() {
@@ -153,8 +166,99 @@ AA9WjQKZ7aKQRUzkuxCkPfAyAw7xzvjoyVGM5mKf5p/AfbdynMk2OmufTqj/ZA1k
 				Expect((*base).TLSClientConfig).To(Equal(&tls.Config{
 					InsecureSkipVerify: false,
 					RootCAs:            expectedCaCertPool,
+					NextProtos:         []string{"h2", "http/1.1"},
 				}))
 			})
 		})
+
+		Context("when the environment gives the target's URL and team", func() {
+			BeforeEach(func() {
+				flyrcContents := `targets:
+  some-target:
+    api: https://concourse.com
+    team: some-team
+    token:
+      type: Bearer
+      value: some-token`
+				ioutil.WriteFile(flyrc, []byte(flyrcContents), 0777)
+
+				os.Setenv(rc.ATCURLEnv, "https://other.concourse.com")
+				os.Setenv(rc.TeamEnv, "other-team")
+			})
+
+			AfterEach(func() {
+				os.Unsetenv(rc.ATCURLEnv)
+				os.Unsetenv(rc.TeamEnv)
+			})
+
+			It("uses them rather than those in .flyrc, without the token saved for another URL", func() {
+				target, err := rc.LoadTarget("some-target", false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target.URL()).To(Equal("https://other.concourse.com"))
+				Expect(target.Team().Name()).To(Equal("other-team"))
+				Expect(target.Token()).To(BeNil())
+			})
+
+			It("keeps the token when the URL is the one it was saved with", func() {
+				os.Setenv(rc.ATCURLEnv, "https://concourse.com")
+
+				target, err := rc.LoadTarget("some-target", false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target.Token()).To(Equal(&rc.TargetToken{Type: "Bearer", Value: "some-token"}))
+			})
+
+			It("loads a target that isn't in .flyrc at all", func() {
+				target, err := rc.LoadTarget("unsaved-target", false)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(target.URL()).To(Equal("https://other.concourse.com"))
+				Expect(target.Team().Name()).To(Equal("other-team"))
+			})
+		})
+
+		Context("when the target has a TLS policy", func() {
+			BeforeEach(func() {
+				flyrcContents := `targets:
+  some-target:
+    api: https://concourse.com
+    tls:
+      min_version: "1.2"
+      cipher_suites:
+      - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+      - TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384`
+				ioutil.WriteFile(flyrc, []byte(flyrcContents), 0777)
+			})
+
+			It("applies it to the transport and the target's TLS config", func() {
+				target, err := rc.LoadTarget("some-target", false)
+				Expect(err).NotTo(HaveOccurred())
+
+				base, ok := target.Client().HTTPClient().Transport.(*http.Transport)
+				Expect(ok).To(BeTrue())
+
+				for _, config := range []*tls.Config{base.TLSClientConfig, target.TLSConfig()} {
+					Expect(config.MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+					Expect(config.CipherSuites).To(Equal([]uint16{
+						tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+						tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+					}))
+				}
+			})
+		})
+
+		Context("when the target's TLS policy is invalid", func() {
+			BeforeEach(func() {
+				flyrcContents := `targets:
+  some-target:
+    api: https://concourse.com
+    tls:
+      min_version: "0.9"`
+				ioutil.WriteFile(flyrc, []byte(flyrcContents), 0777)
+			})
+
+			It("returns an error", func() {
+				_, err := rc.LoadTarget("some-target", false)
+				Expect(err).To(MatchError("unknown TLS version '0.9' (expected 1.0, 1.1, or 1.2)"))
+			})
+		})
 	})
 })
@@ -15,6 +15,16 @@ import (
 
 var ErrNoTargetSpecified = errors.New("no target specified")
 
+// The environment can stand in for flags and .flyrc, e.g. in containers:
+// FLY_TARGET for --target, and FLY_TEAM and FLY_ATC_URL for the team and
+// URL of the target. Flags take precedence over the environment, which takes
+// precedence over .flyrc.
+const (
+	TargetEnv = "FLY_TARGET"
+	TeamEnv   = "FLY_TEAM"
+	ATCURLEnv = "FLY_ATC_URL"
+)
+
 type UnknownTargetError struct {
 	TargetName TargetName
 }
@@ -24,11 +34,18 @@ func (err UnknownTargetError) Error() string {
 }
 
 type TargetProps struct {
-	API      string       `yaml:"api"`
-	TeamName string       `yaml:"team"`
-	Insecure bool         `yaml:"insecure,omitempty"`
-	Token    *TargetToken `yaml:"token,omitempty"`
-	CACert   string       `yaml:"ca_cert,omitempty"`
+	API       string       `yaml:"api"`
+	TeamName  string       `yaml:"team"`
+	Insecure  bool         `yaml:"insecure,omitempty"`
+	Token     *TargetToken `yaml:"token,omitempty"`
+	CACert    string       `yaml:"ca_cert,omitempty"`
+	RateLimit float64      `yaml:"rate_limit,omitempty"`
+	TLS       TLSPolicy    `yaml:"tls,omitempty"`
+	Pipeline  string       `yaml:"pipeline,omitempty"`
+	Job       string       `yaml:"job,omitempty"`
+
+	// FallbackAPIs are tried in order when the API can't be connected to.
+	FallbackAPIs []string `yaml:"fallback_apis,omitempty"`
 }
 
 type TargetToken struct {
@@ -37,6 +54,7 @@ type TargetToken struct {
 }
 
 type targetDetailsYAML struct {
+	Version int `yaml:"version,omitempty"`
 	Targets map[TargetName]TargetProps
 }
 
@@ -45,16 +63,10 @@ func flyrcPath() string {
 }
 
 func DeleteTarget(targetName TargetName) error {
-	flyTargets, err := LoadTargets()
-	if err != nil {
-		return err
-	}
-
-	if _, ok := flyTargets.Targets[targetName]; ok {
+	return updateTargets(func(flyTargets *targetDetailsYAML) error {
 		delete(flyTargets.Targets, targetName)
-	}
-
-	return writeTargets(flyrcPath(), flyTargets)
+		return nil
+	})
 }
 
 func SaveTarget(
@@ -65,21 +77,28 @@ func SaveTarget(
 	token *TargetToken,
 	caCert string,
 ) error {
+	return updateTargets(func(flyTargets *targetDetailsYAML) error {
+		newInfo := flyTargets.Targets[targetName]
+		newInfo.API = api
+		newInfo.Insecure = insecure
+		newInfo.Token = token
+		newInfo.TeamName = teamName
+		newInfo.CACert = caCert
+
+		flyTargets.Targets[targetName] = newInfo
+		return nil
+	})
+}
+
+// LoadTLSPolicy returns the TLS policy configured for the target in .flyrc,
+// if it's there, so that logging in to it again is held to the same policy.
+func LoadTLSPolicy(targetName TargetName) (TLSPolicy, error) {
 	flyTargets, err := LoadTargets()
 	if err != nil {
-		return err
+		return TLSPolicy{}, err
 	}
 
-	flyrc := flyrcPath()
-	newInfo := flyTargets.Targets[targetName]
-	newInfo.API = api
-	newInfo.Insecure = insecure
-	newInfo.Token = token
-	newInfo.TeamName = teamName
-	newInfo.CACert = caCert
-
-	flyTargets.Targets[targetName] = newInfo
-	return writeTargets(flyrc, flyTargets)
+	return flyTargets.Targets[targetName].TLS, nil
 }
 
 func selectTarget(selectedTarget TargetName) (TargetProps, error) {
@@ -92,9 +111,26 @@ func selectTarget(selectedTarget TargetName) (TargetProps, error) {
 	}
 
 	target, ok := flyTargets.Targets[selectedTarget]
-	if !ok {
+
+	// a target can be given by the environment alone. one given another URL
+	// than it was saved with is another ATC, so what it was logged in to
+	// isn't used against it.
+	if api := os.Getenv(ATCURLEnv); api != "" {
+		if api != target.API {
+			target.API = api
+			target.Token = nil
+			target.FallbackAPIs = nil
+		}
+	} else if !ok {
 		return TargetProps{}, UnknownTargetError{selectedTarget}
 	}
+
+	if teamName := os.Getenv(TeamEnv); teamName != "" {
+		target.TeamName = teamName
+	} else if target.TeamName == "" {
+		target.TeamName = atc.DefaultTeamName
+	}
+
 	return target, nil
 }
 
@@ -119,43 +155,115 @@ func userHomeDir() string {
 	panic("could not detect home directory for .flyrc")
 }
 
+// CacheDir returns the directory in which fly keeps state for a target
+// between commands, e.g. the contents of execute's caches.
+func CacheDir(targetName TargetName) string {
+	return filepath.Join(userHomeDir(), ".fly", "cache", string(targetName))
+}
+
+// ResponseCacheDir returns the directory in which fly caches the ATC's
+// responses to listing commands for a few seconds.
+func ResponseCacheDir() string {
+	return filepath.Join(userHomeDir(), ".fly", "responses")
+}
+
+// InputsDir returns the directory in which fly keeps the inputs of builds it
+// executed against a target, so that they can be executed again.
+func InputsDir(targetName TargetName) string {
+	return filepath.Join(userHomeDir(), ".fly", "inputs", string(targetName))
+}
+
 func LoadTargets() (*targetDetailsYAML, error) {
+	flyTargets, migrated, err := loadTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	if migrated {
+		// save the migration for next time, or don't; the targets can be
+		// used either way, e.g. if the home directory is read-only
+		updateTargets(func(*targetDetailsYAML) error { return nil })
+	}
+
+	return flyTargets, nil
+}
+
+// loadTargets reads the targets, migrating them from an older .flyrc if need
+// be, and returns whether it did.
+func loadTargets() (*targetDetailsYAML, bool, error) {
 	var flyTargets *targetDetailsYAML
+	migrated := false
 
 	flyrc := flyrcPath()
 	if _, err := os.Stat(flyrc); err == nil {
 		flyTargetsBytes, err := ioutil.ReadFile(flyrc)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
+
+		flyTargetsBytes, migrated, err = migrateFlyrc(flyTargetsBytes)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse %s: %s", flyrc, err)
+		}
+
 		err = yaml.Unmarshal(flyTargetsBytes, &flyTargets)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	}
 
 	if flyTargets == nil {
-		return &targetDetailsYAML{Targets: map[TargetName]TargetProps{}}, nil
+		flyTargets = &targetDetailsYAML{Version: FlyrcVersion}
 	}
+
+	if flyTargets.Targets == nil {
+		flyTargets.Targets = map[TargetName]TargetProps{}
+	}
+
+	tokens, err := loadTokens()
+	if err != nil {
+		return nil, false, err
+	}
+
 	for name, targetProps := range flyTargets.Targets {
 		if targetProps.TeamName == "" {
 			targetProps.TeamName = atc.DefaultTeamName
-			flyTargets.Targets[name] = targetProps
 		}
+
+		// tokens used to be saved in .flyrc itself; those are still read,
+		// and moved to the token file the next time targets are saved
+		if token, found := tokens[name]; found {
+			targetProps.Token = token
+		}
+
+		flyTargets.Targets[name] = targetProps
 	}
-	return flyTargets, nil
+
+	return flyTargets, migrated, nil
 }
 
 func writeTargets(configFileLocation string, targetsToWrite *targetDetailsYAML) error {
-	yamlBytes, err := yaml.Marshal(targetsToWrite)
+	tokens := map[TargetName]*TargetToken{}
+	withoutTokens := &targetDetailsYAML{Version: FlyrcVersion, Targets: map[TargetName]TargetProps{}}
+
+	for name, targetProps := range targetsToWrite.Targets {
+		if targetProps.Token != nil {
+			tokens[name] = targetProps.Token
+		}
+
+		targetProps.Token = nil
+		withoutTokens.Targets[name] = targetProps
+	}
+
+	err := writeTokens(tokens)
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(configFileLocation, yamlBytes, os.ModePerm)
+	yamlBytes, err := yaml.Marshal(withoutTokens)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return writeFileAtomically(configFileLocation, yamlBytes, 0644)
 }
@@ -1,9 +1,12 @@
 package rc_test
 
 import (
+	"crypto/tls"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/concourse/atc"
 	"github.com/concourse/fly/rc"
@@ -58,6 +61,133 @@ var _ = Describe("Targets", func() {
 		})
 	})
 
+	Describe("LoadTLSPolicy", func() {
+		BeforeEach(func() {
+			flyrcContents := `targets:
+  some-target:
+    api: http://concourse.com
+    tls:
+      min_version: "1.2"`
+			ioutil.WriteFile(flyrc, []byte(flyrcContents), 0777)
+		})
+
+		It("returns the target's policy, for logging in to it again", func() {
+			policy, err := rc.LoadTLSPolicy("some-target")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal(rc.TLSPolicy{MinVersion: "1.2"}))
+
+			target, err := rc.NewUnauthenticatedTarget("some-target", "http://concourse.com", "main", false, "", policy, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target.TLSConfig().MinVersion).To(Equal(uint16(tls.VersionTLS12)))
+		})
+
+		It("returns no policy for a target that isn't saved", func() {
+			policy, err := rc.LoadTLSPolicy("bogus")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policy).To(Equal(rc.TLSPolicy{}))
+		})
+	})
+
+	Describe("token storage", func() {
+		var tokensFile string
+
+		BeforeEach(func() {
+			tokensFile = filepath.Join(userHomeDir(), ".flyrc-tokens")
+
+			err := rc.SaveTarget(
+				"some-target",
+				"http://concourse.com",
+				false,
+				"main",
+				&rc.TargetToken{Type: "Bearer", Value: "some-token"},
+				"",
+			)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(rc.TokenPassphraseEnv)
+		})
+
+		It("saves tokens to a separate file readable only by the user", func() {
+			info, err := os.Stat(tokensFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+			tokens, err := ioutil.ReadFile(tokensFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(tokens)).To(ContainSubstring("some-token"))
+
+			contents, err := ioutil.ReadFile(flyrc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).ToNot(ContainSubstring("some-token"))
+		})
+
+		It("loads the token back onto the target", func() {
+			targets, err := rc.LoadTargets()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(targets.Targets["some-target"].Token).To(Equal(&rc.TargetToken{
+				Type:  "Bearer",
+				Value: "some-token",
+			}))
+		})
+
+		Context("when the token file is readable by other users", func() {
+			BeforeEach(func() {
+				err := os.Chmod(tokensFile, 0644)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("refuses to load targets", func() {
+				_, err := rc.LoadTargets()
+				Expect(err).To(BeAssignableToTypeOf(rc.ErrInsecureTokenFile{}))
+				Expect(err.Error()).To(ContainSubstring("chmod 600 " + tokensFile))
+			})
+		})
+
+		Context("when a passphrase is set", func() {
+			BeforeEach(func() {
+				os.Setenv(rc.TokenPassphraseEnv, "some-passphrase")
+
+				err := rc.SaveTarget(
+					"some-target",
+					"http://concourse.com",
+					false,
+					"main",
+					&rc.TargetToken{Type: "Bearer", Value: "some-token"},
+					"",
+				)
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("encrypts the token file", func() {
+				tokens, err := ioutil.ReadFile(tokensFile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(tokens)).ToNot(ContainSubstring("some-token"))
+
+				targets, err := rc.LoadTargets()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(targets.Targets["some-target"].Token.Value).To(Equal("some-token"))
+			})
+
+			It("loads the targets without their tokens when the passphrase isn't set", func() {
+				os.Unsetenv(rc.TokenPassphraseEnv)
+
+				targets, err := rc.LoadTargets()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(targets.Targets).To(HaveKey(rc.TargetName("some-target")))
+				Expect(targets.Targets["some-target"].Token).To(BeNil())
+			})
+
+			It("fails to load with the wrong passphrase", func() {
+				os.Setenv(rc.TokenPassphraseEnv, "wrong-passphrase")
+
+				_, err := rc.LoadTargets()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
 	Describe("SaveTarget", func() {
 		Describe("CA Cert Flag", func() {
 			Describe("when 'ca_cert' is not set in the flyrc", func() {
@@ -167,5 +297,65 @@ var _ = Describe("Targets", func() {
 				Expect(err).To(Equal(rc.ErrNoTargetSpecified))
 			})
 		})
+
+		Context("when targets are saved concurrently", func() {
+			It("keeps every one of them, and their tokens", func() {
+				wg := new(sync.WaitGroup)
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+
+					go func(i int) {
+						defer GinkgoRecover()
+						defer wg.Done()
+
+						err := rc.SaveTarget(
+							rc.TargetName(fmt.Sprintf("target-%d", i)),
+							"http://concourse.com",
+							false,
+							"main",
+							&rc.TargetToken{Type: "Bearer", Value: fmt.Sprintf("token-%d", i)},
+							"",
+						)
+						Expect(err).ToNot(HaveOccurred())
+					}(i)
+				}
+
+				wg.Wait()
+
+				targets, err := rc.LoadTargets()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(targets.Targets).To(HaveLen(20))
+				Expect(targets.Targets["target-7"].Token).To(Equal(&rc.TargetToken{Type: "Bearer", Value: "token-7"}))
+
+				files, err := ioutil.ReadDir(tmpDir)
+				Expect(err).ToNot(HaveOccurred())
+
+				var names []string
+				for _, file := range files {
+					names = append(names, file.Name())
+				}
+
+				Expect(names).To(ConsistOf(".flyrc", ".flyrc-tokens", ".flyrc.lock"))
+			})
+		})
+
+		Context("when .flyrc is a symlink", func() {
+			It("saves to the file it links to", func() {
+				realFlyrc := filepath.Join(tmpDir, "dotfiles-flyrc")
+				Expect(ioutil.WriteFile(realFlyrc, []byte("targets: {}\n"), 0644)).To(Succeed())
+				Expect(os.Symlink(realFlyrc, flyrc)).To(Succeed())
+
+				err := rc.SaveTarget("some-target", "http://concourse.com", false, "main", nil, "")
+				Expect(err).ToNot(HaveOccurred())
+
+				info, err := os.Lstat(flyrc)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(info.Mode() & os.ModeSymlink).ToNot(BeZero())
+
+				contents, err := ioutil.ReadFile(realFlyrc)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring("some-target"))
+			})
+		})
 	})
 })
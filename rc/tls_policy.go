@@ -0,0 +1,92 @@
+package rc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+)
+
+// TLSPolicy restricts the TLS connections made to a target, e.g. to enforce
+// TLS 1.2+ even when the ATC would accept less. It is configured per target
+// in .flyrc:
+//
+//	targets:
+//	  ci:
+//	    api: https://ci.example.com
+//	    tls:
+//	      min_version: "1.2"
+//	      cipher_suites:
+//	      - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+type TLSPolicy struct {
+	MinVersion   string   `yaml:"min_version,omitempty"`
+	CipherSuites []string `yaml:"cipher_suites,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+}
+
+var tlsCipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_RSA_WITH_AES_128_GCM_SHA256":         tls.TLS_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_RSA_WITH_AES_256_GCM_SHA384":         tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+}
+
+// Validate returns an error if the policy names an unknown TLS version or
+// cipher suite.
+func (policy TLSPolicy) Validate() error {
+	_, _, err := policy.resolve()
+	return err
+}
+
+func (policy TLSPolicy) resolve() (uint16, []uint16, error) {
+	var minVersion uint16
+	if policy.MinVersion != "" {
+		version, found := tlsVersions[policy.MinVersion]
+		if !found {
+			return 0, nil, fmt.Errorf("unknown TLS version '%s' (expected 1.0, 1.1, or 1.2)", policy.MinVersion)
+		}
+
+		minVersion = version
+	}
+
+	var cipherSuites []uint16
+	for _, name := range policy.CipherSuites {
+		suite, found := tlsCipherSuites[name]
+		if !found {
+			return 0, nil, fmt.Errorf("unknown TLS cipher suite '%s'", name)
+		}
+
+		cipherSuites = append(cipherSuites, suite)
+	}
+
+	return minVersion, cipherSuites, nil
+}
+
+func (policy TLSPolicy) key() string {
+	return policy.MinVersion + "/" + strings.Join(policy.CipherSuites, ",")
+}
+
+func newTLSConfig(insecure bool, caCertPool *x509.CertPool, policy TLSPolicy) *tls.Config {
+	// the policy has already been validated when the target was loaded
+	minVersion, cipherSuites, _ := policy.resolve()
+
+	return &tls.Config{
+		InsecureSkipVerify: insecure,
+		RootCAs:            caCertPool,
+		MinVersion:         minVersion,
+		CipherSuites:       cipherSuites,
+	}
+}
@@ -0,0 +1,76 @@
+package rc
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ExpiresAt returns when the token expires, according to its "exp" claim.
+func (token *TargetToken) ExpiresAt() (time.Time, bool) {
+	claims, ok := token.claims()
+	if !ok {
+		return time.Time{}, false
+	}
+
+	expClaim, ok := claims["exp"]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	var intSeconds int64
+
+	floatSeconds, ok := expClaim.(float64)
+	if ok {
+		intSeconds = int64(floatSeconds)
+	} else {
+		stringSeconds, ok := expClaim.(string)
+		if !ok {
+			return time.Time{}, false
+		}
+
+		var err error
+		intSeconds, err = strconv.ParseInt(stringSeconds, 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+
+	return time.Unix(intSeconds, 0).UTC(), true
+}
+
+// TeamName returns the team the token was issued for.
+func (token *TargetToken) TeamName() string {
+	claims, _ := token.claims()
+
+	teamName, _ := claims["teamName"].(string)
+	return teamName
+}
+
+// IsAdmin returns whether the token was issued to an admin.
+func (token *TargetToken) IsAdmin() bool {
+	claims, _ := token.claims()
+
+	isAdmin, _ := claims["isAdmin"].(bool)
+	return isAdmin
+}
+
+// claims parses the token's claims. The token's signature is not verified;
+// only the ATC can do that, so these are only fit for informing the user or
+// failing early, never for enforcing anything.
+func (token *TargetToken) claims() (jwt.MapClaims, bool) {
+	if token == nil || token.Type == "" || token.Value == "" {
+		return nil, false
+	}
+
+	parsedToken, _ := jwt.Parse(token.Value, func(token *jwt.Token) (interface{}, error) {
+		return "", nil
+	})
+	if parsedToken == nil {
+		return nil, false
+	}
+
+	claims, ok := parsedToken.Claims.(jwt.MapClaims)
+	return claims, ok
+}
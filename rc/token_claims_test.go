@@ -0,0 +1,72 @@
+package rc_test
+
+import (
+	"time"
+
+	"github.com/concourse/fly/rc"
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TargetToken", func() {
+	Describe("ExpiresAt", func() {
+		signed := func(claims jwt.MapClaims) *rc.TargetToken {
+			value, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("some-key"))
+			Expect(err).ToNot(HaveOccurred())
+
+			return &rc.TargetToken{Type: "Bearer", Value: value}
+		}
+
+		It("returns the token's exp claim", func() {
+			expiry := time.Unix(1500000000, 0).UTC()
+
+			expiresAt, ok := signed(jwt.MapClaims{"exp": expiry.Unix()}).ExpiresAt()
+			Expect(ok).To(BeTrue())
+			Expect(expiresAt).To(Equal(expiry))
+		})
+
+		It("accepts an exp claim given as a string", func() {
+			expiresAt, ok := signed(jwt.MapClaims{"exp": "1500000000"}).ExpiresAt()
+			Expect(ok).To(BeTrue())
+			Expect(expiresAt).To(Equal(time.Unix(1500000000, 0).UTC()))
+		})
+
+		It("returns false when the token has no exp claim", func() {
+			_, ok := signed(jwt.MapClaims{"sub": "someone"}).ExpiresAt()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns false when the token is not a JWT", func() {
+			_, ok := (&rc.TargetToken{Type: "Bearer", Value: "some-token"}).ExpiresAt()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("returns false when there is no token", func() {
+			var token *rc.TargetToken
+
+			_, ok := token.ExpiresAt()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("TeamName and IsAdmin", func() {
+		It("return the token's team and admin claims", func() {
+			value, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+				"teamName": "main",
+				"isAdmin":  true,
+			}).SignedString([]byte("some-key"))
+			Expect(err).ToNot(HaveOccurred())
+
+			token := &rc.TargetToken{Type: "Bearer", Value: value}
+			Expect(token.TeamName()).To(Equal("main"))
+			Expect(token.IsAdmin()).To(BeTrue())
+		})
+
+		It("return nothing when the token is not a JWT", func() {
+			token := &rc.TargetToken{Type: "Bearer", Value: "some-token"}
+			Expect(token.TeamName()).To(BeEmpty())
+			Expect(token.IsAdmin()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,213 @@
+package rc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/concourse/fly/ui"
+
+	"golang.org/x/crypto/scrypt"
+	"gopkg.in/yaml.v2"
+)
+
+// TokenPassphraseEnv names the environment variable holding the passphrase
+// that tokens are encrypted with at rest. If unset, tokens are stored in
+// plaintext, protected only by the token file's permissions.
+const TokenPassphraseEnv = "FLY_TOKEN_PASSPHRASE"
+
+const encryptedTokensHeader = "fly-encrypted-tokens:v1\n"
+
+var ErrTokensEncrypted = fmt.Errorf("saved tokens are encrypted; set %s to decrypt them", TokenPassphraseEnv)
+
+var warnTokensEncrypted sync.Once
+
+type ErrInsecureTokenFile struct {
+	Path string
+	Mode os.FileMode
+}
+
+func NewErrInsecureTokenFile(path string, mode os.FileMode) ErrInsecureTokenFile {
+	return ErrInsecureTokenFile{
+		Path: path,
+		Mode: mode,
+	}
+}
+
+func (e ErrInsecureTokenFile) Error() string {
+	return fmt.Sprintf("refusing to read tokens from %s: it is accessible by other users (mode %04o). run the following to fix it:\n\n    chmod 600 %s\n", e.Path, e.Mode.Perm(), e.Path)
+}
+
+func tokensPath() string {
+	return filepath.Join(userHomeDir(), ".flyrc-tokens")
+}
+
+// loadTokens reads the tokens saved separately from .flyrc, if any. Tokens
+// that are encrypted while no passphrase is set are taken to be absent, so
+// that e.g. fly targets and fly login still work, with a warning.
+func loadTokens() (map[TargetName]*TargetToken, error) {
+	path := tokensPath()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[TargetName]*TargetToken{}, nil
+		}
+
+		return nil, err
+	}
+
+	// file modes don't reflect ACLs on Windows
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, NewErrInsecureTokenFile(path, info.Mode())
+	}
+
+	payload, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(payload, []byte(encryptedTokensHeader)) {
+		passphrase := os.Getenv(TokenPassphraseEnv)
+		if passphrase == "" {
+			warnTokensEncrypted.Do(func() {
+				fmt.Fprintf(ui.Stderr, "warning: %s; carrying on as if logged out of every target, and saving targets will replace them\n", ErrTokensEncrypted)
+			})
+
+			return map[TargetName]*TargetToken{}, nil
+		}
+
+		payload, err = decryptTokens(bytes.TrimPrefix(payload, []byte(encryptedTokensHeader)), passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := map[TargetName]*TargetToken{}
+	err = yaml.Unmarshal(payload, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func writeTokens(tokens map[TargetName]*TargetToken) error {
+	path := tokensPath()
+
+	if len(tokens) == 0 {
+		err := os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return nil
+	}
+
+	payload, err := yaml.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+
+	passphrase := os.Getenv(TokenPassphraseEnv)
+	if passphrase != "" {
+		payload, err = encryptTokens(payload, passphrase)
+		if err != nil {
+			return err
+		}
+
+		payload = append([]byte(encryptedTokensHeader), payload...)
+	}
+
+	return writeFileAtomically(path, payload, 0600)
+}
+
+const (
+	tokenSaltSize = 16
+	tokenKeySize  = 32
+)
+
+func tokenKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, tokenKeySize)
+}
+
+func encryptTokens(plaintext []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, tokenSaltSize)
+	_, err := io.ReadFull(rand.Reader, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := tokenCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := append(append(salt, nonce...), gcm.Seal(nil, nonce, plaintext, nil)...)
+
+	encoded := make([]byte, base64.StdEncoding.EncodedLen(len(sealed)))
+	base64.StdEncoding.Encode(encoded, sealed)
+
+	return append(encoded, '\n'), nil
+}
+
+func decryptTokens(encoded []byte, passphrase string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(encoded)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < tokenSaltSize {
+		return nil, errors.New("saved tokens are corrupt")
+	}
+
+	salt := sealed[:tokenSaltSize]
+
+	gcm, err := tokenCipher(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < tokenSaltSize+gcm.NonceSize() {
+		return nil, errors.New("saved tokens are corrupt")
+	}
+
+	nonce := sealed[tokenSaltSize : tokenSaltSize+gcm.NonceSize()]
+
+	plaintext, err := gcm.Open(nil, nonce, sealed[tokenSaltSize+gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt saved tokens; is %s correct?", TokenPassphraseEnv)
+	}
+
+	return plaintext, nil
+}
+
+func tokenCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := tokenKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -10,3 +10,17 @@ var ErroredColor = color.New(color.FgWhite, color.BgRed, color.Bold)
 var BlinkingErrorColor = color.New(color.BlinkSlow, color.FgWhite, color.BgRed, color.Bold)
 var AbortedColor = color.New(color.FgMagenta)
 var PausedColor = color.New(color.FgCyan)
+
+// StderrColor is what a build's logs written to stderr are shown in.
+var StderrColor = color.New(color.FgRed)
+
+// PrefixColors are cycled through to tell apart the output of builds
+// streamed at once.
+var PrefixColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgBlue),
+	color.New(color.FgGreen),
+	color.New(color.FgRed),
+}
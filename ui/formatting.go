@@ -20,3 +20,21 @@ func Embolden(message string, params ...interface{}) string {
 func WarningColor(message string, params ...interface{}) string {
 	return color.New(color.FgRed).SprintfFunc()(message, params...)
 }
+
+// FormatBytes formats a number of bytes in binary units, e.g. 1.5MiB.
+func FormatBytes(bytes uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%d%s", bytes, units[unit])
+	}
+
+	return fmt.Sprintf("%.1f%s", value, units[unit])
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Multiplexer interleaves the output of concurrent writers onto a single
+// destination, a whole line at a time, so that lines from different writers
+// never run into each other.
+type Multiplexer struct {
+	dst  io.Writer
+	lock sync.Mutex
+}
+
+func NewMultiplexer(dst io.Writer) *Multiplexer {
+	return &Multiplexer{dst: dst}
+}
+
+// Writer returns a writer whose lines are written to the destination with
+// the given prefix. Each writer must only be used by one goroutine at a
+// time, and flushed once done with.
+func (mux *Multiplexer) Writer(prefix string) *PrefixedWriter {
+	return &PrefixedWriter{mux: mux, prefix: []byte(prefix)}
+}
+
+func (mux *Multiplexer) writeLine(prefix []byte, line []byte) error {
+	mux.lock.Lock()
+	defer mux.lock.Unlock()
+
+	_, err := mux.dst.Write(append(append([]byte{}, prefix...), line...))
+	return err
+}
+
+type PrefixedWriter struct {
+	mux    *Multiplexer
+	prefix []byte
+
+	partial []byte
+}
+
+func (writer *PrefixedWriter) Write(p []byte) (int, error) {
+	writer.partial = append(writer.partial, p...)
+
+	for {
+		i := bytes.IndexByte(writer.partial, '\n')
+		if i < 0 {
+			break
+		}
+
+		err := writer.mux.writeLine(writer.prefix, writer.partial[:i+1])
+		if err != nil {
+			return 0, err
+		}
+
+		writer.partial = writer.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out any incomplete last line, terminating it.
+func (writer *PrefixedWriter) Flush() error {
+	if len(writer.partial) == 0 {
+		return nil
+	}
+
+	line := append(writer.partial, '\n')
+	writer.partial = nil
+
+	return writer.mux.writeLine(writer.prefix, line)
+}
@@ -0,0 +1,72 @@
+package ui_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Multiplexer", func() {
+	var (
+		out *bytes.Buffer
+		mux *Multiplexer
+	)
+
+	BeforeEach(func() {
+		out = new(bytes.Buffer)
+		mux = NewMultiplexer(out)
+	})
+
+	It("prefixes each line", func() {
+		writer := mux.Writer("[a] ")
+
+		fmt.Fprint(writer, "one\ntwo\n")
+		Expect(out.String()).To(Equal("[a] one\n[a] two\n"))
+	})
+
+	It("holds back incomplete lines until they are finished or flushed", func() {
+		writer := mux.Writer("[a] ")
+
+		fmt.Fprint(writer, "par")
+		Expect(out.String()).To(BeEmpty())
+
+		fmt.Fprint(writer, "tial\nlast")
+		Expect(out.String()).To(Equal("[a] partial\n"))
+
+		Expect(writer.Flush()).To(Succeed())
+		Expect(out.String()).To(Equal("[a] partial\n[a] last\n"))
+	})
+
+	It("never interleaves lines from concurrent writers", func() {
+		wg := new(sync.WaitGroup)
+		for _, name := range []string{"a", "b", "c"} {
+			wg.Add(1)
+			go func(name string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				writer := mux.Writer("[" + name + "] ")
+				for i := 0; i < 100; i++ {
+					fmt.Fprintf(writer, "%s%d", name, i)
+					fmt.Fprint(writer, "\n")
+				}
+			}(name)
+		}
+
+		wg.Wait()
+
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		Expect(lines).To(HaveLen(300))
+
+		for _, line := range lines {
+			Expect(line).To(MatchRegexp(`^\[[abc]\] [abc]\d+$`))
+			Expect(line[1]).To(Equal(line[4]))
+		}
+	})
+})
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StructuredOutput is set once fly's output is being written as records,
+// e.g. with --log-format json, so that output can carry fields of its own
+// by writing lines made by RecordLine.
+var StructuredOutput bool
+
+// recordMarker starts a line made by RecordLine. It's a control character
+// that doesn't otherwise turn up in output.
+const recordMarker = '\x1e'
+
+var ansiEscapes = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// Field is a key and value of a record, in addition to its time, level and
+// message.
+type Field struct {
+	Key   string
+	Value string
+}
+
+type encodedRecord struct {
+	Message string     `json:"msg"`
+	Fields  [][]string `json:"fields"`
+}
+
+// RecordLine encodes a line of output along with fields to add to its
+// record, e.g. where a line of a build's log came from. It's only to be
+// written when StructuredOutput is set.
+func RecordLine(message string, fields ...Field) string {
+	encoded := encodedRecord{Message: message}
+	for _, field := range fields {
+		encoded.Fields = append(encoded.Fields, []string{field.Key, field.Value})
+	}
+
+	payload, _ := json.Marshal(encoded)
+
+	return string(recordMarker) + string(payload) + "\n"
+}
+
+// RecordWriter writes each line written to it to its destination as a
+// record in the logfmt or json format, at the given level.
+type RecordWriter struct {
+	dst    io.Writer
+	json   bool
+	level  string
+	lock   *sync.Mutex
+	buffer []byte
+}
+
+// NewRecordWriter returns a RecordWriter. Writers sharing the lock never
+// write records at the same time.
+func NewRecordWriter(dst io.Writer, format string, level string, lock *sync.Mutex) *RecordWriter {
+	return &RecordWriter{
+		dst:   dst,
+		json:  format == "json",
+		level: level,
+		lock:  lock,
+	}
+}
+
+func (writer *RecordWriter) Write(p []byte) (int, error) {
+	writer.buffer = append(writer.buffer, p...)
+
+	for {
+		i := bytes.IndexByte(writer.buffer, '\n')
+		if i < 0 {
+			break
+		}
+
+		err := writer.writeRecord(string(writer.buffer[:i]))
+		if err != nil {
+			return 0, err
+		}
+
+		writer.buffer = writer.buffer[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush writes out any incomplete last line as a record.
+func (writer *RecordWriter) Flush() error {
+	if len(writer.buffer) == 0 {
+		return nil
+	}
+
+	line := string(writer.buffer)
+	writer.buffer = nil
+
+	return writer.writeRecord(line)
+}
+
+func (writer *RecordWriter) writeRecord(line string) error {
+	message := line
+
+	var fields []Field
+	if strings.HasPrefix(line, string(recordMarker)) {
+		var encoded encodedRecord
+		if err := json.Unmarshal([]byte(line[1:]), &encoded); err == nil {
+			message = encoded.Message
+			for _, field := range encoded.Fields {
+				if len(field) == 2 {
+					fields = append(fields, Field{Key: field[0], Value: field[1]})
+				}
+			}
+		}
+	}
+
+	message = strings.TrimRight(ansiEscapes.ReplaceAllString(message, ""), "\r")
+	if message == "" && fields == nil {
+		return nil
+	}
+
+	all := append([]Field{
+		{Key: "time", Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		{Key: "level", Value: writer.level},
+		{Key: "msg", Value: message},
+	}, fields...)
+
+	var record []byte
+	if writer.json {
+		record = jsonRecord(all)
+	} else {
+		record = logfmtRecord(all)
+	}
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	_, err := writer.dst.Write(record)
+	return err
+}
+
+func jsonRecord(fields []Field) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, _ := json.Marshal(field.Key)
+		value, _ := json.Marshal(field.Value)
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteString("}\n")
+
+	return buf.Bytes()
+}
+
+func logfmtRecord(fields []Field) []byte {
+	buf := new(bytes.Buffer)
+
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+
+		buf.WriteString(field.Key)
+		buf.WriteByte('=')
+
+		if field.Value == "" || strings.ContainsAny(field.Value, " =\"\\") || strconv.Quote(field.Value) != `"`+field.Value+`"` {
+			buf.WriteString(strconv.Quote(field.Value))
+		} else {
+			buf.WriteString(field.Value)
+		}
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes()
+}
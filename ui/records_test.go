@@ -0,0 +1,78 @@
+package ui_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecordWriter", func() {
+	var out *bytes.Buffer
+
+	BeforeEach(func() {
+		out = new(bytes.Buffer)
+	})
+
+	Context("in the logfmt format", func() {
+		It("writes each line as a record, quoting values as needed", func() {
+			writer := NewRecordWriter(out, "logfmt", "info", new(sync.Mutex))
+
+			fmt.Fprint(writer, "started\nsaid \"hi\" to=you\n")
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchRegexp(`^time=\S+ level=info msg=started$`))
+			Expect(lines[1]).To(MatchRegexp(`^time=\S+ level=info msg="said \\"hi\\" to=you"$`))
+		})
+
+		It("strips colors and skips blank lines", func() {
+			writer := NewRecordWriter(out, "logfmt", "error", new(sync.Mutex))
+
+			fmt.Fprint(writer, "\x1b[1mrunning tests\x1b[0m\n\n")
+
+			Expect(out.String()).To(MatchRegexp(`^time=\S+ level=error msg="running tests"\n$`))
+		})
+
+		It("only writes an incomplete line once flushed", func() {
+			writer := NewRecordWriter(out, "logfmt", "info", new(sync.Mutex))
+
+			fmt.Fprint(writer, "no newline")
+			Expect(out.String()).To(BeEmpty())
+
+			Expect(writer.Flush()).To(Succeed())
+			Expect(out.String()).To(ContainSubstring(`msg="no newline"`))
+		})
+	})
+
+	Context("in the json format", func() {
+		It("writes each line as an object, with the fields of lines made by RecordLine", func() {
+			writer := NewRecordWriter(out, "json", "info", new(sync.Mutex))
+
+			fmt.Fprint(writer, "plain\n")
+			fmt.Fprint(writer, RecordLine("hello from the task", Field{Key: "step", Value: "task: unit"}, Field{Key: "source", Value: "stdout"}))
+
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var plain map[string]string
+			Expect(json.Unmarshal([]byte(lines[0]), &plain)).To(Succeed())
+			Expect(plain).To(HaveKeyWithValue("level", "info"))
+			Expect(plain).To(HaveKeyWithValue("msg", "plain"))
+			Expect(plain).To(HaveKey("time"))
+
+			var log map[string]string
+			Expect(json.Unmarshal([]byte(lines[1]), &log)).To(Succeed())
+			Expect(log).To(HaveKeyWithValue("msg", "hello from the task"))
+			Expect(log).To(HaveKeyWithValue("step", "task: unit"))
+			Expect(log).To(HaveKeyWithValue("source", "stdout"))
+			Expect(lines[1]).To(MatchRegexp(`^\{"time":.*,"level":"info","msg":"hello from the task","step":"task: unit","source":"stdout"\}$`))
+		})
+	})
+})
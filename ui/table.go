@@ -36,7 +36,7 @@ func (table Table) Render(dst io.Writer, isPrintHeader bool) error {
 
 	if isPrintHeader || isTTY {
 		for i, column := range table.Headers {
-			columnWidth := len(column.Contents)
+			columnWidth := DisplayWidth(column.Contents)
 
 			if columnWidth > columnWidths[i] {
 				columnWidths[i] = columnWidth
@@ -46,7 +46,7 @@ func (table Table) Render(dst io.Writer, isPrintHeader bool) error {
 
 	for _, row := range table.Data {
 		for i, column := range row {
-			columnWidth := len(column.Contents)
+			columnWidth := DisplayWidth(column.Contents)
 
 			if columnWidth > columnWidths[i] {
 				columnWidths[i] = columnWidth
@@ -91,7 +91,7 @@ func (table Table) renderRow(dst io.Writer, row TableRow, widths map[int]int, is
 			return err
 		}
 
-		paddingSize := widths[i] - len(column.Contents)
+		paddingSize := widths[i] - DisplayWidth(column.Contents)
 		_, err = fmt.Fprintf(dst, strings.Repeat(" ", paddingSize))
 		if err != nil {
 			return err
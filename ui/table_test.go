@@ -100,6 +100,30 @@ var _ = Describe("Table", func() {
 			})
 		})
 
+		Context("when cells have wide characters or combining marks", func() {
+			BeforeEach(func() {
+				table.Data = []TableRow{
+					{{Contents: "日本語"}, {Contents: "r1c2"}},
+					{{Contents: "cafe\u0301"}, {Contents: "r2c2"}},
+					{{Contents: "ok ✅"}, {Contents: "r3c2"}},
+				}
+			})
+
+			It("pads them to the columns they take up", func() {
+				buf := gbytes.NewBuffer()
+
+				err := table.Render(buf, false)
+				Expect(err).ToNot(HaveOccurred())
+
+				expectedOutput := "" +
+					"日本語  r1c2\n" +
+					"cafe\u0301    r2c2\n" +
+					"ok ✅   r3c2\n"
+
+				Expect(string(buf.Contents())).To(Equal(expectedOutput))
+			})
+		})
+
 		Context("when the render method is called without a TTY but with print headers flag", func() {
 			It("prints the headers and the data without color", func() {
 				buf := gbytes.NewBuffer()
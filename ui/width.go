@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200d'
+	emojiVariation  = '\ufe0f'
+)
+
+// wideRanges are the ranges of characters a terminal gives two columns: the
+// East Asian wide and fullwidth characters, and emoji shown as pictures.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x16fe0, 0x16fe4},
+	{0x17000, 0x18aff}, {0x1b000, 0x1b16f}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f251}, {0x1f300, 0x1f64f},
+	{0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f90c, 0x1f9ff}, {0x1fa70, 0x1faff},
+	{0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// RuneWidth returns how many columns a terminal gives r on its own: 0 for
+// control characters and the marks that combine with the character before
+// them, 2 for wide characters and emoji, and 1 otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || (r >= 0x7f && r < 0xa0):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1160 && r <= 0x11ff:
+		// medial vowels and final consonants of a Hangul syllable
+		return 0
+	}
+
+	i := sort.Search(len(wideRanges), func(i int) bool {
+		return wideRanges[i][1] >= r
+	})
+
+	if i < len(wideRanges) && wideRanges[i][0] <= r {
+		return 2
+	}
+
+	return 1
+}
+
+// DisplayWidth returns how many columns s takes up on a terminal, ignoring
+// any colors it's printed with.
+func DisplayWidth(s string) int {
+	s = ansiEscapes.ReplaceAllString(s, "")
+
+	width := 0
+	previous := 0
+	joined := false
+
+	for _, r := range s {
+		w := RuneWidth(r)
+
+		switch {
+		case joined:
+			// the second half of an emoji sequence, e.g. a family, shows
+			// as part of the first
+			w = 0
+		case r == emojiVariation && previous == 1:
+			// a symbol asked to be shown as emoji becomes wide
+			w = 1
+		}
+
+		joined = r == zeroWidthJoiner
+
+		width += w
+		if w > 0 {
+			previous = w
+		}
+	}
+
+	return width
+}
+
+// PadRight pads s with spaces to take up width columns.
+func PadRight(s string, width int) string {
+	padding := width - DisplayWidth(s)
+	if padding <= 0 {
+		return s
+	}
+
+	return s + strings.Repeat(" ", padding)
+}
+
+// TruncateBytes returns the longest start of s that's at most limit bytes
+// long without cutting a character in two, leaving combining marks without
+// the character they mark, or leaving a color escape incomplete.
+func TruncateBytes(s string, limit int) string {
+	if limit >= len(s) {
+		return s
+	}
+
+	if limit <= 0 {
+		return ""
+	}
+
+	end := limit
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+
+	// back off to the start of the character the cut is in the middle of,
+	// e.g. one with combining marks or an emoji sequence
+	for end > 0 && inCluster(s, end) {
+		_, size := utf8.DecodeLastRuneInString(s[:end])
+		end -= size
+	}
+
+	if escape := strings.LastIndexByte(s[:end], '\x1b'); escape >= 0 {
+		loc := ansiEscapes.FindStringIndex(s[escape:end])
+		if loc == nil || loc[0] != 0 {
+			end = escape
+		}
+	}
+
+	return s[:end]
+}
+
+// inCluster returns whether s can't be cut at i without splitting what a
+// terminal shows as one character.
+func inCluster(s string, i int) bool {
+	next, _ := utf8.DecodeRuneInString(s[i:])
+	if next == zeroWidthJoiner || unicode.In(next, unicode.Mn, unicode.Me) {
+		return true
+	}
+
+	previous, _ := utf8.DecodeLastRuneInString(s[:i])
+	return previous == zeroWidthJoiner
+}
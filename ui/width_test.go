@@ -0,0 +1,64 @@
+package ui_test
+
+import (
+	. "github.com/concourse/fly/ui"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DisplayWidth", func() {
+	for _, example := range []struct {
+		description string
+		s           string
+		width       int
+	}{
+		{"ascii", "hello", 5},
+		{"East Asian wide characters", "日本語", 6},
+		{"fullwidth characters", "ｆｕｌｌ", 8},
+		{"Hangul syllables", "한국", 4},
+		{"combining marks", "cafe\u0301", 4},
+		{"emoji", "✅ ok", 5},
+		{"symbols shown as emoji", "❤️", 2},
+		{"joined emoji sequences", "👨‍👩‍👧", 2},
+		{"colors", "\x1b[31mred\x1b[0m", 3},
+	} {
+		example := example
+
+		It("counts the columns of "+example.description, func() {
+			Expect(DisplayWidth(example.s)).To(Equal(example.width))
+		})
+	}
+})
+
+var _ = Describe("PadRight", func() {
+	It("pads to the columns the string takes up", func() {
+		Expect(PadRight("日本", 6)).To(Equal("日本  "))
+	})
+
+	It("leaves strings already as wide", func() {
+		Expect(PadRight("日本語", 4)).To(Equal("日本語"))
+	})
+})
+
+var _ = Describe("TruncateBytes", func() {
+	It("leaves strings within the limit", func() {
+		Expect(TruncateBytes("日本", 6)).To(Equal("日本"))
+	})
+
+	It("cuts before a character the limit falls in", func() {
+		Expect(TruncateBytes("ab日本", 4)).To(Equal("ab"))
+	})
+
+	It("cuts before a character whose combining marks the limit falls in", func() {
+		Expect(TruncateBytes("cafe\u0301!", 5)).To(Equal("caf"))
+	})
+
+	It("cuts before an emoji sequence the limit falls in", func() {
+		Expect(TruncateBytes("hi 👨‍👩!", 10)).To(Equal("hi "))
+	})
+
+	It("cuts before a color the limit falls in", func() {
+		Expect(TruncateBytes("ok \x1b[31mred", 6)).To(Equal("ok "))
+	})
+})